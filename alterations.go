@@ -0,0 +1,272 @@
+package chords
+
+import (
+	"fmt"
+)
+
+// AlterationKind indicates how an Alteration modifies a chord.
+type AlterationKind int
+
+const (
+	// RaiseTone means a chord tone was raised by one half-step. For example,
+	// raising the 5th of C7 results in C+7.
+	RaiseTone AlterationKind = iota
+	// LowerTone means a chord tone was lowered by one half-step. For example,
+	// lowering the 3rd of C7 results in C-7.
+	LowerTone
+	// AddTone means a tone was added to the chord. For example, adding a 9th
+	// to C7 results in C9.
+	AddTone
+	// RemoveTone means a tone was removed from the chord. For example,
+	// removing the 9th from C9 results in C7.
+	RemoveTone
+)
+
+// String implements the Stringer interface.
+func (k AlterationKind) String() string {
+	switch k {
+	case RaiseTone:
+		return "raise"
+	case LowerTone:
+		return "lower"
+	case AddTone:
+		return "add"
+	case RemoveTone:
+		return "remove"
+	default:
+		return fmt.Sprintf("?(%d)", k)
+	}
+}
+
+// Alteration is a one-step modification of a chord. (See Chord.Alterations.)
+type Alteration struct {
+	// Kind indicates how the chord was modified.
+	Kind AlterationKind
+	// Tone is the chord tone that was modified. For tones that were raised,
+	// lowered, or removed, this is the tone before it was modified. For tones
+	// that were added, this is the tone that was added. Tones are relative to
+	// a major or minor triad, so the 5th of a diminished chord is a ♭5 and
+	// the 7th of a fully diminished chord is a ♭7.
+	Tone ChordTone
+	// Chord is the resulting chord, in canonical form.
+	Chord *Chord
+}
+
+// String implements the Stringer interface.
+func (a Alteration) String() string {
+	return fmt.Sprintf("%v %v: %v", a.Kind, a.Tone, a.Chord)
+}
+
+// Alterations enumerates all chords that are one step away from this chord.
+// A step is raising or lowering one of the chord's tones (other than the root)
+// by a half-step, adding an extension (a 6th, 7th, 9th, 11th, or 13th), or
+// removing an extension.
+//
+// Together, these form a lattice of chords that can be navigated by calling
+// Alterations on the resulting chords. Only valid chords are returned: a
+// chord is excluded if it fails validation, if two of its tones are
+// enharmonic equivalents (e.g. a ♯13 in a chord that also has a flat 7th), or
+// if it is the same as this chord or the result of an earlier alteration.
+// Tones are only altered within the range of one flat to one sharp, and a
+// 7th is only lowered to a diminished 7th (e.g. 𝄫7) in a fully diminished
+// chord. The bass note, if present, is unchanged.
+func (ch *Chord) Alterations() []Alteration {
+	base := ch.decompose()
+	seen := map[string]struct{}{}
+	orig := ch.Clone()
+	orig.Canonicalize()
+	seen[orig.String()] = struct{}{}
+
+	var alts []Alteration
+	addAlt := func(kind AlterationKind, tone ChordTone, d decomposedChord) {
+		res := d.chord(ch.Root, ch.Bass)
+		if !res.isSound() {
+			return
+		}
+		res.Canonicalize()
+		str := res.String()
+		if _, ok := seen[str]; ok {
+			return
+		}
+		seen[str] = struct{}{}
+		alts = append(alts, Alteration{Kind: kind, Tone: tone, Chord: res})
+	}
+
+	// third
+	switch base.triad {
+	case Maj3:
+		d := base.clone()
+		d.triad = Min3
+		addAlt(LowerTone, ChordTone{Val: 3}, d)
+	case Min3:
+		d := base.clone()
+		d.triad = Maj3
+		addAlt(RaiseTone, ChordTone{Val: 3}, d)
+	case Sus:
+		d := base.clone()
+		d.triad = Maj3
+		addAlt(AddTone, ChordTone{Val: 3}, d)
+	}
+
+	// other tones
+	for i, tn := range base.tones {
+		for _, kind := range []AlterationKind{RaiseTone, LowerTone} {
+			acc := tn.Acc + 1
+			if kind == LowerTone {
+				acc = tn.Acc - 1
+			}
+			if acc < Flat || acc > Sharp {
+				continue
+			}
+			d := base.clone()
+			d.tones[i].Acc = acc
+			addAlt(kind, tn, d)
+		}
+		if tn.Val != 5 {
+			d := base.clone()
+			d.tones = append(d.tones[:i], d.tones[i+1:]...)
+			addAlt(RemoveTone, tn, d)
+		}
+	}
+
+	// extensions
+	for _, v := range []int8{6, 7, 9, 11, 13} {
+		if base.has(v) {
+			continue
+		}
+		d := base.clone()
+		d.tones = append(d.tones, ChordTone{Val: v})
+		addAlt(AddTone, ChordTone{Val: v}, d)
+	}
+
+	return alts
+}
+
+// decomposedChord is a representation of a chord where the triad is only
+// major, minor, or suspended, and the fifth and seventh are explicit tones.
+// All tones are relative to the standard intervals for a major or minor triad
+// (e.g. a 7 with no accidental is a minor seventh).
+type decomposedChord struct {
+	triad TriadType
+	tones []ChordTone
+}
+
+func (ch *Chord) decompose() decomposedChord {
+	var d decomposedChord
+	switch ch.Triad {
+	case Maj3, Aug3:
+		d.triad = Maj3
+	case Sus:
+		d.triad = Sus
+	default:
+		d.triad = Min3
+	}
+	fifth := ch.Triad.fifthTone()
+	hasSeventh := ch.Triad == HDim || ch.Triad == FDim
+	seventh := ChordTone{Val: 7}
+	if ch.Triad == FDim {
+		seventh.Acc = Flat
+	}
+	for _, tn := range ch.ExtraTones {
+		v := tn.Val
+		switch {
+		case v == 5:
+			fifth = tn
+		case v == 7:
+			hasSeventh = true
+			seventh = tn
+			if ch.Triad == Dim3 || ch.Triad == FDim {
+				// these triads have a diminished 7th, which is a flat 7th
+				// relative to the minor triad
+				seventh.Acc--
+			}
+		case v > 7:
+			hasSeventh = true
+			fallthrough
+		default:
+			if !d.has(v) {
+				d.tones = append(d.tones, tn)
+			}
+		}
+	}
+	d.tones = append([]ChordTone{fifth}, d.tones...)
+	if hasSeventh {
+		d.tones = append(d.tones, seventh)
+	}
+	return d
+}
+
+func (d decomposedChord) has(val int8) bool {
+	for _, tn := range d.tones {
+		if tn.Val == val || (tn.Val < 7 && tn.Val+7 == val) || (tn.Val > 7 && tn.Val-7 == val) {
+			return true
+		}
+	}
+	return false
+}
+
+func (d decomposedChord) clone() decomposedChord {
+	return decomposedChord{triad: d.triad, tones: append([]ChordTone(nil), d.tones...)}
+}
+
+// chord re-assembles a decomposed chord into a chord with the given root and
+// bass. It returns nil if the decomposed chord has a combination of tones that
+// cannot be represented.
+func (d decomposedChord) chord(root, bass Note) *Chord {
+	ch := &Chord{Root: root, Triad: d.triad, Bass: bass}
+	var fifth ChordTone
+	var seventh *ChordTone
+	for i, tn := range d.tones {
+		switch tn.Val {
+		case 5:
+			fifth = tn
+		case 7:
+			seventh = &d.tones[i]
+		default:
+			ch.ExtraTones = append(ch.ExtraTones, tn)
+		}
+	}
+	if seventh != nil && seventh.Acc == Flat {
+		// only fully diminished chords may have a diminished 7th
+		if d.triad != Min3 || fifth.Acc != Flat {
+			return nil
+		}
+		ch.Triad = FDim
+		return ch
+	}
+	if fifth.Acc != Natural {
+		ch.ExtraTones = append(ch.ExtraTones, fifth)
+	}
+	if seventh != nil {
+		ch.ExtraTones = append(ch.ExtraTones, *seventh)
+	} else {
+		// without a 7th, higher extensions become "add" tones
+		for i := range ch.ExtraTones {
+			if ch.ExtraTones[i].Val > 7 {
+				ch.ExtraTones[i].Val -= 7
+			}
+		}
+	}
+	return ch
+}
+
+// isSound returns true if the chord is non-nil, valid, and has no two tones
+// that are enharmonic equivalents of one another.
+func (ch *Chord) isSound() bool {
+	if ch == nil || ch.Validate() != nil {
+		return false
+	}
+	notes := ch.Spell()
+	if ch.Bass.N != 0 {
+		notes = notes[1:]
+	}
+	var seen [12]bool
+	for _, n := range notes {
+		c := n.Cardinal()
+		if seen[c] {
+			return false
+		}
+		seen[c] = true
+	}
+	return true
+}
@@ -0,0 +1,34 @@
+package chords
+
+import (
+	"testing"
+)
+
+func TestChord_Alterations(t *testing.T) {
+	cases := map[string][]string{
+		"C7":    {"C-7", "C+7", "C7♭5", "C△7", "C", "C13", "C9", "C11"},
+		"Co":    {"Cø", "Cdim", "Co9", "Co11"},
+		"Csus4": {"C4", "Csus4♯5", "Csus4♭5", "Csus♯4", "C", "Csus4 6", "Csus4 7", "Csus2 4"},
+	}
+	for s, exp := range cases {
+		alts := MustParseChord(s).Alterations()
+		if len(alts) != len(exp) {
+			t.Errorf("Chord.Alterations for %s returned wrong number of results: %v", s, alts)
+			continue
+		}
+		for i, alt := range alts {
+			if alt.Chord.String() != exp[i] {
+				t.Errorf("Chord.Alterations for %s returned wrong chord at %d: %v != %s", s, i, alt.Chord, exp[i])
+			}
+		}
+	}
+
+	// navigating the lattice should not modify the original
+	ch := MustParseChord("A-7")
+	for _, alt := range ch.Alterations() {
+		alt.Chord.Alterations()
+	}
+	if ch.String() != "A-7" {
+		t.Errorf("Chord.Alterations modified chord: %v", ch)
+	}
+}
@@ -0,0 +1,649 @@
+package chords
+
+import (
+	"bytes"
+	"fmt"
+	"math/bits"
+	"strings"
+	"unicode/utf8"
+)
+
+// ScaleChord returns the given chord relative to the key's tonic. For example,
+// in the key of C, the chord D-7 is a minor 7th chord whose root is a major
+// second above the tonic: ii7.
+func (k Key) ScaleChord(ch *Chord) *ScaleChord {
+	return &ScaleChord{
+		Root:       k.Tonic.IntervalTo(ch.Root),
+		InMinorKey: k.Minor,
+		Type:       *ch.ChordType(),
+	}
+}
+
+// CadenceKind identifies a type of cadence: a chord progression that ends a
+// musical phrase.
+type CadenceKind int
+
+const (
+	// AuthenticCadence is a dominant chord resolving to the tonic: V-I.
+	AuthenticCadence CadenceKind = iota
+	// PlagalCadence is a subdominant chord resolving to the tonic: IV-I. This
+	// is also known as the "amen" cadence.
+	PlagalCadence
+	// HalfCadence is a phrase that ends on the dominant chord, without
+	// resolving to the tonic.
+	HalfCadence
+	// DeceptiveCadence is a dominant chord that resolves to the submediant,
+	// instead of to the tonic: V-vi.
+	DeceptiveCadence
+)
+
+// String implements the Stringer interface.
+func (k CadenceKind) String() string {
+	switch k {
+	case AuthenticCadence:
+		return "authentic"
+	case PlagalCadence:
+		return "plagal"
+	case HalfCadence:
+		return "half"
+	case DeceptiveCadence:
+		return "deceptive"
+	default:
+		return fmt.Sprintf("?(%d)", k)
+	}
+}
+
+// Cadence is a cadence found in a sequence of chords. (See FindCadences.)
+type Cadence struct {
+	// Kind is the type of cadence.
+	Kind CadenceKind
+	// Start is the index of the penultimate chord of the cadence (e.g. the V
+	// chord in an authentic cadence).
+	Start int
+	// End is the index of the final chord of the cadence (e.g. the I chord in
+	// an authentic cadence).
+	End int
+}
+
+// String implements the Stringer interface.
+func (c Cadence) String() string {
+	return fmt.Sprintf("%v cadence @ %d-%d", c.Kind, c.Start, c.End)
+}
+
+// FindCadences finds the cadences in the given sequence of chords, which are
+// in the given key. The cadences are returned in the order in which they
+// appear. A chord that is immediately repeated is treated as a single chord, so
+// the sequence G7 G7 C has an authentic cadence from the second G7 to C.
+//
+// The dominant chord is a major triad or dominant 7th chord whose root is the
+// 5th degree of the key (in minor keys, this is the chord borrowed from the
+// harmonic minor scale). The subdominant is a major or minor chord on the 4th
+// degree, and the tonic is a major or minor chord on the 1st degree. Chords
+// that are in inversion (i.e. that have a bass note other than their root) do
+// not participate in cadences.
+//
+// A half cadence is a chord that moves to the dominant, where the dominant is
+// either the final chord in the sequence or is followed by a chord other than
+// the tonic or the submediant. (Other dominant chords, like the G7 in the
+// sequence G G7, are skipped when finding the chord that follows.)
+func FindCadences(key Key, chs []*Chord) []Cadence {
+	scs := make([]*ScaleChord, len(chs))
+	for i, ch := range chs {
+		scs[i] = key.ScaleChord(ch)
+	}
+	// next returns the index of the next chord that is different from the
+	// one at the given index, or -1 if there is no such chord
+	next := func(i int) int {
+		for j := i + 1; j < len(chs); j++ {
+			if chs[j].String() != chs[i].String() {
+				return j
+			}
+		}
+		return -1
+	}
+
+	var cadences []Cadence
+	for i := range scs {
+		j := next(i)
+		if j == -1 {
+			break
+		}
+		if j != i+1 {
+			// not the last of a run of repeated chords
+			continue
+		}
+		from, to := scs[i], scs[j]
+		switch {
+		case from.isCadentialDominant() && to.isTonic():
+			cadences = append(cadences, Cadence{Kind: AuthenticCadence, Start: i, End: j})
+		case from.isCadentialDominant() && to.isSubmediant():
+			cadences = append(cadences, Cadence{Kind: DeceptiveCadence, Start: i, End: j})
+		case from.isSubdominant() && to.isTonic():
+			cadences = append(cadences, Cadence{Kind: PlagalCadence, Start: i, End: j})
+		case !from.isCadentialDominant() && to.isCadentialDominant():
+			k := next(j)
+			for k != -1 && scs[k].isCadentialDominant() {
+				k = next(k)
+			}
+			if k == -1 || (!scs[k].isTonic() && !scs[k].isSubmediant()) {
+				cadences = append(cadences, Cadence{Kind: HalfCadence, Start: i, End: j})
+			}
+		}
+	}
+	return cadences
+}
+
+// isRootPosition returns true if the chord has no bass note or if its bass
+// note is its root.
+func (s *ScaleChord) isRootPosition() bool {
+	return s.Type.Bass.Val <= 1 && s.Type.Bass.Offset == 0
+}
+
+// isCadentialDominant returns true if the chord is a root-position V or V7.
+func (s *ScaleChord) isCadentialDominant() bool {
+	return s.Root == Interval{Val: 5} && s.Type.isDominant() && s.isRootPosition()
+}
+
+// isTonic returns true if the chord is a root-position I or i.
+func (s *ScaleChord) isTonic() bool {
+	return s.Root == Interval{Val: 1} && s.Type.isMajorOrMinor() && s.isRootPosition()
+}
+
+// isSubdominant returns true if the chord is a root-position IV or iv.
+func (s *ScaleChord) isSubdominant() bool {
+	return s.Root == Interval{Val: 4} && s.Type.isMajorOrMinor() && s.isRootPosition()
+}
+
+// isSubmediant returns true if the chord's root is the 6th degree of the key:
+// a major 6th above the tonic in major keys, and a minor 6th in minor keys.
+func (s *ScaleChord) isSubmediant() bool {
+	if s.InMinorKey {
+		return s.Root == Interval{Val: 6, Offset: -1}
+	}
+	return s.Root == Interval{Val: 6}
+}
+
+// isMajorOrMinor returns true if the chord type is a major or minor chord
+// with an unaltered 5th.
+func (t ChordType) isMajorOrMinor() bool {
+	if t.Triad != Maj3 && t.Triad != Min3 {
+		return false
+	}
+	for _, tn := range t.ExtraTones {
+		if tn.Val == 5 && tn.Acc != Natural {
+			return false
+		}
+	}
+	return true
+}
+
+// IdiomKind identifies a common chord pattern. (See FindIdioms.)
+type IdiomKind int
+
+const (
+	// TwoFiveOne is a ii-V-I progression in a major key, like D-7 G7 C△7.
+	TwoFiveOne IdiomKind = iota
+	// MinorTwoFiveOne is a iiø-V-i progression in a minor key, like Dø G7 C-7.
+	MinorTwoFiveOne
+	// Turnaround is a I-vi-ii-V or iii-VI-ii-V progression, which leads back
+	// to the tonic at the end of a section, like C△7 A7 D-7 G7. The vi and ii
+	// chords may be minor or dominant chords.
+	Turnaround
+)
+
+// String implements the Stringer interface.
+func (k IdiomKind) String() string {
+	switch k {
+	case TwoFiveOne:
+		return "ii-V-I"
+	case MinorTwoFiveOne:
+		return "iiø-V-i"
+	case Turnaround:
+		return "turnaround"
+	default:
+		return fmt.Sprintf("?(%d)", k)
+	}
+}
+
+// Idiom is a common chord pattern found in a sequence of chords. (See
+// FindIdioms.)
+type Idiom struct {
+	// Kind is the type of pattern.
+	Kind IdiomKind
+	// Key is the key in which the pattern occurs. This is the key of the
+	// chord to which a ii-V-I resolves or to which a turnaround leads.
+	Key Key
+	// TritoneSub is true if the V chord of the pattern is replaced by its
+	// tritone substitution: the dominant chord whose root is a tritone away,
+	// like D♭7 instead of G7 in the key of C.
+	TritoneSub bool
+	// Start is the index of the first chord in the pattern.
+	Start int
+	// End is the index of the last chord in the pattern. If the pattern's last
+	// chord is repeated, this is the index of the last repetition.
+	End int
+}
+
+// String implements the Stringer interface.
+func (i Idiom) String() string {
+	sub := ""
+	if i.TritoneSub {
+		sub = " (tritone sub)"
+	}
+	return fmt.Sprintf("%v%s in %v @ %d-%d", i.Kind, sub, i.Key, i.Start, i.End)
+}
+
+// FindIdioms scans the given sequence of chords for common jazz patterns:
+// ii-V-I progressions (in both major and minor keys) and turnarounds,
+// including variations where the V chord is replaced by its tritone
+// substitution. Unlike FindCadences, no key is needed: each pattern is
+// reported along with the key that it implies, so patterns that tonicize
+// other keys, like the ii-V-I into the IV chord, are found, too.
+//
+// Chords are identified by their quality, ignoring extensions: so D-9 G13
+// C△7 and D- G7 C are both ii-V-I progressions. A chord that is immediately
+// repeated is treated as a single chord. Patterns may overlap, and they are
+// returned in the order in which they start; patterns that start at the same
+// chord are ordered by kind.
+func FindIdioms(chs []*Chord) []Idiom {
+	type run struct {
+		ch         *Chord
+		q          chordQuality
+		start, end int
+	}
+	var runs []run
+	for i, ch := range chs {
+		if len(runs) > 0 && runs[len(runs)-1].ch.String() == ch.String() {
+			runs[len(runs)-1].end = i
+			continue
+		}
+		runs = append(runs, run{ch: ch, q: qualityOf(ch), start: i, end: i})
+	}
+	steps := func(from, to run) int8 {
+		return posMod(to.ch.Root.Cardinal()-from.ch.Root.Cardinal(), 12)
+	}
+
+	var idioms []Idiom
+	for i := range runs {
+		if i+2 < len(runs) {
+			two, five, one := runs[i], runs[i+1], runs[i+2]
+			sub := steps(two, five) == 11 && steps(five, one) == 11
+			if five.q.isDominant() && (sub || (steps(two, five) == 5 && steps(five, one) == 5)) {
+				idiom := Idiom{TritoneSub: sub, Start: two.start, End: one.end}
+				switch {
+				case two.q == minorQuality && one.q.isTonicMajor():
+					idiom.Kind = TwoFiveOne
+					idiom.Key = Key{Tonic: one.ch.Root}
+					idioms = append(idioms, idiom)
+				case two.q == halfDiminishedQuality && one.q == minorQuality:
+					idiom.Kind = MinorTwoFiveOne
+					idiom.Key = Key{Tonic: one.ch.Root, Minor: true}
+					idioms = append(idioms, idiom)
+				}
+			}
+		}
+		if i+3 < len(runs) {
+			first, six, two, five := runs[i], runs[i+1], runs[i+2], runs[i+3]
+			// the tonic is a major second below the ii chord
+			tonic := two.ch.Root.Transpose(Interval{Val: 7, Offset: -1})
+			fromTonic := func(r run) int8 {
+				return posMod(r.ch.Root.Cardinal()-tonic.Cardinal(), 12)
+			}
+			firstOK := (fromTonic(first) == 0 && first.q.isTonicMajor()) ||
+				(fromTonic(first) == 4 && first.q == minorQuality)
+			sixOK := fromTonic(six) == 9 && (six.q == minorQuality || six.q.isDominant())
+			twoOK := two.q == minorQuality || two.q.isDominant()
+			sub := fromTonic(five) == 1
+			fiveOK := five.q.isDominant() && (sub || fromTonic(five) == 7)
+			if firstOK && sixOK && twoOK && fiveOK {
+				idioms = append(idioms, Idiom{
+					Kind:       Turnaround,
+					Key:        Key{Tonic: tonic},
+					TritoneSub: sub,
+					Start:      first.start,
+					End:        five.end,
+				})
+			}
+		}
+	}
+	return idioms
+}
+
+// chordQuality is the basic quality of a chord, which determines the role it
+// can play in common chord patterns.
+type chordQuality int
+
+const (
+	otherQuality chordQuality = iota
+	// major triads and 6 chords, with no 7th
+	majorTriadQuality
+	// major 7th chords
+	majorSeventhQuality
+	// chords with a major 3rd and a minor 7th
+	dominantQuality
+	// minor triads and minor chords with a 6th or a major or minor 7th
+	minorQuality
+	// minor chords with a ♭5 and minor 7th
+	halfDiminishedQuality
+)
+
+// isTonicMajor returns true for chords that can be a major I chord.
+func (q chordQuality) isTonicMajor() bool {
+	return q == majorTriadQuality || q == majorSeventhQuality
+}
+
+// isDominant returns true for chords that can be a V chord.
+func (q chordQuality) isDominant() bool {
+	return q == majorTriadQuality || q == dominantQuality
+}
+
+// qualityOf returns the quality of the given chord, ignoring extensions
+// other than the 7th.
+func qualityOf(ch *Chord) chordQuality {
+	d := ch.decompose()
+	fifth := d.tones[0].Acc
+	hasSeventh, seventh := false, Natural
+	for _, tn := range d.tones {
+		if tn.Val == 7 {
+			hasSeventh, seventh = true, tn.Acc
+		}
+	}
+	switch {
+	case d.triad == Maj3 && fifth == Natural && !hasSeventh:
+		return majorTriadQuality
+	case d.triad == Maj3 && fifth == Natural && seventh == Sharp:
+		return majorSeventhQuality
+	case d.triad == Maj3 && hasSeventh && seventh == Natural:
+		return dominantQuality
+	case d.triad == Min3 && fifth == Natural && seventh != Flat:
+		return minorQuality
+	case d.triad == Min3 && fifth == Flat && hasSeventh && seventh == Natural:
+		return halfDiminishedQuality
+	default:
+		return otherQuality
+	}
+}
+
+// candidateKeys are the keys considered by InferKey and SegmentKeys, ordered
+// from the simplest key signature to the most complex, with major keys before
+// minor keys that share the same signature. When keys fit a sequence of chords
+// equally well, the earlier key is preferred.
+var candidateKeys = func() []Key {
+	keys := make([]Key, 0, 24)
+	for _, f := range []int{0, 1, -1, 2, -2, 3, -3, 4, -4, 5, -5, 6} {
+		keys = append(keys, keyWithFifths(f, false), keyWithFifths(f, true))
+	}
+	return keys
+}()
+
+// keyFit scores how well a chord fits in a key. Chords whose tones are all in
+// the key score one point, plus another point if the chord is the key's tonic
+// chord. Other chords lose a point for each tone that is not in the key. For
+// minor keys, the raised 7th of the harmonic minor scale is considered to be
+// in the key.
+func keyFit(key Key, keySet uint16, ch *Chord) int {
+	set := pitchClasses(ch.Spell())
+	if outside := set &^ keySet; outside != 0 {
+		return -bits.OnesCount16(outside)
+	}
+	q := qualityOf(ch)
+	if ch.Root.Cardinal() == key.Tonic.Cardinal() &&
+		((key.Minor && q == minorQuality) || (!key.Minor && q.isTonicMajor())) {
+		return 2
+	}
+	return 1
+}
+
+// keyPitchClasses returns the pitch classes considered to be in the given key
+// by keyFit.
+func keyPitchClasses(key Key) uint16 {
+	set := pitchClasses(key.Scale().Spell())
+	if key.Minor {
+		set |= pitchClasses([]Note{key.Tonic.Transpose(Interval{Val: 7})})
+	}
+	return set
+}
+
+// InferKey returns the key that best fits the given sequence of chords. Each
+// chord is scored by whether its tones are all in the key, with a bonus for
+// the key's tonic chord. For minor keys, chords with the raised 7th of the
+// harmonic minor scale, like the V7 chord, are considered to be in the key.
+// If several keys fit equally well, the one with the simplest key signature
+// is returned, preferring major keys. For a sequence that changes keys, use
+// SegmentKeys instead.
+//
+// If no chords are given, the key of C major is returned.
+func InferKey(chs ...*Chord) Key {
+	best, bestScore := candidateKeys[0], 0
+	for i, key := range candidateKeys {
+		keySet := keyPitchClasses(key)
+		score := 0
+		for _, ch := range chs {
+			score += keyFit(key, keySet, ch)
+		}
+		if i == 0 || score > bestScore {
+			best, bestScore = key, score
+		}
+	}
+	return best
+}
+
+// KeyRegion is a span of a sequence of chords that is in a single key. (See
+// SegmentKeys.)
+type KeyRegion struct {
+	// Key is the key of the region.
+	Key Key
+	// Start is the index of the first chord in the region.
+	Start int
+	// End is the index of the last chord in the region.
+	End int
+	// Pivots are candidate pivot chords for the modulation into this region:
+	// the chords at the end of the previous region that are diatonic both to
+	// this key and to the key of the previous region. For example, in a modulation
+	// from C major to G major, an A- chord is both vi in C and ii in G. These
+	// are indexes into the sequence of chords, in ascending order. This is
+	// always empty for the first region.
+	Pivots []int
+}
+
+// String implements the Stringer interface.
+func (r KeyRegion) String() string {
+	return fmt.Sprintf("%v @ %d-%d", r.Key, r.Start, r.End)
+}
+
+// keyChangePenalty is the score lost by SegmentKeys each time the key
+// changes. It is large enough that a single chord that does not fit the
+// current key (like a secondary dominant) does not cause a modulation.
+const keyChangePenalty = 4
+
+// SegmentKeys divides the given sequence of chords into regions, each of
+// which is in a single key. Each region after the first represents a
+// modulation, and includes the candidate pivot chords for the modulation.
+//
+// The chords are scored against every key the same way as InferKey, and the
+// regions are chosen to maximize the total score, with a penalty for each
+// change of key. So a key change is only reported when several chords fit the
+// new key better than the old one; brief excursions, like a secondary dominant
+// or a borrowed chord, stay in the surrounding key. Chords that fit both keys
+// equally well are assigned to the earlier region.
+//
+// If no chords are given, this returns nil.
+func SegmentKeys(chs []*Chord) []KeyRegion {
+	if len(chs) == 0 {
+		return nil
+	}
+	keySets := make([]uint16, len(candidateKeys))
+	for k, key := range candidateKeys {
+		keySets[k] = keyPitchClasses(key)
+	}
+	// Viterbi: scores[k] is the best total score for the chords so far where
+	// the current chord is in key k; from[i][k] is the key of the previous
+	// chord on that best path.
+	scores := make([]int, len(candidateKeys))
+	from := make([][]int, len(chs))
+	for i, ch := range chs {
+		from[i] = make([]int, len(candidateKeys))
+		next := make([]int, len(candidateKeys))
+		bestPrev := 0
+		for k := range scores {
+			if scores[k] > scores[bestPrev] {
+				bestPrev = k
+			}
+		}
+		for k, key := range candidateKeys {
+			// on a tie, prefer to change keys as late as possible, so that
+			// ambiguous chords stay in the earlier key
+			prev := k
+			if i > 0 && scores[bestPrev]-keyChangePenalty >= scores[k] {
+				prev = bestPrev
+			}
+			from[i][k] = prev
+			next[k] = scores[prev] + keyFit(key, keySets[k], ch)
+			if prev != k {
+				next[k] -= keyChangePenalty
+			}
+		}
+		scores = next
+	}
+
+	// walk the best path backwards
+	keys := make([]int, len(chs))
+	for k := range scores {
+		if scores[k] > scores[keys[len(chs)-1]] {
+			keys[len(chs)-1] = k
+		}
+	}
+	for i := len(chs) - 1; i > 0; i-- {
+		keys[i-1] = from[i][keys[i]]
+	}
+
+	var regions []KeyRegion
+	for i, k := range keys {
+		if i > 0 && k == keys[i-1] {
+			regions[len(regions)-1].End = i
+			continue
+		}
+		region := KeyRegion{Key: candidateKeys[k], Start: i, End: i}
+		if i > 0 {
+			both := keySets[k] & keySets[keys[i-1]]
+			for j := i - 1; j >= regions[len(regions)-1].Start; j-- {
+				if set := pitchClasses(chs[j].Spell()); set&both != set {
+					break
+				}
+				region.Pivots = append([]int{j}, region.Pivots...)
+			}
+		}
+		regions = append(regions, region)
+	}
+	return regions
+}
+
+// ChordAnalysis is the roman numeral analysis of one chord in a progression.
+// (See Progression.Analyze.)
+type ChordAnalysis struct {
+	// Chord is the analyzed chord.
+	Chord *Chord
+	// Bar is the index of the bar that contains the chord.
+	Bar int
+	// Beat is the beat of the bar on which the chord starts. The first beat
+	// is zero.
+	Beat int
+	// ScaleChord is the chord relative to the key's tonic.
+	ScaleChord *ScaleChord
+	// Function is the chord's harmonic function in the key. This indicates
+	// whether the chord is a secondary dominant or a borrowed chord.
+	Function HarmonicFunction
+	// Applied is the chord relative to the chord that it tonicizes, if it is
+	// a secondary dominant, like V7/ii. Otherwise, it is nil.
+	Applied *AppliedChord
+	// Numeral is the chord's roman numeral. This is the string form of
+	// Applied, if it is not nil, or else of ScaleChord.
+	Numeral string
+}
+
+// Analysis is the roman numeral analysis of a progression in a key. (See
+// Progression.Analyze.)
+type Analysis struct {
+	// Key is the key in which the progression was analyzed.
+	Key Key
+	// Chords is the analysis of each chord, in the order they are played.
+	Chords []ChordAnalysis
+}
+
+// String implements the Stringer interface. The result has a header line with
+// the key, followed by a line for each chord with its position (the bar and
+// beat, both starting at 1), the chord, its roman numeral, and its function.
+// The columns are aligned. For example:
+//
+//	key of C
+//	1.1  C△7   I△7    tonic
+//	2.1  A7    V7/ii  dominant (secondary of 2)
+//	3.1  D-7   ii7    subdominant
+//	3.3  B♭7   ♭VII7  subdominant (borrowed from aeolian)
+func (a *Analysis) String() string {
+	rows := make([][3]string, len(a.Chords))
+	var widths [3]int
+	for i, ca := range a.Chords {
+		rows[i] = [3]string{fmt.Sprintf("%d.%d", ca.Bar+1, ca.Beat+1), ca.Chord.String(), ca.Numeral}
+		for j, col := range rows[i] {
+			if n := utf8.RuneCountInString(col); n > widths[j] {
+				widths[j] = n
+			}
+		}
+	}
+	var b bytes.Buffer
+	fmt.Fprintf(&b, "key of %v\n", a.Key)
+	for i, row := range rows {
+		for j, col := range row {
+			b.WriteString(col)
+			b.WriteString(strings.Repeat(" ", widths[j]-utf8.RuneCountInString(col)+2))
+		}
+		b.WriteString(a.Chords[i].Function.String())
+		b.WriteByte('\n')
+	}
+	return b.String()
+}
+
+// Analyze returns the roman numeral analysis of the progression in the given
+// key. If the key is not present (its Tonic is the zero value), the key is
+// inferred from the progression's chords using InferKey.
+//
+// Each chord is converted to a ScaleChord relative to the key's tonic and
+// classified with ScaleChord.Function. Repeats are not expanded, so each
+// chord as written appears in the analysis once.
+func (p *Progression) Analyze(key Key) *Analysis {
+	if key.Tonic.N == 0 {
+		key = InferKey(p.Chords()...)
+	}
+	a := &Analysis{Key: key}
+	var diatonic []*ScaleChord
+	for i, bar := range p.Bars {
+		beat := 0
+		for _, bc := range bar.Chords {
+			sc := key.ScaleChord(bc.Chord)
+			fn := sc.Function(key)
+			ca := ChordAnalysis{
+				Chord:      bc.Chord,
+				Bar:        i,
+				Beat:       beat,
+				ScaleChord: sc,
+				Function:   fn,
+				Numeral:    sc.String(),
+			}
+			if fn.Secondary != 0 {
+				if diatonic == nil {
+					diatonic = key.DiatonicChords()
+				}
+				target := diatonic[fn.Secondary-1]
+				ca.Applied = &AppliedChord{
+					Chord:  &ScaleChord{Root: Interval{Val: 5}, InMinorKey: target.Type.hasMinorThird(), Type: sc.Type},
+					Target: target,
+				}
+				ca.Numeral = ca.Applied.String()
+			}
+			a.Chords = append(a.Chords, ca)
+			beat += bc.Beats
+		}
+	}
+	return a
+}
@@ -0,0 +1,229 @@
+package chords
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func parseChords(s string) []*Chord {
+	var chs []*Chord
+	for _, str := range strings.Fields(s) {
+		chs = append(chs, MustParseChord(str))
+	}
+	return chs
+}
+
+func TestFindCadences(t *testing.T) {
+	cases := []struct {
+		key, chords string
+		exp         string
+	}{
+		{"C", "C F G7 C", "authentic cadence @ 2-3"},
+		{"C", "C F G G7 C", "authentic cadence @ 3-4"},
+		{"C", "C G7 G7 C", "authentic cadence @ 2-3"},
+		{"C", "C F C", "plagal cadence @ 1-2"},
+		{"C", "C A- D- G", "half cadence @ 2-3"},
+		{"C", "C D- G A- F G7 C", "deceptive cadence @ 2-3, authentic cadence @ 5-6"},
+		{"C", "C D- G E-", "half cadence @ 1-2"},
+		{"C", "C F/C C", ""},
+		{"A-", "A- D- E7 A-", "authentic cadence @ 2-3"},
+		{"A-", "A- D- E7 F", "deceptive cadence @ 2-3"},
+		{"A-", "A- D- E- A-", ""},
+		{"A-", "A- D- A-", "plagal cadence @ 1-2"},
+	}
+	for _, tc := range cases {
+		cadences := FindCadences(MustParseKey(tc.key), parseChords(tc.chords))
+		strs := make([]string, len(cadences))
+		for i, c := range cadences {
+			strs[i] = c.String()
+		}
+		if actual := strings.Join(strs, ", "); actual != tc.exp {
+			t.Errorf("FindCadences for %s in %s returned wrong value: %q != %q", tc.chords, tc.key, actual, tc.exp)
+		}
+	}
+}
+
+func TestFindIdioms(t *testing.T) {
+	cases := []struct {
+		chords string
+		exp    string
+	}{
+		{"D-7 G7 C△7", "ii-V-I in C @ 0-2"},
+		{"D-9 G13 C6", "ii-V-I in C @ 0-2"},
+		{"Dø G7♭9 C-7", "iiø-V-i in Cm @ 0-2"},
+		{"D-7 D♭7 C△7 C△7", "ii-V-I (tritone sub) in C @ 0-3"},
+		{"C△7 A-7 D-7 G7", "turnaround in C @ 0-3"},
+		{"E-7 A7 D-7 D♭7", "turnaround (tritone sub) in C @ 0-3"},
+		{"C△7 A7 D-7 G7 C△7", "turnaround in C @ 0-3, ii-V-I in C @ 2-4"},
+		{"C△7 C7 G-7 C7 F△7", "ii-V-I in F @ 2-4"},
+		{"D-7 G7 C7", ""},
+		{"D-7 G-7 C△7", ""},
+	}
+	for _, tc := range cases {
+		idioms := FindIdioms(parseChords(tc.chords))
+		strs := make([]string, len(idioms))
+		for i, idiom := range idioms {
+			strs[i] = idiom.String()
+		}
+		if actual := strings.Join(strs, ", "); actual != tc.exp {
+			t.Errorf("FindIdioms for %s returned wrong value: %q != %q", tc.chords, actual, tc.exp)
+		}
+	}
+}
+
+func TestInferKey(t *testing.T) {
+	cases := []struct {
+		chords string
+		exp    string
+	}{
+		{"C F G7 C", "C"},
+		{"D-7 G7 C△7", "C"},
+		{"A- D- E7 A-", "Am"},
+		{"C A- D- G7 A- E7 A-", "Am"},
+		{"B♭△7 G-7 C-7 F7", "B♭"},
+		{"F♯-7 B7 E△7 C♯-7", "E"},
+		{"C A7 D- G7 C", "C"},
+		{"", "C"},
+	}
+	for _, tc := range cases {
+		if actual := InferKey(parseChords(tc.chords)...).String(); actual != tc.exp {
+			t.Errorf("InferKey for %s returned wrong value: %s != %s", tc.chords, actual, tc.exp)
+		}
+	}
+}
+
+func TestKeyFit(t *testing.T) {
+	cases := []struct {
+		key   string
+		chord string
+		exp   int
+	}{
+		{"C", "C△7", 2},
+		{"C", "G7", 1},
+		{"C", "E7", -1},
+		{"Am", "A-", 2},
+		{"Am", "C", 1},
+		// the raised 7th of the harmonic minor scale is in the key
+		{"Am", "E7", 1},
+		{"Am", "G♯o7", 1},
+		{"Cm", "G7♭9", 1},
+		{"Cm", "B♭7", 1},
+		{"Cm", "E7", -1},
+	}
+	for _, tc := range cases {
+		key := MustParseKey(tc.key)
+		if actual := keyFit(key, keyPitchClasses(key), MustParseChord(tc.chord)); actual != tc.exp {
+			t.Errorf("keyFit for %s in %s returned wrong value: %d != %d", tc.chord, tc.key, actual, tc.exp)
+		}
+	}
+}
+
+func TestSegmentKeys(t *testing.T) {
+	cases := []struct {
+		chords string
+		exp    string
+	}{
+		{"C F G7 C", "C @ 0-3"},
+		{"C A7 D- G7 C", "C @ 0-4"},
+		{"C A- D- G7 C A- D7 G E- A- D7 G", "C @ 0-5, G @ 6-11 (pivots 4 5)"},
+		{"C F G7 C F♯-7 B7 E△7 C♯-7 F♯-7 B7 E△7", "C @ 0-3, E @ 4-10"},
+	}
+	for _, tc := range cases {
+		var strs []string
+		for _, r := range SegmentKeys(parseChords(tc.chords)) {
+			str := r.String()
+			if len(r.Pivots) > 0 {
+				str += " (pivots"
+				for _, p := range r.Pivots {
+					str += fmt.Sprintf(" %d", p)
+				}
+				str += ")"
+			}
+			strs = append(strs, str)
+		}
+		if actual := strings.Join(strs, ", "); actual != tc.exp {
+			t.Errorf("SegmentKeys for %s returned wrong value: %q != %q", tc.chords, actual, tc.exp)
+		}
+	}
+}
+
+func TestScaleChord_String(t *testing.T) {
+	cases := []struct {
+		key, chord, exp string
+	}{
+		{"C", "C△7", "I△7"},
+		{"C", "D-7", "ii7"},
+		{"C", "Bø", "viiø"},
+		{"C", "B♭7", "♭VII7"},
+		{"C", "F♯o", "♯ivo"},
+		{"C", "C/E", "I/III"},
+		{"C", "A♭+", "♭VI+"},
+		{"Am", "C△7", "III△7"},
+		{"Am", "E7", "V7"},
+		{"Am", "C♯-", "♯iii"},
+	}
+	for _, tc := range cases {
+		key, err := ParseKey(tc.key)
+		if err != nil {
+			t.Fatalf("failed to parse key %q: %v", tc.key, err)
+		}
+		sc := key.ScaleChord(MustParseChord(tc.chord))
+		if actual := sc.String(); actual != tc.exp {
+			t.Errorf("Key.ScaleChord for %s in %s returned wrong value: %q != %q", tc.chord, tc.key, actual, tc.exp)
+		}
+		parsed, err := ParseScaleChord(tc.exp, key.Minor)
+		if err != nil {
+			t.Errorf("ParseScaleChord(%q) failed: %v", tc.exp, err)
+		} else if actual := parsed.InKey(key.Tonic).String(); actual != tc.chord {
+			t.Errorf("ScaleChord.InKey for %s in %s returned wrong value: %q != %q", tc.exp, tc.key, actual, tc.chord)
+		}
+	}
+	if sc := MustParseScaleChord("bVII7", false); sc.String() != "♭VII7" {
+		t.Errorf("MustParseScaleChord for bVII7 returned wrong value: %q", sc)
+	}
+	for _, bad := range []string{"", "X", "ii+", "♭♭♭I", "I/X"} {
+		if _, err := ParseScaleChord(bad, false); err == nil {
+			t.Errorf("ParseScaleChord(%q) should have failed", bad)
+		}
+	}
+}
+
+func TestProgression_Analyze(t *testing.T) {
+	p := MustParseProgression("| C△7 | A7 | D-7 B♭7 | G7 |")
+	a := p.Analyze(Key{})
+	exp := `key of C
+1.1  C△7  I△7    tonic
+2.1  A7   V7/ii  dominant (secondary of 2)
+3.1  D-7  ii7    subdominant
+3.3  B♭7  ♭VII7  subdominant (borrowed from aeolian)
+4.1  G7   V7     dominant
+`
+	if actual := a.String(); actual != exp {
+		t.Errorf("Progression.Analyze returned wrong value:\n%s", actual)
+	}
+	if ca := a.Chords[3]; ca.Bar != 2 || ca.Beat != 2 || ca.Function.Borrowed != "aeolian" {
+		t.Errorf("wrong analysis of B♭7: %+v", ca)
+	}
+
+	key, _ := ParseKey("G")
+	var numerals []string
+	for _, ca := range p.Analyze(key).Chords {
+		numerals = append(numerals, ca.Numeral)
+	}
+	if actual := strings.Join(numerals, " "); actual != "IV△7 V7/V v7 ♭III7 V7/IV" {
+		t.Errorf("wrong numerals in G: %q", actual)
+	}
+
+	// the V7 of a minor key is diatonic
+	a = MustParseProgression("| A- | D-7 | E7 | A- |").Analyze(Key{})
+	exp = `key of Am
+1.1  A-   i    tonic
+2.1  D-7  iv7  subdominant
+3.1  E7   V7   dominant
+4.1  A-   i    tonic
+`
+	if actual := a.String(); actual != exp {
+		t.Errorf("Progression.Analyze returned wrong value:\n%s", actual)
+	}
+}
@@ -0,0 +1,105 @@
+package chords
+
+import (
+	"fmt"
+)
+
+// ArpPattern is an order in which the notes of an arpeggio are played. (See
+// Arpeggiate.)
+type ArpPattern int
+
+const (
+	// ArpUp plays the notes of the chord from lowest to highest, ending on
+	// the first note an octave higher. For example, C4 E4 G4 C5 for C.
+	ArpUp ArpPattern = iota
+	// ArpDown plays the notes of ArpUp in reverse, from highest to lowest. For
+	// example, C5 G4 E4 C4 for C.
+	ArpDown
+	// ArpUpDown plays the notes of ArpUp followed by those of ArpDown, without
+	// repeating the highest note. For example, C4 E4 G4 C5 G4 E4 C4 for C.
+	ArpUpDown
+	// ArpBrokenThirds plays the notes of ArpUp in pairs, each note followed by
+	// the note two higher than it in the arpeggio. For example,
+	// C4 G4 E4 C5 for C, and C4 G4 E4 B4 G4 C5 for C△7.
+	ArpBrokenThirds
+)
+
+// String implements the Stringer interface.
+func (p ArpPattern) String() string {
+	switch p {
+	case ArpUp:
+		return "up"
+	case ArpDown:
+		return "down"
+	case ArpUpDown:
+		return "up-down"
+	case ArpBrokenThirds:
+		return "broken thirds"
+	default:
+		return fmt.Sprintf("?(%d)", p)
+	}
+}
+
+// Arpeggiate returns the pitches of an arpeggio of the given chord, in the
+// given pattern, that spans the given number of octaves. If octaves is less
+// than one, the arpeggio spans one octave.
+//
+// The arpeggio starts in the octave of middle C (octave 4) on the chord's
+// root. But if the chord has a bass note that is one of its tones, the
+// arpeggio starts on that note instead. For example, the arpeggio of C/E
+// starts on E4. A bass note that is not one of the chord's tones is not
+// played. The notes are the same as those returned by Spell, each at the
+// lowest pitch above the previous one.
+func Arpeggiate(ch *Chord, pattern ArpPattern, octaves int) []Pitch {
+	if octaves < 1 {
+		octaves = 1
+	}
+	upper := ch.Clone()
+	upper.Bass = Note{}
+	notes := upper.Spell()
+	for i, n := range notes {
+		if ch.Bass.N != 0 && n.Cardinal() == ch.Bass.Cardinal() {
+			notes = append(notes[i:len(notes):len(notes)], notes[:i]...)
+			break
+		}
+	}
+
+	up := make([]Pitch, 0, len(notes)*octaves+1)
+	up = append(up, Pitch{Note: notes[0], Octave: 4})
+	for o := 0; o < octaves; o++ {
+		for i := 1; i <= len(notes); i++ {
+			up = append(up, pitchAbove(notes[i%len(notes)], up[len(up)-1]))
+		}
+	}
+
+	switch pattern {
+	case ArpDown:
+		reversePitches(up)
+		return up
+	case ArpUpDown:
+		ret := make([]Pitch, 0, len(up)*2-1)
+		ret = append(ret, up...)
+		for i := len(up) - 2; i >= 0; i-- {
+			ret = append(ret, up[i])
+		}
+		return ret
+	case ArpBrokenThirds:
+		ret := make([]Pitch, 0, len(up)*2)
+		for i := 0; i+2 < len(up); i++ {
+			ret = append(ret, up[i], up[i+2])
+		}
+		if len(up) < 3 {
+			ret = append(ret, up...)
+		}
+		return ret
+	default:
+		return up
+	}
+}
+
+// reversePitches reverses the given pitches in place.
+func reversePitches(ps []Pitch) {
+	for i, j := 0, len(ps)-1; i < j; i, j = i+1, j-1 {
+		ps[i], ps[j] = ps[j], ps[i]
+	}
+}
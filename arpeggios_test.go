@@ -0,0 +1,40 @@
+package chords
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestArpeggiate(t *testing.T) {
+	cases := []struct {
+		chord   string
+		pattern ArpPattern
+		octaves int
+		exp     string
+	}{
+		{"C", ArpUp, 1, "C4 E4 G4 C5"},
+		{"C", ArpUp, 0, "C4 E4 G4 C5"},
+		{"C", ArpDown, 1, "C5 G4 E4 C4"},
+		{"C", ArpUpDown, 1, "C4 E4 G4 C5 G4 E4 C4"},
+		{"C", ArpBrokenThirds, 1, "C4 G4 E4 C5"},
+		{"C△7", ArpBrokenThirds, 1, "C4 G4 E4 B4 G4 C5"},
+		{"C△7", ArpUp, 2, "C4 E4 G4 B4 C5 E5 G5 B5 C6"},
+		{"A-7", ArpDown, 2, "A6 G6 E6 C6 A5 G5 E5 C5 A4"},
+		{"C/E", ArpUp, 1, "E4 G4 C5 E5"},
+		{"D/C", ArpUp, 1, "D4 F♯4 A4 D5"},
+	}
+	for _, tc := range cases {
+		var strs []string
+		for _, p := range Arpeggiate(MustParseChord(tc.chord), tc.pattern, tc.octaves) {
+			strs = append(strs, p.String())
+		}
+		if actual := strings.Join(strs, " "); actual != tc.exp {
+			t.Errorf("Arpeggiate for %s (%v, %d) returned wrong value: %q != %q", tc.chord, tc.pattern, tc.octaves, actual, tc.exp)
+		}
+	}
+
+	if s := fmt.Sprint(ArpPattern(10)); s != "?(10)" {
+		t.Errorf("wrong string for unknown pattern: %s", s)
+	}
+}
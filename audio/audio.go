@@ -0,0 +1,252 @@
+// Package audio synthesizes chords and arpeggios as audio, so they can be
+// heard instead of just spelled. The sound is simple, a sine or sawtooth wave
+// for each pitch, but it is enough to audition a voicing or to play examples
+// for ear training. The result can be written as a WAV file:
+//
+//	v := chords.MustParseChord("C△7").Voicings(chords.Close, chords.PitchRange{})[0]
+//	buf := audio.RenderChord(v, audio.Options{Duration: 2 * time.Second})
+//	err := buf.WriteWAV(f)
+package audio
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math"
+	"time"
+
+	"github.com/jhump/chords"
+)
+
+// Waveform is the shape of the wave used to synthesize each pitch.
+type Waveform int
+
+const (
+	// Sine is a pure tone, with no overtones.
+	Sine Waveform = iota
+	// Saw is a sawtooth wave, which is brighter and buzzier than a sine wave
+	// since it has every harmonic. It is band-limited: harmonics above the
+	// highest frequency the sample rate can represent are left out, so they
+	// don't alias into lower frequencies.
+	Saw
+)
+
+// String implements the Stringer interface.
+func (w Waveform) String() string {
+	switch w {
+	case Sine:
+		return "sine"
+	case Saw:
+		return "saw"
+	default:
+		return fmt.Sprintf("?(%d)", w)
+	}
+}
+
+const (
+	// DefaultSampleRate is the sample rate used when Options.SampleRate is
+	// zero. It is the sample rate of CD audio.
+	DefaultSampleRate = 44100
+	// DefaultDuration is the duration used when Options.Duration is zero.
+	DefaultDuration = 500 * time.Millisecond
+)
+
+// attack and release are the durations over which each sound fades in and
+// out, so that it doesn't start or stop with a click.
+const (
+	attack  = 10 * time.Millisecond
+	release = 50 * time.Millisecond
+)
+
+// Options control how audio is synthesized. The zero value is a sine wave at
+// the default sample rate and duration, in standard tuning.
+type Options struct {
+	// SampleRate is the number of samples per second. If zero,
+	// DefaultSampleRate is used.
+	SampleRate int
+	// Waveform is the shape of the wave used for each pitch.
+	Waveform Waveform
+	// Duration is how long each sound lasts: the whole chord for RenderChord,
+	// or each note for RenderArpeggio. If zero, DefaultDuration is used.
+	Duration time.Duration
+	// Tuning determines the frequency of each pitch. If it is the zero value,
+	// chords.StandardTuning is used.
+	Tuning chords.Tuning
+}
+
+func (o Options) sampleRate() int {
+	if o.SampleRate == 0 {
+		return DefaultSampleRate
+	}
+	return o.SampleRate
+}
+
+func (o Options) duration() time.Duration {
+	if o.Duration == 0 {
+		return DefaultDuration
+	}
+	return o.Duration
+}
+
+// Buffer is synthesized audio: mono, 16-bit PCM samples.
+type Buffer struct {
+	// SampleRate is the number of samples per second.
+	SampleRate int
+	// Samples are the audio samples, in order.
+	Samples []int16
+}
+
+// Duration returns how long the audio lasts.
+func (b *Buffer) Duration() time.Duration {
+	if b.SampleRate == 0 {
+		return 0
+	}
+	return time.Duration(len(b.Samples)) * time.Second / time.Duration(b.SampleRate)
+}
+
+// RenderChord synthesizes the given pitches played together, for the
+// duration in the given options. (See chords.Chord.Voicings.) The pitches
+// are mixed at equal volume, and the mix is scaled so that it never clips.
+// If there are no pitches, the result is silence.
+func RenderChord(v chords.Voicing, opts Options) *Buffer {
+	rate := opts.sampleRate()
+	mix := make([]float64, samplesFor(opts.duration(), rate))
+	for _, p := range v {
+		addTone(mix, p.Frequency(opts.Tuning), rate, opts.Waveform, 1/float64(len(v)))
+	}
+	return &Buffer{SampleRate: rate, Samples: quantize(mix)}
+}
+
+// RenderArpeggio synthesizes the given pitches played one after another, each
+// for the duration in the given options. (See chords.Arpeggiate.)
+func RenderArpeggio(ps []chords.Pitch, opts Options) *Buffer {
+	rate := opts.sampleRate()
+	n := samplesFor(opts.duration(), rate)
+	mix := make([]float64, n*len(ps))
+	for i, p := range ps {
+		addTone(mix[i*n:(i+1)*n], p.Frequency(opts.Tuning), rate, opts.Waveform, 1)
+	}
+	return &Buffer{SampleRate: rate, Samples: quantize(mix)}
+}
+
+// samplesFor returns the number of samples in the given duration.
+func samplesFor(d time.Duration, rate int) int {
+	return int(d * time.Duration(rate) / time.Second)
+}
+
+// peak is the loudest sample, as a fraction of full scale. It leaves some
+// headroom, so that audio from this package can be mixed with other audio.
+const peak = 0.8
+
+// addTone adds a tone of the given frequency and waveform to the given
+// samples, scaled by the given gain, with a fade in and fade out.
+func addTone(samples []float64, freq float64, rate int, wave Waveform, gain float64) {
+	fadeIn := samplesFor(attack, rate)
+	fadeOut := samplesFor(release, rate)
+	if fadeIn+fadeOut > len(samples) {
+		// a very short sound fades in and out for half of its length each
+		fadeIn, fadeOut = len(samples)/2, len(samples)-len(samples)/2
+	}
+	harmonics := 1
+	if wave == Saw {
+		// every harmonic up to the Nyquist frequency
+		harmonics = int(float64(rate) / 2 / freq)
+		if harmonics < 1 {
+			harmonics = 1
+		}
+	}
+	for i := range samples {
+		t := float64(i) / float64(rate)
+		var v float64
+		if wave == Saw {
+			for k := 1; k <= harmonics; k++ {
+				h := math.Sin(2*math.Pi*float64(k)*freq*t) / float64(k)
+				if k%2 == 0 {
+					h = -h
+				}
+				v += h
+			}
+			// the sum of the harmonics ranges from about -π/2 to π/2 (a
+			// little more near the jump, which is why the peak leaves
+			// headroom)
+			v *= 2 / math.Pi
+		} else {
+			v = math.Sin(2 * math.Pi * freq * t)
+		}
+		env := 1.0
+		if i < fadeIn {
+			env = float64(i) / float64(fadeIn)
+		} else if rem := len(samples) - i; rem <= fadeOut {
+			env = float64(rem-1) / float64(fadeOut)
+		}
+		samples[i] += v * env * gain
+	}
+}
+
+// quantize converts the given samples, from -1 to 1, into 16-bit samples.
+func quantize(mix []float64) []int16 {
+	samples := make([]int16, len(mix))
+	for i, v := range mix {
+		v *= peak
+		if v > 1 {
+			v = 1
+		} else if v < -1 {
+			v = -1
+		}
+		samples[i] = int16(math.Round(v * math.MaxInt16))
+	}
+	return samples
+}
+
+// Append appends the samples of the given buffers to this one, so that they
+// play one after another. It returns an error if their sample rates differ.
+func (b *Buffer) Append(others ...*Buffer) error {
+	for _, o := range others {
+		if o.SampleRate != b.SampleRate {
+			return fmt.Errorf("cannot append audio with sample rate %d to audio with sample rate %d", o.SampleRate, b.SampleRate)
+		}
+	}
+	for _, o := range others {
+		b.Samples = append(b.Samples, o.Samples...)
+	}
+	return nil
+}
+
+// WriteWAV writes the audio to the given writer as a WAV file.
+func (b *Buffer) WriteWAV(w io.Writer) error {
+	const bytesPerSample = 2
+	dataSize := uint32(len(b.Samples) * bytesPerSample)
+	header := struct {
+		RIFF          [4]byte
+		Size          uint32
+		WAVE          [4]byte
+		Fmt           [4]byte
+		FmtSize       uint32
+		Format        uint16
+		Channels      uint16
+		SampleRate    uint32
+		ByteRate      uint32
+		BlockAlign    uint16
+		BitsPerSample uint16
+		Data          [4]byte
+		DataSize      uint32
+	}{
+		RIFF:          [4]byte{'R', 'I', 'F', 'F'},
+		Size:          36 + dataSize,
+		WAVE:          [4]byte{'W', 'A', 'V', 'E'},
+		Fmt:           [4]byte{'f', 'm', 't', ' '},
+		FmtSize:       16,
+		Format:        1, // PCM
+		Channels:      1,
+		SampleRate:    uint32(b.SampleRate),
+		ByteRate:      uint32(b.SampleRate * bytesPerSample),
+		BlockAlign:    bytesPerSample,
+		BitsPerSample: 8 * bytesPerSample,
+		Data:          [4]byte{'d', 'a', 't', 'a'},
+		DataSize:      dataSize,
+	}
+	if err := binary.Write(w, binary.LittleEndian, &header); err != nil {
+		return err
+	}
+	return binary.Write(w, binary.LittleEndian, b.Samples)
+}
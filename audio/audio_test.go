@@ -0,0 +1,127 @@
+package audio
+
+import (
+	"bytes"
+	"encoding/binary"
+	"testing"
+	"time"
+
+	"github.com/jhump/chords"
+)
+
+func TestRenderChord(t *testing.T) {
+	a4 := chords.Voicing{chords.MustParsePitch("A4")}
+	buf := RenderChord(a4, Options{Duration: time.Second})
+	if buf.SampleRate != DefaultSampleRate || len(buf.Samples) != DefaultSampleRate || buf.Duration() != time.Second {
+		t.Fatalf("wrong buffer: %d samples at %d Hz", len(buf.Samples), buf.SampleRate)
+	}
+	// a 440 Hz sine wave crosses zero going up 440 times a second
+	if crossings := upwardCrossings(buf.Samples); crossings < 439 || crossings > 441 {
+		t.Errorf("RenderChord for A4 returned wrong number of upward zero crossings: %d", crossings)
+	}
+	if buf.Samples[0] != 0 || buf.Samples[len(buf.Samples)-1] != 0 {
+		t.Errorf("RenderChord for A4 did not fade in and out")
+	}
+	if p := maxAbs(buf.Samples); p < 26000 || p > 26300 {
+		t.Errorf("wrong peak: %d", p)
+	}
+
+	// in A432 tuning, A4 is 432 Hz
+	buf = RenderChord(a4, Options{Duration: time.Second, Tuning: chords.A432Tuning, SampleRate: 8000})
+	if len(buf.Samples) != 8000 {
+		t.Errorf("wrong number of samples: %d", len(buf.Samples))
+	}
+	if crossings := upwardCrossings(buf.Samples); crossings < 431 || crossings > 433 {
+		t.Errorf("RenderChord for A4 in A432 tuning returned wrong number of upward zero crossings: %d", crossings)
+	}
+
+	// a saw wave has the same period as a sine wave, and a chord never clips
+	v := chords.MustParseChord("C△9").Voicings(chords.Close, chords.PitchRange{})[0]
+	for _, wave := range []Waveform{Sine, Saw} {
+		buf = RenderChord(v, Options{Waveform: wave})
+		if buf.Duration() != DefaultDuration {
+			t.Errorf("%v: wrong duration: %v", wave, buf.Duration())
+		}
+		if p := maxAbs(buf.Samples); p == 0 || p >= 32767 {
+			t.Errorf("%v: wrong peak: %d", wave, p)
+		}
+	}
+	buf = RenderChord(a4, Options{Waveform: Saw, Duration: time.Second})
+	if crossings := upwardCrossings(buf.Samples); crossings < 439 || crossings > 441 {
+		t.Errorf("RenderChord for A4 saw wave returned wrong number of upward zero crossings: %d", crossings)
+	}
+
+	if buf := RenderChord(nil, Options{}); maxAbs(buf.Samples) != 0 || buf.Duration() != DefaultDuration {
+		t.Errorf("RenderChord for empty voicing did not return silence")
+	}
+}
+
+func TestRenderArpeggio(t *testing.T) {
+	ps := chords.Arpeggiate(chords.MustParseChord("C"), chords.ArpUp, 1)
+	buf := RenderArpeggio(ps, Options{Duration: 100 * time.Millisecond, SampleRate: 10000})
+	if len(buf.Samples) != 4000 {
+		t.Fatalf("wrong number of samples: %d", len(buf.Samples))
+	}
+	// each note fades out to silence before the next one starts
+	for i := 1000; i < 4000; i += 1000 {
+		if buf.Samples[i-1] != 0 || buf.Samples[i] != 0 {
+			t.Errorf("RenderArpeggio did not return silence at sample %d", i)
+		}
+	}
+
+	other := RenderArpeggio(ps[:1], Options{Duration: 100 * time.Millisecond, SampleRate: 10000})
+	if err := buf.Append(other); err != nil || len(buf.Samples) != 5000 {
+		t.Errorf("failed to append: %v", err)
+	}
+	if err := buf.Append(RenderArpeggio(ps, Options{})); err == nil {
+		t.Errorf("Buffer.Append for a different sample rate should have failed")
+	}
+}
+
+func TestWriteWAV(t *testing.T) {
+	buf := &Buffer{SampleRate: 8000, Samples: []int16{0, 100, -100, 32767}}
+	var b bytes.Buffer
+	if err := buf.WriteWAV(&b); err != nil {
+		t.Fatalf("failed to write: %v", err)
+	}
+	data := b.Bytes()
+	if len(data) != 44+8 {
+		t.Fatalf("wrong length: %d", len(data))
+	}
+	if string(data[0:4]) != "RIFF" || string(data[8:16]) != "WAVEfmt " || string(data[36:40]) != "data" {
+		t.Errorf("wrong header: %q", data[:44])
+	}
+	le := binary.LittleEndian
+	if le.Uint32(data[4:]) != 44 || le.Uint16(data[20:]) != 1 || le.Uint16(data[22:]) != 1 ||
+		le.Uint32(data[24:]) != 8000 || le.Uint32(data[28:]) != 16000 || le.Uint16(data[34:]) != 16 ||
+		le.Uint32(data[40:]) != 8 {
+		t.Errorf("wrong header fields: %v", data[:44])
+	}
+	if int16(le.Uint16(data[46:])) != 100 || int16(le.Uint16(data[48:])) != -100 {
+		t.Errorf("wrong samples: %v", data[44:])
+	}
+}
+
+func upwardCrossings(samples []int16) int {
+	n := 0
+	for i := 1; i < len(samples); i++ {
+		if samples[i-1] < 0 && samples[i] >= 0 {
+			n++
+		}
+	}
+	return n
+}
+
+func maxAbs(samples []int16) int {
+	p := 0
+	for _, s := range samples {
+		v := int(s)
+		if v < 0 {
+			v = -v
+		}
+		if v > p {
+			p = v
+		}
+	}
+	return p
+}
@@ -0,0 +1,260 @@
+package chords
+
+import (
+	"fmt"
+	"sort"
+)
+
+// BassStyle is a style of bass line. (See BassLine.)
+type BassStyle int
+
+const (
+	// WalkingBass plays a note on every beat. Each chord starts on its root
+	// (or bass note) and ends on a note a half-step away from the root of the
+	// next chord, so that the line approaches it chromatically. The beats in
+	// between walk from one to the other, using the chord's tones when there
+	// are enough of them between the two and passing tones from the chord's
+	// scale (see CompatibleScales) when there aren't. If the two are too close
+	// together for either, the line arpeggiates the chord instead.
+	WalkingBass BassStyle = iota
+	// TwoFeelBass plays a note every two beats, alternating between the root
+	// (or bass note) of each chord and its 5th.
+	TwoFeelBass
+	// RootBass plays the root (or bass note) of each chord, held for as long
+	// as the chord lasts.
+	RootBass
+)
+
+// String implements the Stringer interface.
+func (s BassStyle) String() string {
+	switch s {
+	case WalkingBass:
+		return "walking"
+	case TwoFeelBass:
+		return "two-feel"
+	case RootBass:
+		return "roots"
+	default:
+		return fmt.Sprintf("?(%d)", s)
+	}
+}
+
+// BassNote is a note of a bass line.
+type BassNote struct {
+	// Pitch is the pitch that is played.
+	Pitch Pitch
+	// Beat is the beat on which the note starts, counting from zero at the
+	// start of the progression.
+	Beat int
+	// Beats is the number of beats for which the note is played.
+	Beats int
+}
+
+// String implements the Stringer interface. The result is the pitch followed
+// by its start and duration in beats, like "C2@4+1".
+func (n BassNote) String() string {
+	return fmt.Sprintf("%v@%d+%d", n.Pitch, n.Beat, n.Beats)
+}
+
+// bassRange is the range of pitches used in bass lines: the lowest three
+// octaves of a four-string bass.
+var bassRange = PitchRange{
+	Low:  Pitch{Note: Note{N: E}, Octave: 1},
+	High: Pitch{Note: Note{N: D}, Octave: 3},
+}
+
+// BassLine returns a bass line, in the given style, for the given progression.
+// The progression is unrolled first, so repeated sections are played as many
+// times as they are repeated (see Progression.Unroll). The line starts on the
+// lowest pitch of the first chord's root that is at least E1, and every note
+// is between E1 and D3. Each note is as close as possible to the one before
+// it, so the line moves mostly by step.
+//
+// For the last chord of a walking bass line, the next chord is the first
+// chord of the progression, as if the progression were played again.
+func BassLine(prog *Progression, style BassStyle) []BassNote {
+	prog = prog.Unroll()
+	var chs []BarChord
+	for _, bar := range prog.Bars {
+		chs = append(chs, bar.Chords...)
+	}
+	var line []BassNote
+	var prev Pitch
+	beat := 0
+	for i, bc := range chs {
+		ch := bc.Chord
+		bass := ch.Root
+		if ch.Bass.N != 0 {
+			bass = ch.Bass
+		}
+		var root Pitch
+		if i == 0 {
+			root = Pitch{Note: bass, Octave: bassRange.Low.Octave - 1}
+			for root.Less(bassRange.Low) {
+				root.Octave++
+			}
+		} else {
+			root = nearestBassPitch(bass, prev)
+		}
+
+		var pitches []Pitch
+		switch style {
+		case WalkingBass:
+			next := chs[(i+1)%len(chs)].Chord
+			pitches = walk(ch, root, next, bc.Beats)
+		case TwoFeelBass:
+			fifth := nearestBassPitch(ch.fifthNote(), root)
+			for b := 0; b < bc.Beats; b += 2 {
+				p := root
+				if (b/2)%2 == 1 {
+					p = fifth
+				}
+				beats := 2
+				if b+beats > bc.Beats {
+					beats = bc.Beats - b
+				}
+				line = append(line, BassNote{Pitch: p, Beat: beat + b, Beats: beats})
+			}
+			prev = line[len(line)-1].Pitch
+		default:
+			line = append(line, BassNote{Pitch: root, Beat: beat, Beats: bc.Beats})
+			prev = root
+		}
+		for b, p := range pitches {
+			line = append(line, BassNote{Pitch: p, Beat: beat + b, Beats: 1})
+			prev = p
+		}
+		beat += bc.Beats
+	}
+	return line
+}
+
+// walk returns a walking bass line, with one pitch per beat, for the given
+// chord that starts on the given root and leads to the given next chord.
+func walk(ch *Chord, root Pitch, next *Chord, beats int) []Pitch {
+	if beats <= 1 {
+		return []Pitch{root}
+	}
+	nextBass := next.Root
+	if next.Bass.N != 0 {
+		nextBass = next.Bass
+	}
+	// approach the next chord from below if it is higher and from above if
+	// it is lower
+	target := nearestBassPitch(nextBass, root)
+	approach := Pitch{Note: nextBass.TransposeDown(Interval{Val: 2, Offset: -1}), Octave: target.Octave}
+	if target.Less(root) {
+		approach.Note = nextBass.Transpose(Interval{Val: 2, Offset: -1})
+	}
+	for approach.MIDINumber() > target.MIDINumber()+1 {
+		approach.Octave--
+	}
+	for approach.MIDINumber() < target.MIDINumber()-1 {
+		approach.Octave++
+	}
+	if !bassRange.Contains(approach) {
+		// approach from the other side instead
+		if approach.Less(target) {
+			approach = Pitch{Note: nextBass.Transpose(Interval{Val: 2, Offset: -1}), Octave: target.Octave}
+			for approach.MIDINumber() < target.MIDINumber() {
+				approach.Octave++
+			}
+		} else {
+			approach = Pitch{Note: nextBass.TransposeDown(Interval{Val: 2, Offset: -1}), Octave: target.Octave}
+			for approach.MIDINumber() > target.MIDINumber() {
+				approach.Octave--
+			}
+		}
+	}
+
+	pitches := []Pitch{root}
+	if middle := beats - 2; middle > 0 {
+		chordTones := pitchesBetween(ch.Spell(), root, approach)
+		if len(chordTones) >= middle {
+			pitches = append(pitches, evenlySpaced(chordTones, middle)...)
+		} else if scale := CompatibleScales(ch); len(scale) > 0 && len(pitchesBetween(scale[0].Spell(), root, approach)) >= middle {
+			pitches = append(pitches, evenlySpaced(pitchesBetween(scale[0].Spell(), root, approach), middle)...)
+		} else {
+			// not enough room between the root and the approach, so arpeggiate
+			// the chord up from the root (or down, if there isn't room above)
+			// before approaching the next chord
+			octave := Pitch{Note: root.Note, Octave: root.Octave + 1}
+			if !bassRange.Contains(octave) {
+				octave.Octave -= 2
+			}
+			tones := pitchesBetween(ch.Spell(), root, octave)
+			for i := 0; i < middle; i++ {
+				pitches = append(pitches, tones[i%len(tones)])
+			}
+		}
+	}
+	return append(pitches, approach)
+}
+
+// pitchesBetween returns the pitches of the given notes that are strictly
+// between the given pitches, in order from the first pitch towards the
+// second.
+func pitchesBetween(notes []Note, from, to Pitch) []Pitch {
+	lo, hi := from, to
+	if hi.Less(lo) {
+		lo, hi = hi, lo
+	}
+	var ret []Pitch
+	for _, n := range notes {
+		for o := lo.Octave - 1; o <= hi.Octave+1; o++ {
+			p := Pitch{Note: n, Octave: o}
+			if p.MIDINumber() > lo.MIDINumber() && p.MIDINumber() < hi.MIDINumber() {
+				ret = append(ret, p)
+			}
+		}
+	}
+	sort.Slice(ret, func(i, j int) bool {
+		return ret[i].Less(ret[j])
+	})
+	if to.Less(from) {
+		reversePitches(ret)
+	}
+	return ret
+}
+
+// evenlySpaced returns n of the given pitches, spread as evenly as possible
+// through them.
+func evenlySpaced(ps []Pitch, n int) []Pitch {
+	ret := make([]Pitch, n)
+	for i := range ret {
+		ret[i] = ps[(i+1)*(len(ps)+1)/(n+1)-1]
+	}
+	return ret
+}
+
+// nearestBassPitch returns the pitch of the given note, within the range of
+// bass lines, that is closest to the given pitch.
+func nearestBassPitch(n Note, near Pitch) Pitch {
+	var best Pitch
+	bestDist := -1
+	for o := bassRange.Low.Octave; o <= bassRange.High.Octave; o++ {
+		p := Pitch{Note: n, Octave: o}
+		if !bassRange.Contains(p) {
+			continue
+		}
+		dist := p.MIDINumber() - near.MIDINumber()
+		if dist < 0 {
+			dist = -dist
+		}
+		if bestDist < 0 || dist < bestDist {
+			best, bestDist = p, dist
+		}
+	}
+	return best
+}
+
+// fifthNote returns the 5th of the chord, which may be altered, or the root if
+// the chord has no 5th.
+func (ch *Chord) fifthNote() Note {
+	for _, n := range ch.Spell() {
+		if ch.Root.IntervalTo(n).Val == 5 {
+			return n
+		}
+	}
+	return ch.Root
+}
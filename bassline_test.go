@@ -0,0 +1,83 @@
+package chords
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestBassLine(t *testing.T) {
+	cases := []struct {
+		prog  string
+		style BassStyle
+		exp   string
+	}{
+		{
+			prog:  "| C△7 | A-7 | D-7 | G7 |",
+			style: WalkingBass,
+			exp:   "[C2@0+1 E2@1+1 G2@2+1 B♭1@3+1 A1@4+1 C2@5+1 E2@6+1 C♯2@7+1 D2@8+1 F2@9+1 A2@10+1 F♯2@11+1 G2@12+1 F2@13+1 D2@14+1 B2@15+1]",
+		},
+		{
+			prog:  "| C△7 | A-7 | D-7 | G7 |",
+			style: TwoFeelBass,
+			exp:   "[C2@0+2 G1@2+2 A1@4+2 E1@6+2 D2@8+2 A1@10+2 G1@12+2 D2@14+2]",
+		},
+		{
+			prog:  "| C△7 | A-7 | D-7 | G7 |",
+			style: RootBass,
+			exp:   "[C2@0+4 A1@4+4 D2@8+4 G2@12+4]",
+		},
+		{
+			// two beats per chord leaves room only for the root and the
+			// approach note
+			prog:  "| D-7 G7 | C△7 |",
+			style: WalkingBass,
+			exp:   "[D2@0+1 F♯2@1+1 G2@2+1 B2@3+1 C3@4+1 B2@5+1 G2@6+1 C♯3@7+1]",
+		},
+		{
+			prog:  "3/4 | C | G7 |",
+			style: WalkingBass,
+			exp:   "[C2@0+1 A1@1+1 A♭1@2+1 G1@3+1 A1@4+1 B1@5+1]",
+		},
+		{
+			prog:  "3/4 | C | G7 |",
+			style: TwoFeelBass,
+			exp:   "[C2@0+2 G1@2+1 G1@3+2 D2@5+1]",
+		},
+		{
+			// the approach note must stay in range, so E1 is approached from
+			// above
+			prog:  "|: E :|",
+			style: WalkingBass,
+			exp:   "[E1@0+1 G♯1@1+1 B1@2+1 F1@3+1 E1@4+1 G♯1@5+1 B1@6+1 F1@7+1]",
+		},
+		{
+			prog:  "| C/E | F |",
+			style: RootBass,
+			exp:   "[E1@0+4 F1@4+4]",
+		},
+	}
+	for _, tc := range cases {
+		line := BassLine(MustParseProgression(tc.prog), tc.style)
+		if actual := fmt.Sprint(line); actual != tc.exp {
+			t.Errorf("BassLine for %s (%v) returned wrong value: %s != %s", tc.prog, tc.style, actual, tc.exp)
+		}
+	}
+
+	// a longer progression stays in range and fills every beat
+	prog := MustParseProgression("| F7 | B♭7 | F7 | F7 | B♭7 | B♭7 | F7 | D7 | G-7 | C7 | F7 D7 | G-7 C7 |")
+	for _, style := range []BassStyle{WalkingBass, TwoFeelBass, RootBass} {
+		beat := 0
+		for _, n := range BassLine(prog, style) {
+			if !bassRange.Contains(n.Pitch) {
+				t.Errorf("%v: %v is out of range", style, n)
+			}
+			if n.Beat != beat {
+				t.Errorf("%v: %v should start on beat %d", style, n, beat)
+			}
+			beat += n.Beats
+		}
+		if beat != 48 {
+			t.Errorf("BassLine for %v returned wrong number of beats: %d != 48", style, beat)
+		}
+	}
+}
@@ -0,0 +1,493 @@
+// Package chordpro reads and writes files in the ChordPro format. ChordPro is a
+// plain-text format for lyrics with chords, where chords are written inline in
+// square brackets, just before the syllable on which they are played:
+//
+//	{title: Amazing Grace}
+//	{key: G}
+//	A[G]mazing [G7]grace, how [C]sweet the [G]sound
+//
+// Lines that are enclosed in curly braces are directives. Directives at the
+// start of the file are song metadata, like the title and key. Others mark
+// sections of the song, like {start_of_chorus} and {end_of_chorus}.
+//
+// Documents can be transposed, which respells every chord consistently (see
+// chords.Progression.Transpose), and they can be converted into a chords.Song
+// for analysis.
+package chordpro
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+
+	"github.com/jhump/chords"
+)
+
+// Document is a parsed ChordPro file.
+type Document struct {
+	// Metadata is the song metadata from the directives at the start of the
+	// file.
+	Metadata chords.SongMetadata
+	// Lines are the lines of the file that follow the metadata.
+	Lines []Line
+}
+
+// LineKind indicates what kind of content is in a Line.
+type LineKind int
+
+const (
+	// LyricsLine is a line of lyrics, which may contain chords. Blank lines
+	// are lyrics lines with no segments.
+	LyricsLine LineKind = iota
+	// DirectiveLine is a directive, like "{start_of_chorus}".
+	DirectiveLine
+	// CommentLine is a line that starts with '#'. These are ignored by
+	// ChordPro processors but are retained so they can be written back out.
+	CommentLine
+	// GridLine is a line inside of a grid section (between {start_of_grid}
+	// and {end_of_grid}). Chords in a grid are not in square brackets; they
+	// are separated by bar lines and by '.' characters, each of which
+	// continues the previous chord for a beat.
+	GridLine
+)
+
+// String implements the Stringer interface.
+func (k LineKind) String() string {
+	switch k {
+	case LyricsLine:
+		return "lyrics"
+	case DirectiveLine:
+		return "directive"
+	case CommentLine:
+		return "comment"
+	case GridLine:
+		return "grid"
+	default:
+		return fmt.Sprintf("?(%d)", k)
+	}
+}
+
+// Line is one line of a ChordPro document.
+type Line struct {
+	// Kind is the kind of line.
+	Kind LineKind
+	// Name is the directive's name, for directive lines.
+	Name string
+	// Value is the directive's value, for directive lines, or the text of the
+	// comment (not including the leading '#'), for comment lines.
+	Value string
+	// Segments are the chords and lyrics, for lyrics and grid lines.
+	Segments []Segment
+}
+
+// Segment is a chord and the lyrics that follow it, up to the next chord.
+type Segment struct {
+	// ChordText is the text of the chord, as written (without the square
+	// brackets). This is empty for lyrics that appear before the first chord
+	// in a line.
+	ChordText string
+	// Chord is the parsed chord. This is nil if ChordText is empty or could
+	// not be parsed as a chord. ChordPro annotations, like "[*Riff]", and
+	// symbols like "N.C." (no chord) are not chords.
+	Chord *chords.Chord
+	// Lyrics is the text that follows the chord.
+	Lyrics string
+}
+
+// Parse parses the given ChordPro text. Chords are parsed with
+// chords.ParseChord or, if that fails, chords.ParseLegacyChord. Text in square
+// brackets that cannot be parsed as a chord is not an error: it is retained
+// as is, with a nil Chord.
+func Parse(text string) (*Document, error) {
+	meta, body, err := chords.ParseSongMetadata(text)
+	if err != nil {
+		return nil, err
+	}
+	doc := &Document{Metadata: *meta}
+	body = strings.TrimSuffix(body, "\n")
+	if body == "" {
+		return doc, nil
+	}
+	inGrid := false
+	for i, line := range strings.Split(body, "\n") {
+		line = strings.TrimRight(line, "\r")
+		trimmed := strings.TrimSpace(line)
+		switch {
+		case strings.HasPrefix(trimmed, "#"):
+			doc.Lines = append(doc.Lines, Line{Kind: CommentLine, Value: trimmed[1:]})
+		case strings.HasPrefix(trimmed, "{") && strings.HasSuffix(trimmed, "}"):
+			name, val, _ := strings.Cut(trimmed[1:len(trimmed)-1], ":")
+			name = strings.TrimSpace(name)
+			switch strings.ToLower(name) {
+			case "start_of_grid", "sog":
+				inGrid = true
+			case "end_of_grid", "eog":
+				inGrid = false
+			}
+			doc.Lines = append(doc.Lines, Line{Kind: DirectiveLine, Name: name, Value: strings.TrimSpace(val)})
+		case inGrid:
+			doc.Lines = append(doc.Lines, Line{Kind: GridLine, Segments: parseGrid(line)})
+		default:
+			segs, err := parseLyrics(line)
+			if err != nil {
+				return nil, fmt.Errorf("line %d: %v", i+1, err)
+			}
+			doc.Lines = append(doc.Lines, Line{Kind: LyricsLine, Segments: segs})
+		}
+	}
+	return doc, nil
+}
+
+// parseChord parses the given chord text, returning nil if it is not a
+// valid chord.
+func parseChord(s string) *chords.Chord {
+	ch, err := chords.ParseChord(s)
+	if err != nil {
+		ch, err = chords.ParseLegacyChord(s)
+	}
+	if err != nil || ch.Validate() != nil {
+		return nil
+	}
+	return ch
+}
+
+// parseLyrics parses a line of lyrics with inline chords.
+func parseLyrics(line string) ([]Segment, error) {
+	var segs []Segment
+	for line != "" {
+		start := strings.IndexByte(line, '[')
+		if start < 0 {
+			start = len(line)
+		}
+		if start > 0 || len(segs) == 0 {
+			if len(segs) == 0 {
+				segs = append(segs, Segment{})
+			}
+			segs[len(segs)-1].Lyrics += line[:start]
+			line = line[start:]
+			if line == "" {
+				break
+			}
+		}
+		end := strings.IndexByte(line, ']')
+		if end < 0 {
+			return nil, fmt.Errorf("chord %q is missing closing ']'", line)
+		}
+		text := line[1:end]
+		segs = append(segs, Segment{ChordText: text, Chord: parseChord(text)})
+		line = line[end+1:]
+	}
+	if len(segs) > 0 && segs[0].ChordText == "" && segs[0].Lyrics == "" {
+		segs = segs[1:]
+	}
+	return segs, nil
+}
+
+// parseGrid parses a line of a chord grid. Each whitespace-separated word
+// that is a chord becomes a segment, with the text that follows it (such as
+// bar lines and dots) as its lyrics.
+func parseGrid(line string) []Segment {
+	var segs []Segment
+	for line != "" {
+		// split off the next word and the whitespace that follows it
+		end := strings.IndexAny(line, " \t")
+		if end < 0 {
+			end = len(line)
+		}
+		next := end + len(line[end:]) - len(strings.TrimLeft(line[end:], " \t"))
+		word := line[:end]
+		if ch := parseChord(word); ch != nil {
+			segs = append(segs, Segment{ChordText: word, Chord: ch, Lyrics: line[end:next]})
+		} else {
+			if len(segs) == 0 {
+				segs = append(segs, Segment{})
+			}
+			segs[len(segs)-1].Lyrics += line[:next]
+		}
+		line = line[next:]
+	}
+	return segs
+}
+
+// String implements the Stringer interface. It returns the document in
+// ChordPro format: the metadata, as directives (see
+// chords.SongMetadata.Directives), followed by the document's lines.
+func (d *Document) String() string {
+	var b bytes.Buffer
+	b.WriteString(d.Metadata.Directives())
+	for _, line := range d.Lines {
+		switch line.Kind {
+		case DirectiveLine:
+			if line.Value == "" {
+				fmt.Fprintf(&b, "{%s}", line.Name)
+			} else {
+				fmt.Fprintf(&b, "{%s: %s}", line.Name, line.Value)
+			}
+		case CommentLine:
+			fmt.Fprintf(&b, "#%s", line.Value)
+		case GridLine:
+			for _, seg := range line.Segments {
+				b.WriteString(seg.ChordText)
+				b.WriteString(seg.Lyrics)
+			}
+		default:
+			for _, seg := range line.Segments {
+				if seg.ChordText != "" {
+					fmt.Fprintf(&b, "[%s]", seg.ChordText)
+				}
+				b.WriteString(seg.Lyrics)
+			}
+		}
+		b.WriteByte('\n')
+	}
+	return b.String()
+}
+
+// Chords returns all of the chords in the document, in order. Text in square
+// brackets that is not a chord is skipped.
+func (d *Document) Chords() []*chords.Chord {
+	var chs []*chords.Chord
+	for _, line := range d.Lines {
+		for _, seg := range line.Segments {
+			if seg.Chord != nil {
+				chs = append(chs, seg.Chord)
+			}
+		}
+	}
+	return chs
+}
+
+// sectionNames are the names of the sections started by the ChordPro
+// "start_of_*" directives (and their abbreviations).
+var sectionNames = map[string]string{
+	"start_of_verse": "Verse", "sov": "Verse",
+	"start_of_chorus": "Chorus", "soc": "Chorus",
+	"start_of_bridge": "Bridge", "sob": "Bridge",
+	"start_of_grid": "Grid", "sog": "Grid",
+	"start_of_tab": "Tab", "sot": "Tab",
+}
+
+// Song converts the document into a chords.Song. Each section of the document
+// (e.g. the lines between {start_of_verse} and {end_of_verse}) becomes a
+// section of the song. A section's name is the label in its start directive,
+// like "{start_of_verse: Verse 2}", or else is based on the directive, like
+// "Verse" or "Chorus". Lines that are not inside a section directive are put
+// into sections with no name. The {chorus} directive, which repeats the most
+// recent chorus, adds that section again.
+//
+// ChordPro does not indicate how long each chord is played, so each chord in
+// a lyrics line becomes one bar. Chords in a grid, however, are parsed as a
+// chords.Progression, with each '.' continuing the previous chord for another
+// beat.
+func (d *Document) Song() (*chords.Song, error) {
+	song := &chords.Song{Metadata: d.Metadata}
+	song.Metadata.Extra = append([]chords.MetadataField(nil), d.Metadata.Extra...)
+	var sect *chords.Section
+	var lastChorus *chords.Section
+	var grid []string
+	finish := func() error {
+		if sect == nil {
+			return nil
+		}
+		if len(grid) > 0 {
+			p, err := chords.ParseProgression(strings.Replace(strings.Join(grid, " "), ".", "/", -1))
+			if err != nil {
+				return fmt.Errorf("section %q: %v", sect.Name, err)
+			}
+			sect.Body.Bars = append(sect.Body.Bars, p.Bars...)
+			grid = nil
+		}
+		if len(sect.Body.Bars) > 0 || sect.Name != "" {
+			song.Sections = append(song.Sections, sect)
+		}
+		sect = nil
+		return nil
+	}
+	start := func(name string) {
+		sect = &chords.Section{Name: name, Body: &chords.Progression{BeatsPerBar: 4}}
+	}
+	for _, line := range d.Lines {
+		switch line.Kind {
+		case DirectiveLine:
+			lower := strings.ToLower(line.Name)
+			if name, ok := sectionNames[lower]; ok {
+				if err := finish(); err != nil {
+					return nil, err
+				}
+				if line.Value != "" {
+					name = line.Value
+				}
+				start(name)
+				if lower == "start_of_chorus" || lower == "soc" {
+					lastChorus = sect
+				}
+			} else if strings.HasPrefix(lower, "end_of_") || strings.HasPrefix(lower, "eo") {
+				if err := finish(); err != nil {
+					return nil, err
+				}
+			} else if lower == "chorus" && lastChorus != nil {
+				if err := finish(); err != nil {
+					return nil, err
+				}
+				song.Sections = append(song.Sections, lastChorus)
+			}
+		case GridLine:
+			if sect == nil {
+				start("")
+			}
+			var text bytes.Buffer
+			for _, seg := range line.Segments {
+				if seg.Chord != nil {
+					text.WriteString(seg.Chord.String())
+				} else {
+					text.WriteString(seg.ChordText)
+				}
+				text.WriteString(seg.Lyrics)
+			}
+			grid = append(grid, text.String())
+		case LyricsLine:
+			for _, seg := range line.Segments {
+				if seg.Chord == nil {
+					continue
+				}
+				if sect == nil {
+					start("")
+				}
+				sect.Body.Bars = append(sect.Body.Bars, chords.Bar{
+					Chords: []chords.BarChord{{Chord: seg.Chord, Beats: 4}},
+				})
+			}
+		}
+	}
+	if err := finish(); err != nil {
+		return nil, err
+	}
+	return song, nil
+}
+
+// Transpose returns a new document that is this document transposed by the
+// given interval. Chords are transposed and respelled the same way as for
+// chords.Song.Transpose: the song's key is taken from the metadata, if
+// present, or inferred from the chords otherwise.
+//
+// Only the root and bass notes of each chord are rewritten. The rest of the
+// chord text is left as is, so "[C#m7]" becomes "[Ebm7]", not "[E♭-7]".
+// Accidentals are written using ASCII characters ('#' and 'b') unless the
+// original chord used Unicode symbols ('♯' and '♭'). The key in the metadata,
+// and in any {key} directives in the body, are also transposed.
+func (d *Document) Transpose(intv chords.Interval) *Document {
+	return d.transpose(func(s *chords.Song) *chords.Song { return s.Transpose(intv) })
+}
+
+// TransposeToKey returns a new document that is this document transposed into
+// the given key. (See chords.Song.TransposeToKey and Transpose.)
+func (d *Document) TransposeToKey(key chords.Key) *Document {
+	return d.transpose(func(s *chords.Song) *chords.Song { return s.TransposeToKey(key) })
+}
+
+func (d *Document) transpose(fn func(*chords.Song) *chords.Song) *Document {
+	// put all chords into one progression, one per bar, so they are respelled
+	// consistently
+	chs := d.Chords()
+	p := &chords.Progression{BeatsPerBar: 4}
+	for _, ch := range chs {
+		p.Bars = append(p.Bars, chords.Bar{Chords: []chords.BarChord{{Chord: ch, Beats: 4}}})
+	}
+	song := fn(&chords.Song{Metadata: d.Metadata, Sections: []*chords.Section{{Body: p}}})
+	transposed := song.Sections[0].Body.Chords()
+
+	ret := &Document{Metadata: song.Metadata, Lines: make([]Line, len(d.Lines))}
+	var intv chords.Interval
+	switch {
+	case d.Metadata.Key.Tonic.N != 0:
+		intv = d.Metadata.Key.Tonic.IntervalTo(song.Metadata.Key.Tonic)
+	case len(chs) > 0:
+		intv = chs[0].Root.IntervalTo(transposed[0].Root)
+	default:
+		intv = chords.Interval{Val: 1}
+	}
+	i := 0
+	for l, line := range d.Lines {
+		ret.Lines[l] = line
+		if line.Kind == DirectiveLine && strings.EqualFold(line.Name, "key") {
+			if k, err := chords.ParseKey(line.Value); err == nil {
+				ret.Lines[l].Value = chords.Key{Tonic: k.Tonic.Transpose(intv), Minor: k.Minor}.String()
+			}
+			continue
+		}
+		if len(line.Segments) == 0 {
+			continue
+		}
+		ret.Lines[l].Segments = make([]Segment, len(line.Segments))
+		for s, seg := range line.Segments {
+			if seg.Chord != nil {
+				seg.ChordText = Respell(seg.ChordText, seg.Chord, transposed[i])
+				seg.Chord = transposed[i]
+				i++
+			}
+			ret.Lines[l].Segments[s] = seg
+		}
+	}
+	return ret
+}
+
+// Respell rewrites the root and bass notes in the given chord text, which was
+// parsed into the old chord, so that they match the new chord, like a
+// transposition of the old chord. The rest of the text is left as is, so
+// "C#m7" respelled for E♭-7 becomes "Ebm7", not "E♭-7". Accidentals are
+// written with ASCII characters unless the given text uses the Unicode
+// symbols.
+func Respell(text string, old, new *chords.Chord) string {
+	unicode := strings.ContainsAny(text, "♯♭𝄪𝄫♮")
+	body := text
+	bass := ""
+	if old.Bass.N != 0 {
+		if pos := strings.LastIndexByte(text, '/'); pos >= 0 {
+			body, bass = text[:pos], text[pos+1:]
+		}
+	}
+	ret := noteText(new.Root, unicode) + body[notePrefixLen(body):]
+	if bass != "" {
+		ret += "/" + noteText(new.Bass, unicode) + bass[notePrefixLen(bass):]
+	}
+	return ret
+}
+
+// accidentalSpellings are the ways an accidental can be written in a chord
+// symbol, as accepted by chords.ParseChord. A double-flat written as "bb" is
+// listed before "b", so the longest spelling is matched.
+var accidentalSpellings = []string{"bb", "b", "#", "x", "n", "♭", "♯", "𝄫", "𝄪", "♮"}
+
+// notePrefixLen returns the length of the note name and accidental at the
+// start of the given text.
+func notePrefixLen(s string) int {
+	if s == "" {
+		return 0
+	}
+	for _, acc := range accidentalSpellings {
+		if strings.HasPrefix(s[1:], acc) {
+			return 1 + len(acc)
+		}
+	}
+	return 1
+}
+
+// noteText returns the given note as text. If unicode is false, accidentals
+// are written with ASCII characters.
+func noteText(n chords.Note, unicode bool) string {
+	if unicode {
+		return n.String()
+	}
+	acc := ""
+	switch n.Acc {
+	case chords.Sharp:
+		acc = "#"
+	case chords.Flat:
+		acc = "b"
+	case chords.DblSharp:
+		acc = "x"
+	case chords.DblFlat:
+		acc = "bb"
+	}
+	return n.N.String() + acc
+}
@@ -0,0 +1,146 @@
+package chordpro
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/jhump/chords"
+)
+
+const testDoc = `{title: Amazing Grace}
+{key: G}
+# traditional
+{start_of_verse}
+A[G]mazing [G7]grace, how [C]sweet the [G]sound
+That [G]saved a [Em]wretch like [D]me [*Riff]
+{end_of_verse}
+
+{start_of_chorus: Refrain}
+[C]Was [G/B]blind but [D7sus4]now I [G]see [N.C.]
+{end_of_chorus}
+{chorus}
+{start_of_grid}
+| G . . . | C . D . |
+{end_of_grid}
+`
+
+func TestParse(t *testing.T) {
+	doc, err := Parse(testDoc)
+	if err != nil {
+		t.Fatalf("failed to parse: %v", err)
+	}
+	if doc.Metadata.Title != "Amazing Grace" || doc.Metadata.Key.String() != "G" {
+		t.Errorf("wrong metadata: %+v", doc.Metadata)
+	}
+	if actual := doc.String(); actual != testDoc {
+		t.Errorf("round trip failed:\n%s", actual)
+	}
+	var strs []string
+	for _, ch := range doc.Chords() {
+		strs = append(strs, ch.String())
+	}
+	exp := "G G7 C G G E- D C G/B Dsus7 4 G G C D"
+	if actual := strings.Join(strs, " "); actual != exp {
+		t.Errorf("Document.Chords returned wrong value: %q != %q", actual, exp)
+	}
+	line := doc.Lines[2]
+	if line.Kind != LyricsLine || len(line.Segments) != 5 || line.Segments[0].Lyrics != "A" ||
+		line.Segments[4].ChordText != "G" || line.Segments[4].Lyrics != "sound" {
+		t.Errorf("wrong segments: %+v", line.Segments)
+	}
+
+	if _, err := Parse("A[G]mazing [G7grace\n"); err == nil {
+		t.Errorf("Parse(%q) should have failed", "A[G]mazing [G7grace\n")
+	}
+}
+
+func TestDocument_Transpose(t *testing.T) {
+	doc, err := Parse(testDoc)
+	if err != nil {
+		t.Fatalf("failed to parse: %v", err)
+	}
+	actual := doc.Transpose(chords.Interval{Val: 2, Offset: -1}).String()
+	exp := strings.NewReplacer(
+		"{key: G}", "{key: A♭}",
+		"[G]", "[Ab]", "[G7]", "[Ab7]", "[C]", "[Db]", "[Em]", "[Fm]", "[D]", "[Eb]",
+		"[G/B]", "[Ab/C]", "[D7sus4]", "[Eb7sus4]",
+		"| G . . . | C . D . |", "| Ab . . . | Db . Eb . |",
+	).Replace(testDoc)
+	if actual != exp {
+		t.Errorf("wrong transposition:\n%s", actual)
+	}
+
+	doc, err = Parse("{key: C}\n[C#m7]one [D♭7]two\n{key: E}\n[E]three\n")
+	if err != nil {
+		t.Fatalf("failed to parse: %v", err)
+	}
+	actual = doc.TransposeToKey(chords.MustParseKey("E")).String()
+	if exp := "{key: E}\n[Fm7]one [F7]two\n{key: G♯}\n[G#]three\n"; actual != exp {
+		t.Errorf("wrong transposition:\n%s", actual)
+	}
+}
+
+func TestDocument_TransposeAccidentals(t *testing.T) {
+	// every spelling of an accidental accepted by chords.ParseChord is replaced
+	cases := []struct {
+		chord string
+		exp   string
+	}{
+		{"Fx7", "B7"},
+		{"F𝄪7", "B7"},
+		{"Ebb-7", "F#-7"},
+		{"E𝄫-7", "F♯-7"},
+		{"Bn", "D#"},
+		{"B♮", "D♯"},
+		{"Bbm", "Dm"},
+		{"C#/Gx", "E#/C#"},
+		{"Dbb/Fx", "E/B"},
+	}
+	for _, tc := range cases {
+		doc, err := Parse("{key: C}\n[" + tc.chord + "]one\n")
+		if err != nil {
+			t.Fatalf("failed to parse: %v", err)
+		}
+		tr := doc.TransposeToKey(chords.MustParseKey("E"))
+		if actual := tr.Lines[0].Segments[0].ChordText; actual != tc.exp {
+			t.Errorf("Document.TransposeToKey for %s returned wrong value: %q != %q", tc.chord, actual, tc.exp)
+			continue
+		}
+		// the text of the transposed chord parses back into the transposed chord
+		reparsed, err := Parse(tr.String())
+		if err != nil {
+			t.Errorf("Parse(%q) failed: %v", tr.String(), err)
+			continue
+		}
+		if actual, exp := reparsed.Chords()[0].String(), tr.Chords()[0].String(); actual != exp {
+			t.Errorf("Parse for transposed %s returned wrong value: %s != %s", tc.chord, actual, exp)
+		}
+		// and transposing back gives the same chord
+		back := reparsed.TransposeToKey(chords.MustParseKey("C")).Chords()[0]
+		if back.PitchClassSet() != doc.Chords()[0].PitchClassSet() {
+			t.Errorf("Document.TransposeToKey for %s back to C returned wrong value: %v", tc.exp, back)
+		}
+	}
+}
+
+func TestDocument_Song(t *testing.T) {
+	doc, err := Parse(testDoc)
+	if err != nil {
+		t.Fatalf("failed to parse: %v", err)
+	}
+	song, err := doc.Song()
+	if err != nil {
+		t.Fatalf("failed to convert to song: %v", err)
+	}
+	var strs []string
+	for _, sect := range song.Sections {
+		strs = append(strs, sect.Name+": "+sect.Body.String())
+	}
+	exp := "Verse: | G | G7 | C | G | G | E- | D |, " +
+		"Refrain: | C | G/B | Dsus7 4 | G |, " +
+		"Refrain: | C | G/B | Dsus7 4 | G |, " +
+		"Grid: | G | C D |"
+	if actual := strings.Join(strs, ", "); actual != exp {
+		t.Errorf("Document.Song returned wrong value: %q != %q", actual, exp)
+	}
+}
@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"math"
 	"sort"
+	"strings"
 )
 
 //go:generate goyacc -o chordparse.y.go -p chord chordparse.y
@@ -150,31 +151,57 @@ func MustParseChord(s string) *Chord {
 // considered inconsistent and thus invalid. A chord whose triad type
 // is SUS but has no valid suspension note in its ExtraTones is also invalid.
 func (ch *Chord) Validate() error {
+	var first error
+	ch.validate(func(err error) bool {
+		first = err
+		return false
+	})
+	return first
+}
+
+// validate checks that the chord is valid, calling the given function with
+// each problem found. If the function returns false, no more problems are
+// reported.
+func (ch *Chord) validate(report func(error) bool) {
 	if !ch.Root.IsValid() {
-		return fmt.Errorf("chord root %v is invalid", ch.Root)
+		if !report(fmt.Errorf("chord root %v is invalid", ch.Root)) {
+			return
+		}
 	}
 	if ch.Bass.N != 0 && !ch.Bass.IsValid() {
-		return fmt.Errorf("chord bass note %v is invalid", ch.Bass)
+		if !report(fmt.Errorf("chord bass note %v is invalid", ch.Bass)) {
+			return
+		}
 	}
 	if !ch.Triad.IsValid() {
-		return fmt.Errorf("chord triad type %v is invalid", ch.Triad)
+		if !report(fmt.Errorf("chord triad type %v is invalid", ch.Triad)) {
+			return
+		}
 	}
 
 	t := map[int8]Accidental{}
 	for _, e := range ch.ExtraTones {
 		if !e.IsValid() {
-			return fmt.Errorf("tone %v is invalid", e)
+			if !report(fmt.Errorf("tone %v is invalid", e)) {
+				return
+			}
+			continue
 		}
 		v := e.Val
 		if v > 7 {
 			v -= 7
 		}
 		if v < 2 || v == 3 || v > 7 {
-			return fmt.Errorf("tone %d is not a valid chord extra", e.Val)
+			if !report(fmt.Errorf("tone %d is not a valid chord extra", e.Val)) {
+				return
+			}
+			continue
 		}
 		a, ok := t[v]
 		if ok && a != e.Acc {
-			return fmt.Errorf("tone %d has conflicting accidentals: %v and %v", e.Val, a, e.Acc)
+			if !report(fmt.Errorf("tone %d has conflicting accidentals: %v and %v", e.Val, a, e.Acc)) {
+				return
+			}
 		} else if !ok {
 			t[v] = e.Acc
 		}
@@ -183,30 +210,30 @@ func (ch *Chord) Validate() error {
 	if ch.Triad == FDim || ch.Triad == Dim3 {
 		a, ok := t[7]
 		if ok && a != Natural {
-			return fmt.Errorf("diminished chord (other than half diminished) should not have modified 7th: %v", a)
+			if !report(fmt.Errorf("diminished chord (other than half diminished) should not have modified 7th: %v", a)) {
+				return
+			}
 		}
 	}
 	if ch.Triad == FDim || ch.Triad == HDim || ch.Triad == Dim3 {
 		a, ok := t[5]
 		if ok && a != Flat {
-			return fmt.Errorf("diminished chord should not have non-flat 5th: %v", a)
+			report(fmt.Errorf("diminished chord should not have non-flat 5th: %v", a))
 		}
 	} else if ch.Triad == Aug3 {
 		a, ok := t[5]
 		if ok && a != Sharp {
-			return fmt.Errorf("augmented chord should not have non-sharp 5th: %v", a)
+			report(fmt.Errorf("augmented chord should not have non-sharp 5th: %v", a))
 		}
 	} else if ch.Triad == Sus {
 		_, ok2 := t[2]
 		if !ok2 {
 			_, ok4 := t[4]
 			if !ok4 {
-				return errors.New("suspended chord must have 2nd or 4th as suspension note")
+				report(errors.New("suspended chord must have 2nd or 4th as suspension note"))
 			}
 		}
 	}
-
-	return nil
 }
 
 // Canonicalize modifies the chord into a simpler and canonical representation.
@@ -522,6 +549,29 @@ func (ch *Chord) Canonicalize() {
 	ch.canonical = true
 }
 
+// Canonical returns a new chord that is this chord in canonical form. (See
+// Canonicalize.) Unlike Canonicalize, this chord is not modified, so it is
+// safe to use with chords that are shared, like those stored in a map or used
+// by several goroutines. The result does not share this chord's extra tones.
+func (ch *Chord) Canonical() *Chord {
+	ret := ch.Clone()
+	ret.Canonicalize()
+	return ret
+}
+
+// CanonicalizeStrict is like Canonicalize, but it first checks that the chord
+// is valid. (See Validate.) If the chord is not valid, like C7♭9♯9 where the
+// 9th has conflicting accidentals, this returns the error from Validate and
+// the chord is not modified. Canonicalize does its best with such chords, but
+// its result may not make sense.
+func (ch *Chord) CanonicalizeStrict() error {
+	if err := ch.Validate(); err != nil {
+		return err
+	}
+	ch.Canonicalize()
+	return nil
+}
+
 func removeTone(tns []ChordTone, toRemove ChordTone) []ChordTone {
 	var ret []ChordTone
 	for _, tn := range tns {
@@ -641,6 +691,118 @@ func (ch *Chord) Spell() []Note {
 	return ret
 }
 
+// SpellInKey enumerates all of the notes in the chord, like Spell, but spells
+// them to be consistent with the given key. Notes that are in the key's scale
+// use the scale's spelling. So a G♯ major chord in the key of E♭ major is
+// spelled A♭, C, E♭ instead of G♯, B♯, D♯. Other notes keep their spelling
+// unless it has a double accidental or is an accidental on a note that has a
+// natural spelling (like E♯ or C♭). Those notes are respelled as a natural if
+// possible, or else with an accidental that matches the key signature (flats
+// for flat keys and sharps for others).
+func (ch *Chord) SpellInKey(key Key) []Note {
+	return spellNotesInKey(ch.Spell(), key)
+}
+
+// spellNotesInKey respells the given notes, in place, to be consistent with
+// the given key, as described for Chord.SpellInKey. It returns the notes.
+func spellNotesInKey(notes []Note, key Key) []Note {
+	scale := key.Scale().Spell()
+	acc := Sharp
+	if key.fifths() < 0 {
+		acc = Flat
+	}
+	for i, n := range notes {
+		diatonic := false
+		for _, sn := range scale {
+			if sn.Cardinal() == n.Cardinal() {
+				notes[i] = sn
+				diatonic = true
+				break
+			}
+		}
+		if diatonic || n.Acc == Natural {
+			continue
+		}
+		if e := n.Enharmonic(Natural); e.N != 0 {
+			notes[i] = e
+		} else if n.Acc == DblFlat || n.Acc == DblSharp {
+			if e := n.Enharmonic(acc); e.N != 0 {
+				notes[i] = e
+			} else {
+				notes[i] = n.Enharmonic(-acc)
+			}
+		}
+	}
+	return notes
+}
+
+// Clone returns a deep copy of the chord. The copy does not share the chord's
+// extra tones, so either can be modified without affecting the other. (A plain
+// copy of a Chord value shares them, so appending to or changing the extra
+// tones of one can change the other.)
+func (ch *Chord) Clone() *Chord {
+	ret := *ch
+	ret.ExtraTones = append([]ChordTone(nil), ch.ExtraTones...)
+	return &ret
+}
+
+// Transpose returns a new chord that is this chord transposed by the given
+// interval. The root and bass notes are transposed, and all other attributes
+// of the chord are unchanged.
+func (ch *Chord) Transpose(interval Interval) *Chord {
+	ret := *ch
+	ret.Root = ch.Root.Transpose(interval)
+	if ch.Bass.N != 0 {
+		ret.Bass = ch.Bass.Transpose(interval)
+	}
+	ret.ExtraTones = append([]ChordTone(nil), ch.ExtraTones...)
+	return &ret
+}
+
+// With returns a new chord that is this chord with the given tone added, like
+// C7 with a ♭9 added is C7♭9. If the chord already has the tone, the result
+// is an unchanged copy. This chord is not modified, and the result does not
+// share its extra tones, so it can be modified without affecting this chord.
+func (ch *Chord) With(tone ChordTone) *Chord {
+	ret := ch.Clone()
+	if !containsTone(ret.ExtraTones, tone) {
+		ret.ExtraTones = append(ret.ExtraTones, tone)
+		ret.canonical = false
+	}
+	return ret
+}
+
+// Without returns a new chord that is this chord without any of its extra
+// tones with the given value, like C7♭9 without its 9th is C7. The tones of
+// the chord's triad are not extra tones, so they cannot be removed this way.
+// As with With, this chord is not modified.
+func (ch *Chord) Without(val int8) *Chord {
+	ret := ch.Clone()
+	tones := ret.ExtraTones[:0]
+	for _, tn := range ret.ExtraTones {
+		if tn.Val != val {
+			tones = append(tones, tn)
+		}
+	}
+	if len(tones) < len(ret.ExtraTones) {
+		ret.canonical = false
+	}
+	ret.ExtraTones = tones
+	return ret
+}
+
+// WithBass returns a new chord that is this chord with the given bass note,
+// like C7 with the bass note E is C7/E. If the given note is the zero value,
+// the result has no bass note. As with With, this chord is not modified.
+func (ch *Chord) WithBass(n Note) *Chord {
+	ret := ch.Clone()
+	if ret.Bass != n {
+		ret.Bass = n
+		ret.canonical = false
+	}
+	return ret
+}
+
 func (c *Chord) ChordType() *ChordType {
 	var bassInterval Interval
 	if c.Bass.N != 0 {
@@ -947,7 +1109,35 @@ func (c *ChordType) Chord(root Note) *Chord {
 	}
 }
 
-// TODO: ChordType.Canonicalize()
+// Canonicalize modifies the chord type into a simpler and canonical
+// representation, the same way as Chord.Canonicalize.
+func (c *ChordType) Canonicalize() {
+	if c.canonical {
+		return
+	}
+	ch := c.Chord(Note{N: C})
+	ch.Canonicalize()
+	c.Triad = ch.Triad
+	c.ExtraTones = ch.ExtraTones
+	c.canonical = true
+}
+
+// Canonical returns a new chord type that is this chord type in canonical
+// form. Unlike Canonicalize, this chord type is not modified. (See
+// Chord.Canonical.)
+func (c *ChordType) Canonical() *ChordType {
+	ret := c.Clone()
+	ret.Canonicalize()
+	return ret
+}
+
+// Clone returns a deep copy of the chord type. The copy does not share the
+// chord type's extra tones. (See Chord.Clone.)
+func (c *ChordType) Clone() *ChordType {
+	ret := *c
+	ret.ExtraTones = append([]ChordTone(nil), c.ExtraTones...)
+	return &ret
+}
 
 // ScaleChord represents a chord that can be transposed to any scale.
 // Instead of having chord tones represented as notes (like C# for example),
@@ -961,7 +1151,7 @@ func (c *ChordType) Chord(root Note) *Chord {
 //
 // For example, a ScaleChord with a root of {3,0} (i.e. a major third) and
 // a type that is a major triad with a dominant 7 would be printed to string
-// as "III 7 9". If the ScaleChord were a minor triad with no extra tones and
+// as "III7". If the ScaleChord were a minor triad with no extra tones and
 // and a root of {4,0} (e.g. a perfect fourth), it would be "iv".
 //
 // Whether a root interval of a major third is printed as "iii" vs "# iii"
@@ -987,20 +1177,253 @@ func (s *ScaleChord) InKey(keyName Note) *Chord {
 	return s.Type.Chord(chordRoot)
 }
 
+// romanNumerals are the upper-case roman numerals for the degrees of a scale.
+var romanNumerals = [...]string{"I", "II", "III", "IV", "V", "VI", "VII"}
+
+// minorKeyOffsets are the offsets of each degree of the natural minor scale,
+// relative to the major scale.
+var minorKeyOffsets = [...]int8{0, 0, -1, 0, 0, -1, -1}
+
+// String implements the Stringer interface. The result is a roman numeral, in
+// lower-case if the chord is minor or diminished, followed by the chord's
+// quality and extensions in the same notation as Chord.String (except that a
+// minor triad has no "-" since the numeral is already lower-case). For example,
+// "I△7", "ii7", "viiø", and "♭VII7". An inversion is followed by a slash and
+// the numeral of the bass note, like "I/III".
 func (s *ScaleChord) String() string {
-	// TODO
-	// iv
-	return ""
+	var b bytes.Buffer
+	b.WriteString(s.numeral(s.Root, s.Type.hasMinorThird()))
+	ch := s.Type.Chord(Note{N: C})
+	ch.Bass = Note{}
+	quality := strings.TrimPrefix(ch.String(), "C")
+	if s.Type.Triad == Min3 {
+		quality = strings.TrimPrefix(quality, "-")
+	}
+	b.WriteString(quality)
+	if bass := s.Type.Bass; bass.Val > 1 || bass.Offset != 0 {
+		b.WriteByte('/')
+		b.WriteString(s.numeral(addIntervals(s.Root, bass), false))
+	}
+	return b.String()
+}
+
+// numeral returns the roman numeral for the given interval above the scale
+// root. It has an accidental if the interval is not in the scale (a major
+// scale or, if s.InMinorKey, a natural minor scale).
+func (s *ScaleChord) numeral(intv Interval, lower bool) string {
+	offset := intv.Offset
+	if s.InMinorKey {
+		offset -= minorKeyOffsets[intv.Val-1]
+	}
+	str := romanNumerals[intv.Val-1]
+	if lower {
+		str = strings.ToLower(str)
+	}
+	if offset != 0 {
+		str = Accidental(offset).String() + str
+	}
+	return str
 }
 
-// TODO: ParseScaleChord?
+// ParseScaleChord parses a chord in roman numeral notation, in the format
+// produced by ScaleChord.String. The numeral may be preceded by accidentals,
+// which may be written with ASCII characters ('b' and '#') and indicate that
+// the root is not in the scale. The numeral is followed by the chord's quality
+// and extensions, in the notation accepted by ParseChord. A lower-case numeral
+// means a minor triad, unless the quality indicates a diminished chord ("o",
+// "ø", or "dim"). An optional slash and numeral indicate the bass note, like
+// "I/III".
+//
+// The minor flag indicates whether the numerals are relative to a minor key,
+// which affects which roots have accidentals. (See ScaleChord.InMinorKey.)
+func ParseScaleChord(s string, minor bool) (*ScaleChord, error) {
+	sc := &ScaleChord{InMinorKey: minor}
+	root, lower, rest, err := sc.parseNumeral(s)
+	if err != nil {
+		return nil, err
+	}
+	sc.Root = root
+	quality, bass := rest, ""
+	if pos := strings.IndexByte(rest, '/'); pos >= 0 {
+		quality, bass = rest[:pos], rest[pos+1:]
+	}
+	if lower && !strings.HasPrefix(quality, "o") && !strings.HasPrefix(quality, "ø") &&
+		!strings.HasPrefix(quality, "dim") {
+		quality = "-" + quality
+	}
+	ch, err := ParseChord("C" + quality)
+	if err != nil {
+		return nil, fmt.Errorf("invalid chord quality %q: %v", rest, err)
+	}
+	ch.Canonicalize()
+	sc.Type = *ch.ChordType()
+	if bass != "" {
+		bassIntv, _, extra, err := sc.parseNumeral(bass)
+		if err != nil {
+			return nil, err
+		}
+		if extra != "" {
+			return nil, fmt.Errorf("invalid bass %q", bass)
+		}
+		c := Note{N: C}
+		sc.Type.Bass = c.Transpose(sc.Root).IntervalTo(c.Transpose(bassIntv))
+	}
+	return sc, nil
+}
+
+// MustParseScaleChord parses the given string and panics if it is not valid.
+// (See ParseScaleChord.)
+func MustParseScaleChord(s string, minor bool) *ScaleChord {
+	sc, err := ParseScaleChord(s, minor)
+	if err != nil {
+		panic(err)
+	}
+	return sc
+}
+
+// parseNumeral parses a roman numeral, with optional leading accidentals, from
+// the start of the given string. It returns the interval above the scale root,
+// whether the numeral is lower-case, and the rest of the string.
+func (s *ScaleChord) parseNumeral(str string) (Interval, bool, string, error) {
+	var offset int8
+accidentals:
+	for {
+		for _, acc := range []Accidental{Flat, Sharp, DblFlat, DblSharp} {
+			if strings.HasPrefix(str, acc.String()) {
+				offset += acc.Offset()
+				str = str[len(acc.String()):]
+				continue accidentals
+			}
+		}
+		switch {
+		case strings.HasPrefix(str, "b"):
+			offset--
+		case strings.HasPrefix(str, "#"):
+			offset++
+		default:
+			break accidentals
+		}
+		str = str[1:]
+	}
+	for i := len(romanNumerals) - 1; i >= 0; i-- {
+		for _, numeral := range []string{romanNumerals[i], strings.ToLower(romanNumerals[i])} {
+			if !strings.HasPrefix(str, numeral) {
+				continue
+			}
+			intv := Interval{Val: int8(i + 1), Offset: offset}
+			if s.InMinorKey {
+				intv.Offset += minorKeyOffsets[i]
+			}
+			if !intv.IsValid() {
+				return Interval{}, false, "", fmt.Errorf("invalid numeral %q", str)
+			}
+			return intv, numeral != romanNumerals[i], str[len(numeral):], nil
+		}
+	}
+	return Interval{}, false, "", fmt.Errorf("expecting roman numeral: %q", str)
+}
 
 func NewScaleChord(s ScaleType, root int8, extraTones ...int8) *ScaleChord {
 	// TODO
 	return nil
 }
 
-func InferChord(notes ...Note) *Chord {
-	// TODO: wouldn't this be cool
-	return nil
+// chordFromPitchClasses returns a canonical chord with the given root that has
+// the given pitch classes. The pitch classes are given as a bitmask (see
+// pitchClasses), relative to the root: bit 0 is the root, bit 4 is a major
+// third above it, and so on. Tones are named based on their distance from the
+// root: so three half-steps is a minor 3rd, unless the chord also has a major
+// 3rd, in which case it is a ♯9. Since a Chord always has a 5th, a perfect 5th
+// is included even if it is not in the given set. This returns nil if the
+// pitch classes do not make a valid chord (e.g. if there is no 3rd and no
+// suspension).
+func chordFromPitchClasses(root Note, set uint16) *Chord {
+	has := func(steps int) bool {
+		return set&(1<<uint(steps)) != 0
+	}
+	used := uint16(1)
+	use := func(steps int) {
+		used |= 1 << uint(steps)
+	}
+	ch := &Chord{Root: root}
+	switch {
+	case has(4):
+		ch.Triad = Maj3
+		use(4)
+	case has(3):
+		ch.Triad = Min3
+		use(3)
+	default:
+		ch.Triad = Sus
+	}
+	if ch.Triad == Sus {
+		// prefer a 4th as the suspension
+		switch {
+		case has(5):
+			ch.ExtraTones = append(ch.ExtraTones, ChordTone{Val: 4})
+			use(5)
+		case has(2):
+			ch.ExtraTones = append(ch.ExtraTones, ChordTone{Val: 2})
+			use(2)
+		case has(6):
+			ch.ExtraTones = append(ch.ExtraTones, ChordTone{Val: 4, Acc: Sharp})
+			use(6)
+		case has(1):
+			ch.ExtraTones = append(ch.ExtraTones, ChordTone{Val: 2, Acc: Flat})
+			use(1)
+		default:
+			return nil
+		}
+	}
+	hasSeventh := has(10) || has(11)
+	// tones that are spelled differently without a 7th
+	ext := func(val int8, acc Accidental) ChordTone {
+		if !hasSeventh {
+			val -= 7
+		}
+		return ChordTone{Val: val, Acc: acc}
+	}
+
+	switch {
+	case has(7):
+		use(7)
+	case has(6) && used&(1<<6) == 0:
+		ch.ExtraTones = append(ch.ExtraTones, ChordTone{Val: 5, Acc: Flat})
+		use(6)
+	case has(8) && ch.Triad != Sus:
+		ch.ExtraTones = append(ch.ExtraTones, ChordTone{Val: 5, Acc: Sharp})
+		use(8)
+	}
+	switch {
+	case has(10):
+		ch.ExtraTones = append(ch.ExtraTones, ChordTone{Val: 7})
+		use(10)
+	case has(11):
+		ch.ExtraTones = append(ch.ExtraTones, ChordTone{Val: 7, Acc: Sharp})
+		use(11)
+	case has(9) && ch.Triad == Min3 && used&(1<<6) != 0:
+		// diminished 7th
+		ch.ExtraTones = append(ch.ExtraTones, ChordTone{Val: 7, Acc: Flat})
+		use(9)
+	}
+	for steps, tn := range [12]ChordTone{
+		1:  ext(9, Flat),
+		2:  ext(9, Natural),
+		3:  ext(9, Sharp),
+		5:  ext(11, Natural),
+		6:  ext(11, Sharp),
+		8:  ext(13, Flat),
+		9:  ext(13, Natural),
+		11: {Val: 7, Acc: Sharp},
+	} {
+		if tn.Val != 0 && has(steps) && used&(1<<uint(steps)) == 0 {
+			ch.ExtraTones = append(ch.ExtraTones, tn)
+			use(steps)
+		}
+	}
+	if ch.Validate() != nil {
+		return nil
+	}
+	ch.Canonicalize()
+	return ch
 }
@@ -0,0 +1,144 @@
+package chords
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestChord_SpellInKey(t *testing.T) {
+	cases := []struct {
+		chord, key, exp string
+	}{
+		{"G#", "Eb", "A♭ C E♭"},
+		{"Bb7", "C", "B♭ D F A♭"},
+		{"D7#9", "Eb", "D F♯ A C F"},
+		{"Db", "C#m", "C♯ F G♯"},
+		{"E#7", "C", "F A C D♯"},
+		{"Gbo", "F", "G♭ A C E♭"},
+		{"Gbo", "D", "F♯ A C D♯"},
+		{"Gb-7/Fb", "C", "E G♭ A D♭ E"},
+	}
+	for _, tc := range cases {
+		var notes []string
+		for _, n := range MustParseChord(tc.chord).SpellInKey(MustParseKey(tc.key)) {
+			notes = append(notes, n.String())
+		}
+		if strings.Join(notes, " ") != tc.exp {
+			t.Errorf("Chord.SpellInKey for %s in %s returned wrong value: %v != %s", tc.chord, tc.key, notes, tc.exp)
+		}
+	}
+}
+
+func TestChord_With(t *testing.T) {
+	orig := MustParseChord("C7")
+	orig.Canonicalize()
+
+	flat9 := orig.With(ChordTone{Val: 9, Acc: Flat})
+	sharp9 := orig.With(ChordTone{Val: 9, Acc: Sharp})
+	if flat9.String() != "C7♭9" || sharp9.String() != "C7♯9" {
+		t.Errorf("Chord.With returned wrong value: %v, %v != C7♭9, C7♯9", flat9, sharp9)
+	}
+	if orig.String() != "C7" {
+		t.Errorf("Chord.With modified the original chord: %v", orig)
+	}
+	if same := flat9.With(ChordTone{Val: 9, Acc: Flat}); same.String() != "C7♭9" {
+		t.Errorf("Chord.With for C7♭9 returned wrong value: %v != C7♭9", same)
+	}
+	// the added tone is canonicalized like any other
+	ch := orig.With(ChordTone{Val: 5, Acc: Sharp})
+	ch.Canonicalize()
+	if ch.String() != "C+7" {
+		t.Errorf("Chord.With for ♯5 returned wrong value: %v != C+7", ch)
+	}
+
+	if without := MustParseChord("C7♭9♯9").Without(9); without.String() != "C7" {
+		t.Errorf("Chord.Without for C7♭9♯9 returned wrong value: %v != C7", without)
+	}
+	if without := orig.Without(3); without.String() != "C7" {
+		t.Errorf("Chord.Without for C7 returned wrong value: %v != C7", without)
+	}
+
+	slash := orig.WithBass(MustParseNote("E"))
+	if slash.String() != "C7/E" {
+		t.Errorf("Chord.WithBass for E returned wrong value: %v != C7/E", slash)
+	}
+	if ch := slash.WithBass(Note{}); ch.String() != "C7" {
+		t.Errorf("Chord.WithBass for no bass returned wrong value: %v != C7", ch)
+	}
+	if orig.Bass.N != 0 {
+		t.Errorf("Chord.WithBass modified the original chord: %v", orig)
+	}
+}
+
+func TestChord_Canonical(t *testing.T) {
+	ch := MustParseChord("C-7♭5")
+	orig := ch.String()
+	canonical := ch.Canonical()
+	if canonical.String() != "Cø" {
+		t.Errorf("Chord.Canonical returned wrong value: %v != Cø", canonical)
+	}
+	if ch.String() != orig {
+		t.Errorf("Chord.Canonical modified the original chord: %v", ch)
+	}
+	// the result does not share extra tones with the original
+	canonical.ExtraTones = append(canonical.ExtraTones[:0], ChordTone{Val: 9})
+	if ch.String() != orig {
+		t.Errorf("Chord.Canonical shares extra tones with the original chord: %v", ch)
+	}
+
+	ct := MustParseChord("C7♯5/E").ChordType()
+	origType := ct.Chord(MustParseNote("D")).String()
+	canonicalType := ct.Canonical()
+	if actual := canonicalType.Chord(MustParseNote("D")).String(); actual != "D+7/F♯" {
+		t.Errorf("ChordType.Canonical returned wrong value: %s != D+7/F♯", actual)
+	}
+	if actual := ct.Chord(MustParseNote("D")).String(); actual != origType {
+		t.Errorf("ChordType.Canonical modified the original chord type: %s", actual)
+	}
+	ct.Canonicalize()
+	if actual := ct.Chord(MustParseNote("D")).String(); actual != "D+7/F♯" {
+		t.Errorf("ChordType.Canonicalize returned wrong value: %s != D+7/F♯", actual)
+	}
+}
+
+func TestChord_Clone(t *testing.T) {
+	ch := MustParseChord("C7♭9/E")
+	orig := ch.String()
+	clone := ch.Clone()
+	if clone.String() != ch.String() {
+		t.Errorf("Chord.Clone returned wrong value: %v != %v", clone, ch)
+	}
+	clone.ExtraTones[0] = ChordTone{Val: 6}
+	clone.ExtraTones = append(clone.ExtraTones, ChordTone{Val: 13, Acc: Flat})
+	clone.Bass = Note{}
+	if ch.String() != orig {
+		t.Errorf("Chord.Clone shares state with the original chord: %v", ch)
+	}
+
+	ct := ch.ChordType()
+	ctClone := ct.Clone()
+	ctClone.ExtraTones[0] = ChordTone{Val: 6}
+	if actual := ct.Chord(MustParseNote("C")).String(); actual != orig {
+		t.Errorf("ChordType.Clone shares state with the original chord type: %s", actual)
+	}
+}
+
+func TestChord_CanonicalizeStrict(t *testing.T) {
+	ch := MustParseChord("C-7♭5")
+	if err := ch.CanonicalizeStrict(); err != nil {
+		t.Errorf("Chord.CanonicalizeStrict for C-7♭5 failed: %v", err)
+	} else if ch.String() != "Cø" {
+		t.Errorf("Chord.CanonicalizeStrict for C-7♭5 returned wrong value: %v != Cø", ch)
+	}
+
+	for _, s := range []string{"C7♭9♯9", "C+♭5", "Co♯5"} {
+		ch := MustParseChord(s)
+		orig := ch.String()
+		if err := ch.CanonicalizeStrict(); err == nil {
+			t.Errorf("Chord.CanonicalizeStrict for %s should have failed: %v", s, ch)
+		}
+		if ch.String() != orig {
+			t.Errorf("Chord.CanonicalizeStrict for %s modified the chord: %v", s, ch)
+		}
+	}
+}
@@ -0,0 +1,138 @@
+package chords
+
+import (
+	"bytes"
+	"fmt"
+)
+
+// IntervalChord is a chord given by the intervals of its tones above its root,
+// for chords that are not built from 3rds, like tone clusters and quartal
+// chords (stacks of 4ths). A Chord always has a 3rd or a suspension and a 5th,
+// so it cannot describe such chords, but an IntervalChord can describe any
+// set of notes above a root. Its tones can be named as a Chord on a
+// best-effort basis. (See Name.)
+type IntervalChord struct {
+	// Root is the lowest note of the chord.
+	Root Note
+	// Intervals are the intervals of the chord's other tones above its
+	// root, from lowest to highest. They may span more than an octave, like
+	// the 10th in a stack of three 4ths. They must be ascending.
+	Intervals []CompoundInterval
+}
+
+// StackedChord returns a chord that is a stack of the given number of the
+// given interval above the given root, so it has one more note than the
+// number of intervals. A stack of 4ths is a quartal chord, like C F B♭ E♭,
+// and a stack of 5ths is a quintal chord.
+func StackedChord(root Note, intv Interval, count int) *IntervalChord {
+	ch := &IntervalChord{Root: root}
+	var sum CompoundInterval
+	for i := 0; i < count; i++ {
+		if i == 0 {
+			sum = Compound(intv, 0)
+		} else {
+			sum = addCompoundIntervals(sum, intv)
+		}
+		ch.Intervals = append(ch.Intervals, sum)
+	}
+	return ch
+}
+
+// ClusterChord returns a chromatic tone cluster: the given number of notes,
+// each a half-step above the one before, starting with the given root. Each
+// note is spelled with the conventional interval above the root for its
+// number of half-steps (see IntervalFromHalfSteps), so the cluster of four
+// notes on C is C D♭ D E♭.
+func ClusterChord(root Note, size int) *IntervalChord {
+	ch := &IntervalChord{Root: root}
+	for i := 1; i < size; i++ {
+		ch.Intervals = append(ch.Intervals, Compound(intervalsByHalfSteps[i%12], int8(i/12)))
+	}
+	return ch
+}
+
+// addCompoundIntervals returns the interval that spans the given compound
+// interval plus the given simple interval.
+func addCompoundIntervals(a CompoundInterval, b Interval) CompoundInterval {
+	sum := CompoundInterval{Val: a.Val + b.Val - 1}
+	steps := a.NumHalfSteps() + int(stepsByInterval[b.Val-1]+b.Offset)
+	sum.Offset = int8(steps - sum.NumHalfSteps())
+	return sum
+}
+
+// Spell returns the notes of the chord, from the root up.
+func (ch *IntervalChord) Spell() []Note {
+	notes := make([]Note, 0, len(ch.Intervals)+1)
+	notes = append(notes, ch.Root)
+	for _, intv := range ch.Intervals {
+		notes = append(notes, ch.Root.Transpose(intv.Simple()))
+	}
+	return notes
+}
+
+// PitchClassSet returns the set of the pitch classes in the chord.
+func (ch *IntervalChord) PitchClassSet() PitchClassSet {
+	return PitchClassSetOf(ch.Spell()...)
+}
+
+// Transpose returns a new chord that is this chord transposed by the given
+// interval. Only the root changes, since the other tones are relative to it.
+// This chord is not modified.
+func (ch *IntervalChord) Transpose(interval Interval) *IntervalChord {
+	return &IntervalChord{
+		Root:      ch.Root.Transpose(interval),
+		Intervals: append([]CompoundInterval(nil), ch.Intervals...),
+	}
+}
+
+// String implements the Stringer interface. The result is the root followed
+// by the intervals in parentheses, written like chord tones: a number with an
+// accidental if the interval is not major or perfect. So a stack of three 4ths
+// on C is "C(4 ♭7 ♭10)".
+func (ch *IntervalChord) String() string {
+	var b bytes.Buffer
+	b.WriteString(ch.Root.String())
+	b.WriteByte('(')
+	for i, intv := range ch.Intervals {
+		if i > 0 {
+			b.WriteByte(' ')
+		}
+		if intv.Offset != 0 {
+			b.WriteString(Accidental(intv.Offset).String())
+		}
+		fmt.Fprintf(&b, "%d", intv.Val)
+	}
+	b.WriteByte(')')
+	return b.String()
+}
+
+// Name returns the Chord with the same notes, if there is one, so that a
+// chord that happens to be tertian can be named conventionally. A chord that
+// is the same as this one with its 5th left out, which is common in larger
+// chords, also counts: so the quartal chord C F B♭ E♭ is C-11, and a stack of
+// 5ths C G D A E is C2 6. Of the chords that match (see
+// ChordsWithPitchClasses), the most plausible one with this chord's root is
+// chosen. If none has this chord's root, the most plausible one is chosen,
+// with this chord's root as its bass. This returns nil if the notes are not
+// a Chord, as with most clusters.
+func (ch *IntervalChord) Name() *Chord {
+	set := ch.PitchClassSet()
+	cands := chordsContaining(set, 0)
+	for _, cand := range chordsContaining(set, 1) {
+		// the only extra pitch class may be the chord's 5th
+		if added := cand.PitchClassSet().Difference(set); added == PitchClassSet(0).With(PitchClass(cand.Root)+7) {
+			cands = append(cands, cand)
+		}
+	}
+	if len(cands) == 0 {
+		return nil
+	}
+	for _, cand := range cands {
+		if PitchClass(cand.Root) == PitchClass(ch.Root) {
+			cand.Root = ch.Root
+			return cand
+		}
+	}
+	cands[0].Bass = ch.Root
+	return cands[0]
+}
@@ -0,0 +1,94 @@
+package chords
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestIntervalChord(t *testing.T) {
+	c, e := MustParseNote("C"), MustParseNote("E")
+	cases := []struct {
+		chord *IntervalChord
+		str   string
+		spell string
+		name  string
+	}{
+		{
+			chord: StackedChord(c, Interval{Val: 4}, 3),
+			str:   "C(4 ♭7 ♭10)",
+			spell: "[C F B♭ E♭]",
+			name:  "C-11",
+		},
+		{
+			chord: StackedChord(c, Interval{Val: 4}, 2),
+			str:   "C(4 ♭7)",
+			spell: "[C F B♭]",
+			name:  "Csus4 7",
+		},
+		{
+			chord: StackedChord(c, Interval{Val: 5}, 4),
+			str:   "C(5 9 13 17)",
+			spell: "[C G D A E]",
+			name:  "C2 6",
+		},
+		{
+			chord: StackedChord(c, Interval{Val: 3, Offset: -1}, 3),
+			str:   "C(♭3 ♭5 𝄫7)",
+			spell: "[C E♭ G♭ B𝄫]",
+			name:  "Co",
+		},
+		{
+			chord: StackedChord(e, Interval{Val: 4}, 5),
+			str:   "E(4 ♭7 ♭10 ♭13 ♭16)",
+			spell: "[E A D G C F]",
+			name:  "E-7♭9 11♯5",
+		},
+		{
+			chord: ClusterChord(c, 4),
+			str:   "C(♭2 2 ♭3)",
+			spell: "[C D♭ D E♭]",
+			name:  "<nil>",
+		},
+		{
+			chord: ClusterChord(c, 14),
+			str:   "C(♭2 2 ♭3 3 4 ♭5 5 ♭6 6 ♭7 7 8 ♭9)",
+			spell: "[C D♭ D E♭ E F G♭ G A♭ A B♭ B C D♭]",
+			name:  "<nil>",
+		},
+		{
+			chord: &IntervalChord{Root: c, Intervals: []CompoundInterval{{Val: 4}, {Val: 6}, {Val: 9}}},
+			str:   "C(4 6 9)",
+			spell: "[C F A D]",
+			name:  "Csus2 4 6",
+		},
+	}
+	for _, tc := range cases {
+		if actual := tc.chord.String(); actual != tc.str {
+			t.Errorf("IntervalChord.String returned wrong value: %s != %s", actual, tc.str)
+		}
+		if actual := fmt.Sprint(tc.chord.Spell()); actual != tc.spell {
+			t.Errorf("IntervalChord.Spell for %v returned wrong value: %s != %s", tc.chord, actual, tc.spell)
+		}
+		name := "<nil>"
+		if ch := tc.chord.Name(); ch != nil {
+			name = ch.String()
+		}
+		if name != tc.name {
+			t.Errorf("IntervalChord.Name for %v returned wrong value: %s != %s", tc.chord, name, tc.name)
+		}
+	}
+}
+
+func TestIntervalChord_Transpose(t *testing.T) {
+	ch := StackedChord(MustParseNote("C"), Interval{Val: 4}, 3)
+	tr := ch.Transpose(Interval{Val: 3, Offset: -1})
+	if actual, exp := fmt.Sprint(tr.Spell()), "[E♭ A♭ D♭ G♭]"; actual != exp {
+		t.Errorf("IntervalChord.Transpose returned wrong value: %s != %s", actual, exp)
+	}
+	if actual, exp := tr.PitchClassSet(), PitchClassSetOf(tr.Spell()...); actual != exp {
+		t.Errorf("IntervalChord.PitchClassSet for transposed chord returned wrong value: %v != %v", actual, exp)
+	}
+	if actual, exp := fmt.Sprint(ch.Spell()), "[C F B♭ E♭]"; actual != exp {
+		t.Errorf("IntervalChord.Transpose modified the original chord: %s != %s", actual, exp)
+	}
+}
@@ -0,0 +1,346 @@
+// Command chordpractice is a command-line program that prints a daily practice
+// plan. The plan is built from a configuration file that lists the material
+// to practice: chords, keys, and progressions.
+//
+// Each day, the plan picks a key to work in, from the configured list of keys.
+// The "chords of the week" are a subset of the configured chords that changes
+// every week. The plan spells the chords of the week, with a voicing and an
+// arpeggio of each to practice, and the scale of the day's key, with a scale
+// run. It then lists the configured progressions transposed into that key.
+// The voicing style and arpeggio pattern also change every day.
+//
+// With the -midi flag, a backing track is written for each progression, as a
+// MIDI file in the given directory, so that it can be played along with.
+//
+// The configuration file is JSON, like so:
+//
+//	{
+//	  "chords": ["Cmaj7", "C7", "C-7", "Cø", "Co", "C7#9"],
+//	  "chordsPerWeek": 3,
+//	  "keys": ["C", "F", "Bb", "Eb", "G", "D", "A"],
+//	  "progressions": ["D-7 G7 Cmaj7", "| C A-7 | D-7 G7 |"],
+//	  "tempo": 100,
+//	  "repeats": 4
+//	}
+//
+// A progression is either a chord chart, in the format accepted by
+// chords.ParseProgression, or a list of chords with one bar for each chord.
+// Chords and progressions are written in the key of C major and are
+// transposed into the day's key. For a minor key, they are transposed into its
+// relative major, so that they are in the key's scale: in A minor, C F G stays
+// C F G, and D-7 G7 C△7 is the ii-V-III of A minor.
+//
+// The tempo of the backing tracks is in beats per minute, and each backing
+// track plays its progression the given number of times. If they are not
+// given, the tempo is 120 and each progression is played four times.
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/jhump/chords"
+	"github.com/jhump/chords/midi"
+)
+
+type config struct {
+	Chords        []string `json:"chords"`
+	ChordsPerWeek int      `json:"chordsPerWeek"`
+	Keys          []string `json:"keys"`
+	Progressions  []string `json:"progressions"`
+	Tempo         int      `json:"tempo"`
+	Repeats       int      `json:"repeats"`
+}
+
+func main() {
+	configFile := flag.String("config", "", "the configuration file (required)")
+	date := flag.String("date", "", "the date of the plan, in YYYY-MM-DD format (default today)")
+	midiDir := flag.String("midi", "", "write a MIDI backing track for each progression to the given `directory`")
+	flag.Usage = func() {
+		fmt.Fprintln(os.Stderr, "Usage:")
+		fmt.Fprintf(os.Stderr, "  %s -config file [-date YYYY-MM-DD] [-midi directory]\n\n", path.Base(os.Args[0]))
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+	if *configFile == "" || flag.NArg() > 0 {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	day := time.Now()
+	if *date != "" {
+		var err error
+		day, err = time.Parse("2006-01-02", *date)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Invalid date %q: %v\n", *date, err)
+			os.Exit(2)
+		}
+	}
+
+	conf, err := readConfig(*configFile)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to read configuration %s: %v\n", *configFile, err)
+		os.Exit(1)
+	}
+	p, err := buildPlan(conf, day)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%v\n", err)
+		os.Exit(1)
+	}
+	if *midiDir != "" {
+		if err := writeBackingTracks(p, *midiDir, conf); err != nil {
+			fmt.Fprintf(os.Stderr, "Failed to write backing tracks: %v\n", err)
+			os.Exit(1)
+		}
+	}
+	printPlan(os.Stdout, p)
+}
+
+func readConfig(filename string) (*config, error) {
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+	var conf config
+	if err := json.Unmarshal(data, &conf); err != nil {
+		return nil, err
+	}
+	if len(conf.Keys) == 0 {
+		conf.Keys = []string{"C"}
+	}
+	if conf.ChordsPerWeek <= 0 {
+		conf.ChordsPerWeek = 3
+	}
+	if conf.Tempo <= 0 {
+		conf.Tempo = midi.DefaultTempo
+	}
+	if conf.Repeats <= 0 {
+		conf.Repeats = 4
+	}
+	return &conf, nil
+}
+
+// voicingStyles are the voicing styles practiced, one per day, in rotation.
+var voicingStyles = []chords.VoicingStyle{chords.Close, chords.Drop2, chords.Drop3, chords.Shell}
+
+// arpPatterns are the arpeggio patterns practiced, one per day, in rotation.
+var arpPatterns = []chords.ArpPattern{chords.ArpUp, chords.ArpDown, chords.ArpUpDown, chords.ArpBrokenThirds}
+
+// plan is the practice plan for one day.
+type plan struct {
+	day        time.Time
+	key        chords.Key
+	style      chords.VoicingStyle
+	arpPattern chords.ArpPattern
+	chords     []chordExercise
+	scale      *chords.DirectionalScale
+	progs      []progression
+}
+
+// chordExercise is one of the chords of the week, with a voicing and an
+// arpeggio of it to practice.
+type chordExercise struct {
+	chord    *chords.Chord
+	voicing  chords.Voicing
+	arpeggio []chords.Pitch
+}
+
+// progression is a progression to comp over and, if one was written, the
+// name of the file with its backing track.
+type progression struct {
+	prog *chords.Progression
+	file string
+}
+
+// cMajor is the key in which the configured material is written.
+var cMajor = chords.Key{Tonic: chords.Note{N: chords.C}}
+
+// buildPlan builds the practice plan for the given day from the given
+// configuration.
+func buildPlan(conf *config, day time.Time) (*plan, error) {
+	y, m, d := day.Date()
+	dayNum := int(time.Date(y, m, d, 0, 0, 0, 0, time.UTC).Unix() / (24 * 60 * 60))
+	_, weekNum := day.ISOWeek()
+
+	keyName := conf.Keys[posMod(dayNum, len(conf.Keys))]
+	key, err := chords.ParseKey(keyName)
+	if err != nil {
+		return nil, fmt.Errorf("invalid key %q: %v", keyName, err)
+	}
+	p := &plan{
+		day:        day,
+		key:        key,
+		style:      voicingStyles[posMod(dayNum, len(voicingStyles))],
+		arpPattern: arpPatterns[posMod(dayNum, len(arpPatterns))],
+		scale:      chords.DirectionalScaleType{Ascending: key.Scale().Type, Descending: key.Scale().Type}.WithRoot(key.Tonic),
+	}
+
+	if len(conf.Chords) > 0 {
+		n := conf.ChordsPerWeek
+		if n > len(conf.Chords) {
+			n = len(conf.Chords)
+		}
+		// transpose the chords together, so they are spelled consistently
+		week := &chords.Progression{}
+		for i := 0; i < n; i++ {
+			ch, err := parseChord(conf.Chords[posMod(weekNum*n+i, len(conf.Chords))])
+			if err != nil {
+				return nil, err
+			}
+			week.Bars = append(week.Bars, chords.Bar{Chords: []chords.BarChord{{Chord: ch, Beats: 4}}})
+		}
+		for _, ch := range inKey(week, key).Chords() {
+			p.chords = append(p.chords, chordExercise{
+				chord:    ch,
+				voicing:  voicing(ch, p.style),
+				arpeggio: chords.Arpeggiate(ch, p.arpPattern, 1),
+			})
+		}
+	}
+
+	for _, s := range conf.Progressions {
+		prog, err := parseProgression(s)
+		if err != nil {
+			return nil, err
+		}
+		p.progs = append(p.progs, progression{prog: inKey(prog, key)})
+	}
+	return p, nil
+}
+
+// inKey returns the given progression, written in C major, transposed into
+// the given key. For a minor key, the progression is transposed into the
+// relative major, so that its chords are in the key's scale.
+func inKey(prog *chords.Progression, key chords.Key) *chords.Progression {
+	song := &chords.Song{Metadata: chords.SongMetadata{Key: cMajor}, Sections: []*chords.Section{{Body: prog}}}
+	return song.TransposeToKey(key).Sections[0].Body
+}
+
+// voicing returns a voicing of the given chord in the given style, from the
+// middle of the default range. If the chord has no such voicing, like a
+// triad in the drop-3 style, its close voicing is used instead.
+func voicing(ch *chords.Chord, style chords.VoicingStyle) chords.Voicing {
+	vs := ch.Voicings(style, chords.PitchRange{})
+	if len(vs) == 0 {
+		vs = ch.Voicings(chords.Close, chords.PitchRange{})
+	}
+	if len(vs) == 0 {
+		return nil
+	}
+	return vs[len(vs)/2]
+}
+
+// writeBackingTracks writes a MIDI file for each of the plan's progressions to
+// the given directory, with the progression played as many times, and at the
+// tempo, given in the configuration. The names of the files are recorded in
+// the plan.
+func writeBackingTracks(p *plan, dir string, conf *config) error {
+	for i := range p.progs {
+		unrolled := p.progs[i].prog.Unroll()
+		looped := &chords.Progression{BeatsPerBar: unrolled.BeatsPerBar}
+		for n := 0; n < conf.Repeats; n++ {
+			looped.Bars = append(looped.Bars, unrolled.Bars...)
+		}
+		name := filepath.Join(dir, fmt.Sprintf("progression-%d.mid", i+1))
+		f, err := os.Create(name)
+		if err != nil {
+			return err
+		}
+		err = midi.WriteFile(f, midi.ProgressionEvents(looped, midi.PlaybackOptions{}), float64(conf.Tempo))
+		if closeErr := f.Close(); err == nil {
+			err = closeErr
+		}
+		if err != nil {
+			return err
+		}
+		p.progs[i].file = name
+	}
+	return nil
+}
+
+// printPlan writes the given plan to w.
+func printPlan(w io.Writer, p *plan) {
+	fmt.Fprintf(w, "Practice plan for %s\n", p.day.Format("Monday, January 2, 2006"))
+	fmt.Fprintf(w, "Key of the day: %v\n", p.key)
+
+	if len(p.chords) > 0 {
+		fmt.Fprintf(w, "\nChords of the week (%v voicings, %v arpeggios):\n", p.style, p.arpPattern)
+		for _, ex := range p.chords {
+			fmt.Fprintf(w, "  %-12v %v\n", ex.chord, spell(ex.chord.Spell()))
+			if ex.voicing != nil {
+				fmt.Fprintf(w, "    voicing:  %v\n", ex.voicing)
+			}
+			fmt.Fprintf(w, "    arpeggio: %v\n", chords.Voicing(ex.arpeggio))
+		}
+	}
+
+	fmt.Fprintln(w, "\nScale to review:")
+	fmt.Fprintf(w, "  %v %s: %v\n", p.key.Tonic, scaleName(p.key), spell(p.key.Scale().Spell()))
+	fmt.Fprintf(w, "    run: %v\n", chords.Voicing(p.scale.Run(chords.Pitch{Note: p.key.Tonic, Octave: 4}, 1)))
+
+	if len(p.progs) > 0 {
+		fmt.Fprintln(w, "\nProgressions to comp over:")
+		for _, prog := range p.progs {
+			fmt.Fprintf(w, "  %v\n", prog.prog)
+			if prog.file != "" {
+				fmt.Fprintf(w, "    backing track: %s\n", prog.file)
+			}
+		}
+	}
+}
+
+func parseChord(s string) (*chords.Chord, error) {
+	ch, err := chords.ParseChord(s)
+	if err == nil {
+		err = ch.Validate()
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse %q as a chord: %v", s, err)
+	}
+	ch.Canonicalize()
+	return ch, nil
+}
+
+// parseProgression parses the given progression, which is either a chord chart
+// or a list of chords, one per bar.
+func parseProgression(s string) (*chords.Progression, error) {
+	if !strings.Contains(s, "|") {
+		s = "| " + strings.Join(strings.Fields(s), " | ") + " |"
+	}
+	prog, err := chords.ParseProgression(s)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse progression %q: %v", s, err)
+	}
+	return prog, nil
+}
+
+func scaleName(k chords.Key) string {
+	if k.Minor {
+		return "natural minor"
+	}
+	return "major"
+}
+
+func spell(notes []chords.Note) string {
+	strs := make([]string, len(notes))
+	for i, n := range notes {
+		strs[i] = n.String()
+	}
+	return strings.Join(strs, " ")
+}
+
+// posMod returns i modulo n, which is never negative, even if i is.
+func posMod(i, n int) int {
+	i %= n
+	if i < 0 {
+		i += n
+	}
+	return i
+}
@@ -0,0 +1,114 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestPrintPlan(t *testing.T) {
+	conf := &config{
+		Chords:        []string{"Cmaj7", "C7", "C-7", "Cø"},
+		ChordsPerWeek: 2,
+		Keys:          []string{"C", "Am", "Bb"},
+		Progressions:  []string{"C F G", "|: D-7 G7 | Cmaj7 :|"},
+		Tempo:         120,
+		Repeats:       4,
+	}
+	p, err := buildPlan(conf, time.Date(2024, 3, 7, 0, 0, 0, 0, time.UTC))
+	if err != nil {
+		t.Fatalf("buildPlan failed: %v", err)
+	}
+	var b bytes.Buffer
+	printPlan(&b, p)
+	exp := `Practice plan for Thursday, March 7, 2024
+Key of the day: Am
+
+Chords of the week (drop-2 voicings, down arpeggios):
+  C△7          C E G B
+    voicing:  C4 G4 B4 E5
+    arpeggio: C5 B4 G4 E4 C4
+  C7           C E G B♭
+    voicing:  C4 G4 B♭4 E5
+    arpeggio: C5 B♭4 G4 E4 C4
+
+Scale to review:
+  A natural minor: A B C D E F G
+    run: A4 B4 C5 D5 E5 F5 G5 A5 G5 F5 E5 D5 C5 B4 A4
+
+Progressions to comp over:
+  | C | F | G |
+  |: D-7 G7 | C△7 :|
+`
+	if b.String() != exp {
+		t.Errorf("printPlan returned wrong value:\n%s", b.String())
+	}
+}
+
+func TestBuildPlan_Keys(t *testing.T) {
+	conf := &config{Keys: []string{"C", "Eb", "F#m"}, Progressions: []string{"D-7 G7 C"}}
+	cases := []struct {
+		date string
+		key  string
+		prog string
+	}{
+		{"2024-03-06", "C", "| D-7 | G7 | C |"},
+		{"2024-03-07", "E♭", "| F-7 | B♭7 | E♭ |"},
+		// minor keys use the relative major, so the chords are in the key
+		{"2024-03-08", "F♯m", "| B-7 | E7 | A |"},
+		// dates before 1970 have negative day numbers
+		{"1969-07-20", "C", "| D-7 | G7 | C |"},
+		{"1969-07-21", "E♭", "| F-7 | B♭7 | E♭ |"},
+		{"1900-01-01", "F♯m", "| B-7 | E7 | A |"},
+	}
+	for _, tc := range cases {
+		day, err := time.Parse("2006-01-02", tc.date)
+		if err != nil {
+			t.Fatal(err)
+		}
+		p, err := buildPlan(conf, day)
+		if err != nil {
+			t.Errorf("buildPlan for %s failed: %v", tc.date, err)
+			continue
+		}
+		if p.key.String() != tc.key {
+			t.Errorf("buildPlan for %s returned wrong key: %v != %s", tc.date, p.key, tc.key)
+		}
+		if s := p.progs[0].prog.String(); s != tc.prog {
+			t.Errorf("buildPlan for %s returned wrong progression: %s != %s", tc.date, s, tc.prog)
+		}
+	}
+}
+
+func TestWriteBackingTracks(t *testing.T) {
+	conf := &config{Keys: []string{"C"}, Progressions: []string{"C F", "D-7 G7"}, Tempo: 100, Repeats: 2}
+	p, err := buildPlan(conf, time.Date(2024, 3, 6, 0, 0, 0, 0, time.UTC))
+	if err != nil {
+		t.Fatalf("buildPlan failed: %v", err)
+	}
+	dir := t.TempDir()
+	if err := writeBackingTracks(p, dir, conf); err != nil {
+		t.Fatalf("writeBackingTracks failed: %v", err)
+	}
+	for i, prog := range p.progs {
+		if prog.file == "" {
+			t.Errorf("no backing track recorded for progression %d", i+1)
+			continue
+		}
+		data, err := os.ReadFile(prog.file)
+		if err != nil {
+			t.Errorf("failed to read backing track for progression %d: %v", i+1, err)
+			continue
+		}
+		if !bytes.HasPrefix(data, []byte("MThd")) {
+			t.Errorf("backing track for progression %d is not a MIDI file", i+1)
+		}
+	}
+	var b bytes.Buffer
+	printPlan(&b, p)
+	if !strings.Contains(b.String(), "backing track: "+p.progs[1].file) {
+		t.Errorf("printPlan does not list backing track:\n%s", b.String())
+	}
+}
@@ -1,6 +1,6 @@
 // Command chordreader is a command-line program that spells chords. The
-// chord names are given as command-line args. The program fails if an
-// invalid chord name is given.
+// chord names are given as command-line args or read from standard input.
+// The program fails if an invalid chord name is given.
 //
 // The program parses the chord names, computes a canonical name, and then
 // spells the chord, printing out all of its constituent tones.
@@ -26,24 +26,81 @@
 //
 // A chord name can end with a bass tone, indicated by a '/' followed by the
 // bass tone (same syntax as the chord's root tone).
+//
+// With the -json flag, the results are printed as JSON instead, one object
+// per line, for use by scripts and other programs. Each object has the input,
+// the canonical name, the spelled notes, and the interval of each note above
+// the root:
+//
+//	{"input":"C-7","name":"C-7","notes":["C","E♭","G","B♭"],"intervals":["perfect unison","minor 3rd","perfect 5th","minor 7th"]}
+//
+// If a chord is invalid, its object has only the input and an error instead.
+//
+// If the only argument is '-', chords are read from standard input instead,
+// one line at a time, and the results are printed as each line is read. Each
+// line may have one chord or several, like a line of a chord chart: bar lines,
+// repeat signs, and other chart symbols are skipped. Blank lines and lines
+// that start with '#' are ignored.
+//
+// With the -midi flag, the chords are also written to a MIDI file, one bar
+// per chord, so they can be heard. They are played as block chords or, with
+// the -arpeggiate flag, as arpeggios:
+//
+//	chordreader -midi out.mid C△7 A-7 D-7 G7
+//
+// The "find" subcommand does the reverse of spelling: it names the chords
+// that the given notes (or pitches) form, ranked from the best description to
+// the worst:
+//
+//	chordreader find C E G Bb
+//
+// The "scale" subcommand spells scales instead of chords, along with their
+// modes and diatonic chords:
+//
+//	chordreader scale "F# dorian"
+//
+// The "transpose" subcommand reads a chord chart or lead sheet from standard
+// input and writes it with its chords transposed into another key, leaving
+// lyrics and other text untouched:
+//
+//	chordreader transpose -from C -to Eb < chart.txt
+//
+// The "negate" subcommand maps chords through the negative harmony of a key,
+// reflecting their notes around the axis between the key's tonic and 5th:
+//
+//	chordreader negate -key C Cmaj7 A-7 D-7 G7
+//
+// An invalid chord is reported, and the rest of the chords are still read and
+// spelled. The program exits with a non-zero status at the end if any chord
+// was invalid.
 package main
 
 import (
+	"bufio"
+	"encoding/json"
+	"flag"
 	"fmt"
+	"io"
 	"os"
 	"path"
+	"strings"
 
 	"github.com/jhump/chords"
+	"github.com/jhump/chords/midi"
 )
 
-func main() {
-	args := os.Args[1:]
-	if len(args) == 0 {
-		fmt.Println("Usage:")
-		fmt.Printf("  %s chord...\n", path.Base(os.Args[0]))
-		fmt.Println(`
+const usage = `
 Each argument is a chord. Each chord will be spelled out and its canonical name
-printed.
+printed. If the only argument is '-', chords are read from standard input, one
+line at a time. A line may have several chords, like a line of a chord chart.
+With -midi, the chords are also written to a MIDI file so they can be heard.
+
+The "find" subcommand does the reverse: it names the chords that the given
+notes form, like "C E G Bb". The "scale" subcommand spells scales instead, like
+"F# dorian", along with their modes and diatonic chords. The "transpose"
+subcommand transposes the chords in a chart read from standard input, and the
+"negate" subcommand maps chords into the negative harmony of a key. Run a
+subcommand with no arguments for details.
 
 Valid chords must first indicate their root tone as: 'A'-'G' (must be capital)
 followed by an optional 'n', '♮', '#', '♯', 'b', '♭', 'x', '𝄪', 'bb', or '𝄫'.
@@ -63,23 +120,189 @@ omitted. The 'sus' is followed by a '2' or '4', with an optional sharp (for 4)
 or flat (for 2) modifier in between, to indicate which note replaces the 3rd.
 
 A chord can end with a bass tone, indicated by a '/' followed by the bass tone
-(same syntax as the chord's root tone).`)
+(same syntax as the chord's root tone).
+
+Options:`
+
+// result is the JSON representation of a chord, printed with the -json flag.
+type result struct {
+	Input     string   `json:"input"`
+	Name      string   `json:"name,omitempty"`
+	Notes     []string `json:"notes,omitempty"`
+	Intervals []string `json:"intervals,omitempty"`
+	Error     string   `json:"error,omitempty"`
+}
+
+// subcommands are the subcommands of the program, by name. Each is given the
+// rest of the arguments and returns the program's exit status.
+var subcommands = map[string]func(args []string) int{
+	"find":      findCommand,
+	"negate":    negateCommand,
+	"scale":     scaleCommand,
+	"transpose": transposeCommand,
+}
+
+func main() {
+	if len(os.Args) > 1 {
+		if cmd, ok := subcommands[os.Args[1]]; ok {
+			os.Exit(cmd(os.Args[2:]))
+		}
+	}
+
+	jsonOutput := flag.Bool("json", false, "print the results as JSON, one object per line")
+	midiFile := flag.String("midi", "", "also write the chords to the given MIDI `file`, one bar per chord")
+	arpeggiate := flag.Bool("arpeggiate", false, "arpeggiate the chords in the MIDI file instead of playing them as block chords")
+	flag.Usage = func() {
+		fmt.Fprintln(os.Stderr, "Usage:")
+		fmt.Fprintf(os.Stderr, "  %s [-json] [-midi file [-arpeggiate]] chord...\n", path.Base(os.Args[0]))
+		fmt.Fprintf(os.Stderr, "  %s [-json] [-midi file [-arpeggiate]] -\n", path.Base(os.Args[0]))
+		fmt.Fprintf(os.Stderr, "  %s find note...\n", path.Base(os.Args[0]))
+		fmt.Fprintf(os.Stderr, "  %s negate -key key chord...\n", path.Base(os.Args[0]))
+		fmt.Fprintf(os.Stderr, "  %s scale scale...\n", path.Base(os.Args[0]))
+		fmt.Fprintf(os.Stderr, "  %s transpose [-from key] -to key < chart\n", path.Base(os.Args[0]))
+		fmt.Fprintln(os.Stderr, usage)
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+	if flag.NArg() == 0 {
+		flag.Usage()
+		os.Exit(2)
 	}
 
-	chs := map[string]*chords.Chord{}
-	for _, s := range args {
-		ch, err := chords.ParseChord(s)
-		if err != nil {
-			fmt.Fprintf(os.Stderr, "Failed to parse %q as a chord: %v\n", s, err)
+	r := &reader{json: *jsonOutput, out: os.Stdout}
+	if flag.NArg() == 1 && flag.Arg(0) == "-" {
+		scanner := bufio.NewScanner(os.Stdin)
+		for lineNum := 1; scanner.Scan(); lineNum++ {
+			r.readLine(fmt.Sprintf("line %d: ", lineNum), scanner.Text())
+		}
+		if err := scanner.Err(); err != nil {
+			fmt.Fprintf(os.Stderr, "Failed to read input: %v\n", err)
 			os.Exit(1)
 		}
-		err = ch.Validate()
-		if err != nil {
-			fmt.Fprintf(os.Stderr, "Failed to parse %q as a chord: %v\n", s, err)
+	} else {
+		for _, s := range flag.Args() {
+			r.readChord("", s)
+		}
+	}
+	if *midiFile != "" {
+		style := midi.BlockComp
+		if *arpeggiate {
+			style = midi.ArpeggiatedComp
+		}
+		if err := writeMIDI(*midiFile, r.chords, style); err != nil {
+			fmt.Fprintf(os.Stderr, "Failed to write MIDI file %s: %v\n", *midiFile, err)
 			os.Exit(1)
 		}
-		chs[s] = ch
-		ch.Canonicalize()
-		fmt.Printf("%s => %v: %v\n", s, ch, ch.Spell())
 	}
+	if r.failed {
+		os.Exit(1)
+	}
+}
+
+// writeMIDI writes the given chords to a MIDI file with the given name, one
+// bar of 4/4 for each chord, played in the given style.
+func writeMIDI(filename string, chs []*chords.Chord, style midi.CompStyle) error {
+	prog := &chords.Progression{}
+	for _, ch := range chs {
+		prog.Bars = append(prog.Bars, chords.Bar{Chords: []chords.BarChord{{Chord: ch, Beats: 4}}})
+	}
+	f, err := os.Create(filename)
+	if err != nil {
+		return err
+	}
+	err = midi.WriteFile(f, midi.ProgressionEvents(prog, midi.PlaybackOptions{Style: style}), midi.DefaultTempo)
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	return err
+}
+
+// reader spells chords and prints the results.
+type reader struct {
+	json   bool
+	out    io.Writer
+	failed bool
+	// chords are the valid chords that were read, in order
+	chords []*chords.Chord
+}
+
+// readLine spells the chords in the given line of input, which is described
+// by the given prefix in error messages.
+func (r *reader) readLine(prefix, line string) {
+	line = strings.TrimSpace(line)
+	if line == "" || strings.HasPrefix(line, "#") {
+		return
+	}
+	for _, tok := range strings.Fields(strings.NewReplacer("|", " ", ":", " ").Replace(line)) {
+		if isChartSymbol(tok) {
+			continue
+		}
+		r.readChord(prefix, tok)
+	}
+}
+
+// isChartSymbol returns true if the given token is part of the notation of a
+// chord chart, like a repeat count or time signature, rather than a chord.
+func isChartSymbol(tok string) bool {
+	switch {
+	case tok == "/" || tok == "%":
+		return true
+	case len(tok) > 1 && tok[0] == 'x' && strings.Trim(tok[1:], "0123456789") == "":
+		// repeat count, like "x3"
+		return true
+	case tok[0] >= '0' && tok[0] <= '9':
+		// time signature, like "3/4"
+		return true
+	}
+	return false
+}
+
+// readChord spells the given chord and prints the result. If the chord is
+// invalid, the error is reported, prefixed with the given prefix.
+func (r *reader) readChord(prefix, s string) {
+	ch, err := parseChord(s)
+	if r.json {
+		printJSON(r.out, s, ch, err)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%sFailed to parse %q as a chord: %v\n", prefix, s, err)
+		r.failed = true
+		return
+	}
+	r.chords = append(r.chords, ch)
+	if !r.json {
+		fmt.Fprintf(r.out, "%s => %v: %v\n", s, ch, ch.Spell())
+	}
+}
+
+// parseChord parses and validates the given chord, and returns it in
+// canonical form.
+func parseChord(s string) (*chords.Chord, error) {
+	ch, err := chords.ParseChord(s)
+	if err == nil {
+		err = ch.Validate()
+	}
+	if err != nil {
+		return nil, err
+	}
+	ch.Canonicalize()
+	return ch, nil
+}
+
+// printJSON prints the given chord, or the error parsing it, as a JSON object
+// on a single line.
+func printJSON(w io.Writer, input string, ch *chords.Chord, err error) {
+	res := result{Input: input}
+	if err != nil {
+		res.Error = err.Error()
+	} else {
+		res.Name = ch.String()
+		for _, n := range ch.Spell() {
+			res.Notes = append(res.Notes, n.String())
+			res.Intervals = append(res.Intervals, chords.Compound(ch.Root.IntervalTo(n), 0).String())
+		}
+	}
+	enc := json.NewEncoder(w)
+	enc.SetEscapeHTML(false)
+	_ = enc.Encode(res)
 }
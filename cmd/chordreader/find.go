@@ -0,0 +1,94 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path"
+
+	"github.com/jhump/chords"
+)
+
+const findUsage = `
+Each argument is a note, like "C" or "Bb", or a pitch, like "E4" or "Bb3". The
+chords that the notes form are printed, from the best description to the
+worst, along with their scores: lower scores are simpler chords. If the notes
+are pitches, the lowest is the bass, so inversions are recognized. Otherwise,
+the -bass flag can give the bass note.
+
+Options:`
+
+// findCommand implements the "find" subcommand, which names the chords that
+// the given notes form. It returns the program's exit status.
+func findCommand(args []string) int {
+	fs := flag.NewFlagSet("find", flag.ExitOnError)
+	bass := fs.String("bass", "", "the `note` in the bass, if the notes are not pitches")
+	rootless := fs.Bool("rootless", false, "also find chords whose root is not among the notes")
+	limit := fs.Int("n", 5, "the maximum `number` of chords to print, or zero for all of them")
+	fs.Usage = func() {
+		fmt.Fprintln(os.Stderr, "Usage:")
+		fmt.Fprintf(os.Stderr, "  %s find [-bass note] [-rootless] [-n number] note...\n", path.Base(os.Args[0]))
+		fmt.Fprintln(os.Stderr, findUsage)
+		fs.PrintDefaults()
+	}
+	_ = fs.Parse(args)
+	if fs.NArg() == 0 {
+		fs.Usage()
+		return 2
+	}
+
+	opts := chords.InferenceOptions{Rootless: *rootless}
+	if *bass != "" {
+		n, err := chords.ParseNote(*bass)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Failed to parse %q as a note: %v\n", *bass, err)
+			return 2
+		}
+		opts.Bass = n
+	}
+	notes, err := parseNotes(fs.Args(), &opts)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Invalid notes: %v\n", err)
+		return 2
+	}
+
+	cands := chords.InferChordsWithOptions(notes, opts)
+	if len(cands) == 0 {
+		fmt.Fprintf(os.Stderr, "The notes %v do not form a chord\n", notes)
+		return 1
+	}
+	if *limit > 0 && len(cands) > *limit {
+		cands = cands[:*limit]
+	}
+	for i, cand := range cands {
+		fmt.Printf("%d. %-16v (score %d): %v\n", i+1, cand.Chord, cand.Score, cand.Chord.Spell())
+	}
+	return 0
+}
+
+// parseNotes parses the given notes. If every one is a pitch, like "E4", and
+// opts has no bass note, the lowest pitch becomes its bass.
+func parseNotes(args []string, opts *chords.InferenceOptions) ([]chords.Note, error) {
+	notes := make([]chords.Note, len(args))
+	var lowest *chords.Pitch
+	allPitches := true
+	for i, s := range args {
+		if p, err := chords.ParsePitch(s); err == nil {
+			notes[i] = p.Note
+			if lowest == nil || p.MIDINumber() < lowest.MIDINumber() {
+				lowest = &p
+			}
+			continue
+		}
+		allPitches = false
+		n, err := chords.ParseNote(s)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse %q as a note: %v", s, err)
+		}
+		notes[i] = n
+	}
+	if allPitches && opts.Bass.N == 0 {
+		opts.Bass = lowest.Note
+	}
+	return notes, nil
+}
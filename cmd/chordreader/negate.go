@@ -0,0 +1,60 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path"
+
+	"github.com/jhump/chords"
+)
+
+const negateUsage = `
+Each argument is a chord. Each chord is mapped through the "negative harmony"
+of the key given by -key: its notes are reflected around the axis between the
+key's tonic and 5th, so in C, C and G trade places, as do E and Eb. The
+resulting chord is printed along with its notes. In C, for example, G7 becomes
+Dø and Cmaj7 becomes Abmaj7.
+
+Options:`
+
+// negateCommand implements the "negate" subcommand, which maps the given chords
+// into negative harmony. It returns the program's exit status.
+func negateCommand(args []string) int {
+	fs := flag.NewFlagSet("negate", flag.ExitOnError)
+	keyName := fs.String("key", "", "the `key` whose negative harmony to use, like \"C\" or \"Am\" (required)")
+	fs.Usage = func() {
+		fmt.Fprintln(os.Stderr, "Usage:")
+		fmt.Fprintf(os.Stderr, "  %s negate -key key chord...\n", path.Base(os.Args[0]))
+		fmt.Fprintln(os.Stderr, negateUsage)
+		fs.PrintDefaults()
+	}
+	_ = fs.Parse(args)
+	if *keyName == "" || fs.NArg() == 0 {
+		fs.Usage()
+		return 2
+	}
+	key, err := chords.ParseKey(*keyName)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Invalid key %q: %v\n", *keyName, err)
+		return 2
+	}
+
+	status := 0
+	for _, s := range fs.Args() {
+		ch, err := parseChord(s)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Failed to parse %q as a chord: %v\n", s, err)
+			status = 1
+			continue
+		}
+		neg := chords.NegateChord(ch, key)
+		if neg == nil {
+			fmt.Fprintf(os.Stderr, "The negation of %v does not form a chord\n", ch)
+			status = 1
+			continue
+		}
+		fmt.Printf("%s => %v: %v\n", s, neg, neg.Spell())
+	}
+	return status
+}
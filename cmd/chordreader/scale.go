@@ -0,0 +1,70 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path"
+
+	"github.com/jhump/chords"
+)
+
+const scaleUsage = `
+Each argument is a scale: a root note followed by the name of a scale type,
+like "F# dorian" or "Bb harmonic minor". Each scale will be spelled out, along
+with its modes and, for seven-note scales, its diatonic triads and seventh
+chords.`
+
+// scaleCommand implements the "scale" subcommand, which spells scales. It
+// returns the program's exit status.
+func scaleCommand(args []string) int {
+	fs := flag.NewFlagSet("scale", flag.ExitOnError)
+	fs.Usage = func() {
+		fmt.Fprintln(os.Stderr, "Usage:")
+		fmt.Fprintf(os.Stderr, "  %s scale scale...\n", path.Base(os.Args[0]))
+		fmt.Fprintln(os.Stderr, scaleUsage)
+	}
+	_ = fs.Parse(args)
+	if fs.NArg() == 0 {
+		fs.Usage()
+		return 2
+	}
+	status := 0
+	for i, s := range fs.Args() {
+		sc, err := chords.ParseScale(s)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Failed to parse %q as a scale: %v\n", s, err)
+			status = 1
+			continue
+		}
+		if i > 0 {
+			fmt.Println()
+		}
+		printScale(os.Stdout, sc)
+	}
+	return status
+}
+
+// printScale prints the notes, modes, and diatonic chords of the given scale.
+func printScale(w io.Writer, sc *chords.Scale) {
+	fmt.Fprintf(w, "%v: %v\n", sc, sc.Spell())
+	fmt.Fprintln(w, "Modes:")
+	for _, m := range sc.Modes() {
+		fmt.Fprintf(w, "  %v %s: %v\n", m.Scale.Root, m.Name, m.Scale.Spell())
+	}
+	for _, c := range []struct {
+		label string
+		depth int
+	}{{"Triads", 3}, {"Seventh chords", 4}} {
+		chs := sc.Chords(c.depth)
+		if len(chs) == 0 {
+			continue
+		}
+		fmt.Fprintf(w, "%s:", c.label)
+		for _, ch := range chs {
+			fmt.Fprintf(w, " %v", ch)
+		}
+		fmt.Fprintln(w)
+	}
+}
@@ -0,0 +1,166 @@
+package main
+
+import (
+	"bufio"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/jhump/chords"
+	"github.com/jhump/chords/chordpro"
+)
+
+const transposeUsage = `
+Reads a chord chart or lead sheet from standard input and writes it to standard
+output with its chords transposed into the key given by -to. Chords are found
+on lines that contain only chords (and chart symbols, like bar lines) and in
+square brackets, like "[G7]", in other lines. All other text, like lyrics, is
+left untouched. The chords are respelled consistently for the new key, so a
+chart in E will not have both Db and C# chords. The spacing of chord lines is
+adjusted so that chords stay above the same lyrics.
+
+Options:`
+
+// transposeCommand implements the "transpose" subcommand, which transposes the
+// chords in text read from stdin. It returns the program's exit status.
+func transposeCommand(args []string) int {
+	fs := flag.NewFlagSet("transpose", flag.ExitOnError)
+	from := fs.String("from", "", "the `key` of the input, like \"C\" or \"Am\" (default inferred from the chords)")
+	to := fs.String("to", "", "the `key` to transpose into (required)")
+	fs.Usage = func() {
+		fmt.Fprintln(os.Stderr, "Usage:")
+		fmt.Fprintf(os.Stderr, "  %s transpose [-from key] -to key < chart\n", path.Base(os.Args[0]))
+		fmt.Fprintln(os.Stderr, transposeUsage)
+		fs.PrintDefaults()
+	}
+	_ = fs.Parse(args)
+	if *to == "" || fs.NArg() > 0 {
+		fs.Usage()
+		return 2
+	}
+	toKey, err := chords.ParseKey(*to)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Invalid key %q: %v\n", *to, err)
+		return 2
+	}
+	var fromKey chords.Key
+	if *from != "" {
+		if fromKey, err = chords.ParseKey(*from); err != nil {
+			fmt.Fprintf(os.Stderr, "Invalid key %q: %v\n", *from, err)
+			return 2
+		}
+	}
+
+	var lines []string
+	scanner := bufio.NewScanner(os.Stdin)
+	for scanner.Scan() {
+		lines = append(lines, scanner.Text())
+	}
+	if err := scanner.Err(); err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to read input: %v\n", err)
+		return 1
+	}
+	transposeChart(os.Stdout, lines, fromKey, toKey)
+	return 0
+}
+
+// chartChord is a chord found in a chart, with its position.
+type chartChord struct {
+	line       int
+	start, end int
+	chord      *chords.Chord
+	// inLine is true for a chord in brackets in a line of other text
+	inLine bool
+}
+
+var (
+	wordPattern    = regexp.MustCompile(`\S+`)
+	bracketPattern = regexp.MustCompile(`\[([^\]]+)\]`)
+)
+
+// transposeChart writes the given lines to w with their chords transposed from
+// the given key into the other. If from is the zero Key, the key is inferred
+// from the chords.
+func transposeChart(w io.Writer, lines []string, from, to chords.Key) {
+	var found []chartChord
+	for l, line := range lines {
+		found = append(found, findChartChords(l, line)...)
+	}
+
+	// transpose all chords together, as one song, so they are spelled
+	// consistently
+	prog := &chords.Progression{}
+	for _, c := range found {
+		prog.Bars = append(prog.Bars, chords.Bar{Chords: []chords.BarChord{{Chord: c.chord, Beats: 4}}})
+	}
+	song := &chords.Song{Metadata: chords.SongMetadata{Key: from}, Sections: []*chords.Section{{Body: prog}}}
+	transposed := song.TransposeToKey(to).Sections[0].Body.Chords()
+
+	for l, line := range lines {
+		var b strings.Builder
+		pos := 0
+		for len(found) > 0 && found[0].line == l {
+			c := found[0]
+			text := chordpro.Respell(line[c.start:c.end], c.chord, transposed[0])
+			found, transposed = found[1:], transposed[1:]
+
+			gap := line[pos:c.start]
+			if c.inLine || strings.TrimLeft(gap, " ") != "" {
+				b.WriteString(gap)
+			} else {
+				// keep the chord in the same column, if there is room, so
+				// that it stays above the same lyrics
+				spaces := utf8.RuneCountInString(line[:c.start]) - utf8.RuneCountInString(b.String())
+				if spaces < 1 && pos > 0 {
+					spaces = 1
+				}
+				b.WriteString(strings.Repeat(" ", spaces))
+			}
+			b.WriteString(text)
+			pos = c.end
+		}
+		b.WriteString(line[pos:])
+		fmt.Fprintln(w, b.String())
+	}
+}
+
+// findChartChords returns the chords in the given line. If every word in the
+// line is a chord or a chart symbol, the line is a chord line, and all of its
+// chords are returned. Otherwise, only chords in brackets are returned.
+func findChartChords(l int, line string) []chartChord {
+	var found []chartChord
+	chordLine := false
+	for _, loc := range wordPattern.FindAllStringIndex(line, -1) {
+		word := line[loc[0]:loc[1]]
+		trimmed := strings.Trim(word, "|:")
+		if trimmed == "" || isChartSymbol(trimmed) {
+			continue
+		}
+		ch, err := chords.ParseChord(trimmed)
+		if err == nil {
+			err = ch.Validate()
+		}
+		if err != nil {
+			found, chordLine = nil, false
+			break
+		}
+		start := loc[0] + strings.Index(word, trimmed)
+		found = append(found, chartChord{line: l, start: start, end: start + len(trimmed), chord: ch})
+		chordLine = true
+	}
+	if chordLine {
+		return found
+	}
+	for _, loc := range bracketPattern.FindAllStringSubmatchIndex(line, -1) {
+		ch, err := chords.ParseChord(line[loc[2]:loc[3]])
+		if err == nil && ch.Validate() == nil {
+			found = append(found, chartChord{line: l, start: loc[2], end: loc[3], chord: ch, inLine: true})
+		}
+	}
+	return found
+}
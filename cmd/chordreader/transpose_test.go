@@ -0,0 +1,110 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/jhump/chords"
+)
+
+func TestFindChartChords(t *testing.T) {
+	cases := []struct {
+		line string
+		exp  string
+	}{
+		{"C       G7/B    Am", "C@0-1 G7/B@8-12 Am@16-18"},
+		{"|: C | % | Dm7 G7 :| x3", "C@3-4 Dm7@11-14 G7@15-17"},
+		{"3/4 | C / / | Bb |", "C@6-7 Bb@14-16"},
+		// chords in brackets are only found in lines of other text
+		{"A[G]mazing [G7]grace", "[G]@2-3 [G7]@12-14"},
+		{"[C] [F]", "[C]@1-2 [F]@5-6"},
+		{"[Cfoo]bar [D]", "[D]@11-12"},
+		// lyrics are not chords, even if some of their words are
+		{"Amazing grace, how sweet the sound", ""},
+		{"A C E", "A@0-1 C@2-3 E@4-5"},
+		{"A cat", ""},
+		{"", ""},
+	}
+	for _, tc := range cases {
+		var strs []string
+		for _, c := range findChartChords(3, tc.line) {
+			if c.line != 3 {
+				t.Errorf("findChartChords for %q returned wrong line: %d != 3", tc.line, c.line)
+			}
+			s := fmt.Sprintf("%s@%d-%d", tc.line[c.start:c.end], c.start, c.end)
+			if c.inLine {
+				s = "[" + tc.line[c.start:c.end] + "]" + s[c.end-c.start:]
+			}
+			strs = append(strs, s)
+		}
+		if actual := strings.Join(strs, " "); actual != tc.exp {
+			t.Errorf("findChartChords for %q returned wrong value: %q != %q", tc.line, actual, tc.exp)
+		}
+	}
+}
+
+func TestTransposeChart(t *testing.T) {
+	chart := `Verse:
+C       G7/B    Am
+Amazing grace,  how sweet
+C G C F G C
+|: C | F G :| x2
+A[C]mazing [Dm7]grace
+[C♯o]how [D♭7]sweet
+`
+	cases := []struct {
+		from, to string
+		exp      string
+	}{
+		{
+			to: "D",
+			exp: `Verse:
+D       A7/C#   Bm
+Amazing grace,  how sweet
+D A D G A D
+|: D | G A :| x2
+A[D]mazing [Em7]grace
+[D♯o]how [D♯7]sweet
+`,
+		},
+		{
+			// chords that get longer push the next chord over, but there is
+			// always a space between chords
+			to: "Eb",
+			exp: `Verse:
+Eb      Bb7/D   Cm
+Amazing grace,  how sweet
+Eb Bb Eb Ab Bb Eb
+|: Eb | Ab Bb :| x2
+A[Eb]mazing [Fm7]grace
+[Eo]how [E7]sweet
+`,
+		},
+		{
+			// the key is given, instead of inferred
+			from: "F",
+			to:   "C",
+			exp: `Verse:
+G       D7/F#   Em
+Amazing grace,  how sweet
+G D G C D G
+|: G | C D :| x2
+A[G]mazing [Am7]grace
+[A♭o]how [A♭7]sweet
+`,
+		},
+	}
+	for _, tc := range cases {
+		var from chords.Key
+		if tc.from != "" {
+			from = chords.MustParseKey(tc.from)
+		}
+		var b bytes.Buffer
+		transposeChart(&b, strings.Split(strings.TrimSuffix(chart, "\n"), "\n"), from, chords.MustParseKey(tc.to))
+		if actual := b.String(); actual != tc.exp {
+			t.Errorf("transposeChart for %s returned wrong value:\n%s", tc.to, actual)
+		}
+	}
+}
@@ -0,0 +1,307 @@
+package chords
+
+import (
+	"bytes"
+	"sort"
+)
+
+// SimilarityOptions control how Similarity weighs the ways in which two chords
+// can be alike. Each weight must be zero or positive, and only their relative
+// sizes matter. A zero weight means the corresponding measure is ignored, but
+// if all of the weights are zero, the default weights are used instead.
+type SimilarityOptions struct {
+	// PitchClassWeight is the weight of the pitch classes the chords share:
+	// the number of pitch classes in both chords, out of the number of pitch
+	// classes in either. The default weight is 2.
+	PitchClassWeight float64
+	// RootWeight is the weight of the relationship between the roots of the
+	// chords, which is measured by how far apart they are around the circle
+	// of 5ths. Roots a 5th apart are closely related, and roots a tritone
+	// apart are the most distant. The default weight is 1.
+	RootWeight float64
+	// QualityWeight is the weight of the qualities of the chords, which is
+	// measured like the pitch classes they share, but with both chords
+	// transposed to the same root. So C7 and F♯7 have the same quality, and
+	// C7 and C△7 have similar qualities. The default weight is 1.
+	QualityWeight float64
+}
+
+var defaultSimilarityOptions = SimilarityOptions{
+	PitchClassWeight: 2,
+	RootWeight:       1,
+	QualityWeight:    1,
+}
+
+// Similarity returns how similar the two chords are, from 0 for chords with
+// nothing in common to 1 for chords with the same pitch classes and root, like
+// C and B♯. It combines the pitch classes the chords share, how closely their
+// roots are related, and how similar their qualities are, using the default
+// weights in SimilarityOptions. So C and A- are more similar than C and E-,
+// and both are more similar than C and F♯.
+func Similarity(a, b *Chord) float64 {
+	return SimilarityWithOptions(a, b, SimilarityOptions{})
+}
+
+// SimilarityWithOptions returns how similar the two chords are, weighing the
+// measures of similarity with the given options. (See Similarity.)
+func SimilarityWithOptions(a, b *Chord, opts SimilarityOptions) float64 {
+	if opts.PitchClassWeight == 0 && opts.RootWeight == 0 && opts.QualityWeight == 0 {
+		opts = defaultSimilarityOptions
+	}
+	setA, setB := a.PitchClassSet(), b.PitchClassSet()
+	rootA, rootB := PitchClass(a.Root), PitchClass(b.Root)
+
+	pitchClasses := jaccard(setA, setB)
+	// the number of 5ths between the roots, from 0 to 6
+	fifths := posModInt(7*(rootB-rootA), 12)
+	if fifths > 6 {
+		fifths = 12 - fifths
+	}
+	root := 1 - float64(fifths)/6
+	quality := jaccard(setA.Transpose(-rootA), setB.Transpose(-rootB))
+
+	total := opts.PitchClassWeight + opts.RootWeight + opts.QualityWeight
+	return (opts.PitchClassWeight*pitchClasses + opts.RootWeight*root + opts.QualityWeight*quality) / total
+}
+
+// jaccard returns the number of pitch classes in both sets, out of the number
+// of pitch classes in either.
+func jaccard(a, b PitchClassSet) float64 {
+	union := a.Union(b).Len()
+	if union == 0 {
+		return 1
+	}
+	return float64(a.Intersection(b).Len()) / float64(union)
+}
+
+// CommonTones returns the notes the two chords have in common. Notes are
+// compared by pitch class, and they are spelled as in the first chord, in the
+// order the first chord spells them (see Spell). So the common tones of C♯7
+// and D♭△7 are C♯, E♯, and G♯. Each pitch class appears once, even if the
+// first chord's bass note is also one of its tones.
+func CommonTones(a, b *Chord) []Note {
+	other := b.PitchClassSet()
+	var seen PitchClassSet
+	var common []Note
+	for _, n := range a.Spell() {
+		pc := PitchClass(n)
+		if other.Contains(pc) && !seen.Contains(pc) {
+			seen = seen.With(pc)
+			common = append(common, n)
+		}
+	}
+	return common
+}
+
+// CommonToneCount returns the number of pitch classes the two chords have in
+// common. (See CommonTones.)
+func CommonToneCount(a, b *Chord) int {
+	return a.PitchClassSet().Intersection(b.PitchClassSet()).Len()
+}
+
+// ToneChange is a chord tone that was chromatically altered, like the 3rd of
+// C7 that is lowered to make C-7. (See ChordDiff.)
+type ToneChange struct {
+	// From is the tone before it was altered.
+	From ChordTone
+	// To is the tone after it was altered.
+	To ChordTone
+}
+
+// String implements the Stringer interface. The result is the two tones
+// separated by an arrow, like "3→♭3".
+func (c ToneChange) String() string {
+	return c.From.String() + "→" + c.To.String()
+}
+
+// NoteChange is a note that was replaced by another, like the root of C7 that
+// is replaced to make F7. (See ChordDiff.)
+type NoteChange struct {
+	// From is the note before it was replaced.
+	From Note
+	// To is the note after it was replaced.
+	To Note
+}
+
+// String implements the Stringer interface. The result is the two notes
+// separated by an arrow, like "C→F".
+func (c NoteChange) String() string {
+	return c.From.String() + "→" + c.To.String()
+}
+
+// ChordDiff describes how one chord differs from another: its root, bass
+// note, and tones. (See Diff.) As with Alteration, tones are relative to a
+// major or minor triad, so the 5th of a diminished chord is a ♭5, the 3rd of
+// a minor chord is a ♭3, and the 7th of a fully diminished chord is a ♭7.
+type ChordDiff struct {
+	// Root is the change to the chord's root, or nil if both chords have
+	// the same root.
+	Root *NoteChange
+	// Bass is the change to the chord's bass note, or nil if both chords
+	// have the same bass note. A chord with no bass note has its root in
+	// the bass, but the bass only changes with the root if either chord has
+	// a bass note.
+	Bass *NoteChange
+	// Added are the tones of the second chord that the first does not have.
+	Added []ChordTone
+	// Removed are the tones of the first chord that the second does not
+	// have.
+	Removed []ChordTone
+	// Altered are the tones of the first chord that the second chord has in
+	// altered form.
+	Altered []ToneChange
+}
+
+// IsEmpty returns true if the diff has no changes: the two chords have the
+// same root, bass note, and tones.
+func (d *ChordDiff) IsEmpty() bool {
+	return d.Root == nil && d.Bass == nil && len(d.Added) == 0 && len(d.Removed) == 0 && len(d.Altered) == 0
+}
+
+// String implements the Stringer interface. The result lists the changes,
+// like "added ♭9, altered 3→♭3" or "root C→F, bass C→A". If there are no
+// changes, the result is "unchanged".
+func (d *ChordDiff) String() string {
+	if d.IsEmpty() {
+		return "unchanged"
+	}
+	var b bytes.Buffer
+	section := func(label string, n int, item func(int) string) {
+		if n == 0 {
+			return
+		}
+		if b.Len() > 0 {
+			b.WriteString(", ")
+		}
+		b.WriteString(label)
+		for i := 0; i < n; i++ {
+			b.WriteByte(' ')
+			b.WriteString(item(i))
+		}
+	}
+	if d.Root != nil {
+		section("root", 1, func(int) string { return d.Root.String() })
+	}
+	if d.Bass != nil {
+		section("bass", 1, func(int) string { return d.Bass.String() })
+	}
+	section("added", len(d.Added), func(i int) string { return d.Added[i].String() })
+	section("removed", len(d.Removed), func(i int) string { return d.Removed[i].String() })
+	section("altered", len(d.Altered), func(i int) string { return d.Altered[i].String() })
+	return b.String()
+}
+
+// Diff returns the tones that were added, removed, or chromatically altered
+// from the first chord to the second, like "added ♭9" from G7 to G7♭9 or
+// "altered 3→♭3" from C7 to C-7. A tone of the second chord is an alteration
+// of a tone of the first if they are the same degree (where 9ths, 11ths, and
+// 13ths are the same degrees as 2nds, 4ths, and 6ths) and the first chord
+// does not also have it unaltered.
+//
+// Tones are compared relative to the root of each chord, so chords with
+// different roots are compared by their qualities, and the diff also reports
+// the change to the root, like "root C→D" from C to D. Changes to the bass
+// note are reported the same way, like "bass C→E" from C7 to C7/E. Roots and
+// bass notes are compared by pitch class, so C and B♯ are unchanged.
+func Diff(a, b *Chord) *ChordDiff {
+	d := &ChordDiff{}
+	if PitchClass(a.Root) != PitchClass(b.Root) {
+		d.Root = &NoteChange{From: a.Root, To: b.Root}
+	}
+	if a.Bass.N != 0 || b.Bass.N != 0 {
+		bassA, bassB := a.Bass, b.Bass
+		if bassA.N == 0 {
+			bassA = a.Root
+		}
+		if bassB.N == 0 {
+			bassB = b.Root
+		}
+		if PitchClass(bassA) != PitchClass(bassB) {
+			d.Bass = &NoteChange{From: bassA, To: bassB}
+		}
+	}
+
+	from, to := diffTones(a), diffTones(b)
+	// tones in both chords are unchanged
+	for i := 0; i < len(from); i++ {
+		for j := range to {
+			if sameDegree(from[i], to[j]) && from[i].Acc == to[j].Acc {
+				from = append(from[:i], from[i+1:]...)
+				to = append(to[:j], to[j+1:]...)
+				i--
+				break
+			}
+		}
+	}
+	for _, tn := range from {
+		altered := false
+		for j := range to {
+			if sameDegree(tn, to[j]) {
+				d.Altered = append(d.Altered, ToneChange{From: tn, To: to[j]})
+				to = append(to[:j], to[j+1:]...)
+				altered = true
+				break
+			}
+		}
+		if !altered {
+			d.Removed = append(d.Removed, tn)
+		}
+	}
+	d.Added = to
+	return d
+}
+
+// diffTones returns the tones of the given chord other than its root, relative
+// to a major or minor triad (like decompose, but keeping every tone), in
+// order of degree. The tones are those of the chord in canonical form, so the
+// 7th implied by a 9th, 11th, or 13th is included.
+func diffTones(ch *Chord) []ChordTone {
+	ch = ch.Canonical()
+	var tns []ChordTone
+	switch ch.Triad {
+	case Maj3, Aug3:
+		tns = append(tns, ChordTone{Val: 3})
+	case Sus:
+		// the suspension is one of the extra tones
+	default:
+		tns = append(tns, ChordTone{Val: 3, Acc: Flat})
+	}
+	hasFifth, hasSeventh := false, false
+	for _, tn := range ch.ExtraTones {
+		switch tn.Val {
+		case 5:
+			hasFifth = true
+		case 7:
+			hasSeventh = true
+			if ch.Triad == Dim3 || ch.Triad == FDim {
+				// these triads have a diminished 7th, which is a flat 7th
+				// relative to the minor triad
+				tn.Acc--
+			}
+		}
+		tns = append(tns, tn)
+	}
+	if !hasFifth {
+		tns = append(tns, ch.Triad.fifthTone())
+	}
+	if !hasSeventh && (ch.Triad == HDim || ch.Triad == FDim) {
+		seventh := ChordTone{Val: 7}
+		if ch.Triad == FDim {
+			seventh.Acc = Flat
+		}
+		tns = append(tns, seventh)
+	}
+	sort.SliceStable(tns, func(i, j int) bool {
+		if tns[i].Val != tns[j].Val {
+			return tns[i].Val < tns[j].Val
+		}
+		return tns[i].Acc < tns[j].Acc
+	})
+	return tns
+}
+
+// sameDegree returns true if the two tones are the same degree of the scale,
+// where 9ths, 11ths, and 13ths are the same degrees as 2nds, 4ths, and 6ths.
+func sameDegree(a, b ChordTone) bool {
+	return (a.Val-1)%7 == (b.Val-1)%7
+}
@@ -0,0 +1,99 @@
+package chords
+
+import (
+	"fmt"
+	"math"
+	"testing"
+)
+
+func TestSimilarity(t *testing.T) {
+	cases := []struct {
+		a, b string
+		exp  float64
+	}{
+		{a: "C", b: "C", exp: 1},
+		{a: "C", b: "B♯", exp: 1},
+		{a: "C", b: "A-", exp: 0.5},
+		{a: "C", b: "E-", exp: 11.0 / 24},
+		{a: "C", b: "F♯", exp: 0.25},
+		// same quality, a 5th apart: 1/7 of the pitch classes in common
+		{a: "C7", b: "G7", exp: (2.0/7 + 5.0/6 + 1) / 4},
+		// same root: 3/5 of the pitch classes and quality in common
+		{a: "C7", b: "C△7", exp: (6.0/5 + 1 + 3.0/5) / 4},
+	}
+	for _, tc := range cases {
+		a, b := MustParseChord(tc.a), MustParseChord(tc.b)
+		actual := Similarity(a, b)
+		if math.Abs(actual-tc.exp) > 1e-9 {
+			t.Errorf("Similarity for %s, %s returned wrong value: %v != %v", tc.a, tc.b, actual, tc.exp)
+		}
+		if reverse := Similarity(b, a); math.Abs(reverse-actual) > 1e-9 {
+			t.Errorf("Similarity for %s, %s is not symmetric: %v != %v", tc.b, tc.a, reverse, actual)
+		}
+	}
+
+	c, a := MustParseChord("C"), MustParseChord("A-")
+	if actual := SimilarityWithOptions(c, a, SimilarityOptions{RootWeight: 1}); math.Abs(actual-0.5) > 1e-9 {
+		t.Errorf("SimilarityWithOptions for only the root weight returned wrong value: %v != 0.5", actual)
+	}
+	if actual := SimilarityWithOptions(c, MustParseChord("F♯"), SimilarityOptions{QualityWeight: 3}); actual != 1 {
+		t.Errorf("SimilarityWithOptions for only the quality weight returned wrong value: %v != 1", actual)
+	}
+}
+
+func TestCommonTones(t *testing.T) {
+	cases := []struct {
+		a, b string
+		exp  string
+	}{
+		{a: "C", b: "A-", exp: "[C E]"},
+		{a: "C", b: "F♯", exp: "[]"},
+		{a: "C♯7", b: "D♭△7", exp: "[C♯ E♯ G♯]"},
+		{a: "D♭△7", b: "C♯7", exp: "[D♭ F A♭]"},
+		{a: "C/E", b: "E-", exp: "[E G]"},
+		{a: "G7", b: "C△7", exp: "[G B]"},
+	}
+	for _, tc := range cases {
+		a, b := MustParseChord(tc.a), MustParseChord(tc.b)
+		if actual := fmt.Sprint(CommonTones(a, b)); actual != tc.exp {
+			t.Errorf("CommonTones for %s, %s returned wrong value: %s != %s", tc.a, tc.b, actual, tc.exp)
+		}
+		if n, exp := CommonToneCount(a, b), len(CommonTones(a, b)); n != exp {
+			t.Errorf("CommonTones for %s, %s returned wrong value: %d != %d", tc.a, tc.b, n, exp)
+		}
+	}
+}
+
+func TestDiff(t *testing.T) {
+	cases := []struct {
+		a, b string
+		exp  string
+	}{
+		{a: "G7", b: "G7♭9", exp: "added ♭9"},
+		{a: "G7♭9", b: "G7", exp: "removed ♭9"},
+		{a: "C7", b: "C-7", exp: "altered 3→♭3"},
+		{a: "C7", b: "C△7", exp: "altered 7→△7"},
+		{a: "C", b: "C+", exp: "altered 5→♯5"},
+		{a: "C-7", b: "Cø", exp: "altered 5→♭5"},
+		{a: "Cø", b: "Co", exp: "altered 7→♭7"},
+		{a: "C9", b: "C7♭9♯9", exp: "added ♯9, altered 9→♭9"},
+		{a: "C", b: "Csus4", exp: "added 4, removed 3"},
+		{a: "C6", b: "C13", exp: "added 7"},
+		{a: "C", b: "D", exp: "root C→D"},
+		{a: "C7", b: "F7/A", exp: "root C→F, bass C→A"},
+		{a: "C7", b: "C7/E", exp: "bass C→E"},
+		{a: "C/G", b: "E-/G", exp: "root C→E, altered 3→♭3"},
+		{a: "C", b: "D-7", exp: "root C→D, added 7, altered 3→♭3"},
+		{a: "C", b: "B♯", exp: "unchanged"},
+		{a: "C/E", b: "C/F♭", exp: "unchanged"},
+	}
+	for _, tc := range cases {
+		d := Diff(MustParseChord(tc.a), MustParseChord(tc.b))
+		if actual := d.String(); actual != tc.exp {
+			t.Errorf("Diff for %s to %s returned wrong value: %q != %q", tc.a, tc.b, actual, tc.exp)
+		}
+		if d.IsEmpty() != (tc.exp == "unchanged") {
+			t.Errorf("%s to %s: wrong result from IsEmpty: %v", tc.a, tc.b, d.IsEmpty())
+		}
+	}
+}
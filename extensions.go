@@ -0,0 +1,256 @@
+package chords
+
+import "fmt"
+
+// SimplifyLevel indicates how much Chord.Simplify simplifies a chord: which
+// of its tones are kept.
+type SimplifyLevel int
+
+const (
+	// SimplifyToTriad keeps only the triad of the chord, like C for C13.
+	// Altered 5ths are part of the triad, so C7♭5 is simplified to C♭5.
+	// Half and fully diminished chords are simplified to diminished triads.
+	SimplifyToTriad SimplifyLevel = iota
+	// SimplifyToSeventh keeps the triad and the 7th of the chord, like C7
+	// for C13. A 6th is kept as well, so C6 is unchanged.
+	SimplifyToSeventh
+	// SimplifyToNinth keeps the triad, the 7th (or 6th), and any 9ths of the
+	// chord, like C7♭9 for C7♭9♯11. Added 2nds are kept too, since they are
+	// 9ths in a chord with no 7th.
+	SimplifyToNinth
+)
+
+// String implements the Stringer interface.
+func (l SimplifyLevel) String() string {
+	switch l {
+	case SimplifyToTriad:
+		return "triad"
+	case SimplifyToSeventh:
+		return "seventh"
+	case SimplifyToNinth:
+		return "ninth"
+	default:
+		return fmt.Sprintf("?(%d)", l)
+	}
+}
+
+// Simplify returns a new chord that is this chord with the tones above the
+// given level removed, for charts that spell out only the essential harmony.
+// The suspension of a suspended chord is always kept, so Csus4 9 is simplified
+// to Csus4 or Csus4 7. If the chord's bass note was one of the removed tones,
+// the bass note is removed too, so C7/B♭ is simplified to C. Other bass notes
+// are kept, so C/E is unchanged. The result is in canonical form. (See
+// Canonicalize.) This chord is not modified.
+func (ch *Chord) Simplify(level SimplifyLevel) *Chord {
+	ret := ch.Clone()
+	ret.Canonicalize()
+	var susTone ChordTone
+	if ret.Triad == Sus {
+		susTone = ret.susTone()
+	}
+	var tones []ChordTone
+	for _, tn := range ret.ExtraTones {
+		keep := false
+		switch tn.Val {
+		case 5:
+			keep = true
+		case 6, 7:
+			keep = level >= SimplifyToSeventh
+		case 2, 9:
+			keep = level >= SimplifyToNinth
+		}
+		if keep && tn != susTone {
+			tones = append(tones, tn)
+		}
+	}
+	if susTone.Val != 0 {
+		tones = append(tones, susTone)
+	}
+	if level == SimplifyToTriad && (ret.Triad == HDim || ret.Triad == FDim) {
+		ret.Triad = Dim3
+	}
+	ret.ExtraTones = tones
+	ret.canonical = false
+
+	if ret.Bass.N != 0 {
+		chordTones := ch.Clone()
+		chordTones.Bass = Note{}
+		remaining := ret.Clone()
+		remaining.Bass = Note{}
+		bass := PitchClass(ret.Bass)
+		if chordTones.PitchClassSet().Contains(bass) && !remaining.PitchClassSet().Contains(bass) {
+			ret.Bass = Note{}
+		}
+	}
+	ret.Canonicalize()
+	return ret
+}
+
+// susTone returns the suspension of a suspended chord: its 2nd or 4th, or, if
+// it has neither, its 9th or 11th as a 2nd or 4th. If it has several, the 4th
+// is preferred.
+func (ch *Chord) susTone() ChordTone {
+	var found ChordTone
+	for _, vals := range [][2]int8{{4, 2}, {11, 9}} {
+		for _, v := range vals {
+			for _, tn := range ch.ExtraTones {
+				if tn.Val == v && found.Val == 0 {
+					found = tn
+				}
+			}
+		}
+		if found.Val != 0 {
+			if found.Val > 7 {
+				found.Val -= 7
+			}
+			return found
+		}
+	}
+	return found
+}
+
+// Extend returns a new chord that is this chord extended up to the given
+// tension, which must be 9, 11, or 13, for arrangers who want to thicken a
+// chord. Every tension up to the given one is added, along with a 7th, unless
+// the chord already has that tone (in any form), so C7♯11 extended to 13 is
+// C9♯11 13.
+//
+// The tones that are added depend on the quality of the chord:
+//   - A major triad gets a major 7th, and other triads get a minor 7th,
+//     except for a diminished triad, which becomes fully diminished.
+//   - Every 9th is a major 9th.
+//   - Chords with a major 3rd get a ♯11, since a natural 11th clashes with
+//     the 3rd. Other chords get a natural 11th.
+//   - Chords with a diminished 5th get a ♭13, and augmented chords get no
+//     13th, since a ♭13 is the same as their ♯5. So do dominant 7th chords
+//     with an altered 9th, like G7♭9. Other chords get a natural 13th.
+//
+// So C extended to 13 is C△9♯11 13, and C-7 extended to 11 is C-9 11.
+// To choose tensions from a scale instead, use ExtendInScale. The result is in
+// canonical form. This chord is not modified.
+func (ch *Chord) Extend(to int8) (*Chord, error) {
+	return ch.extend(to, nil)
+}
+
+// ExtendInScale returns a new chord that is this chord extended up to the
+// given tension, like Extend, but the 7th and tensions that are added are
+// those of the given scale. (See Tensions.) Tensions that are not available
+// in the scale, because the scale has no such note or because it is an avoid
+// note, are not added. If the scale has several tensions of the same degree,
+// the lowest is added. So G7 in G altered extended to 13 is G7♭9♯11♭13 (the
+// ♯9 is left out since the ♭9 is lower), and C△7 in C major extended to 13 is
+// C△9 13, with no 11th since it is an avoid note. If the scale has no 7th,
+// the 7th is chosen as for Extend. A diminished triad becomes half diminished
+// if the scale has a minor 7th, like Bdim in C major.
+func (ch *Chord) ExtendInScale(to int8, s *Scale) (*Chord, error) {
+	return ch.extend(to, s)
+}
+
+func (ch *Chord) extend(to int8, s *Scale) (*Chord, error) {
+	if to != 9 && to != 11 && to != 13 {
+		return nil, fmt.Errorf("cannot extend chord to %d: must be 9, 11, or 13", to)
+	}
+	ret := ch.Clone()
+	ret.Canonicalize()
+	ret.canonical = false
+
+	if !ret.decompose().has(7) {
+		ok := false
+		if s != nil {
+			ok = ret.addScaleSeventh(s)
+		}
+		if !ok {
+			switch ret.Triad {
+			case Maj3:
+				ret.ExtraTones = append(ret.ExtraTones, ChordTone{Val: 7, Acc: Sharp})
+			case Dim3:
+				ret.Triad = FDim
+			default:
+				ret.ExtraTones = append(ret.ExtraTones, ChordTone{Val: 7})
+			}
+		}
+		// canonicalize now so that Tensions sees the 7th
+		ret.Canonicalize()
+		ret.canonical = false
+	}
+
+	var available []ChordTone
+	if s != nil {
+		available, _ = ret.Tensions(s)
+	}
+	d := ret.decompose()
+	major := d.triad == Maj3
+	var fifth ChordTone
+	alteredNinth := false
+	for _, tn := range d.tones {
+		switch {
+		case tn.Val == 5:
+			fifth = tn
+		case tn.Val == 9 && tn.Acc != Natural:
+			alteredNinth = true
+		}
+	}
+	dominant := major && containsTone(d.tones, ChordTone{Val: 7})
+	for v := int8(9); v <= to; v += 2 {
+		if d.has(v) {
+			continue
+		}
+		tn := ChordTone{Val: v}
+		if s != nil {
+			found := false
+			for _, a := range available {
+				if a.Val == v {
+					tn, found = a, true
+					break
+				}
+			}
+			if !found {
+				continue
+			}
+		} else {
+			switch v {
+			case 11:
+				if major {
+					tn.Acc = Sharp
+				}
+			case 13:
+				if fifth.Acc == Sharp {
+					continue
+				}
+				if fifth.Acc == Flat || (dominant && alteredNinth) {
+					tn.Acc = Flat
+				}
+			}
+		}
+		ret.ExtraTones = append(ret.ExtraTones, tn)
+	}
+	ret.Canonicalize()
+	return ret, nil
+}
+
+// addScaleSeventh adds the 7th of the given scale to the chord: the note of
+// the scale that is a minor or major 7th above the chord's root. A diminished
+// triad becomes half diminished if the scale has a minor 7th, or else fully
+// diminished if it has a diminished 7th. This returns false if the scale has
+// no such note.
+func (ch *Chord) addScaleSeventh(s *Scale) bool {
+	var set PitchClassSet
+	for _, n := range s.Spell() {
+		set = set.With(PitchClass(n) - PitchClass(ch.Root))
+	}
+	switch {
+	case ch.Triad == Dim3 && set.Contains(10):
+		ch.Triad = HDim
+	case ch.Triad == Dim3 && set.Contains(9):
+		ch.Triad = FDim
+	case ch.Triad == Dim3:
+		return false
+	case set.Contains(10):
+		ch.ExtraTones = append(ch.ExtraTones, ChordTone{Val: 7})
+	case set.Contains(11):
+		ch.ExtraTones = append(ch.ExtraTones, ChordTone{Val: 7, Acc: Sharp})
+	default:
+		return false
+	}
+	return true
+}
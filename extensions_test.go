@@ -0,0 +1,104 @@
+package chords
+
+import "testing"
+
+func TestChord_Simplify(t *testing.T) {
+	cases := []struct {
+		chord                 string
+		triad, seventh, ninth string
+	}{
+		{chord: "C", triad: "C", seventh: "C", ninth: "C"},
+		{chord: "C13♯11", triad: "C", seventh: "C7", ninth: "C7"},
+		{chord: "C-9", triad: "C-", seventh: "C-7", ninth: "C-9"},
+		{chord: "C-11", triad: "C-", seventh: "C-7", ninth: "C-7"},
+		{chord: "C7♭9♯11", triad: "C", seventh: "C7", ninth: "C7♭9"},
+		{chord: "C6", triad: "C", seventh: "C6", ninth: "C6"},
+		{chord: "Cø", triad: "Cdim", seventh: "Cø", ninth: "Cø"},
+		{chord: "Co", triad: "Cdim", seventh: "Co", ninth: "Co"},
+		{chord: "C+7", triad: "C+", seventh: "C+7", ninth: "C+7"},
+		{chord: "C7♭5", triad: "C♭5", seventh: "C7♭5", ninth: "C7♭5"},
+		{chord: "Csus4 9", triad: "Csus4", seventh: "Csus4 7", ninth: "Csus4 9"},
+		{chord: "Csus2 4", triad: "Csus4", seventh: "Csus4", ninth: "Csus2 4"},
+		// bass notes are removed with the tones they double
+		{chord: "C/E", triad: "C/E", seventh: "C/E", ninth: "C/E"},
+		{chord: "C7/B♭", triad: "C", seventh: "C7/B♭", ninth: "C7/B♭"},
+		{chord: "C9/D", triad: "C", seventh: "C7", ninth: "C9/D"},
+		{chord: "C△7/F♯", triad: "C/F♯", seventh: "C△7/F♯", ninth: "C△7/F♯"},
+	}
+	for _, tc := range cases {
+		ch := MustParseChord(tc.chord)
+		orig := ch.String()
+		for level, exp := range []string{tc.triad, tc.seventh, tc.ninth} {
+			if actual := ch.Simplify(SimplifyLevel(level)).String(); actual != exp {
+				t.Errorf("Chord.Simplify for %s, %v returned wrong value: %s != %s", tc.chord, SimplifyLevel(level), actual, exp)
+			}
+		}
+		if ch.String() != orig {
+			t.Errorf("%s: chord was modified: %v", tc.chord, ch)
+		}
+	}
+}
+
+func TestChord_Extend(t *testing.T) {
+	cases := []struct {
+		chord                  string
+		nine, eleven, thirteen string
+	}{
+		{chord: "C", nine: "C△9", eleven: "C△9♯11", thirteen: "C△9♯11 13"},
+		{chord: "C-7", nine: "C-9", eleven: "C-9 11", thirteen: "C-9 11 13"},
+		{chord: "C7", nine: "C9", eleven: "C9♯11", thirteen: "C9♯11 13"},
+		{chord: "C-△7", nine: "C-△9", eleven: "C-△9 11", thirteen: "C-△9 11 13"},
+		{chord: "Cdim", nine: "Co9", eleven: "Co9 11", thirteen: "Co9 11♭13"},
+		{chord: "Cø", nine: "Cø9", eleven: "Cø9 11", thirteen: "Cø9 11♭13"},
+		{chord: "C+", nine: "C+9", eleven: "C+9♯11", thirteen: "C+9♯11"},
+		{chord: "Csus4", nine: "Csus4 9", eleven: "Csus4 9", thirteen: "Csus4 9 13"},
+		{chord: "C7♯11", nine: "C9♯11", eleven: "C9♯11", thirteen: "C9♯11 13"},
+		{chord: "G7♭9", nine: "G7♭9", eleven: "G7♭9♯11", thirteen: "G7♭9♯11♭13"},
+		{chord: "C/E", nine: "C△9/E", eleven: "C△9♯11/E", thirteen: "C△9♯11 13/E"},
+	}
+	for _, tc := range cases {
+		ch := MustParseChord(tc.chord)
+		orig := ch.String()
+		for i, exp := range []string{tc.nine, tc.eleven, tc.thirteen} {
+			to := int8(9 + 2*i)
+			actual, err := ch.Extend(to)
+			if err != nil {
+				t.Errorf("Chord.Extend for %s extended to %d failed: %v", tc.chord, to, err)
+			} else if actual.String() != exp {
+				t.Errorf("Chord.Extend for %s extended to %d returned wrong value: %v != %s", tc.chord, to, actual, exp)
+			}
+		}
+		if ch.String() != orig {
+			t.Errorf("%s: chord was modified: %v", tc.chord, ch)
+		}
+	}
+	for _, to := range []int8{0, 7, 10, 15} {
+		if _, err := MustParseChord("C").Extend(to); err == nil {
+			t.Errorf("Chord.Extend(%d) should have failed", to)
+		}
+	}
+}
+
+func TestChord_ExtendInScale(t *testing.T) {
+	cases := []struct {
+		chord, scale, exp string
+	}{
+		{chord: "G7", scale: "G altered", exp: "G7♭9♯11♭13"},
+		{chord: "C△7", scale: "C major", exp: "C△9 13"},
+		{chord: "C", scale: "C lydian", exp: "C△9♯11 13"},
+		{chord: "D-", scale: "C major", exp: "D-9 11 13"},
+		{chord: "G", scale: "C major", exp: "G9 13"},
+		{chord: "Bdim", scale: "C major", exp: "Bø11♭13"},
+		{chord: "A-", scale: "A harmonic minor", exp: "A-△9 11"},
+		// the scale has no 7th, so the default is used
+		{chord: "C", scale: "C major pentatonic", exp: "C△9 13"},
+	}
+	for _, tc := range cases {
+		actual, err := MustParseChord(tc.chord).ExtendInScale(13, MustParseScale(tc.scale))
+		if err != nil {
+			t.Errorf("Chord.ExtendInScale for %s in %s failed: %v", tc.chord, tc.scale, err)
+		} else if actual.String() != tc.exp {
+			t.Errorf("Chord.ExtendInScale for %s in %s returned wrong value: %v != %s", tc.chord, tc.scale, actual, tc.exp)
+		}
+	}
+}
@@ -0,0 +1,148 @@
+package fretboard
+
+import (
+	"sort"
+
+	"github.com/jhump/chords"
+)
+
+// CapoChord is a chord played with a capo.
+type CapoChord struct {
+	// Sounding is the chord that is heard.
+	Sounding *chords.Chord
+	// Shape is the chord that is fingered, relative to the capo. It is the
+	// sounding chord transposed down by the number of frets of the capo.
+	Shape *chords.Chord
+}
+
+// TransposeForCapo returns the chords that are fingered to play the given
+// progression with a capo at the given fret. The first result is the whole
+// progression as fingered, transposed down by the capo's number of half-steps
+// (see chords.Progression.Transpose). The second result pairs each distinct
+// chord of the progression, in the order in which they first appear, with its
+// shape. So with a capo at the third fret, a progression in B♭ is fingered in
+// G, and the B♭ chords are played with G shapes.
+func TransposeForCapo(prog *chords.Progression, fret int) (*chords.Progression, []CapoChord) {
+	steps := int8(((12 - fret%12) % 12))
+	shapes := prog.Transpose(chords.IntervalFromHalfSteps(steps, chords.Major))
+	sounding, shaped := prog.Chords(), shapes.Chords()
+	seen := map[string]bool{}
+	var chs []CapoChord
+	for i, ch := range sounding {
+		if key := ch.String(); !seen[key] {
+			seen[key] = true
+			chs = append(chs, CapoChord{Sounding: ch, Shape: shaped[i]})
+		}
+	}
+	return shapes, chs
+}
+
+// CapoOptions controls how BestCapo chooses capo positions.
+type CapoOptions struct {
+	// Instrument is the instrument that is played. If it has no strings,
+	// Guitar is used.
+	Instrument Instrument
+	// MaxFret is the highest fret at which the capo can be placed. If zero,
+	// the seventh fret is the highest.
+	MaxFret int
+}
+
+// CapoPosition is a way to play a progression with a capo. (See BestCapo.)
+type CapoPosition struct {
+	// Fret is the fret at which the capo is placed, or zero if no capo is
+	// used.
+	Fret int
+	// Shapes is the progression as fingered. (See TransposeForCapo.)
+	Shapes *chords.Progression
+	// Chords are the distinct chords of the progression along with their
+	// shapes and the open-position fingerings used to play them.
+	Chords []CapoChord
+	// Fingerings are the fingerings for the shapes, in the same order as
+	// Chords.
+	Fingerings []Fingering
+	// Difficulty is the sum of the difficulties of the fingerings. (See
+	// Fingering.Difficulty.)
+	Difficulty int
+}
+
+// BestCapo returns the capo positions at which every chord of the given
+// progression can be played with an open-position shape, ordered from easiest
+// to hardest. An open-position shape is a fingering that uses at least one
+// open string, has no barre, and uses no frets above the fourth (relative to
+// the capo). For each chord, the easiest such fingering is used, and positions
+// with the same total difficulty are ordered by fret, lowest first.
+//
+// This returns nil if there is no capo position (including no capo) where all
+// of the chords have open-position shapes.
+func BestCapo(prog *chords.Progression, opts CapoOptions) []CapoPosition {
+	inst := opts.Instrument
+	if len(inst.Strings) == 0 {
+		inst = Guitar
+	}
+	maxFret := opts.MaxFret
+	if maxFret == 0 {
+		maxFret = 7
+	}
+	if maxFret > inst.Frets {
+		maxFret = inst.Frets
+	}
+
+	// the same shape may be used at more than one capo position
+	cache := map[string]*Fingering{}
+	openShape := func(ch *chords.Chord) *Fingering {
+		key := ch.String()
+		if f, ok := cache[key]; ok {
+			return f
+		}
+		var found *Fingering
+		for _, f := range inst.Fingerings(ch) {
+			if isOpenPosition(f) {
+				f := f
+				found = &f
+				break
+			}
+		}
+		cache[key] = found
+		return found
+	}
+
+	var positions []CapoPosition
+	for fret := 0; fret <= maxFret; fret++ {
+		shapes, chs := TransposeForCapo(prog, fret)
+		pos := CapoPosition{Fret: fret, Shapes: shapes, Chords: chs}
+		for _, ch := range chs {
+			f := openShape(ch.Shape)
+			if f == nil {
+				pos.Fingerings = nil
+				break
+			}
+			pos.Fingerings = append(pos.Fingerings, *f)
+			pos.Difficulty += f.Difficulty
+		}
+		if len(pos.Fingerings) == len(chs) {
+			positions = append(positions, pos)
+		}
+	}
+	sort.SliceStable(positions, func(i, j int) bool {
+		return positions[i].Difficulty < positions[j].Difficulty
+	})
+	return positions
+}
+
+// isOpenPosition returns true if the given fingering is an open-position
+// shape. (See BestCapo.)
+func isOpenPosition(f Fingering) bool {
+	if f.Barre != nil {
+		return false
+	}
+	open := false
+	for _, fret := range f.Frets {
+		if fret > 4 {
+			return false
+		}
+		if fret == 0 {
+			open = true
+		}
+	}
+	return open
+}
@@ -0,0 +1,63 @@
+package fretboard
+
+import (
+	"testing"
+
+	"github.com/jhump/chords"
+)
+
+func TestTransposeForCapo(t *testing.T) {
+	shapes, chs := TransposeForCapo(chords.MustParseProgression("| B♭ | E♭ | F/A | B♭ |"), 3)
+	if exp := "| G | C | D/F♯ | G |"; shapes.String() != exp {
+		t.Errorf("TransposeForCapo returned wrong shapes: %q != %q", shapes, exp)
+	}
+	exp := [][2]string{{"B♭", "G"}, {"E♭", "C"}, {"F/A", "D/F♯"}}
+	if len(chs) != len(exp) {
+		t.Fatalf("wrong number of chords: %d", len(chs))
+	}
+	for i, ch := range chs {
+		if ch.Sounding.String() != exp[i][0] || ch.Shape.String() != exp[i][1] {
+			t.Errorf("TransposeForCapo returned wrong chord %d: %v %v != %v", i, ch.Sounding, ch.Shape, exp[i])
+		}
+	}
+
+	shapes, _ = TransposeForCapo(chords.MustParseProgression("| C | G |"), 0)
+	if exp := "| C | G |"; shapes.String() != exp {
+		t.Errorf("TransposeForCapo returned wrong shapes: %q != %q", shapes, exp)
+	}
+}
+
+func TestBestCapo(t *testing.T) {
+	positions := BestCapo(chords.MustParseProgression("| B♭ | E♭ | F | B♭ |"), CapoOptions{})
+	if len(positions) == 0 {
+		t.Fatalf("no capo positions")
+	}
+	best := positions[0]
+	if best.Fret != 1 || best.Shapes.String() != "| A | D | E | A |" {
+		t.Errorf("wrong best position: %d %v", best.Fret, best.Shapes)
+	}
+	if len(best.Fingerings) != 3 || best.Fingerings[0].String() != "x02220" {
+		t.Errorf("wrong fingerings: %v", best.Fingerings)
+	}
+	for i := 1; i < len(positions); i++ {
+		if positions[i].Difficulty < positions[i-1].Difficulty {
+			t.Errorf("positions not sorted by difficulty")
+		}
+		for _, f := range positions[i].Fingerings {
+			if !isOpenPosition(f) {
+				t.Errorf("not an open-position fingering: %v", f)
+			}
+		}
+	}
+
+	// limit the capo and use a different instrument
+	positions = BestCapo(chords.MustParseProgression("| B♭ | E♭ | F | B♭ |"), CapoOptions{Instrument: Ukulele, MaxFret: 2})
+	for _, pos := range positions {
+		if pos.Fret > 2 {
+			t.Errorf("capo is too high: %d", pos.Fret)
+		}
+		if len(pos.Fingerings) > 0 && len(pos.Fingerings[0].Frets) != 4 {
+			t.Errorf("wrong instrument: %v", pos.Fingerings)
+		}
+	}
+}
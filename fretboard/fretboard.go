@@ -0,0 +1,354 @@
+// Package fretboard models fretted, stringed instruments, like the guitar, and
+// finds fingerings for chords on them. An instrument is described by the
+// pitches of its open strings and its number of frets:
+//
+//	inst := fretboard.Guitar
+//	for _, f := range inst.Fingerings(chords.MustParseChord("C")) {
+//		fmt.Println(f) // x32010, x35553, ...
+//	}
+//
+// Fingerings are ranked by difficulty, so the first ones are the shapes a
+// player would usually reach for: open chords and barre chords with as few
+// fingers, and as small a stretch, as possible.
+package fretboard
+
+import (
+	"bytes"
+	"sort"
+	"strconv"
+
+	"github.com/jhump/chords"
+)
+
+// Instrument is a fretted, stringed instrument. The pitches of its strings can
+// be any tuning, with any number of strings. For example, a guitar in DADGAD
+// tuning is:
+//
+//	fretboard.Instrument{Strings: fretboard.LookupTuning("DADGAD"), Frets: 22}
+type Instrument struct {
+	// Strings are the pitches of the open strings. They are ordered the same
+	// way as in a chord diagram: from the string that is lowest when the
+	// instrument is held (the thickest string on a guitar) to the highest.
+	Strings []chords.Pitch
+	// Frets is the number of frets.
+	Frets int
+	// FirstFrets, if not nil, is the fret at which each string starts, in the
+	// same order as Strings. It is zero for most strings, which start at the
+	// nut. But the short fifth string of a banjo starts at the fifth fret. A
+	// short string can be played open (fret zero) or fretted above the fret
+	// at which it starts, and frets are numbered from the nut, as in banjo
+	// tablature: so pressing a banjo's fifth string at the seventh fret raises
+	// it two half-steps.
+	FirstFrets []int
+	// IgnoreBass, if true, allows fingerings whose lowest pitch is not the
+	// bass note (or root) of the chord. This is how chords are usually played
+	// on instruments with re-entrant tunings, like the ukulele, or that are
+	// not used to play bass lines, like the mandolin.
+	IgnoreBass bool
+}
+
+// Muted is the fret value of a string that is not played. (See Fingering.)
+const Muted = -1
+
+// maxFretSpan is the largest number of frets, from lowest to highest, that a
+// fingering can stretch across.
+const maxFretSpan = 3
+
+// maxMutedStrings is the largest number of strings that a fingering can leave
+// unplayed.
+const maxMutedStrings = 2
+
+// Fingering is a way to play a chord on an instrument.
+type Fingering struct {
+	// Frets is the fret at which each string of the instrument is pressed, in
+	// the same order as the instrument's strings. Zero means the string is
+	// played open, and Muted means it is not played.
+	Frets []int
+	// Pitches are the pitches played by each string, in the same order as the
+	// instrument's strings. They are spelled the same way as the notes of the
+	// chord. The pitch for a muted string is the zero value.
+	Pitches []chords.Pitch
+	// Barre, if not nil, indicates that one finger is laid across several
+	// strings at the lowest fret of the fingering.
+	Barre *Barre
+	// Difficulty is a score for how hard the fingering is to play. Higher
+	// values are harder. It accounts for the number of fingers needed, how
+	// far they are stretched, how far up the neck they are, barres, and
+	// muted strings. (See Playability.Difficulty.) A fingering that omits
+	// the 5th of its chord is also a little harder, since it sounds thinner.
+	Difficulty int
+}
+
+// Barre is a single finger pressing down several adjacent strings at the same
+// fret.
+type Barre struct {
+	// Fret is the fret that is pressed.
+	Fret int
+	// First and Last are the indexes of the first and last strings pressed
+	// by the barre. (See Instrument.Strings.)
+	First, Last int
+}
+
+// String implements the Stringer interface. The result is the fret for each
+// string, with an "x" for muted strings, like "x32010" for an open C chord.
+// If any fret is greater than 9, the frets are separated by hyphens, like
+// "x-10-12-12-12-10".
+func (f Fingering) String() string {
+	sep := false
+	for _, fret := range f.Frets {
+		if fret > 9 {
+			sep = true
+		}
+	}
+	var b bytes.Buffer
+	for i, fret := range f.Frets {
+		if i > 0 && sep {
+			b.WriteByte('-')
+		}
+		if fret == Muted {
+			b.WriteByte('x')
+		} else {
+			b.WriteString(strconv.Itoa(fret))
+		}
+	}
+	return b.String()
+}
+
+// Fingerings returns the playable fingerings of the given chord, ordered from
+// easiest to hardest (see Fingering.Difficulty). Every note of the chord (see
+// chords.Chord.Spell) is played in each fingering, except that an unaltered 5th
+// may be omitted. The lowest pitch is always the chord's bass note, or its root
+// if it has no bass note, unless the instrument ignores the bass. Each
+// fingering needs at most four fingers (one of which may be a barre), and its
+// fretted notes span at most four frets.
+//
+// This returns nil if the chord has more notes than the instrument has strings
+// or if it cannot be played.
+func (inst Instrument) Fingerings(ch *chords.Chord) []Fingering {
+	notes := ch.Spell()
+	bass := ch.Root
+	if ch.Bass.N != 0 {
+		bass = ch.Bass
+	}
+	// the 5th is optional unless it is altered
+	fifth := -1
+	fifthNote := ch.Root.Transpose(chords.Interval{Val: 5})
+	for _, n := range notes {
+		if n == fifthNote {
+			fifth = int(n.Cardinal())
+		}
+	}
+	byClass := map[int]chords.Note{}
+	required := 0
+	for _, n := range notes {
+		pc := int(n.Cardinal())
+		if _, ok := byClass[pc]; ok {
+			// the bass is spelled first and may also be a chord tone
+			continue
+		}
+		byClass[pc] = n
+		if pc != fifth {
+			required++
+		}
+	}
+	if required > len(inst.Strings) {
+		return nil
+	}
+
+	// options has the possible frets for each string, for a given window
+	options := make([][]int, len(inst.Strings))
+	seen := map[string]bool{}
+	var results []Fingering
+	frets := make([]int, len(inst.Strings))
+	var try func(i int)
+	try = func(i int) {
+		if i < len(frets) {
+			for _, fret := range options[i] {
+				frets[i] = fret
+				try(i + 1)
+			}
+			return
+		}
+		f, ok := inst.evaluate(frets, byClass, bass, fifth, required)
+		if !ok {
+			return
+		}
+		if key := f.String(); !seen[key] {
+			seen[key] = true
+			results = append(results, f)
+		}
+	}
+	for low := 1; low+maxFretSpan <= inst.Frets || low == 1; low++ {
+		for s := range inst.Strings {
+			options[s] = append(options[s][:0], Muted)
+			for fret := 0; fret <= low+maxFretSpan && fret <= inst.Frets; fret++ {
+				if fret > 0 && (fret < low || fret <= inst.firstFret(s)) {
+					continue
+				}
+				if _, ok := byClass[pitchClass(inst.midiNumber(s, fret))]; ok {
+					options[s] = append(options[s], fret)
+				}
+			}
+		}
+		try(0)
+	}
+
+	sort.SliceStable(results, func(i, j int) bool {
+		if results[i].Difficulty != results[j].Difficulty {
+			return results[i].Difficulty < results[j].Difficulty
+		}
+		// prefer fuller chords, and then those nearer to the nut
+		if mi, mj := mutedStrings(results[i].Frets), mutedStrings(results[j].Frets); mi != mj {
+			return mi < mj
+		}
+		return lowestFret(results[i].Frets) < lowestFret(results[j].Frets)
+	})
+	return results
+}
+
+// evaluate checks whether the given frets are a playable fingering of the
+// chord whose notes, by pitch class, are given. If so, it returns the
+// fingering with its pitches, barre, and difficulty.
+func (inst Instrument) evaluate(frets []int, byClass map[int]chords.Note, bass chords.Note, fifth, required int) (Fingering, bool) {
+	f := Fingering{Frets: append([]int(nil), frets...), Pitches: make([]chords.Pitch, len(frets))}
+	played := map[int]bool{}
+	lowest, lowestString := 0, -1
+	minFret, maxFret, fretted := 0, 0, 0
+	for s, fret := range frets {
+		if fret == Muted {
+			continue
+		}
+		midi := inst.midiNumber(s, fret)
+		pc := pitchClass(midi)
+		played[pc] = true
+		f.Pitches[s] = pitchWithMIDINumber(byClass[pc], midi)
+		if lowestString < 0 || midi < lowest {
+			lowest, lowestString = midi, s
+		}
+		if fret > 0 {
+			if fretted == 0 || fret < minFret {
+				minFret = fret
+			}
+			if fret > maxFret {
+				maxFret = fret
+			}
+			fretted++
+		}
+	}
+	if lowestString < 0 || (!inst.IgnoreBass && pitchClass(lowest) != int(bass.Cardinal())) {
+		return Fingering{}, false
+	}
+	count := 0
+	for pc := range byClass {
+		if played[pc] && pc != fifth {
+			count++
+		}
+	}
+	if count < required {
+		return Fingering{}, false
+	}
+	if fretted > 0 && maxFret-minFret > maxFretSpan {
+		return Fingering{}, false
+	}
+
+	if fretted > 4 {
+		// try a barre across the strings at the lowest fret
+		first, last := -1, -1
+		for s, fret := range frets {
+			if fret == minFret {
+				if first < 0 {
+					first = s
+				}
+				last = s
+			}
+		}
+		covered := 0
+		for s := first; s <= last; s++ {
+			if frets[s] < minFret {
+				// an open or muted string can't be under the barre
+				return Fingering{}, false
+			}
+			if frets[s] == minFret {
+				covered++
+			}
+		}
+		if covered < 2 {
+			return Fingering{}, false
+		}
+		f.Barre = &Barre{Fret: minFret, First: first, Last: last}
+		if fretted-covered+1 > 4 {
+			return Fingering{}, false
+		}
+	}
+
+	if mutedStrings(frets) > maxMutedStrings {
+		// too many muted strings makes for a thin chord
+		return Fingering{}, false
+	}
+	f.Difficulty = Score(f).Difficulty()
+	if fifth >= 0 && !played[fifth] {
+		f.Difficulty++
+	}
+	return f, true
+}
+
+// firstFret returns the fret at which the given string starts. (See
+// Instrument.FirstFrets.)
+func (inst Instrument) firstFret(s int) int {
+	if s < len(inst.FirstFrets) {
+		return inst.FirstFrets[s]
+	}
+	return 0
+}
+
+// midiNumber returns the MIDI note number of the given string when pressed at
+// the given fret.
+func (inst Instrument) midiNumber(s, fret int) int {
+	midi := inst.Strings[s].MIDINumber()
+	if fret > 0 {
+		midi += fret - inst.firstFret(s)
+	}
+	return midi
+}
+
+// mutedStrings returns the number of strings that are not played.
+func mutedStrings(frets []int) int {
+	n := 0
+	for _, fret := range frets {
+		if fret == Muted {
+			n++
+		}
+	}
+	return n
+}
+
+// lowestFret returns the lowest fret that is pressed, or zero if all strings
+// are open or muted.
+func lowestFret(frets []int) int {
+	low := 0
+	for _, fret := range frets {
+		if fret > 0 && (low == 0 || fret < low) {
+			low = fret
+		}
+	}
+	return low
+}
+
+// pitchClass returns the pitch class of the given MIDI note number. Like
+// chords.Note.Cardinal, it is the number of half-steps above A, from 0 to 11.
+func pitchClass(midi int) int {
+	return (((midi - 9) % 12) + 12) % 12
+}
+
+// pitchWithMIDINumber returns the pitch of the given note with the given MIDI
+// note number. The note's pitch class must match the number.
+func pitchWithMIDINumber(n chords.Note, midi int) chords.Pitch {
+	p := chords.Pitch{Note: n, Octave: int8(midi/12 - 1)}
+	for p.MIDINumber() > midi {
+		p.Octave--
+	}
+	for p.MIDINumber() < midi {
+		p.Octave++
+	}
+	return p
+}
@@ -0,0 +1,112 @@
+package fretboard
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/jhump/chords"
+)
+
+func TestInstrument_Fingerings(t *testing.T) {
+	cases := []struct {
+		chord string
+		exp   string
+	}{
+		{"C", "x32010"},
+		{"G", "320003"},
+		{"D", "xx0232"},
+		{"A-", "x02210"},
+		{"E", "022100"},
+		{"F", "10321x"},
+		{"G7", "320001"},
+		{"D-7", "xx0211"},
+		{"C△7", "x32000"},
+		{"C/G", "332010"},
+		// the bass is also one of the chord's tones, but not the 5th
+		{"C/E", "032010"},
+		{"G/B", "x2000x"},
+		{"A-/C", "x32210"},
+	}
+	for _, tc := range cases {
+		fs := Guitar.Fingerings(chords.MustParseChord(tc.chord))
+		if len(fs) == 0 {
+			t.Errorf("%s: no fingerings", tc.chord)
+			continue
+		}
+		if actual := fs[0].String(); actual != tc.exp {
+			t.Errorf("Instrument.Fingerings for %s returned wrong value: %q != %q", tc.chord, actual, tc.exp)
+		}
+		for i := 1; i < len(fs); i++ {
+			if fs[i].Difficulty < fs[i-1].Difficulty {
+				t.Errorf("%s: fingerings not sorted: %v (%d) before %v (%d)",
+					tc.chord, fs[i-1], fs[i-1].Difficulty, fs[i], fs[i].Difficulty)
+			}
+		}
+	}
+
+	var barre *Fingering
+	for _, f := range Guitar.Fingerings(chords.MustParseChord("F")) {
+		if f.String() == "133211" {
+			barre = &f
+			break
+		}
+	}
+	if barre == nil {
+		t.Fatalf("missing barre chord for F")
+	}
+	if barre.Barre == nil || *barre.Barre != (Barre{Fret: 1, First: 0, Last: 5}) {
+		t.Errorf("wrong barre: %+v", barre.Barre)
+	}
+	if actual := fmt.Sprint(barre.Pitches); actual != "[F2 C3 F3 A3 C4 F4]" {
+		t.Errorf("wrong pitches: %s", actual)
+	}
+
+	f := Fingering{Frets: []int{Muted, 10, 12, 12, 12, 10}}
+	if f.String() != "x-10-12-12-12-10" {
+		t.Errorf("wrong string: %s", f)
+	}
+
+	threeStrings := Instrument{Strings: Guitar.Strings[:3], Frets: 12}
+	if fs := threeStrings.Fingerings(chords.MustParseChord("C7♭9")); fs != nil {
+		t.Errorf("Instrument.Fingerings for too few strings returned wrong value: %v", fs)
+	}
+}
+
+func TestInstrument_FingeringsRanking(t *testing.T) {
+	// when fingerings are equally hard, the fuller chord comes first, and a
+	// shape is harder to play up the neck than near the nut
+	cases := []struct {
+		chord string
+		exp   string
+	}{
+		{"G", "320003"},
+		{"C-", "x31013"},
+		{"Csus4", "x33011"},
+		{"Co", "x3424x"},
+		{"C♯o", "x4535x"},
+	}
+	for _, tc := range cases {
+		fs := Guitar.Fingerings(chords.MustParseChord(tc.chord))
+		if len(fs) == 0 {
+			t.Errorf("Instrument.Fingerings for %s returned no fingerings", tc.chord)
+			continue
+		}
+		if actual := fs[0].String(); actual != tc.exp {
+			t.Errorf("Instrument.Fingerings for %s returned wrong value: %q != %q", tc.chord, actual, tc.exp)
+		}
+	}
+
+	difficulty := func(chord, frets string) int {
+		t.Helper()
+		for _, f := range Guitar.Fingerings(chords.MustParseChord(chord)) {
+			if f.String() == frets {
+				return f.Difficulty
+			}
+		}
+		t.Fatalf("missing fingering %s for %s", frets, chord)
+		return 0
+	}
+	if low, high := difficulty("F-7", "131111"), difficulty("C-7", "8-10-8-8-8-8"); high <= low {
+		t.Errorf("Instrument.Fingerings returned wrong difficulty for C-7 at the 8th fret: %d <= %d", high, low)
+	}
+}
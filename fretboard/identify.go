@@ -0,0 +1,88 @@
+package fretboard
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/jhump/chords"
+)
+
+// ParseFrets parses the frets of a fingering, in the same format as
+// Fingering.String: a fret number for each string, or an "x" for a muted
+// string, like "x32010". If any fret is greater than 9, the frets must be
+// separated by hyphens, like "x-10-12-12-12-10".
+func ParseFrets(s string) ([]int, error) {
+	var fields []string
+	if strings.Contains(s, "-") {
+		fields = strings.Split(s, "-")
+	} else {
+		for _, r := range s {
+			fields = append(fields, string(r))
+		}
+	}
+	if len(fields) == 0 {
+		return nil, fmt.Errorf("fingering %q has no strings", s)
+	}
+	frets := make([]int, len(fields))
+	for i, field := range fields {
+		if field == "x" || field == "X" {
+			frets[i] = Muted
+			continue
+		}
+		fret, err := strconv.Atoi(field)
+		if err != nil || fret < 0 {
+			return nil, fmt.Errorf("invalid fret %q in fingering %q", field, s)
+		}
+		frets[i] = fret
+	}
+	return frets, nil
+}
+
+// inferenceNotes are the notes used to spell each pitch class, indexed by the
+// number of half-steps above A, when inferring chords from frets. They are
+// the spellings used in the most common keys. Chord roots are respelled as
+// needed. (See chords.InferChordOverBass.)
+var inferenceNotes = [12]chords.Note{
+	{N: chords.A}, {N: chords.B, Acc: chords.Flat}, {N: chords.B}, {N: chords.C},
+	{N: chords.C, Acc: chords.Sharp}, {N: chords.D}, {N: chords.E, Acc: chords.Flat}, {N: chords.E},
+	{N: chords.F}, {N: chords.F, Acc: chords.Sharp}, {N: chords.G}, {N: chords.A, Acc: chords.Flat},
+}
+
+// InferChords returns the chords that are played by the given frets, ranked
+// from the best description to the worst. There is a fret for each of the
+// instrument's strings, in the same order, and Muted strings are not played.
+// (See ParseFrets.) The lowest pitch played is the bass, so the results
+// include inversions and slash chords, like C/G for "332010". But if the
+// instrument ignores the bass, the results are never slash chords. (See
+// chords.InferChordOverBass for how the chords are ranked.)
+//
+// This returns an error if the number of frets does not match the number of
+// strings or if a fret cannot be played. If the frets are valid but they
+// don't play a chord, the result is nil.
+func (inst Instrument) InferChords(frets []int) ([]*chords.Chord, error) {
+	if len(frets) != len(inst.Strings) {
+		return nil, fmt.Errorf("fingering has %d frets but instrument has %d strings", len(frets), len(inst.Strings))
+	}
+	var notes []chords.Note
+	var bass chords.Note
+	lowest := 0
+	for s, fret := range frets {
+		if fret == Muted {
+			continue
+		}
+		if fret < 0 || fret > inst.Frets || (fret > 0 && fret <= inst.firstFret(s)) {
+			return nil, fmt.Errorf("fret %d cannot be played on string %d", fret, s+1)
+		}
+		midi := inst.midiNumber(s, fret)
+		n := inferenceNotes[pitchClass(midi)]
+		notes = append(notes, n)
+		if bass.N == 0 || midi < lowest {
+			bass, lowest = n, midi
+		}
+	}
+	if inst.IgnoreBass {
+		bass = chords.Note{}
+	}
+	return chords.InferChordOverBass(bass, notes...), nil
+}
@@ -0,0 +1,94 @@
+package fretboard
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestParseFrets(t *testing.T) {
+	cases := []struct {
+		s   string
+		exp []int
+	}{
+		{"x32010", []int{Muted, 3, 2, 0, 1, 0}},
+		{"X32010", []int{Muted, 3, 2, 0, 1, 0}},
+		{"x-10-12-12-12-10", []int{Muted, 10, 12, 12, 12, 10}},
+		{"0003", []int{0, 0, 0, 3}},
+	}
+	for _, tc := range cases {
+		frets, err := ParseFrets(tc.s)
+		if err != nil {
+			t.Errorf("ParseFrets for %s failed: %v", tc.s, err)
+			continue
+		}
+		if fmt.Sprint(frets) != fmt.Sprint(tc.exp) {
+			t.Errorf("ParseFrets for %s returned wrong value: %v != %v", tc.s, frets, tc.exp)
+		}
+		// round-trips through Fingering.String
+		if s := (Fingering{Frets: frets}).String(); s != tc.s && tc.s[0] != 'X' {
+			t.Errorf("%s: round-trip gave %q", tc.s, s)
+		}
+	}
+
+	for _, s := range []string{"", "x3201a", "x-3--2"} {
+		if _, err := ParseFrets(s); err == nil {
+			t.Errorf("ParseFrets for %q should have failed", s)
+		}
+	}
+}
+
+func TestInstrument_InferChords(t *testing.T) {
+	cases := []struct {
+		inst  Instrument
+		frets string
+		exp   string
+	}{
+		{Guitar, "x32010", "C"},
+		{Guitar, "320003", "G"},
+		{Guitar, "x02210", "A-"},
+		{Guitar, "133211", "F"},
+		{Guitar, "244322", "F♯"},
+		{Guitar, "x4666x", "D♭"},
+		{Guitar, "320001", "G7"},
+		{Guitar, "xx0211", "D-7"},
+		{Guitar, "x32000", "C△7"},
+		{Guitar, "x-10-12-12-12-10", "G"},
+		// the bass makes for an inversion
+		{Guitar, "332010", "C/G"},
+		{Guitar, "x03211", "F/A"},
+		// the ukulele ignores the bass
+		{Ukulele, "0003", "C"},
+		{Ukulele, "2010", "F"},
+	}
+	for _, tc := range cases {
+		frets, err := ParseFrets(tc.frets)
+		if err != nil {
+			t.Fatalf("%s: failed to parse: %v", tc.frets, err)
+		}
+		chs, err := tc.inst.InferChords(frets)
+		if err != nil {
+			t.Errorf("Instrument.InferChords for %s failed: %v", tc.frets, err)
+			continue
+		}
+		if len(chs) == 0 {
+			t.Errorf("%s: no chords", tc.frets)
+			continue
+		}
+		if actual := chs[0].String(); actual != tc.exp {
+			t.Errorf("Instrument.InferChords for %s returned wrong value: %s != %s (%v)", tc.frets, actual, tc.exp, chs)
+		}
+	}
+
+	// only two distinct notes isn't a chord
+	if chs, err := Guitar.InferChords([]int{Muted, Muted, 0, 2, Muted, Muted}); err != nil || chs != nil {
+		t.Errorf("Instrument.InferChords for two notes returned wrong value: %v, %v", chs, err)
+	}
+	for _, frets := range [][]int{{Muted, 3, 2, 0, 1}, {Muted, 3, 2, 0, 1, 30}, {Muted, 3, 2, 0, 1, -2}} {
+		if _, err := Guitar.InferChords(frets); err == nil {
+			t.Errorf("Instrument.InferChords for %v should have failed", frets)
+		}
+	}
+	if _, err := Banjo.InferChords([]int{3, 0, 0, 0, 0}); err == nil {
+		t.Errorf("Instrument.InferChords for fret below start of banjo's short string should have failed")
+	}
+}
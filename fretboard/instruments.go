@@ -0,0 +1,66 @@
+package fretboard
+
+var (
+	// Guitar is a six-string guitar in standard tuning (E A D G B E), with 22
+	// frets.
+	Guitar = Instrument{Strings: MustParseTuning("E2 A2 D3 G3 B3 E4"), Frets: 22}
+	// Ukulele is a soprano, concert, or tenor ukulele in standard tuning
+	// (G C E A), with 15 frets. Its G string is re-entrant: it is tuned above
+	// the C string, not below it.
+	Ukulele = Instrument{Strings: MustParseTuning("G4 C4 E4 A4"), Frets: 15, IgnoreBass: true}
+	// BaritoneUkulele is a baritone ukulele, tuned like the four highest
+	// strings of a guitar (D G B E), with 19 frets.
+	BaritoneUkulele = Instrument{Strings: MustParseTuning("D3 G3 B3 E4"), Frets: 19}
+	// Mandolin is a mandolin, tuned in fifths like a violin (G D A E), with 20
+	// frets. Each of its four courses of paired strings is modeled as one
+	// string.
+	Mandolin = Instrument{Strings: MustParseTuning("G3 D4 A4 E5"), Frets: 20, IgnoreBass: true}
+	// Banjo is a five-string banjo in open G tuning (g D G B D), with 22
+	// frets. Its short fifth string, which is first, starts at the fifth fret
+	// and is tuned to the G above the other strings' G.
+	Banjo = Instrument{
+		Strings:    MustParseTuning("G4 D3 G3 B3 D4"),
+		Frets:      22,
+		FirstFrets: []int{5, 0, 0, 0, 0},
+		IgnoreBass: true,
+	}
+)
+
+// instruments are the built-in instrument presets. The first name for each is
+// its canonical name; the rest are aliases.
+var instruments = []struct {
+	names []string
+	inst  *Instrument
+}{
+	{[]string{"guitar"}, &Guitar},
+	{[]string{"ukulele", "uke"}, &Ukulele},
+	{[]string{"baritone ukulele", "baritone uke"}, &BaritoneUkulele},
+	{[]string{"mandolin"}, &Mandolin},
+	{[]string{"banjo", "5-string banjo"}, &Banjo},
+}
+
+// Instruments returns the canonical names of the built-in instrument presets,
+// which can be passed to LookupInstrument.
+func Instruments() []string {
+	names := make([]string, len(instruments))
+	for i, entry := range instruments {
+		names[i] = entry.names[0]
+	}
+	return names
+}
+
+// LookupInstrument returns the built-in instrument with the given name, like
+// "guitar", "ukulele", "mandolin", or "banjo". The second value is false if
+// there is no such instrument. Names are matched the same way as in
+// LookupTuning.
+func LookupInstrument(name string) (Instrument, bool) {
+	name = normalizeName(name)
+	for _, entry := range instruments {
+		for _, n := range entry.names {
+			if normalizeName(n) == name {
+				return *entry.inst, true
+			}
+		}
+	}
+	return Instrument{}, false
+}
@@ -0,0 +1,56 @@
+package fretboard
+
+import (
+	"testing"
+
+	"github.com/jhump/chords"
+)
+
+func TestInstruments(t *testing.T) {
+	cases := []struct {
+		inst  string
+		chord string
+		exp   string
+	}{
+		{"ukulele", "C", "0003"},
+		{"Uke", "G", "0232"},
+		{"ukulele", "A-", "2000"},
+		{"ukulele", "G7", "0212"},
+		{"baritone ukulele", "D", "0232"},
+		{"mandolin", "D", "2002"},
+		{"mandolin", "C", "0230"},
+		{"banjo", "G", "00000"},
+		{"banjo", "C", "02012"},
+		{"banjo", "D", "77777"},
+	}
+	for _, tc := range cases {
+		inst, ok := LookupInstrument(tc.inst)
+		if !ok {
+			t.Errorf("failed to look up %q", tc.inst)
+			continue
+		}
+		fs := inst.Fingerings(chords.MustParseChord(tc.chord))
+		if len(fs) == 0 {
+			t.Errorf("%s on %s: no fingerings", tc.chord, tc.inst)
+			continue
+		}
+		if actual := fs[0].String(); actual != tc.exp {
+			t.Errorf("Instrument.Fingerings for %s on %s returned wrong value: %q != %q", tc.chord, tc.inst, actual, tc.exp)
+		}
+	}
+
+	for _, name := range Instruments() {
+		if _, ok := LookupInstrument(name); !ok {
+			t.Errorf("failed to look up %q", name)
+		}
+	}
+	if _, ok := LookupInstrument("theorbo"); ok {
+		t.Errorf("LookupInstrument(%q) should have failed", "theorbo")
+	}
+
+	// the banjo's short string starts at the fifth fret
+	fs := Banjo.Fingerings(chords.MustParseChord("D"))
+	if p := fs[0].Pitches[0].String(); p != "A4" {
+		t.Errorf("wrong pitch for fifth string: %s", p)
+	}
+}
@@ -0,0 +1,184 @@
+package fretboard
+
+import (
+	"github.com/jhump/chords"
+)
+
+// Playability describes what makes a fingering hard to play. (See Score.)
+type Playability struct {
+	// Fingers is the number of fingers needed. A barre counts as one finger.
+	Fingers int
+	// Stretch is the number of frets between the lowest and highest frets
+	// that are pressed. It is zero if no more than one fret is pressed.
+	Stretch int
+	// Position is the lowest fret that is pressed, or zero if every string
+	// is open or muted.
+	Position int
+	// Barre is true if the fingering needs a barre.
+	Barre bool
+	// MutedStrings is the number of strings that are not played.
+	MutedStrings int
+	// InnerMutedStrings is the number of muted strings that are between
+	// strings that are played. These are harder to mute than the strings at
+	// the edges, which the strumming hand can skip.
+	InnerMutedStrings int
+	// OpenStringsUpTheNeck is true if the fingering plays open strings while
+	// the hand presses frets above the fourth, far from the nut.
+	OpenStringsUpTheNeck bool
+}
+
+// Score returns the playability of the given fingering.
+func Score(f Fingering) Playability {
+	var p Playability
+	maxFret, fretted, open := 0, 0, false
+	firstPlayed, lastPlayed := -1, -1
+	for s, fret := range f.Frets {
+		if fret == Muted {
+			p.MutedStrings++
+			continue
+		}
+		if firstPlayed < 0 {
+			firstPlayed = s
+		}
+		lastPlayed = s
+		if fret == 0 {
+			open = true
+			continue
+		}
+		if fretted == 0 || fret < p.Position {
+			p.Position = fret
+		}
+		if fret > maxFret {
+			maxFret = fret
+		}
+		fretted++
+	}
+	for s := firstPlayed + 1; s < lastPlayed; s++ {
+		if f.Frets[s] == Muted {
+			p.InnerMutedStrings++
+		}
+	}
+	p.Fingers = fretted
+	if f.Barre != nil {
+		p.Barre = true
+		for s := f.Barre.First; s <= f.Barre.Last && s < len(f.Frets); s++ {
+			if f.Frets[s] == f.Barre.Fret {
+				p.Fingers--
+			}
+		}
+		p.Fingers++
+	}
+	if fretted > 0 {
+		p.Stretch = maxFret - p.Position
+	}
+	p.OpenStringsUpTheNeck = open && maxFret > 4
+	return p
+}
+
+// Difficulty returns a score for how hard a fingering with this playability
+// is to play. Higher values are harder. Each finger and each muted string
+// adds one, and each fret of stretch adds one more. Muted inner strings add
+// three more, barres add two, and open strings up the neck add three. Playing
+// far up the neck adds one for every seven frets above the nut.
+func (p Playability) Difficulty() int {
+	d := p.Fingers + p.MutedStrings + 3*p.InnerMutedStrings + p.Stretch + p.Position/7
+	if p.Barre {
+		d += 2
+	}
+	if p.OpenStringsUpTheNeck {
+		d += 3
+	}
+	return d
+}
+
+// Shift returns the number of frets that the hand moves to change from one
+// fingering to the next. It is the distance between their positions (see
+// Playability.Position). A fingering that presses no frets can be played with
+// the hand anywhere, so changing to or from one doesn't move the hand.
+func Shift(from, to Fingering) int {
+	a, b := Score(from).Position, Score(to).Position
+	if a == 0 || b == 0 {
+		return 0
+	}
+	if a > b {
+		return a - b
+	}
+	return b - a
+}
+
+// maxCandidates is the number of each chord's easiest fingerings considered
+// by SmoothestFingerings.
+const maxCandidates = 12
+
+// SmoothestFingerings returns a fingering for each chord of the given
+// progression, chosen so that the progression is as easy to play as possible.
+// The cost of the progression is the sum of the difficulties of its
+// fingerings (see Fingering.Difficulty) plus the shifts between consecutive
+// fingerings (see Shift). So an easy shape may be passed over for a slightly
+// harder one that is closer to its neighbors. For beginners, who are better
+// served by the easiest shape for each chord regardless of its neighbors, use
+// the first result of Fingerings instead.
+//
+// This returns nil if any chord of the progression cannot be played on the
+// instrument.
+func (inst Instrument) SmoothestFingerings(prog *chords.Progression) []Fingering {
+	chs := prog.Chords()
+	if len(chs) == 0 {
+		return nil
+	}
+	cache := map[string][]Fingering{}
+	candidates := make([][]Fingering, len(chs))
+	for i, ch := range chs {
+		key := ch.String()
+		fs, ok := cache[key]
+		if !ok {
+			fs = inst.Fingerings(ch)
+			if len(fs) > maxCandidates {
+				fs = fs[:maxCandidates]
+			}
+			cache[key] = fs
+		}
+		if len(fs) == 0 {
+			return nil
+		}
+		candidates[i] = fs
+	}
+
+	// costs[i][j] is the least cost of playing chords 0 through i, ending
+	// with candidates[i][j]; prev[i][j] is the candidate for chord i-1 in
+	// that case
+	costs := make([][]int, len(chs))
+	prev := make([][]int, len(chs))
+	for i, fs := range candidates {
+		costs[i] = make([]int, len(fs))
+		prev[i] = make([]int, len(fs))
+		for j, f := range fs {
+			costs[i][j] = f.Difficulty
+			if i == 0 {
+				continue
+			}
+			best, bestCost := -1, 0
+			for k, from := range candidates[i-1] {
+				if c := costs[i-1][k] + Shift(from, f); best < 0 || c < bestCost {
+					best, bestCost = k, c
+				}
+			}
+			prev[i][j] = best
+			costs[i][j] += bestCost
+		}
+	}
+
+	last := len(chs) - 1
+	j := 0
+	for k := range costs[last] {
+		if costs[last][k] < costs[last][j] {
+			j = k
+		}
+	}
+	fingerings := make([]Fingering, len(chs))
+	for i := last; i >= 0; i-- {
+		fingerings[i] = candidates[i][j]
+		j = prev[i][j]
+	}
+	return fingerings
+}
@@ -0,0 +1,91 @@
+package fretboard
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/jhump/chords"
+)
+
+func TestScore(t *testing.T) {
+	cases := []struct {
+		f   Fingering
+		exp Playability
+	}{
+		{
+			f:   Fingering{Frets: []int{Muted, 3, 2, 0, 1, 0}},
+			exp: Playability{Fingers: 3, Stretch: 2, Position: 1, MutedStrings: 1},
+		},
+		{
+			f:   Fingering{Frets: []int{1, 3, 3, 2, 1, 1}, Barre: &Barre{Fret: 1, First: 0, Last: 5}},
+			exp: Playability{Fingers: 4, Stretch: 2, Position: 1, Barre: true},
+		},
+		{
+			f:   Fingering{Frets: []int{Muted, 3, Muted, 0, 1, 0}},
+			exp: Playability{Fingers: 2, Stretch: 2, Position: 1, MutedStrings: 2, InnerMutedStrings: 1},
+		},
+		{
+			f:   Fingering{Frets: []int{0, 7, 6, 0, 0, 0}},
+			exp: Playability{Fingers: 2, Stretch: 1, Position: 6, OpenStringsUpTheNeck: true},
+		},
+		{
+			f:   Fingering{Frets: []int{0, 0, 0, 0, 0, 0}},
+			exp: Playability{},
+		},
+	}
+	for _, tc := range cases {
+		if actual := Score(tc.f); actual != tc.exp {
+			t.Errorf("Score for %v returned wrong value: %+v != %+v", tc.f, actual, tc.exp)
+		}
+	}
+
+	// the difficulty of a fingering is its playability's difficulty, unless
+	// it omits the 5th
+	for _, ch := range []string{"C", "F", "B♭", "E-7", "D7"} {
+		for _, f := range Guitar.Fingerings(chords.MustParseChord(ch)) {
+			d := Score(f).Difficulty()
+			if f.Difficulty != d && f.Difficulty != d+1 {
+				t.Errorf("%s: %v has difficulty %d, but its score is %d", ch, f, f.Difficulty, d)
+			}
+		}
+	}
+}
+
+func TestShift(t *testing.T) {
+	cases := []struct {
+		from, to Fingering
+		exp      int
+	}{
+		{Fingering{Frets: []int{Muted, 3, 2, 0, 1, 0}}, Fingering{Frets: []int{Muted, 0, 2, 2, 1, 0}}, 0},
+		{Fingering{Frets: []int{1, 3, 3, 2, 1, 1}}, Fingering{Frets: []int{Muted, 8, 10, 10, 10, 8}}, 7},
+		{Fingering{Frets: []int{Muted, 8, 10, 10, 10, 8}}, Fingering{Frets: []int{3, 5, 5, 4, 3, 3}}, 5},
+		{Fingering{Frets: []int{0, 0, 0, 0, 0, 0}}, Fingering{Frets: []int{Muted, 8, 10, 10, 10, 8}}, 0},
+	}
+	for _, tc := range cases {
+		if actual := Shift(tc.from, tc.to); actual != tc.exp {
+			t.Errorf("Shift for %v -> %v returned wrong value: %d != %d", tc.from, tc.to, actual, tc.exp)
+		}
+	}
+}
+
+func TestInstrument_SmoothestFingerings(t *testing.T) {
+	cases := []struct {
+		prog string
+		exp  string
+	}{
+		{"C A- F G", "[x32010 x02210 10321x 320003]"},
+		{"E-7 A7 D△7", "[020000 x02020 xx0222]"},
+		// the easiest B is x21402, but x2444x is nearer to F♯
+		{"B E F♯ B", "[x21402 022100 244322 x2444x]"},
+	}
+	for _, tc := range cases {
+		fs := Guitar.SmoothestFingerings(chords.MustParseProgression(tc.prog))
+		if actual := fmt.Sprint(fs); actual != tc.exp {
+			t.Errorf("Instrument.SmoothestFingerings for %s returned wrong value: %s != %s", tc.prog, actual, tc.exp)
+		}
+	}
+
+	if fs := Ukulele.SmoothestFingerings(chords.MustParseProgression("C C13♯11")); fs != nil {
+		t.Errorf("Instrument.SmoothestFingerings for unplayable chord returned wrong value: %v", fs)
+	}
+}
@@ -0,0 +1,201 @@
+package fretboard
+
+import (
+	"bytes"
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/jhump/chords"
+)
+
+// Tab is tablature for an instrument: a line for each string, with the frets
+// that are played written on the lines from left to right. For example, an
+// open C chord followed by a C arpeggio on a guitar is:
+//
+//	e|-0---------|
+//	B|-1-------1-|
+//	G|-0-----0---|
+//	D|-2---2-----|
+//	A|-3-3-------|
+//	E|-----------|
+//
+// Chords are added with AddFingering, single notes with AddPitches, and bar
+// lines with AddBar. The zero value is an empty tab for Guitar.
+type Tab struct {
+	// Instrument is the instrument that is played. If it has no strings,
+	// Guitar is used.
+	Instrument Instrument
+	// columns are the frets played at each step, one per string. A nil
+	// column is a bar line.
+	columns [][]int
+}
+
+// instrument returns the instrument for the tab.
+func (t *Tab) instrument() Instrument {
+	if len(t.Instrument.Strings) == 0 {
+		return Guitar
+	}
+	return t.Instrument
+}
+
+// AddFingering adds a chord to the tab, with all of the strings of the given
+// fingering played at once. The fingering should be for the tab's instrument.
+// Muted strings are left blank.
+func (t *Tab) AddFingering(f Fingering) {
+	col := make([]int, len(t.instrument().Strings))
+	for s := range col {
+		col[s] = Muted
+		if s < len(f.Frets) {
+			col[s] = f.Frets[s]
+		}
+	}
+	t.columns = append(t.columns, col)
+}
+
+// AddPitches adds the given pitches to the tab, one after another, like the
+// pitches of an arpeggio (see chords.Arpeggiate). Each pitch is played on the
+// string that keeps the hand in the same position, where the frets played
+// span at most four frets, preferring open strings and lower frets. When the
+// next pitch can't be played in the current position, the hand moves as
+// little as possible.
+//
+// This returns an error, and adds nothing to the tab, if any of the pitches
+// cannot be played on the instrument.
+func (t *Tab) AddPitches(pitches ...chords.Pitch) error {
+	inst := t.instrument()
+	cols := make([][]int, 0, len(pitches))
+	// low and high are the lowest and highest frets played in the current
+	// position, or zero if only open strings have been played
+	low, high := 0, 0
+	for _, p := range pitches {
+		best, bestFret, bestCost := -1, 0, 0
+		for s := range inst.Strings {
+			fret, ok := inst.fretFor(s, p.MIDINumber())
+			if !ok {
+				continue
+			}
+			// cost is how far the hand must move to play the fret
+			cost := 0
+			if fret > 0 && low > 0 {
+				lo, hi := low, high
+				if fret < lo {
+					lo = fret
+				}
+				if fret > hi {
+					hi = fret
+				}
+				if hi-lo > maxFretSpan {
+					cost = hi - lo - maxFretSpan
+				}
+			}
+			if best < 0 || cost < bestCost || (cost == bestCost && fret < bestFret) {
+				best, bestFret, bestCost = s, fret, cost
+			}
+		}
+		if best < 0 {
+			return fmt.Errorf("pitch %v cannot be played on the instrument", p)
+		}
+		switch {
+		case bestFret == 0:
+		case bestCost > 0 || low == 0:
+			low, high = bestFret, bestFret
+		case bestFret < low:
+			low = bestFret
+		case bestFret > high:
+			high = bestFret
+		}
+		col := make([]int, len(inst.Strings))
+		for s := range col {
+			col[s] = Muted
+		}
+		col[best] = bestFret
+		cols = append(cols, col)
+	}
+	t.columns = append(t.columns, cols...)
+	return nil
+}
+
+// AddBar adds a bar line to the tab.
+func (t *Tab) AddBar() {
+	t.columns = append(t.columns, nil)
+}
+
+// String implements the Stringer interface. The result has a line for each
+// string, from the highest string on top to the lowest on the bottom, as is
+// usual for tablature. So the first line is for the last of the instrument's
+// strings. Each line starts with the name of the string's note. If a note
+// names more than one string, the names of all but the lowest are in lower
+// case (like the "e" for the high E string of a guitar).
+func (t *Tab) String() string {
+	inst := t.instrument()
+	names := make([]string, len(inst.Strings))
+	width := 0
+	for s, p := range inst.Strings {
+		names[s] = p.Note.String()
+		for _, other := range inst.Strings {
+			if other.Note == p.Note && other.MIDINumber() < p.MIDINumber() {
+				names[s] = strings.ToLower(names[s])
+				break
+			}
+		}
+		if w := utf8.RuneCountInString(names[s]); w > width {
+			width = w
+		}
+	}
+
+	lines := make([]bytes.Buffer, len(inst.Strings))
+	for s := range lines {
+		lines[s].WriteString(names[s])
+		lines[s].WriteString(strings.Repeat(" ", width-utf8.RuneCountInString(names[s])))
+		lines[s].WriteString("|-")
+	}
+	for i, col := range t.columns {
+		if col == nil {
+			// a bar line at the end is the closing one, written below
+			if i < len(t.columns)-1 {
+				for s := range lines {
+					lines[s].WriteString("|-")
+				}
+			}
+			continue
+		}
+		// all of the frets in a column are written with the same width
+		colWidth := 1
+		for _, fret := range col {
+			if fret >= 10 {
+				colWidth = 2
+			}
+		}
+		for s, fret := range col {
+			str := ""
+			if fret != Muted {
+				str = strconv.Itoa(fret)
+			}
+			lines[s].WriteString(str)
+			lines[s].WriteString(strings.Repeat("-", colWidth-len(str)+1))
+		}
+	}
+
+	var b bytes.Buffer
+	for s := len(lines) - 1; s >= 0; s-- {
+		b.Write(lines[s].Bytes())
+		b.WriteString("|\n")
+	}
+	return b.String()
+}
+
+// fretFor returns the fret at which the given string plays the given MIDI note
+// number. It returns false if the string can't play it.
+func (inst Instrument) fretFor(s, midi int) (int, bool) {
+	open := inst.Strings[s].MIDINumber()
+	if midi == open {
+		return 0, true
+	}
+	fret := midi - open + inst.firstFret(s)
+	if midi < open || fret <= inst.firstFret(s) || fret > inst.Frets {
+		return 0, false
+	}
+	return fret, true
+}
@@ -0,0 +1,63 @@
+package fretboard
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/jhump/chords"
+)
+
+func TestTab(t *testing.T) {
+	var tab Tab
+	c := chords.MustParseChord("C")
+	tab.AddFingering(Guitar.Fingerings(c)[0])
+	err := tab.AddPitches(chords.MustParsePitch("C3"), chords.MustParsePitch("E3"), chords.MustParsePitch("G3"), chords.MustParsePitch("C4"))
+	if err != nil {
+		t.Fatalf("failed to add pitches: %v", err)
+	}
+	tab.AddBar()
+	if err := tab.AddPitches(chords.MustParsePitch("A2"), chords.MustParsePitch("C3"), chords.MustParsePitch("E3"), chords.MustParsePitch("A3")); err != nil {
+		t.Fatalf("failed to add pitches: %v", err)
+	}
+	tab.AddBar()
+	tab.AddFingering(Fingering{Frets: []int{Muted, 12, 14, 14, 13, 12}})
+	tab.AddBar()
+	expected := strings.TrimPrefix(`
+e|-0---------|---------|-12-|
+B|-1-------1-|---------|-13-|
+G|-0-----0---|-------2-|-14-|
+D|-2---2-----|-----2---|-14-|
+A|-3-3-------|-0-3-----|-12-|
+E|-----------|---------|----|
+`, "\n")
+	if actual := tab.String(); actual != expected {
+		t.Errorf("Tab.String returned wrong value:\n%s", actual)
+	}
+
+	// pitches that can't be played are not added
+	before := tab.String()
+	if err := tab.AddPitches(chords.MustParsePitch("E4"), chords.MustParsePitch("C1")); err == nil {
+		t.Errorf("Tab.AddPitches for C1 should have failed")
+	}
+	if tab.String() != before {
+		t.Errorf("tab changed after error:\n%s", tab.String())
+	}
+}
+
+func TestTab_Instrument(t *testing.T) {
+	tab := Tab{Instrument: Banjo}
+	if err := tab.AddPitches(chords.MustParsePitch("G4"), chords.MustParsePitch("A4"), chords.MustParsePitch("D3")); err != nil {
+		t.Fatalf("failed to add pitches: %v", err)
+	}
+	actual := tab.String()
+	expected := strings.TrimPrefix(`
+d|-------|
+B|-------|
+G|-------|
+D|-----0-|
+g|-0-7---|
+`, "\n")
+	if actual != expected {
+		t.Errorf("Tab.String for banjo returned wrong value:\n%s", actual)
+	}
+}
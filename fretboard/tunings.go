@@ -0,0 +1,113 @@
+package fretboard
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/jhump/chords"
+)
+
+// ParseTuning parses the pitches of an instrument's open strings from the
+// given string, like "E2 A2 D3 G3 B3 E4". The strings are separated by spaces
+// or commas and are in the same order as Instrument.Strings. Octaves may be
+// omitted, as in "D A D G A D". In that case, the first string is in octave 2
+// (like the lowest string of a guitar), and every other string is the lowest
+// pitch above the string before it. So re-entrant tunings, where a string is
+// lower than the one before it (like a ukulele's), must include octaves.
+func ParseTuning(s string) ([]chords.Pitch, error) {
+	fields := strings.FieldsFunc(s, func(r rune) bool {
+		return r == ',' || r == ' ' || r == '\t'
+	})
+	if len(fields) == 0 {
+		return nil, fmt.Errorf("tuning %q has no strings", s)
+	}
+	pitches := make([]chords.Pitch, len(fields))
+	for i, field := range fields {
+		if p, err := chords.ParsePitch(field); err == nil {
+			pitches[i] = p
+			continue
+		}
+		n, err := chords.ParseNote(field)
+		if err != nil {
+			return nil, fmt.Errorf("invalid string %q in tuning: %v", field, err)
+		}
+		if i == 0 {
+			pitches[i] = chords.Pitch{Note: n, Octave: 2}
+			continue
+		}
+		p := chords.Pitch{Note: n, Octave: pitches[i-1].Octave - 1}
+		for p.MIDINumber() <= pitches[i-1].MIDINumber() {
+			p.Octave++
+		}
+		pitches[i] = p
+	}
+	return pitches, nil
+}
+
+// MustParseTuning parses the given string into the pitches of an instrument's
+// open strings and panics if the string is not valid. (See ParseTuning.)
+func MustParseTuning(s string) []chords.Pitch {
+	t, err := ParseTuning(s)
+	if err != nil {
+		panic(err)
+	}
+	return t
+}
+
+// tunings are the built-in tuning presets. The first name for each is its
+// canonical name; the rest are aliases.
+var tunings = []struct {
+	names  []string
+	tuning string
+}{
+	{[]string{"standard", "E standard"}, "E2 A2 D3 G3 B3 E4"},
+	{[]string{"drop D"}, "D2 A2 D3 G3 B3 E4"},
+	{[]string{"drop C"}, "C2 G2 C3 F3 A3 D4"},
+	{[]string{"half-step down", "E♭ standard"}, "E♭2 A♭2 D♭3 G♭3 B♭3 E♭4"},
+	{[]string{"whole-step down", "D standard"}, "D2 G2 C3 F3 A3 D4"},
+	{[]string{"DADGAD"}, "D2 A2 D3 G3 A3 D4"},
+	{[]string{"open D"}, "D2 A2 D3 F♯3 A3 D4"},
+	{[]string{"open E"}, "E2 B2 E3 G♯3 B3 E4"},
+	{[]string{"open G"}, "D2 G2 D3 G3 B3 D4"},
+	{[]string{"open A"}, "E2 A2 E3 A3 C♯4 E4"},
+	{[]string{"open C"}, "C2 G2 C3 G3 C4 E4"},
+	{[]string{"7-string", "seven-string"}, "B1 E2 A2 D3 G3 B3 E4"},
+	{[]string{"baritone"}, "B1 E2 A2 D3 F♯3 B3"},
+	{[]string{"bass", "4-string bass"}, "E1 A1 D2 G2"},
+	{[]string{"5-string bass"}, "B0 E1 A1 D2 G2"},
+}
+
+// Tunings returns the canonical names of the built-in tuning presets, which
+// can be passed to LookupTuning.
+func Tunings() []string {
+	names := make([]string, len(tunings))
+	for i, entry := range tunings {
+		names[i] = entry.names[0]
+	}
+	return names
+}
+
+// LookupTuning returns the pitches of the open strings for the built-in tuning
+// with the given name, or nil if there is no such tuning. Presets include
+// standard guitar tuning, drop D, DADGAD, open tunings (like "open G"), and
+// tunings for 7-string and baritone guitars and for basses. Names are not
+// case-sensitive, and words may be separated by spaces, hyphens, or
+// underscores. So "Drop D" and "drop-d" are the same.
+func LookupTuning(name string) []chords.Pitch {
+	name = normalizeName(name)
+	for _, entry := range tunings {
+		for _, n := range entry.names {
+			if normalizeName(n) == name {
+				return MustParseTuning(entry.tuning)
+			}
+		}
+	}
+	return nil
+}
+
+// normalizeName normalizes the given preset name for comparison.
+func normalizeName(name string) string {
+	name = strings.ToLower(name)
+	name = strings.NewReplacer("-", " ", "_", " ").Replace(name)
+	return strings.Join(strings.Fields(name), " ")
+}
@@ -0,0 +1,62 @@
+package fretboard
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/jhump/chords"
+)
+
+func TestParseTuning(t *testing.T) {
+	cases := []struct {
+		tuning string
+		exp    string
+	}{
+		{"E2 A2 D3 G3 B3 E4", "[E2 A2 D3 G3 B3 E4]"},
+		{"D A D G A D", "[D2 A2 D3 G3 A3 D4]"},
+		{"Eb,Ab,Db,Gb,Bb,Eb", "[E♭2 A♭2 D♭3 G♭3 B♭3 E♭4]"},
+		{"B1 E A D G B E", "[B1 E2 A2 D3 G3 B3 E4]"},
+		{"G4 C4 E4 A4", "[G4 C4 E4 A4]"},
+	}
+	for _, tc := range cases {
+		pitches, err := ParseTuning(tc.tuning)
+		if err != nil {
+			t.Errorf("failed to parse %q: %v", tc.tuning, err)
+			continue
+		}
+		if actual := fmt.Sprint(pitches); actual != tc.exp {
+			t.Errorf("ParseTuning for %q returned wrong value: %s != %s", tc.tuning, actual, tc.exp)
+		}
+	}
+
+	for _, bad := range []string{"", " , ", "E A D G B H", "E2 A2 X3"} {
+		if _, err := ParseTuning(bad); err == nil {
+			t.Errorf("ParseTuning for %q should have failed", bad)
+		}
+	}
+}
+
+func TestLookupTuning(t *testing.T) {
+	for _, name := range Tunings() {
+		if LookupTuning(name) == nil {
+			t.Errorf("failed to look up %q", name)
+		}
+	}
+	if actual := fmt.Sprint(LookupTuning("Drop_D")); actual != "[D2 A2 D3 G3 B3 E4]" {
+		t.Errorf("wrong tuning for drop D: %s", actual)
+	}
+	if actual := fmt.Sprint(LookupTuning("e-flat standard")); actual != "[]" {
+		t.Errorf("LookupTuning for e-flat standard returned wrong value: %s", actual)
+	}
+
+	// DADGAD lets a D chord ring out on open strings
+	inst := Instrument{Strings: LookupTuning("dadgad"), Frets: 22}
+	if fs := inst.Fingerings(chords.MustParseChord("D")); len(fs) == 0 || fs[0].String() != "000204" {
+		t.Errorf("wrong fingerings for D in DADGAD")
+	}
+	// a 7-string guitar can put the low B in the bass
+	inst = Instrument{Strings: LookupTuning("7-string"), Frets: 24}
+	if fs := inst.Fingerings(chords.MustParseChord("E-/B")); len(fs) == 0 || fs[0].Pitches[0].String() != "B1" {
+		t.Errorf("wrong fingerings for E-/B on a 7-string")
+	}
+}
@@ -0,0 +1,419 @@
+package chords
+
+import (
+	"fmt"
+	"strings"
+)
+
+// addIntervals returns the interval that results from going up by both of the
+// given intervals, reduced to a single octave. So a major third plus a minor
+// third is a perfect fifth, and a perfect fifth plus a perfect fifth is a
+// major second.
+func addIntervals(a, b Interval) Interval {
+	c := Note{N: C}
+	return c.IntervalTo(c.Transpose(a).Transpose(b))
+}
+
+// SecondaryDominantOf returns the secondary (or applied) dominant of the given
+// chord: the dominant 7th chord whose root is a perfect fifth above the given
+// chord's root. For example, the secondary dominant of ii in C major (D-) is
+// V7/ii, which is A7.
+func SecondaryDominantOf(target *ScaleChord) *ScaleChord {
+	return &ScaleChord{
+		Root:       addIntervals(target.Root, Interval{Val: 5}),
+		InMinorKey: target.InMinorKey,
+		Type: ChordType{
+			Triad:      Maj3,
+			ExtraTones: []ChordTone{{Val: 7}},
+		},
+	}
+}
+
+// AppliedChord is a chord that is relative to one of the other chords in a key
+// instead of to the key's tonic, like V7/ii (aka a secondary or applied
+// chord). For example, V7/ii in C major is A7: the V7 chord of D minor.
+type AppliedChord struct {
+	// Chord is the applied chord, relative to the root of Target. Its
+	// InMinorKey field indicates whether the target is a minor chord.
+	Chord *ScaleChord
+	// Target is the chord to which the applied chord is relative (the chord
+	// that it tonicizes), relative to the key's tonic.
+	Target *ScaleChord
+}
+
+// ParseAppliedChord parses an applied chord in roman numeral notation, like
+// "V7/ii". The chord and the target, separated by a slash, are each parsed
+// with ParseScaleChord, except that neither may have a bass note. The
+// minor flag indicates whether the target is relative to a minor key. The
+// applied chord is relative to a minor key if the target is a minor chord: so
+// "VII/vi" is the VII chord of the minor key of vi.
+func ParseAppliedChord(s string, minor bool) (*AppliedChord, error) {
+	pos := strings.IndexByte(s, '/')
+	switch {
+	case pos < 0:
+		return nil, fmt.Errorf("applied chord %q is missing '/' and target", s)
+	case strings.Count(s, "/") > 1:
+		return nil, fmt.Errorf("applied chord %q cannot have a bass note", s)
+	}
+	target, err := ParseScaleChord(s[pos+1:], minor)
+	if err != nil {
+		return nil, err
+	}
+	ch, err := ParseScaleChord(s[:pos], target.Type.hasMinorThird())
+	if err != nil {
+		return nil, err
+	}
+	return &AppliedChord{Chord: ch, Target: target}, nil
+}
+
+// String implements the Stringer interface. The result is the applied chord
+// and the target, separated by a slash, like "V7/ii".
+func (a *AppliedChord) String() string {
+	return a.Chord.String() + "/" + a.Target.String()
+}
+
+// ScaleChord returns the applied chord relative to the key's tonic. For
+// example, V7/ii is VI7.
+func (a *AppliedChord) ScaleChord() *ScaleChord {
+	return &ScaleChord{
+		Root:       addIntervals(a.Target.Root, a.Chord.Root),
+		InMinorKey: a.Target.InMinorKey,
+		Type:       a.Chord.Type,
+	}
+}
+
+// InKey returns the applied chord in the key with the given tonic.
+func (a *AppliedChord) InKey(keyName Note) *Chord {
+	return a.Chord.InKey(keyName.Transpose(a.Target.Root))
+}
+
+// hasMinorThird returns true if the chord type has a minor 3rd.
+func (t ChordType) hasMinorThird() bool {
+	switch t.Triad {
+	case Min3, Dim3, HDim, FDim:
+		return true
+	default:
+		return false
+	}
+}
+
+// DiatonicChords returns the triads of the key. (See ScaleType.ScaleChords.)
+func (k Key) DiatonicChords() []*ScaleChord {
+	return k.scaleType().ScaleChords(3)
+}
+
+// scaleType returns MajorScale or MinorScale, depending on the key's mode.
+func (k Key) scaleType() ScaleType {
+	if k.Minor {
+		return MinorScale
+	}
+	return MajorScale
+}
+
+// SecondaryDominants returns the secondary dominants of the key: the dominant
+// 7th chords of each diatonic chord other than the tonic and other than
+// diminished chords (which cannot be tonicized). In a major key, these are
+// V7/ii, V7/iii, V7/IV, V7/V, and V7/vi, in that order. In a minor key, these
+// are V7/III, V7/iv, V7/v, V7/VI, and V7/VII.
+//
+// Use ScaleChord.InKey with the key's tonic to get the actual chords: in C
+// major, the secondary dominants are A7, B7, C7, D7, and E7.
+func (k Key) SecondaryDominants() []*ScaleChord {
+	var chs []*ScaleChord
+	for i, sc := range k.DiatonicChords() {
+		if i == 0 || sc.Type.Triad == Dim3 {
+			continue
+		}
+		chs = append(chs, SecondaryDominantOf(sc))
+	}
+	return chs
+}
+
+// BorrowedChord is a chord borrowed from a mode that is parallel to a key
+// (i.e. that has the same tonic), also known as modal interchange. (See
+// Key.BorrowedChords.)
+type BorrowedChord struct {
+	// Chord is the borrowed chord, relative to the key's tonic.
+	Chord *ScaleChord
+	// Degree is the scale degree of the chord's root in the mode from which
+	// it is borrowed. The first degree is 1.
+	Degree int
+	// Source is the name of the mode from which the chord is borrowed, like
+	// "aeolian" or "harmonic minor".
+	Source string
+}
+
+// parallelModes are the modes from which chords are borrowed, in order of
+// preference, for major and minor keys.
+var parallelModes = [2][]struct {
+	name string
+	typ  ScaleType
+}{
+	{
+		{"aeolian", AeolianMode},
+		{"dorian", DorianMode},
+		{"phrygian", PhrygianMode},
+		{"mixolydian", MixolydianMode},
+		{"lydian", LydianMode},
+		{"locrian", LocrianMode},
+		{"harmonic minor", HarmonicMinorScale},
+		{"melodic minor", MelodicMinorScale},
+	},
+	{
+		{"harmonic minor", HarmonicMinorScale},
+		{"melodic minor", MelodicMinorScale},
+		{"ionian", IonianMode},
+		{"dorian", DorianMode},
+		{"phrygian", PhrygianMode},
+		{"mixolydian", MixolydianMode},
+		{"lydian", LydianMode},
+		{"locrian", LocrianMode},
+	},
+}
+
+// BorrowedChords enumerates the chords that can be borrowed from the modes
+// that are parallel to this key. The depth is the number of notes in each
+// chord, as for ScaleType.ScaleChords: 3 for triads, 4 for seventh chords, and
+// so on.
+//
+// Only chords that are not diatonic to the key are returned: for C major, this
+// includes iv (F-) and ♭VII (B♭) borrowed from aeolian, and ♭II (D♭) borrowed
+// from phrygian. Chords that could be borrowed from more than one mode are
+// only returned once. Modes are considered in order of how commonly chords
+// are borrowed from them: for major keys, aeolian, dorian, phrygian,
+// mixolydian, lydian, locrian, harmonic minor, and melodic minor; for minor
+// keys, harmonic minor, melodic minor, ionian (major), dorian, phrygian,
+// mixolydian, lydian, and locrian. Within each mode, chords are in scale
+// order.
+func (k Key) BorrowedChords(depth int) []BorrowedChord {
+	keySet := pitchClasses(k.Scale().Spell())
+	seen := map[string]struct{}{}
+	modes := parallelModes[0]
+	if k.Minor {
+		modes = parallelModes[1]
+	}
+	var borrowed []BorrowedChord
+	for _, mode := range modes {
+		for i, sc := range mode.typ.ScaleChords(depth) {
+			ch := sc.InKey(k.Tonic)
+			set := pitchClasses(ch.Spell())
+			if set&keySet == set {
+				// diatonic
+				continue
+			}
+			str := ch.String()
+			if _, ok := seen[str]; ok {
+				continue
+			}
+			seen[str] = struct{}{}
+			sc.InMinorKey = k.Minor
+			borrowed = append(borrowed, BorrowedChord{Chord: sc, Degree: i + 1, Source: mode.name})
+		}
+	}
+	return borrowed
+}
+
+// FunctionKind is the role that a chord plays in a key.
+type FunctionKind int
+
+const (
+	// UnknownFunction means the chord has no clear function in the key, such
+	// as a chromatic chord that is neither a secondary dominant nor borrowed
+	// from a parallel mode.
+	UnknownFunction FunctionKind = iota
+	// TonicFunction is for chords that provide stability and resolution, such
+	// as I, iii, and vi in a major key.
+	TonicFunction
+	// SubdominantFunction is for chords that lead away from the tonic, such as
+	// ii and IV in a major key. These are also called pre-dominant chords.
+	SubdominantFunction
+	// DominantFunction is for chords that create tension that resolves to the
+	// tonic (or to another chord, for secondary dominants), such as V and
+	// vii° in a major key.
+	DominantFunction
+)
+
+// String implements the Stringer interface.
+func (k FunctionKind) String() string {
+	switch k {
+	case UnknownFunction:
+		return "unknown"
+	case TonicFunction:
+		return "tonic"
+	case SubdominantFunction:
+		return "subdominant"
+	case DominantFunction:
+		return "dominant"
+	default:
+		return fmt.Sprintf("?(%d)", k)
+	}
+}
+
+// HarmonicFunction describes the role of a chord in a key. (See
+// ScaleChord.Function.)
+type HarmonicFunction struct {
+	// Kind is the chord's function.
+	Kind FunctionKind
+	// Secondary is the scale degree of the diatonic chord of which this chord
+	// is a secondary dominant, or zero if it is not a secondary dominant. For
+	// example, this is 5 for V7/V. The first degree is 1.
+	Secondary int
+	// Borrowed is the name of the parallel mode from which this chord is
+	// borrowed, or empty if it is not a borrowed chord. (See
+	// Key.BorrowedChords.)
+	Borrowed string
+}
+
+// String implements the Stringer interface. The result is the function's
+// kind, followed by annotations for secondary and borrowed chords, like
+// "dominant (secondary of 5)" or "subdominant (borrowed from aeolian)".
+func (f HarmonicFunction) String() string {
+	switch {
+	case f.Secondary != 0:
+		return fmt.Sprintf("%v (secondary of %d)", f.Kind, f.Secondary)
+	case f.Borrowed != "":
+		return fmt.Sprintf("%v (borrowed from %s)", f.Kind, f.Borrowed)
+	default:
+		return f.Kind.String()
+	}
+}
+
+// diatonicFunctions are the functions of the chords on each degree of major
+// and minor keys.
+var diatonicFunctions = [2][7]FunctionKind{
+	{TonicFunction, SubdominantFunction, TonicFunction, SubdominantFunction, DominantFunction, TonicFunction, DominantFunction},
+	{TonicFunction, SubdominantFunction, TonicFunction, SubdominantFunction, DominantFunction, SubdominantFunction, DominantFunction},
+}
+
+// Function classifies the chord's role in the given key. The chord is
+// relative to the key's tonic.
+//
+// Chords that are diatonic to the key are classified by the scale degree of
+// their root: in a major key, I, iii, and vi are tonic; ii and IV are
+// subdominant; and V and vii° are dominant. Minor keys are the same, except
+// that VI is subdominant. In a minor key, chords with the raised 7th of the
+// harmonic minor scale, like V7 and vii°7, are also diatonic, as they are for
+// InferKey.
+//
+// Chords that are not diatonic are checked to see if they are secondary
+// dominants (see Key.SecondaryDominants), which have dominant function: a
+// major triad or dominant 7th chord whose root is a perfect fifth above one of
+// the other diatonic chords. Otherwise, they are checked to see if they are
+// borrowed from a parallel mode (see Key.BorrowedChords). Borrowed chords on
+// the 1st and 3rd degrees are tonic; a major chord on the 5th degree or a
+// chord whose root is the leading tone (a major 7th above the tonic) is
+// dominant; and all others are subdominant, like iv, ♭VI, and ♭VII in a major
+// key. Chords that are none of these have unknown function.
+func (s *ScaleChord) Function(key Key) HarmonicFunction {
+	set := pitchClasses(s.InKey(key.Tonic).Spell())
+	keySet := keyPitchClasses(key)
+	if set&keySet == set {
+		minor := 0
+		if key.Minor {
+			minor = 1
+		}
+		return HarmonicFunction{Kind: diatonicFunctions[minor][s.Root.Val-1]}
+	}
+
+	rootSteps := posMod(s.Root.NumHalfSteps(), 12)
+	if s.Type.isDominant() {
+		for i, sc := range key.DiatonicChords() {
+			if i == 0 || sc.Type.Triad == Dim3 {
+				continue
+			}
+			if posMod(SecondaryDominantOf(sc).Root.NumHalfSteps(), 12) == rootSteps {
+				return HarmonicFunction{Kind: DominantFunction, Secondary: i + 1}
+			}
+		}
+	}
+
+	modes := parallelModes[0]
+	if key.Minor {
+		modes = parallelModes[1]
+	}
+	for _, mode := range modes {
+		modeSet := pitchClasses(mode.typ.WithRoot(key.Tonic).Spell())
+		if set&modeSet != set {
+			continue
+		}
+		kind := SubdominantFunction
+		switch {
+		case s.Root.Val == 1 || s.Root.Val == 3:
+			kind = TonicFunction
+		case s.Root.Val == 5 && s.Type.Triad == Maj3, rootSteps == 11:
+			kind = DominantFunction
+		}
+		return HarmonicFunction{Kind: kind, Borrowed: mode.name}
+	}
+
+	return HarmonicFunction{}
+}
+
+// isDominant returns true if the chord type is a major triad or a dominant
+// chord: one with a major 3rd and, if it has a 7th, a minor 7th.
+func (t ChordType) isDominant() bool {
+	if t.Triad != Maj3 {
+		return false
+	}
+	for _, tn := range t.ExtraTones {
+		if tn.Val == 7 && tn.Acc != Natural {
+			return false
+		}
+	}
+	return true
+}
+
+// NegateChord returns the chord that corresponds to the given chord in the
+// "negative harmony" of the given key. Each note of the chord is reflected
+// around the axis halfway between the key's tonic and its 5th, so in C the
+// tonic and 5th trade places (C and G), as do the major and minor 3rds (E
+// and E♭). This differs from Negate, which reflects notes around a single
+// note; the reflected notes here are a perfect 5th higher. Only the key's
+// tonic determines the notes. Whether the key is major or minor only affects
+// how they are spelled.
+//
+// Reflecting a chord turns it upside down, so the note that was at the top
+// of its stack of 3rds usually becomes the root. In C, C△7 becomes A♭△7, G7
+// becomes Dø, and D-7 becomes G-7 (rather than B♭6, which has the same
+// notes). The reflected notes are spelled to fit the parallel key (see
+// Chord.SpellInKey), whose scale is the reflection of the key's scale, and
+// then named with InferChordsWithOptions. Of the names that are equally
+// plausible, the one with that root is chosen. A bass note is reflected too,
+// so the result is a slash chord when the given chord is.
+//
+// This returns nil if the reflected notes don't form a chord.
+func NegateChord(ch *Chord, key Key) *Chord {
+	negate := func(n Note) Note {
+		return key.Tonic.TransposeDown(key.Tonic.IntervalTo(n)).Transpose(Interval{Val: 5})
+	}
+	upper := ch.Clone()
+	upper.Bass = Note{}
+	notes := upper.Spell()
+	for i, n := range notes {
+		notes[i] = negate(n)
+	}
+	// C major reflects to C minor, so C minor's scale is used for spelling
+	parallel := Key{Tonic: key.Tonic, Minor: !key.Minor}
+	spellNotesInKey(notes, parallel)
+	var opts InferenceOptions
+	if ch.Bass.N != 0 {
+		opts.Bass = spellNotesInKey([]Note{negate(ch.Bass)}, parallel)[0]
+	}
+	cands := InferChordsWithOptions(notes, opts)
+	if len(cands) == 0 {
+		return nil
+	}
+	// the last note is the highest tone in the chord's stack of 3rds
+	top := PitchClass(notes[len(notes)-1])
+	for _, cand := range cands {
+		if cand.Score > cands[0].Score {
+			break
+		}
+		root := cand.Chord.Root
+		// skip awkward spellings of the root, like E♯ for F
+		if PitchClass(root) == top && (root.Acc == Natural || root.Enharmonic(Natural).N == 0) {
+			return cand.Chord
+		}
+	}
+	return cands[0].Chord
+}
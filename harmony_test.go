@@ -0,0 +1,172 @@
+package chords
+
+import (
+	"strings"
+	"testing"
+)
+
+func scaleChordNames(key Key, scs []*ScaleChord) string {
+	names := make([]string, len(scs))
+	for i, sc := range scs {
+		names[i] = sc.InKey(key.Tonic).String()
+	}
+	return strings.Join(names, " ")
+}
+
+func TestSecondaryDominants(t *testing.T) {
+	cases := map[string]string{
+		"C":  "A7 B7 C7 D7 E7",
+		"Eb": "C7 D7 E♭7 F7 G7",
+		"Am": "G7 A7 B7 C7 D7",
+		"F#": "D♯7 E♯7 F♯7 G♯7 A♯7",
+	}
+	for s, exp := range cases {
+		k := MustParseKey(s)
+		if actual := scaleChordNames(k, k.SecondaryDominants()); actual != exp {
+			t.Errorf("wrong secondary dominants for %v: %s != %s", k, actual, exp)
+		}
+	}
+
+	v := &ScaleChord{Root: Interval{Val: 5}, Type: ChordType{Triad: Maj3}}
+	if ch := SecondaryDominantOf(v).InKey(MustParseNote("Bb")); ch.String() != "C7" {
+		t.Errorf("wrong V7/V in B♭: %v", ch)
+	}
+	if notes := spellString(SecondaryDominantOf(v).InKey(MustParseNote("B")).Spell()); notes != "C♯ E♯ G♯ B" {
+		t.Errorf("wrong spelling of V7/V in B: %s", notes)
+	}
+}
+
+func TestKey_BorrowedChords(t *testing.T) {
+	k := MustParseKey("C")
+	borrowed := k.BorrowedChords(3)
+	var strs []string
+	for _, b := range borrowed {
+		strs = append(strs, b.Chord.InKey(k.Tonic).String()+" ("+b.Source+")")
+	}
+	exp := "C- (aeolian), Ddim (aeolian), E♭ (aeolian), F- (aeolian), G- (aeolian), A♭ (aeolian), B♭ (aeolian), " +
+		"Adim (dorian), D♭ (phrygian), Gdim (phrygian), B♭- (phrygian), Edim (mixolydian), " +
+		"D (lydian), F♯dim (lydian), B- (lydian), Cdim (locrian), E♭- (locrian), G♭ (locrian), " +
+		"E♭+ (harmonic minor)"
+	if actual := strings.Join(strs, ", "); actual != exp {
+		t.Errorf("wrong borrowed chords for %v: %s", k, actual)
+	}
+
+	k = MustParseKey("Am")
+	for _, b := range k.BorrowedChords(4) {
+		if b.Source == "harmonic minor" && b.Degree == 5 {
+			if ch := b.Chord.InKey(k.Tonic).String(); ch != "E7" {
+				t.Errorf("wrong V7 borrowed from harmonic minor: %s", ch)
+			}
+			return
+		}
+	}
+	t.Errorf("V7 not borrowed for %v", k)
+}
+
+func TestScaleChord_Function(t *testing.T) {
+	seventh := []ChordTone{{Val: 7}}
+	cases := []struct {
+		key   string
+		chord ScaleChord
+		exp   string
+	}{
+		{"C", ScaleChord{Root: Interval{Val: 1}, Type: ChordType{Triad: Maj3}}, "tonic"},
+		{"C", ScaleChord{Root: Interval{Val: 2}, Type: ChordType{Triad: Min3, ExtraTones: seventh}}, "subdominant"},
+		{"C", ScaleChord{Root: Interval{Val: 5}, Type: ChordType{Triad: Maj3, ExtraTones: seventh}}, "dominant"},
+		{"C", ScaleChord{Root: Interval{Val: 6}, Type: ChordType{Triad: Min3}}, "tonic"},
+		{"C", ScaleChord{Root: Interval{Val: 7}, Type: ChordType{Triad: HDim}}, "dominant"},
+		{"C", ScaleChord{Root: Interval{Val: 6}, Type: ChordType{Triad: Maj3, ExtraTones: seventh}}, "dominant (secondary of 2)"},
+		{"C", ScaleChord{Root: Interval{Val: 2}, Type: ChordType{Triad: Maj3}}, "dominant (secondary of 5)"},
+		{"C", ScaleChord{Root: Interval{Val: 4}, Type: ChordType{Triad: Min3}}, "subdominant (borrowed from aeolian)"},
+		{"C", ScaleChord{Root: Interval{Val: 7, Offset: -1}, Type: ChordType{Triad: Maj3, ExtraTones: seventh}}, "subdominant (borrowed from aeolian)"},
+		{"C", ScaleChord{Root: Interval{Val: 1}, Type: ChordType{Triad: Min3}}, "tonic (borrowed from aeolian)"},
+		{"C", ScaleChord{Root: Interval{Val: 2, Offset: -1}, Type: ChordType{Triad: Maj3, ExtraTones: seventh}}, "unknown"},
+		{"Am", ScaleChord{Root: Interval{Val: 4}, Type: ChordType{Triad: Min3}}, "subdominant"},
+		{"Am", ScaleChord{Root: Interval{Val: 6, Offset: -1}, Type: ChordType{Triad: Maj3}}, "subdominant"},
+		// the raised 7th of the harmonic minor scale is in the key
+		{"Am", ScaleChord{Root: Interval{Val: 5}, Type: ChordType{Triad: Maj3, ExtraTones: seventh}}, "dominant"},
+		{"Am", ScaleChord{Root: Interval{Val: 5}, Type: ChordType{Triad: Maj3}}, "dominant"},
+		{"Am", ScaleChord{Root: Interval{Val: 7}, Type: ChordType{Triad: Dim3, ExtraTones: seventh}}, "dominant"},
+		{"Am", ScaleChord{Root: Interval{Val: 3, Offset: -1}, Type: ChordType{Triad: Maj3, ExtraTones: seventh}}, "dominant (secondary of 6)"},
+	}
+	for _, tc := range cases {
+		k := MustParseKey(tc.key)
+		sc := tc.chord
+		sc.InMinorKey = k.Minor
+		if actual := sc.Function(k).String(); actual != tc.exp {
+			t.Errorf("ScaleChord.Function for %v in %v returned wrong value: %q != %q", sc.InKey(k.Tonic), k, actual, tc.exp)
+		}
+	}
+}
+
+func TestParseAppliedChord(t *testing.T) {
+	cases := []struct {
+		key, numeral, scaleChord, chord string
+	}{
+		{"C", "V7/ii", "VI7", "A7"},
+		{"C", "V7/V", "II7", "D7"},
+		{"C", "viio/V", "♯ivo", "F♯o"},
+		{"C", "VII/vi", "V", "G"},
+		{"C", "iiø/iii", "♯ivø", "F♯ø"},
+		{"Am", "V7/III", "VII7", "G7"},
+		{"Am", "V7♭9/iv", "I7♭9", "A7♭9"},
+	}
+	for _, tc := range cases {
+		key, err := ParseKey(tc.key)
+		if err != nil {
+			t.Fatalf("failed to parse key %q: %v", tc.key, err)
+		}
+		a, err := ParseAppliedChord(tc.numeral, key.Minor)
+		if err != nil {
+			t.Errorf("ParseAppliedChord(%q) failed: %v", tc.numeral, err)
+			continue
+		}
+		if actual := a.String(); actual != tc.numeral {
+			t.Errorf("AppliedChord.String returned wrong value: %q != %q", actual, tc.numeral)
+		}
+		if actual := a.ScaleChord().String(); actual != tc.scaleChord {
+			t.Errorf("ParseAppliedChord for %s in %s returned wrong value: %q != %q", tc.numeral, tc.key, actual, tc.scaleChord)
+		}
+		if actual := a.InKey(key.Tonic).String(); actual != tc.chord {
+			t.Errorf("ParseAppliedChord for %s in %s returned wrong value: %q != %q", tc.numeral, tc.key, actual, tc.chord)
+		}
+	}
+
+	for _, bad := range []string{"V7", "V7/", "X/ii", "V7/ii/iii"} {
+		if _, err := ParseAppliedChord(bad, false); err == nil {
+			t.Errorf("ParseAppliedChord for %q should have failed", bad)
+		}
+	}
+}
+
+func TestNegateChord(t *testing.T) {
+	cases := []struct {
+		key, chord, exp string
+	}{
+		{"C", "C", "C-"},
+		{"C", "C-", "C"},
+		{"C", "F", "G-"},
+		{"C", "C△7", "A♭△7"},
+		{"C", "A-7", "C-7"},
+		{"C", "D-7", "G-7"},
+		{"C", "G7", "Dø"},
+		{"C", "B♭7", "Bø"},
+		{"C", "C6", "E♭6"},
+		{"C", "Csus4", "Csus2"},
+		{"C", "C/E", "C-/E♭"},
+		{"C", "Fo", "Do"},
+		{"F♯", "G7", "Dø"},
+		{"Am", "C△7", "D△7"},
+		{"Am", "E7", "Bø"},
+		{"E♭", "E♭△7", "C♭△7"},
+		{"E♭", "B♭7", "Fø"},
+	}
+	for _, tc := range cases {
+		ch := NegateChord(MustParseChord(tc.chord), MustParseKey(tc.key))
+		if ch == nil {
+			t.Errorf("NegateChord for %s in %s returned wrong value: nil != %s", tc.chord, tc.key, tc.exp)
+		} else if actual := ch.String(); actual != tc.exp {
+			t.Errorf("NegateChord for %s in %s returned wrong value: %s != %s", tc.chord, tc.key, actual, tc.exp)
+		}
+	}
+}
@@ -0,0 +1,472 @@
+package chords
+
+import (
+	"math"
+	"math/bits"
+	"sort"
+)
+
+// InferChord returns the chord that best describes the given notes, or nil if
+// the notes don't form a chord (for example, if there are fewer than three
+// distinct notes, or if none of them can be the root of a chord that has a
+// 3rd or a suspension). Every one of the given notes is considered as the
+// root, and the simplest resulting chord is returned. (See
+// InferChordOverBass for how chords are ranked.) Since the notes are not
+// ordered by pitch, there is no bass note, and the chord is never a slash
+// chord.
+func InferChord(notes ...Note) *Chord {
+	cands := inferChords(notes, InferenceOptions{})
+	if len(cands) == 0 {
+		return nil
+	}
+	return cands[0].Chord
+}
+
+// InferChords returns all of the chords that describe the given notes, ranked
+// from the best description to the worst, along with their scores. The first
+// is the chord returned by InferChord. So C E G A is best described as C6,
+// with A-7 next. As with InferChord, there is no bass note, so none of the
+// chords are slash chords. (See InferChordOverBass to take a bass note into
+// account.) This returns nil if the notes don't form a chord.
+func InferChords(notes ...Note) []*ChordCandidate {
+	return inferChords(notes, InferenceOptions{})
+}
+
+// InferChordOverBass returns the chords that describe the given notes when
+// the given bass note is the lowest note played, ranked from the best
+// description to the worst. The bass is one of the chord's notes, whether or
+// not it is also among the given notes. If the bass is the zero Note, no note
+// is taken as the bass, as with InferChord.
+//
+// Every distinct note is considered as the root. Simpler chords rank higher:
+// a chord is penalized for each of its extra tones (like a 7th or 9th), for
+// each altered tone, for a suspension, for a diminished or augmented triad,
+// for a minor chord with a major 7th, for a triad with no 5th, and for being a
+// slash chord (where the bass is not the root). So C E G A is C6 when C is the
+// bass, but A-7/C ranks below it. With E as the bass, E G C is C/E, which
+// ranks higher than E-♯5. Ties are broken by the order of the notes, with the
+// bass first.
+//
+// The root of each chord is spelled to match the given notes as closely as
+// possible, preferring spellings with fewer accidentals. Notes may be given
+// more than once, as when they are doubled in other octaves, and they may be
+// given with more than one spelling, like both D♯ and E♭, as they often are
+// in MIDI or scanned scores: each pitch class counts once, and the bass is
+// spelled as the chord spells it if it is given both ways. Zero Notes are
+// ignored. This returns nil if the notes don't form a chord.
+func InferChordOverBass(bass Note, notes ...Note) []*Chord {
+	if bass.N != 0 {
+		notes = append([]Note{bass}, notes...)
+	}
+	var chs []*Chord
+	for _, cand := range inferChords(notes, InferenceOptions{Bass: bass}) {
+		chs = append(chs, cand.Chord)
+	}
+	return chs
+}
+
+// ChordCandidate is one of the chords that describe a set of notes, as found
+// by chord inference.
+type ChordCandidate struct {
+	// Chord is the chord that describes the notes.
+	Chord *Chord
+	// Score is how complex the chord is as a description of the notes. Lower
+	// scores are better, and zero is a plain major or minor triad. (See
+	// InferChordOverBass for what raises the score.)
+	Score int
+}
+
+// midiNoteSpellings are the notes used to spell each pitch class, indexed by
+// the number of half-steps above C, when inferring chords from MIDI note
+// numbers. They are the spellings used in the most common keys. Chord roots
+// are respelled as needed.
+var midiNoteSpellings = [12]Note{
+	{N: C}, {N: C, Acc: Sharp}, {N: D}, {N: E, Acc: Flat}, {N: E}, {N: F},
+	{N: F, Acc: Sharp}, {N: G}, {N: A, Acc: Flat}, {N: A}, {N: B, Acc: Flat}, {N: B},
+}
+
+// InferChordFromMIDI returns the chords that describe the given MIDI note
+// numbers (where 60 is middle C), ranked from the best description to the
+// worst, along with their scores. The lowest note is the bass. Notes that are
+// doubled in other octaves count only once, and the order of the notes
+// doesn't matter.
+//
+// Since MIDI notes have no spelling, the root of each chord is spelled so
+// that the chord has as few accidentals as possible, like D♭ (D♭ F A♭) rather
+// than C♯ (C♯ E♯ G♯). The bass of a slash chord is spelled with the most
+// common spelling of its pitch class (with a flat for the black keys except
+// for F♯ and C♯), unless it is a chord tone that is spelled in the chord with
+// no more accidentals. So the bass of C♯-7/G♯ is G♯ but the bass of E-♯5/C
+// is C, not B♯. Chords are ranked the same way as by InferChordOverBass. This
+// returns nil if the notes don't form a chord.
+func InferChordFromMIDI(notes []int) []*ChordCandidate {
+	if len(notes) == 0 {
+		return nil
+	}
+	lowest := notes[0]
+	spelled := make([]Note, len(notes))
+	for i, n := range notes {
+		spelled[i] = midiNoteSpellings[((n%12)+12)%12]
+		if n < lowest {
+			lowest = n
+		}
+	}
+	bass := midiNoteSpellings[((lowest%12)+12)%12]
+	cands := inferChords(append([]Note{bass}, spelled...), InferenceOptions{Bass: bass})
+	for _, cand := range cands {
+		if cand.Chord.Bass.N == 0 {
+			continue
+		}
+		upper := cand.Chord.Clone()
+		upper.Bass = Note{}
+		for _, n := range upper.Spell() {
+			if n.Cardinal() == bass.Cardinal() && accidentals(n) <= accidentals(bass) {
+				cand.Chord.Bass = n
+				break
+			}
+		}
+	}
+	return cands
+}
+
+// InferChordFromPitches returns the chords that describe the given pitches,
+// ranked from the best description to the worst, along with their scores. The
+// lowest pitch is the bass, so inversions and slash chords can be told apart:
+// E4 G4 C5 is C/E, which ranks above E-♯5, but C4 E4 G4 is C. Pitches that are
+// doubled in other octaves count only once, and the order of the pitches
+// doesn't matter. Unlike InferChordFromMIDI, the pitches are spelled, so the
+// chords are spelled to match them. Chords are ranked the same way as by
+// InferChordOverBass. This returns nil if the pitches don't form a chord.
+func InferChordFromPitches(ps ...Pitch) []*ChordCandidate {
+	if len(ps) == 0 {
+		return nil
+	}
+	lowest := ps[0]
+	notes := make([]Note, len(ps))
+	for i, p := range ps {
+		notes[i] = p.Note
+		if p.MIDINumber() < lowest.MIDINumber() {
+			lowest = p
+		}
+	}
+	return InferChordsWithOptions(notes, InferenceOptions{Bass: lowest.Note})
+}
+
+// InferChordFromFrequencies returns the chords that describe the given
+// frequencies, in hertz, such as the peaks found by analyzing a recording. Each
+// frequency is quantized to the nearest pitch in the given tuning, so a note
+// that is out of tune by up to a quarter-tone (50 cents) in either direction
+// is still recognized. (See Pitch.CentsTo to measure how far out of tune it
+// is.) If the given tuning is the zero value, StandardTuning is used.
+// Frequencies that are not positive are ignored.
+//
+// The pitches are then treated like MIDI notes, with the lowest as the bass,
+// so the chords are spelled and ranked as by InferChordFromMIDI. This returns
+// nil if the frequencies don't form a chord.
+func InferChordFromFrequencies(freqs []float64, tuning Tuning) []*ChordCandidate {
+	tuning = tuning.orDefault()
+	ref := float64(tuning.Reference.MIDINumber())
+	var notes []int
+	for _, f := range freqs {
+		if !(f > 0) || math.IsInf(f, 1) {
+			continue
+		}
+		notes = append(notes, int(math.Round(ref+12*math.Log2(f/tuning.Frequency))))
+	}
+	return InferChordFromMIDI(notes)
+}
+
+// accidentals returns the number of accidentals in the given note's spelling.
+func accidentals(n Note) int {
+	if n.Acc < 0 {
+		return -int(n.Acc)
+	}
+	return int(n.Acc)
+}
+
+// InferenceOptions control how chords are inferred from notes. The zero value
+// infers chords the same way as InferChords.
+type InferenceOptions struct {
+	// Bass, if not the zero Note, is the lowest note played, as with
+	// InferChordOverBass. It is one of the chord's notes, whether or not it
+	// is also among the given notes.
+	Bass Note
+	// Roots, if not empty, are the only notes considered as the root of the
+	// chord, like a root that is known from context (such as a bass line or
+	// a chord chart). They need not be among the given notes, so the root
+	// can be missing from the voicing. The roots are not respelled.
+	Roots []Note
+	// Rootless, if true, considers every pitch class as the root of the
+	// chord, even those that are not among the given notes, so that rootless
+	// voicings can be recognized. This is ignored if Roots is not empty.
+	Rootless bool
+}
+
+// rootlessPenalty is added to the score of a chord whose root is not among
+// the notes. It ranks such a chord below one whose root is present, even if
+// that chord has an extra tone or two more.
+const rootlessPenalty = 3
+
+// InferChordsWithOptions returns the chords that describe the given notes,
+// ranked from the best description to the worst, along with their scores. It
+// is like InferChords, but the options can give a bass note and can allow the
+// chord's root to be missing from the notes, as in the rootless voicings
+// that pianists use, where the bass player plays the root. So E G B♭ D, which
+// is best described as Eø, can be read as a rootless C9, and E B♭, which is
+// too few notes for a chord on its own, can be read as a C7 shell voicing.
+//
+// Chords whose root is not among the notes are ranked the same way as other
+// chords (see InferChordOverBass), but with a penalty, so a reading with the
+// root present is preferred. To pick out the intended reading of a rootless
+// voicing, give the possible roots as a hint. Chords with a missing 5th, like
+// C E B♭ for C7, need no options: the 5th is often omitted from chords with
+// a 7th, so its absence is only penalized in triads. This returns nil if the
+// notes don't form a chord with any of the roots that are considered.
+func InferChordsWithOptions(notes []Note, opts InferenceOptions) []*ChordCandidate {
+	if opts.Bass.N != 0 {
+		notes = append([]Note{opts.Bass}, notes...)
+	}
+	return inferChords(notes, opts)
+}
+
+// inferChords returns the chords that describe the given notes, ranked from
+// best to worst. If opts.Bass is not the zero Note, it is the bass of the
+// chords and it must also be among the notes. (See InferChordsWithOptions.)
+func inferChords(notes []Note, opts InferenceOptions) []*ChordCandidate {
+	notes = distinctNotes(notes)
+	set := pitchClasses(notes)
+	// roots are the candidate roots, and respell is true for those whose
+	// spelling can be changed to suit the chord
+	roots, respell := opts.Roots, false
+	if len(roots) == 0 {
+		roots, respell = notes, true
+		if opts.Rootless {
+			roots = append(append([]Note(nil), notes...), midiNoteSpellings[:]...)
+		}
+	}
+	var candidates []*ChordCandidate
+	var seen uint16
+	for _, n := range roots {
+		if n.N == 0 {
+			continue
+		}
+		c := n.Cardinal()
+		if seen&(1<<uint(c)) != 0 {
+			continue
+		}
+		seen |= 1 << uint(c)
+		full := set | 1<<uint(c)
+		if bits.OnesCount16(full) < 3 {
+			continue
+		}
+		rel := rotatePitchClasses(full, -int(c))
+		root := n
+		if respell {
+			root = spellChordRoot(n, rel, notes)
+		}
+		ch := chordFromPitchClasses(root, rel)
+		if ch == nil {
+			continue
+		}
+		if opts.Bass.N != 0 && opts.Bass.Cardinal() != c {
+			ch.Bass = bassSpelling(ch, opts.Bass, notes)
+		}
+		score := inferenceScore(ch, rel)
+		if set&(1<<uint(c)) == 0 {
+			score += rootlessPenalty
+		}
+		candidates = append(candidates, &ChordCandidate{Chord: ch, Score: score})
+	}
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return candidates[i].Score < candidates[j].Score
+	})
+	return candidates
+}
+
+// distinctNotes returns the given notes without any zero Notes and with each
+// spelling only once, in the order in which they first appear. Enharmonic
+// notes, like D♯ and E♭, are both kept, since either may be the spelling that
+// best suits the chord.
+func distinctNotes(notes []Note) []Note {
+	distinct := make([]Note, 0, len(notes))
+	for _, n := range notes {
+		if n.N != 0 && !containsNote(distinct, n) {
+			distinct = append(distinct, n)
+		}
+	}
+	return distinct
+}
+
+// containsNote returns true if the given notes include the given note, spelled
+// the same way.
+func containsNote(notes []Note, search Note) bool {
+	for _, n := range notes {
+		if n == search {
+			return true
+		}
+	}
+	return false
+}
+
+// bassSpelling returns the spelling of the given bass note to use for the
+// given chord. If the notes include another spelling of the bass, as when
+// they have both D♯ and E♭, and that is how the chord spells that tone, the
+// chord's spelling is used, so that the bass matches the chord.
+func bassSpelling(ch *Chord, bass Note, notes []Note) Note {
+	for _, n := range ch.Spell() {
+		if n != bass && n.Cardinal() == bass.Cardinal() && containsNote(notes, n) {
+			return n
+		}
+	}
+	return bass
+}
+
+// spellChordRoot returns a spelling of the given note to use as the root of a
+// chord with the given pitch classes (relative to the root, as with
+// chordFromPitchClasses). Like spellRootForNotes, it chooses the spelling for
+// which the most of the given notes appear in the chord, as spelled, breaking
+// ties by choosing the chord with the fewest accidentals and then by keeping
+// the note's own spelling.
+func spellChordRoot(root Note, rel uint16, notes []Note) Note {
+	best := root
+	bestMatches, bestAccs := -1, 0
+	for _, r := range append([]Note{root}, root.Enharmonics()...) {
+		ch := chordFromPitchClasses(r, rel)
+		if ch == nil {
+			return root
+		}
+		matches, accs := 0, 0
+		for _, cn := range ch.Spell() {
+			accs += accidentals(cn)
+			for _, n := range notes {
+				if n == cn {
+					matches++
+					break
+				}
+			}
+		}
+		if matches > bestMatches || (matches == bestMatches && accs < bestAccs) {
+			best, bestMatches, bestAccs = r, matches, accs
+		}
+	}
+	return best
+}
+
+// inferenceScore returns how unlikely the given chord is as a description of
+// the given pitch classes (relative to the chord's root). Lower scores are
+// better. (See InferChordOverBass.)
+func inferenceScore(ch *Chord, rel uint16) int {
+	score := 0
+	alteredFifth, seventh := false, false
+	for _, tn := range ch.ExtraTones {
+		score++
+		if tn.Val >= 7 {
+			seventh = true
+		}
+		switch {
+		case tn.Val == 7 && tn.Acc == Sharp:
+			// the major 7th is only unusual in a minor chord
+			if ch.Triad == Min3 {
+				score++
+			}
+		case tn.Acc != Natural:
+			score++
+			alteredFifth = alteredFifth || tn.Val == 5
+		}
+	}
+	switch ch.Triad {
+	case Sus:
+		score++
+	case Aug3, Dim3:
+		score++
+		alteredFifth = true
+	case HDim, FDim:
+		// the implied 7th is already among the extra tones
+		alteredFifth = true
+	}
+	if !alteredFifth && !seventh && rel&(1<<7) == 0 {
+		// the 5th is often left out of larger chords, but not of triads
+		score++
+	}
+	if ch.Bass.N != 0 {
+		score++
+	}
+	return score
+}
+
+// ChordsWithPitchClasses returns every chord, with any root, whose pitch
+// classes are exactly the given set, like C6 and A-7 for {0,4,7,9}. Since a
+// chord's root is one of its pitch classes, each chord's root is in the set.
+// There is one chord for each root that can name the set, spelled as by
+// InferChordFromMIDI. Unlike chord inference, every chord is returned, even
+// unlikely ones, but the chords are ordered from the most plausible to the
+// least (see InferChordOverBass) and then by root.
+//
+// Since every chord has a 5th, a set with no perfect 5th above a given root
+// is named with that root only if the 5th is altered, like Co for {0,3,6}.
+func ChordsWithPitchClasses(set PitchClassSet) []*Chord {
+	return chordsContaining(set, 0)
+}
+
+// ChordsContainingPitchClasses returns every chord, with any root, whose
+// pitch classes include all of the given set and at most extra others, for
+// searching for the chords that contain some notes. So with no extra pitch
+// classes, this is the same as ChordsWithPitchClasses. The root of a chord
+// need not be in the set, but it counts as one of the extra pitch classes.
+// Chords are named and ordered as by ChordsWithPitchClasses, except that
+// chords with fewer pitch classes come first.
+func ChordsContainingPitchClasses(set PitchClassSet, extra int) []*Chord {
+	return chordsContaining(set, extra)
+}
+
+// chordsContaining returns every chord whose pitch classes include all of the
+// given set and at most extra others.
+func chordsContaining(set PitchClassSet, extra int) []*Chord {
+	set &= allPitchClasses
+	type found struct {
+		ch    *Chord
+		size  int
+		score int
+		root  int
+	}
+	var results []found
+	seen := map[string]bool{}
+	for root := 0; root < 12; root++ {
+		base := set.With(root)
+		others := base.Complement()
+		// each superset adds a subset of the other pitch classes
+		for add := others; ; add = (add - 1) & others {
+			full := base | add
+			if full.Len()-set.Len() <= extra {
+				rel := uint16(full.Transpose(-root))
+				r := spellChordRoot(midiNoteSpellings[root], rel, nil)
+				if ch := chordFromPitchClasses(r, rel); ch != nil && ch.Validate() == nil {
+					pcs := ch.PitchClassSet()
+					if pcs.IsSupersetOf(set) && pcs.Len()-set.Len() <= extra && !seen[ch.String()] {
+						seen[ch.String()] = true
+						results = append(results, found{ch: ch, size: pcs.Len(), score: inferenceScore(ch, rel), root: root})
+					}
+				}
+			}
+			if add == 0 {
+				break
+			}
+		}
+	}
+	sort.SliceStable(results, func(i, j int) bool {
+		a, b := results[i], results[j]
+		switch {
+		case a.size != b.size:
+			return a.size < b.size
+		case a.score != b.score:
+			return a.score < b.score
+		default:
+			return a.root < b.root
+		}
+	})
+	chs := make([]*Chord, len(results))
+	for i, r := range results {
+		chs[i] = r.ch
+	}
+	return chs
+}
@@ -0,0 +1,356 @@
+package chords
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func parseNotes(t *testing.T, s string) []Note {
+	var notes []Note
+	for _, f := range strings.Fields(s) {
+		n, err := ParseNote(f)
+		if err != nil {
+			t.Fatalf("failed to parse note %q: %v", f, err)
+		}
+		notes = append(notes, n)
+	}
+	return notes
+}
+
+func TestInferChord(t *testing.T) {
+	cases := []struct {
+		notes string
+		exp   string
+	}{
+		{"C E G", "C"},
+		{"G E C", "C"},
+		{"C E♭ G", "C-"},
+		{"C E G♯", "C+"},
+		{"B D F", "Bdim"},
+		{"C F G", "Csus4"},
+		{"C E G B♭", "C7"},
+		{"C E G B", "C△7"},
+		{"C E♭ G B", "C-△7"},
+		{"B D F A", "Bø"},
+		{"B D F A♭", "Bo"},
+		{"C E B♭", "C7"},
+		{"G B D F A", "G9"},
+		{"C E B♭ D", "C9"},
+		{"C E♭ G♭ B♭ D", "Cø9"},
+		// ties go to the first note
+		{"C E G A", "C6"},
+		{"A C E G", "A-7"},
+		// roots are respelled to match the other notes
+		{"C♯ F A♭", "D♭"},
+		{"C E", ""},
+	}
+	for _, tc := range cases {
+		ch := InferChord(parseNotes(t, tc.notes)...)
+		actual := ""
+		if ch != nil {
+			actual = ch.String()
+		}
+		if actual != tc.exp {
+			t.Errorf("InferChord for %s returned wrong value: %q != %q", tc.notes, actual, tc.exp)
+		}
+	}
+}
+
+func TestInferChords(t *testing.T) {
+	cases := []struct {
+		notes string
+		exp   string
+	}{
+		{"C E G", "[C(0) E-♯5(2) Gsus4 6(4)]"},
+		{"C E G A", "[C6(1) A-7(1) E-4♯5(3) Gsus2 4 6(5)]"},
+		{"B D F A♭", "[Bo(1) Do(1) E♯o(1) G♯o(1)]"},
+		{"C E", "[]"},
+	}
+	for _, tc := range cases {
+		cands := InferChords(parseNotes(t, tc.notes)...)
+		var strs []string
+		for _, cand := range cands {
+			strs = append(strs, fmt.Sprintf("%v(%d)", cand.Chord, cand.Score))
+		}
+		if actual := "[" + strings.Join(strs, " ") + "]"; actual != tc.exp {
+			t.Errorf("InferChords for %s returned wrong value: %s != %s", tc.notes, actual, tc.exp)
+		}
+		if best := InferChord(parseNotes(t, tc.notes)...); len(cands) > 0 && best.String() != cands[0].Chord.String() {
+			t.Errorf("%s: InferChord returned %v, but the best candidate is %v", tc.notes, best, cands[0].Chord)
+		}
+	}
+}
+
+func TestInferChordsWithOptions(t *testing.T) {
+	cases := []struct {
+		notes string
+		opts  InferenceOptions
+		exp   string
+	}{
+		// no options is the same as InferChords
+		{"C E G A", InferenceOptions{}, "C6 A-7 E-4♯5 Gsus2 4 6"},
+		{"C E G A", InferenceOptions{Bass: MustParseNote("A")}, "A-7 C6/A E-4♯5/A Gsus2 4 6/A"},
+		// rootless voicings
+		{"E G B♭ D", InferenceOptions{}, "Eø G-6 B♭6♭5 Dsus2 4♭6"},
+		{"E G B♭ D", InferenceOptions{Roots: []Note{MustParseNote("C")}}, "C9"},
+		{"E G B♭ D", InferenceOptions{Roots: []Note{MustParseNote("C"), MustParseNote("E")}}, "Eø C9"},
+		{"B♭ D E A", InferenceOptions{Roots: []Note{MustParseNote("C")}}, "C9 13"},
+		{"E♭ G B♭ D", InferenceOptions{Roots: []Note{MustParseNote("C")}}, "C-9"},
+		// shell voicings
+		{"E B♭", InferenceOptions{}, ""},
+		{"E B♭", InferenceOptions{Roots: []Note{MustParseNote("C")}}, "C7"},
+		{"E B♭", InferenceOptions{Roots: []Note{MustParseNote("C")}, Bass: MustParseNote("E")}, "C7/E"},
+		// roots from hints are not respelled
+		{"F B", InferenceOptions{Roots: []Note{MustParseNote("C♯")}}, "C♯7"},
+	}
+	for _, tc := range cases {
+		var strs []string
+		for _, cand := range InferChordsWithOptions(parseNotes(t, tc.notes), tc.opts) {
+			strs = append(strs, cand.Chord.String())
+		}
+		if actual := strings.Join(strs, " "); actual != tc.exp {
+			t.Errorf("InferChordsWithOptions for %s, %+v returned wrong value: %s != %s", tc.notes, tc.opts, actual, tc.exp)
+		}
+	}
+
+	// with Rootless, every pitch class is a possible root, but the chords
+	// with roots among the notes rank higher
+	cands := InferChordsWithOptions(parseNotes(t, "E G B♭ D"), InferenceOptions{Rootless: true})
+	if len(cands) != 12 {
+		t.Errorf("InferChordsWithOptions for rootless returned wrong number of candidates: %d != 12", len(cands))
+	}
+	found := false
+	for i, cand := range cands {
+		if cand.Chord.String() == "C9" {
+			found = true
+			if i < 3 {
+				t.Errorf("InferChordsWithOptions for rootless returned wrong rank for C9: %d", i)
+			}
+		}
+	}
+	if !found {
+		t.Errorf("InferChordsWithOptions for rootless did not return C9")
+	}
+	cands = InferChordsWithOptions(parseNotes(t, "E B♭"), InferenceOptions{Rootless: true})
+	if len(cands) == 0 || cands[0].Chord.String() != "C7" {
+		t.Errorf("InferChordsWithOptions for rootless E B♭ returned wrong value: %v", cands)
+	}
+}
+
+func TestInferChordOverBass(t *testing.T) {
+	cases := []struct {
+		bass, notes string
+		exp         string
+	}{
+		{"C", "E G", "[C E-♯5/C Gsus4 6/C]"},
+		{"E", "G C", "[C/E E-♯5 Gsus4 6/E]"},
+		{"C", "E G A", "[C6 A-7/C E-4♯5/C Gsus2 4 6/C]"},
+		{"A", "C E G", "[A-7 C6/A E-4♯5/A Gsus2 4 6/A]"},
+		{"B", "G D F", "[G7/B Bdim♭6 D-4 6/B Fsus2 6♭5/B]"},
+		// the bass need not be among the other notes
+		{"G", "C E", "[C/G E-♯5/G Gsus4 6]"},
+		{"C", "E", "[]"},
+	}
+	for _, tc := range cases {
+		bass := parseNotes(t, tc.bass)[0]
+		chs := InferChordOverBass(bass, parseNotes(t, tc.notes)...)
+		if actual := fmt.Sprint(chs); actual != tc.exp {
+			t.Errorf("InferChordOverBass for %s/%s returned wrong value: %s != %s", tc.notes, tc.bass, actual, tc.exp)
+		}
+	}
+
+	// with no bass, it's the same as InferChord
+	chs := InferChordOverBass(Note{}, parseNotes(t, "E G C")...)
+	if len(chs) == 0 || chs[0].String() != "C" {
+		t.Errorf("InferChordOverBass for no bass returned wrong value: %v", chs)
+	}
+}
+
+func TestInferChordFromMIDI(t *testing.T) {
+	cases := []struct {
+		notes []int
+		exp   string
+	}{
+		{[]int{60, 64, 67}, "[C(0) E-♯5/C(3) Gsus4 6/C(5)]"},
+		{[]int{64, 67, 72}, "[C/E(1) E-♯5(2) Gsus4 6/E(5)]"},
+		// octave doublings and order don't matter
+		{[]int{76, 52, 67, 60, 72}, "[C/E(1) E-♯5(2) Gsus4 6/E(5)]"},
+		{[]int{48, 60, 64, 67, 69}, "[C6(1) A-7/C(2) E-4♯5/C(4) Gsus2 4 6/C(6)]"},
+		// roots are spelled with the fewest accidentals, and the bass as it
+		// is spelled in the chord
+		{[]int{61, 65, 68}, "[D♭(0) F-♯5/C♯(3) A♭sus4 6/D♭(5)]"},
+		{[]int{59, 63, 66}, "[B(0) E♭-♯5/B(3) F♯sus4 6/B(5)]"},
+		{[]int{56, 60, 63, 66}, "[A♭7(1) Cdim♭6/A♭(4) E♭-4 6/A♭(4) F♯sus2 6♭5/G♯(6)]"},
+		{[]int{56, 61, 64, 71}, "[C♯-7/G♯(2) E6/G♯(2) A♭-4♯5(3) Bsus2 4 6/G♯(6)]"},
+		{[]int{46, 60, 64, 67}, "[C7/B♭(2) Edim♭6/B♭(4) G-4 6/B♭(4) B♭sus2 6♭5(5)]"},
+		{[]int{60, 64, 76}, "[]"},
+		{nil, "[]"},
+	}
+	for _, tc := range cases {
+		var strs []string
+		for _, cand := range InferChordFromMIDI(tc.notes) {
+			strs = append(strs, fmt.Sprintf("%v(%d)", cand.Chord, cand.Score))
+		}
+		if actual := "[" + strings.Join(strs, " ") + "]"; actual != tc.exp {
+			t.Errorf("InferChordFromMIDI for %v returned wrong value: %s != %s", tc.notes, actual, tc.exp)
+		}
+	}
+}
+
+func TestInferChordFromPitches(t *testing.T) {
+	cases := []struct {
+		pitches string
+		exp     string
+	}{
+		{"C4 E4 G4", "[C(0) E-♯5/C(3) Gsus4 6/C(5)]"},
+		{"E4 G4 C5", "[C/E(1) E-♯5(2) Gsus4 6/E(5)]"},
+		// order and doublings don't matter
+		{"C5 G4 E3 C4", "[C/E(1) E-♯5(2) Gsus4 6/E(5)]"},
+		{"A2 C4 E4 G4", "[A-7(1) C6/A(2) E-4♯5/A(4) Gsus2 4 6/A(6)]"},
+		// pitches keep their spelling
+		{"C♯4 E♯4 G♯4", "[C♯(0) E♯-♯5/C♯(3) G♯sus4 6/C♯(5)]"},
+		{"C4 E4", "[]"},
+		{"", "[]"},
+	}
+	for _, tc := range cases {
+		var ps []Pitch
+		for _, f := range strings.Fields(tc.pitches) {
+			ps = append(ps, MustParsePitch(f))
+		}
+		var strs []string
+		for _, cand := range InferChordFromPitches(ps...) {
+			strs = append(strs, fmt.Sprintf("%v(%d)", cand.Chord, cand.Score))
+		}
+		if actual := "[" + strings.Join(strs, " ") + "]"; actual != tc.exp {
+			t.Errorf("InferChordFromPitches for %s returned wrong value: %s != %s", tc.pitches, actual, tc.exp)
+		}
+	}
+}
+
+func TestInferChordFromFrequencies(t *testing.T) {
+	var freqs []float64
+	for _, s := range []string{"E3", "C4", "G4"} {
+		freqs = append(freqs, MustParsePitch(s).Frequency(StandardTuning))
+	}
+	// slightly out of tune
+	freqs[1] *= 1.01
+	freqs[2] /= 1.01
+	// and some noise
+	freqs = append(freqs, 0, -1)
+	cands := InferChordFromFrequencies(freqs, Tuning{})
+	if len(cands) == 0 || cands[0].Chord.String() != "C/E" {
+		t.Errorf("InferChordFromFrequencies returned wrong value: %v", cands)
+	}
+
+	// the same frequencies are a half-step higher in baroque tuning
+	cands = InferChordFromFrequencies(freqs, BaroqueTuning)
+	if len(cands) == 0 || cands[0].Chord.String() != "D♭/F" {
+		t.Errorf("InferChordFromFrequencies for baroque tuning returned wrong value: %v", cands)
+	}
+
+	if cands := InferChordFromFrequencies([]float64{440, 880}, StandardTuning); cands != nil {
+		t.Errorf("InferChordFromFrequencies for two notes returned wrong value: %v", cands)
+	}
+}
+
+func TestChordsWithPitchClasses(t *testing.T) {
+	cases := []struct {
+		chord string
+		exp   string
+	}{
+		{chord: "C6", exp: "[C6 A-7 E-4♯5]"},
+		{chord: "C+", exp: "[C+ E+ A♭+]"},
+		{chord: "Co", exp: "[B♯o D♯o F♯o Ao]"},
+	}
+	for _, tc := range cases {
+		set := MustParseChord(tc.chord).PitchClassSet()
+		if actual := fmt.Sprint(ChordsWithPitchClasses(set)); actual != tc.exp {
+			t.Errorf("ChordsWithPitchClasses for %s returned wrong value: %s != %s", tc.chord, actual, tc.exp)
+		}
+	}
+	// every chord has a 5th, so C and E alone are not a chord
+	if chs := ChordsWithPitchClasses(PitchClassSetOf(Note{N: C}, Note{N: E})); len(chs) != 0 {
+		t.Errorf("ChordsWithPitchClasses for C E returned wrong value: %v", chs)
+	}
+}
+
+func TestChordsContainingPitchClasses(t *testing.T) {
+	set := MustParseChord("C").PitchClassSet()
+	chs := ChordsContainingPitchClasses(set, 1)
+	if len(chs) == 0 || chs[0].String() != "C" {
+		t.Fatalf("ChordsContainingPitchClasses for C returned wrong value: %v", chs)
+	}
+	names := map[string]bool{}
+	for _, ch := range chs {
+		names[ch.String()] = true
+		pcs := ch.PitchClassSet()
+		if !pcs.IsSupersetOf(set) || pcs.Len() > 4 {
+			t.Errorf("%v does not contain %v with at most one other pitch class", ch, set)
+		}
+	}
+	for _, name := range []string{"C7", "C△7", "C6", "A-7", "C2", "Gsus4 6"} {
+		if !names[name] {
+			t.Errorf("ChordsContainingPitchClasses for C did not return %s: %v", name, chs)
+		}
+	}
+	if names["C9"] {
+		t.Errorf("ChordsContainingPitchClasses for C returned C9, with two extra pitch classes: %v", chs)
+	}
+	if more := ChordsContainingPitchClasses(set, 2); len(more) <= len(chs) {
+		t.Errorf("ChordsContainingPitchClasses for two extra pitch classes returned too few chords: %d", len(more))
+	}
+}
+
+func TestInferChords_MessyInput(t *testing.T) {
+	names := func(cands []*ChordCandidate) string {
+		var strs []string
+		for _, cand := range cands {
+			strs = append(strs, cand.Chord.String())
+		}
+		return strings.Join(strs, " ")
+	}
+	n := MustParseNote
+	p := MustParsePitch
+	cases := []struct {
+		name  string
+		cands []*ChordCandidate
+		exp   string
+	}{
+		{
+			name:  "doubled notes",
+			cands: InferChords(n("C"), n("C"), n("E"), n("G"), n("E")),
+			exp:   "C E-♯5 Gsus4 6",
+		},
+		{
+			name:  "zero notes",
+			cands: InferChords(Note{}, n("C"), n("E"), Note{}, n("G")),
+			exp:   "C E-♯5 Gsus4 6",
+		},
+		{
+			name:  "enharmonic notes",
+			cands: InferChords(n("C"), n("E♭"), n("D♯"), n("G")),
+			exp:   "C- E♭6 Gsus4♭6",
+		},
+		{
+			name:  "enharmonic bass",
+			cands: InferChordsWithOptions([]Note{n("B"), n("D♯"), n("F♯")}, InferenceOptions{Bass: n("E♭")}),
+			exp:   "B/D♯ E♭-♯5 F♯sus4 6/D♯",
+		},
+		{
+			name:  "octave doublings",
+			cands: InferChordFromPitches(p("E♭3"), p("G3"), p("B♭3"), p("D♯4"), p("E♭4"), p("G4")),
+			exp:   "E♭ G-♯5/D♯ B♭sus4 6/E♭",
+		},
+	}
+	for _, tc := range cases {
+		if actual := names(tc.cands); actual != tc.exp {
+			t.Errorf("InferChords for %s returned wrong value: %s != %s", tc.name, actual, tc.exp)
+		}
+		for _, cand := range tc.cands {
+			if err := cand.Chord.Validate(); err != nil {
+				t.Errorf("%s: %v is not valid: %v", tc.name, cand.Chord, err)
+			}
+		}
+	}
+}
@@ -0,0 +1,99 @@
+package chords
+
+import "fmt"
+
+// Inversion returns the inversion of the chord, as determined by its bass
+// note, and its label in figured bass. The inversion is 0 for root position,
+// 1 if the bass note is the 3rd (the first inversion), 2 if it is the 5th, 3
+// if it is the 7th, and so on, in the order the chord is spelled (see Spell).
+// So C/E is in first inversion and C7/B♭ is in third inversion. A chord with
+// no bass note, or whose bass note is its root, is in root position.
+//
+// Figures are given for triads and seventh chords: "" (root position), "6",
+// and "6/4" for triads, and "7", "6/5", "4/3", and "4/2" for seventh chords.
+// Other chords, like suspended chords and chords with extensions, have no
+// figure, so the figure is the empty string.
+//
+// If the bass note is not one of the chord's tones, like C/F♯, the chord is
+// not an inversion, so the result is -1 and the empty string. Notes are
+// compared by pitch class, so C/F♭ is the same as C/E.
+func (ch *Chord) Inversion() (n int, figure string) {
+	upper := ch.Clone()
+	upper.Bass = Note{}
+	upper.Canonicalize()
+	notes := upper.Spell()
+	n = -1
+	if ch.Bass.N == 0 {
+		n = 0
+	} else {
+		bass := PitchClass(ch.Bass)
+		for i, note := range notes {
+			if PitchClass(note) == bass {
+				n = i
+				break
+			}
+		}
+	}
+	if n < 0 || upper.Triad == Sus {
+		return n, ""
+	}
+	switch {
+	case len(notes) == 3:
+		return n, [...]string{"", "6", "6/4"}[n]
+	case len(notes) == 4 && upper.decompose().has(7):
+		return n, [...]string{"7", "6/5", "4/3", "4/2"}[n]
+	default:
+		return n, ""
+	}
+}
+
+// Invert returns a new chord that is this chord in the given inversion, with
+// the corresponding chord tone as its bass note. (See Inversion.) So the first
+// inversion of C is C/E, and the third inversion of G7 is G7/F. The zeroth
+// inversion is root position, so the result has no bass note. The bass note is
+// spelled as it is in the chord (see Spell). This returns an error if the
+// chord has no tone for the given inversion, like the third inversion of a
+// triad. This chord is not modified.
+func (ch *Chord) Invert(n int) (*Chord, error) {
+	upper := ch.Clone()
+	upper.Bass = Note{}
+	if n == 0 {
+		return upper, nil
+	}
+	notes := upper.Canonical().Spell()
+	if n < 0 || n >= len(notes) {
+		return nil, fmt.Errorf("invalid inversion %d for %v, which has %d notes", n, upper, len(notes))
+	}
+	return upper.WithBass(notes[n]), nil
+}
+
+// Reinterpret returns other ways to describe a slash chord whose bass note is
+// not one of its tones, ranked from the most plausible to the least. The notes
+// of the chord, including the bass, are described as with InferChordOverBass,
+// and readings with the chord's own root are left out. So C/A is read as A-7,
+// since a bass note is usually heard as the root, and F/G is read as Gsus4 9.
+// This returns nil if the chord has no bass note or if its bass note is one of
+// its tones, since it is then an inversion (see Inversion).
+func (ch *Chord) Reinterpret() []*ChordCandidate {
+	if ch.Bass.N == 0 {
+		return nil
+	}
+	upper := ch.Clone()
+	upper.Bass = Note{}
+	upper.Canonicalize()
+	notes := upper.Spell()
+	bass := PitchClass(ch.Bass)
+	for _, n := range notes {
+		if PitchClass(n) == bass {
+			return nil
+		}
+	}
+	root := PitchClass(ch.Root)
+	var readings []*ChordCandidate
+	for _, cand := range inferChords(append([]Note{ch.Bass}, notes...), InferenceOptions{Bass: ch.Bass}) {
+		if PitchClass(cand.Chord.Root) != root {
+			readings = append(readings, cand)
+		}
+	}
+	return readings
+}
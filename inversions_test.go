@@ -0,0 +1,123 @@
+package chords
+
+import "testing"
+
+func TestChord_Inversion(t *testing.T) {
+	cases := []struct {
+		chord  string
+		n      int
+		figure string
+	}{
+		{chord: "C", n: 0, figure: ""},
+		{chord: "C/C", n: 0, figure: ""},
+		{chord: "C/E", n: 1, figure: "6"},
+		{chord: "C-/G", n: 2, figure: "6/4"},
+		{chord: "Bdim/D", n: 1, figure: "6"},
+		{chord: "G7", n: 0, figure: "7"},
+		{chord: "G7/B", n: 1, figure: "6/5"},
+		{chord: "G7/D", n: 2, figure: "4/3"},
+		{chord: "G7/F", n: 3, figure: "4/2"},
+		{chord: "C△7/B", n: 3, figure: "4/2"},
+		{chord: "Bø/A", n: 3, figure: "4/2"},
+		{chord: "Co/E♭", n: 1, figure: "6/5"},
+		{chord: "C/F♭", n: 1, figure: "6"},
+		// no figures for other chords
+		{chord: "C9/D", n: 4, figure: ""},
+		{chord: "C6/A", n: 3, figure: ""},
+		{chord: "Csus4/F", n: 1, figure: ""},
+		// not an inversion
+		{chord: "C/F♯", n: -1, figure: ""},
+		{chord: "C7/A", n: -1, figure: ""},
+	}
+	for _, tc := range cases {
+		n, figure := MustParseChord(tc.chord).Inversion()
+		if n != tc.n || figure != tc.figure {
+			t.Errorf("Chord.Inversion for %s returned wrong value: %d, %q != %d, %q", tc.chord, n, figure, tc.n, tc.figure)
+		}
+	}
+}
+
+func TestChord_Invert(t *testing.T) {
+	cases := []struct {
+		chord string
+		n     int
+		exp   string
+	}{
+		{chord: "C", n: 0, exp: "C"},
+		{chord: "C", n: 1, exp: "C/E"},
+		{chord: "C", n: 2, exp: "C/G"},
+		{chord: "C/G", n: 0, exp: "C"},
+		{chord: "C/G", n: 1, exp: "C/E"},
+		{chord: "G7", n: 3, exp: "G7/F"},
+		{chord: "Bø", n: 2, exp: "Bø/F"},
+		{chord: "Co", n: 3, exp: "Co/B𝄫"},
+		{chord: "C9", n: 4, exp: "C9/D"},
+		{chord: "Csus4", n: 1, exp: "Csus4/F"},
+		{chord: "F♯-", n: 1, exp: "F♯-/A"},
+	}
+	for _, tc := range cases {
+		ch := MustParseChord(tc.chord)
+		actual, err := ch.Invert(tc.n)
+		if err != nil {
+			t.Errorf("Chord.Invert for %s, %d failed: %v", tc.chord, tc.n, err)
+			continue
+		}
+		if actual.String() != tc.exp {
+			t.Errorf("Chord.Invert for %s, %d returned wrong value: %v != %s", tc.chord, tc.n, actual, tc.exp)
+		}
+		if n, _ := actual.Inversion(); n != tc.n {
+			t.Errorf("%s, %d: result %v is in inversion %d", tc.chord, tc.n, actual, n)
+		}
+		if ch.String() != tc.chord {
+			t.Errorf("%s, %d: chord was modified: %v", tc.chord, tc.n, ch)
+		}
+	}
+
+	for _, n := range []int{-1, 3} {
+		if actual, err := MustParseChord("C").Invert(n); err == nil {
+			t.Errorf("Chord.Invert(%d) for C should have failed: %v", n, actual)
+		}
+	}
+}
+
+func TestChord_Reinterpret(t *testing.T) {
+	cases := []struct {
+		chord string
+		best  string
+	}{
+		{chord: "C/A", best: "A-7"},
+		{chord: "F/G", best: "Gsus4 9"},
+		{chord: "B♭/C", best: "Csus4 9"},
+		{chord: "E/C", best: "C+△7"},
+		{chord: "C-/F", best: "Fsus2 7"},
+	}
+	for _, tc := range cases {
+		ch := MustParseChord(tc.chord)
+		readings := ch.Reinterpret()
+		if len(readings) == 0 {
+			t.Errorf("Chord.Reinterpret for %s returned no readings", tc.chord)
+			continue
+		}
+		if actual := readings[0].Chord.String(); actual != tc.best {
+			t.Errorf("Chord.Reinterpret for %s returned wrong best reading: %s != %s", tc.chord, actual, tc.best)
+		}
+		for i, r := range readings {
+			if PitchClass(r.Chord.Root) == PitchClass(ch.Root) {
+				t.Errorf("%s: reading %v has the same root", tc.chord, r.Chord)
+			}
+			// readings with no 5th are still spelled with one
+			if !ch.PitchClassSet().IsSubsetOf(r.Chord.PitchClassSet()) {
+				t.Errorf("%s: reading %v is missing notes", tc.chord, r.Chord)
+			}
+			if i > 0 && r.Score < readings[i-1].Score {
+				t.Errorf("%s: readings are not ranked: %v before %v", tc.chord, readings[i-1].Chord, r.Chord)
+			}
+		}
+	}
+
+	for _, s := range []string{"C", "C/E", "G7/F", "C/F♭"} {
+		if readings := MustParseChord(s).Reinterpret(); readings != nil {
+			t.Errorf("Chord.Reinterpret for %s returned wrong value: %v", s, readings)
+		}
+	}
+}
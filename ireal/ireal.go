@@ -0,0 +1,818 @@
+// Package ireal reads and writes chord charts in the iReal Pro format. iReal Pro
+// shares charts as URLs with an "irealb://" scheme. A URL contains one or more
+// songs, and an optional playlist name, each of which is separated by "===".
+// Each song is a sequence of fields separated by "=": its title, composer,
+// style, key, and chart, followed by playback settings.
+//
+// The chart is obfuscated, and it uses its own notation for bar lines, repeats,
+// sections, and chord symbols. For example, the chord symbols use '^' for a
+// major 7th, 'h' for half-diminished, and 'o' for diminished. This package
+// translates charts to and from the chords.Song model.
+package ireal
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/jhump/chords"
+)
+
+const (
+	// urlScheme is the prefix of iReal Pro URLs.
+	urlScheme = "irealb://"
+	// musicPrefix is the prefix of an obfuscated chart.
+	musicPrefix = "1r34LbKcu7"
+)
+
+// Playlist is a collection of songs from an iReal Pro URL.
+type Playlist struct {
+	// Name is the name of the playlist. It is empty if the URL has only one
+	// song and no name.
+	Name string
+	// Songs are the songs in the playlist.
+	Songs []*chords.Song
+}
+
+// Parse parses the given iReal Pro URL, which must start with "irealb://".
+// The older "irealbook://" format is not supported.
+//
+// For each song, the title, composer, style, key, and tempo populate the
+// corresponding fields of the song's metadata. The iReal Pro playback style
+// and repeat count are stored in the metadata's extra fields, named "playback"
+// and "repeats". Since fields are separated by "=", an "=" in the text of a
+// field is escaped as "%3D" (and a "%" as "%25") before the URL is encoded.
+//
+// The sections of each chart (marked with "*A", "*B", "*i" for the intro, and
+// so on) become sections of the song. Repeats ("{" and "}") become repeated
+// bars in the section's progression or, if they have numbered endings ("N1",
+// "N2"), a repeated section with endings. The beats in each bar are divided
+// evenly among its chords, except that a 'p' (a slash) continues the previous
+// chord. Markings that do not affect the chords, like comments, codas, and
+// fermatas, are ignored, as are "n" (no chord) and alternate chords (which
+// are written in parentheses). Text that directly follows a chord symbol but
+// is not part of iReal Pro's notation, like the "foo" in "Cfoo", is an error.
+func Parse(u string) (*Playlist, error) {
+	if !strings.HasPrefix(u, urlScheme) {
+		return nil, fmt.Errorf("URL must start with %q", urlScheme)
+	}
+	data, err := url.PathUnescape(u[len(urlScheme):])
+	if err != nil {
+		return nil, err
+	}
+	// Songs are separated by "===", but fields can be empty, so a song's
+	// fields can also contain "===". So the songs are instead found by
+	// looking for their charts, which are followed by three more fields.
+	fields := strings.Split(data, "=")
+	pl := &Playlist{}
+	for len(fields) > 0 {
+		music := -1
+		for i, f := range fields {
+			if strings.HasPrefix(f, musicPrefix) {
+				music = i
+				break
+			}
+		}
+		if music < 0 {
+			if len(pl.Songs) == 0 {
+				return nil, errors.New("URL has no songs")
+			}
+			pl.Name = unescapeField(strings.Join(fields, "="))
+			break
+		}
+		end := music + 4
+		if end > len(fields) {
+			end = len(fields)
+		}
+		song, err := parseSong(fields[:end], music)
+		if err != nil {
+			return nil, fmt.Errorf("song %d: %v", len(pl.Songs)+1, err)
+		}
+		pl.Songs = append(pl.Songs, song)
+		// skip the two empty fields of the "===" separator
+		fields = fields[end:]
+		for i := 0; i < 2 && len(fields) > 0 && fields[0] == ""; i++ {
+			fields = fields[1:]
+		}
+	}
+	return pl, nil
+}
+
+// parseSong parses one song from an iReal Pro URL, given its fields and the
+// index of the field with its chart.
+func parseSong(fields []string, music int) (*chords.Song, error) {
+	if music < 5 {
+		return nil, errors.New("song is missing fields")
+	}
+	var meta chords.SongMetadata
+	meta.Title = unescapeField(fields[0])
+	meta.Composer = unescapeField(fields[1])
+	meta.Style = unescapeField(fields[3])
+	if fields[4] != "" {
+		k, err := parseKey(fields[4])
+		if err != nil {
+			return nil, err
+		}
+		meta.Key = k
+	}
+	extra := fields[music+1:]
+	if len(extra) > 0 && extra[0] != "" {
+		meta.Extra = append(meta.Extra, chords.MetadataField{Name: "playback", Value: unescapeField(extra[0])})
+	}
+	if len(extra) > 1 && extra[1] != "" && extra[1] != "0" {
+		tempo, err := strconv.Atoi(extra[1])
+		if err != nil || tempo < 0 {
+			return nil, fmt.Errorf("invalid tempo %q", extra[1])
+		}
+		meta.Tempo = tempo
+	}
+	if len(extra) > 2 && extra[2] != "" && extra[2] != "0" {
+		meta.Extra = append(meta.Extra, chords.MetadataField{Name: "repeats", Value: unescapeField(extra[2])})
+	}
+
+	song, err := parseChart(unscramble(fields[music][len(musicPrefix):]))
+	if err != nil {
+		return nil, err
+	}
+	song.Metadata = meta
+	return song, nil
+}
+
+// fieldEscaper escapes the text of a field, so that it can contain the "="
+// that separates fields. The escaped text is percent-encoded a second time
+// when the URL is formatted.
+var fieldEscaper = strings.NewReplacer("%", "%25", "=", "%3D")
+
+// fieldUnescaper reverses fieldEscaper.
+var fieldUnescaper = strings.NewReplacer("%25", "%", "%3D", "=")
+
+// escapeField escapes the given text for use as a field.
+func escapeField(s string) string {
+	return fieldEscaper.Replace(s)
+}
+
+// unescapeField returns the text of the given field.
+func unescapeField(s string) string {
+	return fieldUnescaper.Replace(s)
+}
+
+// parseKey parses a key in iReal Pro notation, like "Bb" or "F#-".
+func parseKey(s string) (chords.Key, error) {
+	k, err := chords.ParseKey(s)
+	if err != nil {
+		return chords.Key{}, fmt.Errorf("invalid key %q: %v", s, err)
+	}
+	return k, nil
+}
+
+// formatKey formats a key in iReal Pro notation.
+func formatKey(k chords.Key) string {
+	s := noteText(k.Tonic)
+	if k.Minor {
+		s += "-"
+	}
+	return s
+}
+
+// unscramble reverses the obfuscation of an iReal Pro chart. The chart is
+// obfuscated in 50-character chunks (except for the last 2 to 51 characters),
+// and some common sequences are replaced with codes.
+func unscramble(s string) string {
+	var b bytes.Buffer
+	for len(s) > 51 {
+		b.WriteString(obfuscate50(s[:50]))
+		s = s[50:]
+	}
+	b.WriteString(s)
+	return strings.NewReplacer("Kcl", "| x", "LZ", " |", "XyQ", "   ").Replace(b.String())
+}
+
+// scramble obfuscates an iReal Pro chart. (See unscramble.)
+func scramble(s string) string {
+	var b bytes.Buffer
+	for len(s) > 51 {
+		b.WriteString(obfuscate50(s[:50]))
+		s = s[50:]
+	}
+	b.WriteString(s)
+	return b.String()
+}
+
+// obfuscate50 swaps characters in the given 50-character chunk. This is its
+// own inverse.
+func obfuscate50(s string) string {
+	b := []byte(s)
+	for i := 0; i < 5; i++ {
+		b[i], b[49-i] = s[49-i], s[i]
+	}
+	for i := 10; i < 24; i++ {
+		b[i], b[49-i] = s[49-i], s[i]
+	}
+	return string(b)
+}
+
+// sectionNames maps iReal Pro section markers to section names.
+var sectionNames = map[byte]string{
+	'A': "A", 'B': "B", 'C': "C", 'D': "D", 'i': "Intro", 'V': "Verse",
+}
+
+// chordRegex matches a chord symbol in an iReal Pro chart.
+var chordRegex = regexp.MustCompile(`^[A-G][b#]?(?:[0-9^+\-hob#]|sus|alt|add)*(?:/[A-G][b#]?)?`)
+
+// chartParser holds the state for parsing an iReal Pro chart.
+type chartParser struct {
+	song  *chords.Song
+	beats int
+	// the current section and the index of the bar in its body where the
+	// current repeat started
+	sect        *chords.Section
+	repeatStart int
+	// the ending being parsed (1-based), or zero if not in an ending
+	ending int
+	// the chords of the current bar, with "p" tokens for slashes
+	bar []string
+	// the previous two bars, for "x" and "r" symbols
+	prevBars []chords.Bar
+	// whether the next bar starts a repeat
+	startRepeat bool
+}
+
+// parseChart parses an (unscrambled) iReal Pro chart into a song.
+func parseChart(s string) (*chords.Song, error) {
+	p := &chartParser{song: &chords.Song{}, beats: 4}
+	for len(s) > 0 {
+		c := s[0]
+		switch {
+		case c == 'T' && len(s) >= 3:
+			num, den := s[1]-'0', s[2]-'0'
+			if num > 9 || den > 9 {
+				return nil, fmt.Errorf("invalid time signature %q", s[:3])
+			}
+			if num == 1 && den == 2 {
+				// T12 is 12/8
+				p.beats = 12
+			} else {
+				p.beats = int(num)
+			}
+			s = s[3:]
+			continue
+		case c == '*' && len(s) >= 2:
+			if err := p.endBar(); err != nil {
+				return nil, err
+			}
+			p.startSection(sectionNames[s[1]])
+			s = s[2:]
+			continue
+		case c == 'N' && len(s) >= 2 && s[1] >= '0' && s[1] <= '9':
+			if err := p.endBar(); err != nil {
+				return nil, err
+			}
+			if err := p.startEnding(int(s[1] - '0')); err != nil {
+				return nil, err
+			}
+			s = s[2:]
+			continue
+		case c == '<':
+			end := strings.IndexByte(s, '>')
+			if end < 0 {
+				return nil, errors.New("comment is missing closing '>'")
+			}
+			s = s[end+1:]
+			continue
+		case c == '(':
+			end := strings.IndexByte(s, ')')
+			if end < 0 {
+				return nil, errors.New("alternate chord is missing closing ')'")
+			}
+			s = s[end+1:]
+			continue
+		case c == '|' || c == '[' || c == ']' || c == 'Z':
+			if err := p.endBar(); err != nil {
+				return nil, err
+			}
+			if c == ']' || c == 'Z' {
+				p.endEnding()
+			}
+		case c == '{':
+			if err := p.endBar(); err != nil {
+				return nil, err
+			}
+			p.startRepeat = true
+		case c == '}':
+			if err := p.endBar(); err != nil {
+				return nil, err
+			}
+			p.endRepeat()
+		case c == 'x':
+			p.repeatBars(1)
+		case c == 'r':
+			p.repeatBars(2)
+		case c == 'p':
+			p.bar = append(p.bar, "p")
+		default:
+			if m := chordRegex.FindString(s); m != "" {
+				if end := chordTextEnd(s, len(m)); end > len(m) {
+					return nil, fmt.Errorf("invalid chord %q", s[:end])
+				}
+				p.bar = append(p.bar, m)
+				s = s[len(m):]
+				continue
+			}
+			// other symbols, like spaces, commas, segno, coda, and fermata,
+			// do not affect the chords
+		}
+		s = s[1:]
+	}
+	if err := p.endBar(); err != nil {
+		return nil, err
+	}
+	p.finishSection()
+	return p.song, nil
+}
+
+// chordTextEnd returns the end of the text that starts at the given position
+// and continues the chord symbol before it. Text like "Cfoo" or "Cdim9",
+// where the chord's quality isn't one iReal Pro uses, is an error rather
+// than a "C" followed by other symbols. Other chart symbols that can follow
+// a chord directly are either punctuation, upper case letters, or 'p'.
+func chordTextEnd(s string, pos int) int {
+	for pos < len(s) {
+		c := s[pos]
+		if (c < 'a' || c > 'z' || c == 'p') && (c < '0' || c > '9') && !strings.ContainsRune("^+-#/", rune(c)) {
+			break
+		}
+		pos++
+	}
+	return pos
+}
+
+// startSection starts a new section with the given name.
+func (p *chartParser) startSection(name string) {
+	p.finishSection()
+	p.sect = &chords.Section{Name: name, Body: &chords.Progression{BeatsPerBar: p.beats}}
+	p.repeatStart = 0
+	p.ending = 0
+}
+
+// finishSection adds the current section to the song, if it has any bars. If
+// the whole section is repeated, the repeat is expressed with the section's
+// Repeat field instead of with repeat marks on its bars.
+func (p *chartParser) finishSection() {
+	sect := p.sect
+	p.sect = nil
+	if sect == nil || (len(sect.Body.Bars) == 0 && len(sect.Endings) == 0) {
+		return
+	}
+	bars := sect.Body.Bars
+	if len(sect.Endings) == 0 && bars[0].RepeatStart && bars[len(bars)-1].RepeatCount > 0 {
+		whole := true
+		for i, bar := range bars {
+			if (i > 0 && bar.RepeatStart) || (i < len(bars)-1 && bar.RepeatCount > 0) {
+				whole = false
+				break
+			}
+		}
+		if whole {
+			sect.Repeat = bars[len(bars)-1].RepeatCount
+			bars[0].RepeatStart = false
+			bars[len(bars)-1].RepeatCount = 0
+		}
+	}
+	p.song.Sections = append(p.song.Sections, sect)
+}
+
+// progression returns the progression to which bars are currently added.
+func (p *chartParser) progression() *chords.Progression {
+	if p.sect == nil {
+		p.startSection("")
+	}
+	if p.ending > 0 {
+		return p.sect.Endings[p.ending-1]
+	}
+	return p.sect.Body
+}
+
+// startEnding starts the given numbered ending of the current repeat.
+func (p *chartParser) startEnding(n int) error {
+	if p.sect == nil || n != len(p.sect.Endings)+1 {
+		return fmt.Errorf("ending %d is out of order", n)
+	}
+	if n == 1 {
+		body := p.sect.Body
+		if p.repeatStart > 0 {
+			// the bars before the repeat are not repeated, so they go into
+			// their own section
+			before := &chords.Section{Name: p.sect.Name, Body: &chords.Progression{
+				BeatsPerBar: body.BeatsPerBar,
+				Bars:        body.Bars[:p.repeatStart],
+			}}
+			p.song.Sections = append(p.song.Sections, before)
+			body.Bars = body.Bars[p.repeatStart:]
+			p.repeatStart = 0
+		}
+		if len(body.Bars) > 0 {
+			// the repeat is expressed by the section's endings instead
+			body.Bars[0].RepeatStart = false
+		}
+	}
+	p.sect.Endings = append(p.sect.Endings, &chords.Progression{BeatsPerBar: p.beats})
+	p.ending = n
+	return nil
+}
+
+// endEnding ends the current section's endings, if the last ending is being
+// parsed. Any bars that follow go into a new section.
+func (p *chartParser) endEnding() {
+	if p.ending > 0 && p.ending == len(p.sect.Endings) && p.ending > 1 {
+		name := p.sect.Name
+		p.startSection(name)
+	}
+}
+
+// endRepeat ends the current repeat.
+func (p *chartParser) endRepeat() {
+	prog := p.progression()
+	if p.ending > 0 {
+		// the end of an ending that repeats back to the start of the section
+		return
+	}
+	if len(prog.Bars) > 0 {
+		prog.Bars[len(prog.Bars)-1].RepeatCount = 2
+	}
+}
+
+// repeatBars adds copies of the previous n bars.
+func (p *chartParser) repeatBars(n int) {
+	if len(p.prevBars) < n {
+		return
+	}
+	prog := p.progression()
+	for _, bar := range p.prevBars[len(p.prevBars)-n:] {
+		bar = chords.Bar{Chords: bar.Chords}
+		prog.Bars = append(prog.Bars, bar)
+		p.prevBars = append(p.prevBars, bar)
+	}
+	p.prevBars = p.prevBars[len(p.prevBars)-2:]
+	// the repeated bars take the place of the current bar, so it must not be
+	// added again when the bar line is reached
+	p.bar = nil
+}
+
+// endBar adds the current bar, if it has any chords, to the current
+// progression.
+func (p *chartParser) endBar() error {
+	if len(p.bar) == 0 {
+		return nil
+	}
+	tokens := p.bar
+	p.bar = nil
+	var bar chords.Bar
+	for i, tok := range tokens {
+		n := p.beats / len(tokens)
+		if i < p.beats%len(tokens) {
+			n++
+		}
+		if tok == "p" {
+			if len(bar.Chords) > 0 {
+				bar.Chords[len(bar.Chords)-1].Beats += n
+			} else if len(p.prevBars) > 0 {
+				prev := p.prevBars[len(p.prevBars)-1].Chords
+				bar.Chords = append(bar.Chords, chords.BarChord{Chord: prev[len(prev)-1].Chord, Beats: n})
+			}
+			continue
+		}
+		ch, err := parseChord(tok)
+		if err != nil {
+			return err
+		}
+		bar.Chords = append(bar.Chords, chords.BarChord{Chord: ch, Beats: n})
+	}
+	if len(bar.Chords) == 0 {
+		return nil
+	}
+	prog := p.progression()
+	if p.startRepeat {
+		bar.RepeatStart = true
+		p.startRepeat = false
+		if p.ending == 0 {
+			p.repeatStart = len(prog.Bars)
+		}
+	}
+	prog.Bars = append(prog.Bars, bar)
+	p.prevBars = append(p.prevBars, bar)
+	if len(p.prevBars) > 2 {
+		p.prevBars = p.prevBars[1:]
+	}
+	return nil
+}
+
+// susRegex matches dominant suspended chords whose alterations are written
+// before the "sus", like "7b9sus".
+var susRegex = regexp.MustCompile(`^(7|9|11|13)((?:[b#][0-9]+)+)sus$`)
+
+// parseChord parses a chord in iReal Pro notation. The notation is translated
+// to the notation accepted by chords.ParseLegacyChord.
+func parseChord(s string) (*chords.Chord, error) {
+	root, bass := s, ""
+	if pos := strings.IndexByte(s, '/'); pos >= 0 {
+		root, bass = s[:pos], s[pos:]
+	}
+	n := 1
+	if len(root) > 1 && (root[1] == 'b' || root[1] == '#') {
+		n = 2
+	}
+	quality := root[n:]
+	// an 11th or 13th without a 9th is written with "add" (see formatChord)
+	var added []chords.ChordTone
+	for _, ext := range []int8{13, 11} {
+		suffix := "add" + strconv.Itoa(int(ext))
+		if strings.HasSuffix(quality, suffix) && strings.Contains(quality[:len(quality)-len(suffix)], "7") {
+			quality = quality[:len(quality)-len(suffix)]
+			added = append(added, chords.ChordTone{Val: ext})
+		}
+	}
+	quality = susRegex.ReplaceAllString(quality, "${1}sus$2")
+	switch {
+	case quality == "^" || quality == "-^":
+		quality += "7"
+	case strings.HasPrefix(quality, "o"):
+		// "o" is a diminished triad, and "o7" is a diminished 7th chord
+		quality = "dim" + quality[1:]
+	case quality == "h":
+		quality = "h7"
+	}
+	quality = strings.Replace(quality, "^", "△", 1)
+	ch, err := chords.ParseLegacyChord(root[:n] + quality + bass)
+	if err == nil {
+		for _, tn := range added {
+			ch = ch.With(tn)
+		}
+		err = ch.Validate()
+	}
+	if err != nil {
+		return nil, fmt.Errorf("invalid chord %q: %v", s, err)
+	}
+	ch.Canonicalize()
+	return ch, nil
+}
+
+// URL formats the playlist as an iReal Pro URL. (See Parse.)
+//
+// iReal Pro only supports a fixed set of chord qualities. Chords are written
+// using the iReal Pro notation for their canonical form, like "C^7" for C△7 and
+// "Ch7" for Cø, but chords that have no equivalent in iReal Pro's set of
+// qualities are written anyway and may not be displayed correctly. They can
+// still be read back with Parse.
+//
+// Only sections named "A", "B", "C", "D", "Intro", and "Verse" have section
+// markers in the chart. If the song's form indicates that a section is played
+// more than once, it is written once for each time it is played.
+func (pl *Playlist) URL() string {
+	parts := make([]string, 0, len(pl.Songs)+1)
+	for _, song := range pl.Songs {
+		parts = append(parts, formatSong(song))
+	}
+	if pl.Name != "" || len(parts) > 1 {
+		parts = append(parts, escapeField(pl.Name))
+	}
+	return urlScheme + url.PathEscape(strings.Join(parts, "==="))
+}
+
+// SongURL formats the given song as an iReal Pro URL. (See Playlist.URL.)
+func SongURL(song *chords.Song) string {
+	return (&Playlist{Songs: []*chords.Song{song}}).URL()
+}
+
+// formatSong formats one song for an iReal Pro URL.
+func formatSong(song *chords.Song) string {
+	meta := &song.Metadata
+	key := ""
+	if meta.Key.Tonic.N != 0 {
+		key = formatKey(meta.Key)
+	}
+	playback, repeats := "", "0"
+	for _, f := range meta.Extra {
+		switch f.Name {
+		case "playback":
+			playback = f.Value
+		case "repeats":
+			repeats = f.Value
+		}
+	}
+	fields := []string{
+		escapeField(meta.Title), escapeField(meta.Composer), "", escapeField(meta.Style), key, "",
+		musicPrefix + scramble(formatChart(song)),
+		escapeField(playback), strconv.Itoa(meta.Tempo), escapeField(repeats),
+	}
+	return strings.Join(fields, "=")
+}
+
+// sectionMarkers maps section names to iReal Pro section markers.
+var sectionMarkers = map[string]string{
+	"A": "*A", "B": "*B", "C": "*C", "D": "*D", "Intro": "*i", "Verse": "*V",
+}
+
+// formatChart formats the song's sections as an iReal Pro chart.
+func formatChart(song *chords.Song) string {
+	sections := song.Sections
+	if len(song.Form) > 0 {
+		sections = nil
+		for _, name := range song.Form {
+			if sect := song.Section(name); sect != nil {
+				sections = append(sections, sect)
+			}
+		}
+	}
+	w := &chartWriter{}
+	for _, sect := range sections {
+		w.writeSection(sect)
+	}
+	s := w.buf.String()
+	if strings.HasSuffix(s, "]") {
+		// the final bar line
+		s = s[:len(s)-1] + "Z"
+	}
+	return s
+}
+
+// chartWriter holds the state for writing an iReal Pro chart.
+type chartWriter struct {
+	buf   bytes.Buffer
+	beats int
+}
+
+// writeSection writes one section. A section that is played twice is written
+// as a repeat. A section that is played more than twice, but has no endings,
+// is written as a repeat followed by copies for the remaining passes.
+func (w *chartWriter) writeSection(sect *chords.Section) {
+	marker := sectionMarkers[sect.Name]
+	passes := sect.Repeat
+	if passes < 1 {
+		passes = 1
+	}
+	switch {
+	case len(sect.Endings) > 0:
+		w.buf.WriteString("{" + marker)
+		w.writeBars(sect.Body)
+		for i, e := range sect.Endings {
+			if i > 0 {
+				w.buf.WriteString("}")
+			}
+			fmt.Fprintf(&w.buf, "|N%d", i+1)
+			w.writeBars(e)
+		}
+		w.buf.WriteString("]")
+	case passes > 1:
+		w.buf.WriteString("{" + marker)
+		w.writeBars(sect.Body)
+		w.buf.WriteString("}")
+		for i := 2; i < passes; i++ {
+			w.buf.WriteString("[" + marker)
+			w.writeBars(sect.Body)
+			w.buf.WriteString("]")
+		}
+	default:
+		w.buf.WriteString("[" + marker)
+		w.writeBars(sect.Body)
+		w.buf.WriteString("]")
+	}
+}
+
+// writeBars writes the bars of the given progression, with bar lines between
+// them. Repeats within the progression are written with braces.
+func (w *chartWriter) writeBars(p *chords.Progression) {
+	beats := p.BeatsPerBar
+	if beats == 0 {
+		beats = 4
+	}
+	if beats != w.beats {
+		if beats == 12 {
+			w.buf.WriteString("T12")
+		} else {
+			fmt.Fprintf(&w.buf, "T%d4", beats)
+		}
+		w.beats = beats
+	}
+	for i, bar := range p.Bars {
+		if bar.RepeatStart {
+			w.buf.WriteString("{")
+		} else if i > 0 && !strings.HasSuffix(w.buf.String(), "}") {
+			w.buf.WriteString("|")
+		}
+		writeBar(&w.buf, bar)
+		if bar.RepeatCount > 0 {
+			w.buf.WriteString("}")
+		}
+	}
+}
+
+// writeBar writes the chords of one bar. Chords are separated by spaces, and
+// chords that last longer than others are followed by slashes ('p').
+func writeBar(b *bytes.Buffer, bar chords.Bar) {
+	unit := 0
+	for _, bc := range bar.Chords {
+		unit = gcd(unit, bc.Beats)
+	}
+	for i, bc := range bar.Chords {
+		if i > 0 {
+			b.WriteByte(' ')
+		}
+		b.WriteString(formatChord(bc.Chord))
+		for n := unit; n < bc.Beats; n += unit {
+			b.WriteString(" p")
+		}
+	}
+}
+
+func gcd(a, b int) int {
+	for b != 0 {
+		a, b = b, a%b
+	}
+	return a
+}
+
+// qualities maps the canonical form of chord qualities (see
+// chords.Chord.String) to their iReal Pro notation, for qualities where the
+// notations differ by more than their symbols.
+var qualities = map[string]string{
+	"△7": "^7", "△9": "^9", "△13": "^13", "-△7": "-^7", "-△9": "-^9",
+	"ø": "h7", "ø9": "h9", "o": "o7",
+	"9 11": "11", "-9 11": "-11", "2": "add9", "2 6": "69", "-2 6": "-69",
+	"+7": "7#5", "+7♯9": "7#9#5", "+7♭9": "7b9#5",
+	"sus4": "sus", "sus4 7": "7sus", "sus4 9": "9sus", "sus4 9 13": "13sus",
+}
+
+// formatChord formats a chord in iReal Pro notation. In iReal Pro, unaltered
+// 11ths and 13ths imply the 9th, so a 7th chord that has one without a 9th is
+// written with "add", like "C7add13" for C13.
+func formatChord(ch *chords.Chord) string {
+	c := ch.Canonical()
+	added := ""
+	if hasToneVal(c, 7) && !hasToneVal(c, 9) && !hasToneVal(c, 2) {
+		for _, ext := range []int8{11, 13} {
+			if tones := removeTone(c.ExtraTones, chords.ChordTone{Val: ext}); len(tones) < len(c.ExtraTones) {
+				c = c.Clone()
+				c.ExtraTones = tones
+				c.Canonicalize()
+				added += "add" + strconv.Itoa(int(ext))
+			}
+		}
+	}
+	str := c.String()
+	bass := ""
+	if c.Bass.N != 0 {
+		pos := strings.LastIndexByte(str, '/')
+		str, bass = str[:pos], "/"+noteText(c.Bass)
+	}
+	quality := strings.TrimPrefix(str, c.Root.String())
+	if q, ok := qualities[quality]; ok {
+		quality = q
+	} else {
+		if strings.HasPrefix(quality, "sus4 ") && hasToneVal(c, 7) {
+			// suspended dominant chords are written like "7b9sus"
+			quality = quality[len("sus4 "):] + "sus"
+		}
+		quality = strings.NewReplacer("dim", "o", "△", "^", "ø", "h", "♭", "b", "♯", "#", " ", "").Replace(quality)
+	}
+	return noteText(c.Root) + quality + added + bass
+}
+
+// hasToneVal returns true if the given chord has an extra tone with the given
+// value, with any accidental.
+func hasToneVal(ch *chords.Chord, val int8) bool {
+	for _, tn := range ch.ExtraTones {
+		if tn.Val == val {
+			return true
+		}
+	}
+	return false
+}
+
+// removeTone returns the given tones without the given one. The given slice
+// is not modified.
+func removeTone(tns []chords.ChordTone, toRemove chords.ChordTone) []chords.ChordTone {
+	var ret []chords.ChordTone
+	for _, tn := range tns {
+		if tn != toRemove {
+			ret = append(ret, tn)
+		}
+	}
+	return ret
+}
+
+// noteText returns the given note with ASCII accidentals.
+func noteText(n chords.Note) string {
+	switch n.Acc {
+	case chords.Sharp:
+		return n.N.String() + "#"
+	case chords.Flat:
+		return n.N.String() + "b"
+	default:
+		// iReal Pro does not support double accidentals
+		return n.N.String()
+	}
+}
@@ -0,0 +1,210 @@
+package ireal
+
+import (
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/jhump/chords"
+)
+
+func makeURL(songs ...string) string {
+	return urlScheme + url.PathEscape(strings.Join(songs, "==="))
+}
+
+func makeSong(fields string, chart string) string {
+	return strings.Replace(fields, "%s", musicPrefix+scramble(chart), 1)
+}
+
+func TestParse(t *testing.T) {
+	u := makeURL(makeSong("Test Song=Doe John==Medium Swing=C==%s=Jazz-Medium Swing=120=3",
+		"{*AT44C^7 |A-7 |D-7 |G7 }*B[F^7 p Fo7 |E-7 A7b9 |Dh7 |G7b9sus Z"))
+	pl, err := Parse(u)
+	if err != nil {
+		t.Fatalf("failed to parse: %v", err)
+	}
+	if pl.Name != "" || len(pl.Songs) != 1 {
+		t.Fatalf("wrong playlist: %+v", pl)
+	}
+	song := pl.Songs[0]
+	meta := song.Metadata
+	if meta.Title != "Test Song" || meta.Composer != "Doe John" || meta.Style != "Medium Swing" ||
+		meta.Key.String() != "C" || meta.Tempo != 120 || len(meta.Extra) != 2 {
+		t.Errorf("wrong metadata: %+v", meta)
+	}
+	if len(song.Sections) != 2 || song.Sections[0].Name != "A" || song.Sections[0].Repeat != 2 {
+		t.Fatalf("wrong sections: %+v", song.Sections)
+	}
+	exp := "| C△7 | A-7 | D-7 | G7 |"
+	if actual := song.Sections[0].Body.String(); actual != exp {
+		t.Errorf("Parse returned wrong body for section A: %q != %q", actual, exp)
+	}
+	exp = "| F△7 / / Fo | E-7 A7♭9 | Dø | Gsus4 7♭9 |"
+	if actual := song.Sections[1].Body.String(); actual != exp {
+		t.Errorf("Parse returned wrong body for section B: %q != %q", actual, exp)
+	}
+
+	// endings, repeated bars, and a playlist name
+	u = makeURL(makeSong("X=Y==Rock=A-==%s=Rock=0=0", "{*AC |F |N1G7 |C }|N2G7 |C ]*BE-7 |A7 |x |r|<fine>(B7)D7 Z"), "My List")
+	pl, err = Parse(u)
+	if err != nil {
+		t.Fatalf("failed to parse: %v", err)
+	}
+	if pl.Name != "My List" || len(pl.Songs) != 1 {
+		t.Fatalf("wrong playlist: %+v", pl)
+	}
+	song = pl.Songs[0]
+	if song.Metadata.Key.String() != "Am" || len(song.Sections) != 2 {
+		t.Fatalf("wrong song: %+v", song)
+	}
+	if sect := song.Sections[0]; sect.Body.String() != "| C | F |" || len(sect.Endings) != 2 ||
+		sect.Endings[1].String() != "| G7 | C |" {
+		t.Errorf("wrong section: %s", sect.Body)
+	}
+	exp = "| E-7 | A7 | A7 | A7 | A7 | D7 |"
+	if actual := song.Sections[1].Body.String(); actual != exp {
+		t.Errorf("Parse returned wrong body for second section: %q != %q", actual, exp)
+	}
+
+	for _, bad := range []string{
+		"irealbook://foo=bar",
+		makeURL("Title=Composer==Style=C==1r34"),
+		makeURL(makeSong("Style=C=%s=Jazz=0=0", "[C Z")),
+		makeURL(makeSong("Title=Composer==Style=H==%s=Jazz=0=0", "[C Z")),
+		makeURL(makeSong("Title=Composer==Style=C==%s=Jazz=0=0", "[C <comment Z")),
+		makeURL(makeSong("Title=Composer==Style=C==%s=Jazz=0=0", "[C |N2G Z")),
+		makeURL(makeSong("Title=Composer==Style=C==%s=Jazz=0=0", "[Cfoo Z")),
+		makeURL(makeSong("Title=Composer==Style=C==%s=Jazz=0=0", "[C7 Cdim9 Z")),
+		makeURL(makeSong("Title=Composer==Style=C==%s=Jazz=0=0", "[C/D7 Z")),
+	} {
+		if _, err := Parse(bad); err == nil {
+			t.Errorf("Parse for %q should have failed", bad)
+		}
+	}
+}
+
+func TestPlaylist_URL(t *testing.T) {
+	song, err := chords.ParseSong(`{title: Blues}
+{key: F}
+{tempo: 140}
+[A] x2
+| F7 | B♭7 | F7 / C-7 F7 | B♭7 | B♭o | F7 / / D7♭9 | G-7 | C7 |
+[B]
+| F△7 | Gø C7 | A-7 D7 | G-7 C7 |
+1. | F6 |
+2. | Csus4 C7 | F |
+`)
+	if err != nil {
+		t.Fatalf("failed to parse song: %v", err)
+	}
+	u := (&Playlist{Songs: []*chords.Song{song, song}, Name: "Blues"}).URL()
+	pl, err := Parse(u)
+	if err != nil {
+		t.Fatalf("failed to parse %q: %v", u, err)
+	}
+	if pl.Name != "Blues" || len(pl.Songs) != 2 {
+		t.Fatalf("wrong playlist: %+v", pl)
+	}
+	actual := pl.Songs[1]
+	if actual.Metadata.Title != "Blues" || actual.Metadata.Key.String() != "F" || actual.Metadata.Tempo != 140 {
+		t.Errorf("wrong metadata: %+v", actual.Metadata)
+	}
+	if len(actual.Sections) != 2 {
+		t.Fatalf("wrong sections: %+v", actual.Sections)
+	}
+	for i, sect := range actual.Sections {
+		if sect.Body.String() != song.Sections[i].Body.String() || sect.Repeat != song.Sections[i].Repeat ||
+			len(sect.Endings) != len(song.Sections[i].Endings) {
+			t.Errorf("section %d does not match: %s", i, sect.Body)
+		}
+	}
+
+	// text fields can contain the "=" that separates fields
+	song.Metadata.Title = "Blues = 12 Bars"
+	song.Metadata.Composer = "100% Doe"
+	pl, err = Parse((&Playlist{Songs: []*chords.Song{song}, Name: "A=B"}).URL())
+	if err != nil {
+		t.Fatalf("failed to parse: %v", err)
+	}
+	if pl.Name != "A=B" || len(pl.Songs) != 1 {
+		t.Fatalf("wrong playlist: %+v", pl)
+	}
+	if meta := pl.Songs[0].Metadata; meta.Title != song.Metadata.Title || meta.Composer != song.Metadata.Composer ||
+		meta.Tempo != 140 {
+		t.Errorf("wrong metadata: %+v", meta)
+	}
+
+	// sus2 has no iReal Pro equivalent, so it is written as-is
+	p := chords.MustParseProgression("| C△7 / Csus2 | B♭-7 E♭7♭9 | D♭o | G+7♭9 |")
+	exp := "[*AT44C^7 p p Csus2|Bb-7 Eb7b9|Dbo7|G7b9#5Z"
+	chart := formatChart(&chords.Song{Sections: []*chords.Section{{Name: "A", Body: p}}})
+	if chart != exp {
+		t.Errorf("formatChart returned wrong value: %q != %q", chart, exp)
+	}
+}
+
+func TestFormatChord(t *testing.T) {
+	// every chord is read back the same way it was written
+	for _, triad := range []string{"", "-", "+", "dim", "sus2", "sus4"} {
+		for _, seventh := range []string{"", "7", "△7"} {
+			for _, ext := range []string{"", "9", "11", "13", "9 11", "11 13", "♭9", "♯9", "♯11", "♭13", "♯11 13", "♭9 13", "2", "4", "6", "6 9"} {
+				for _, bass := range []string{"", "/G"} {
+					ch, err := chords.ParseChord("B♭" + triad + seventh + " " + ext + bass)
+					if err != nil || ch.Validate() != nil {
+						// not every combination is a valid chord symbol
+						continue
+					}
+					str := formatChord(ch)
+					actual, err := parseChord(str)
+					if err != nil {
+						t.Errorf("parseChord(%q) failed for %v: %v", str, ch, err)
+					} else if actual.String() != ch.Canonical().String() {
+						t.Errorf("parseChord(%q) returned wrong value: %v != %v", str, actual, ch.Canonical())
+					}
+				}
+			}
+		}
+	}
+	for _, s := range []string{"Co", "Cø", "Cø9", "Cø11", "Co9", "Co11", "Cdim2", "Cdim♭6"} {
+		ch := chords.MustParseChord(s)
+		str := formatChord(ch)
+		if actual, err := parseChord(str); err != nil {
+			t.Errorf("parseChord(%q) failed for %v: %v", str, ch, err)
+		} else if actual.String() != ch.Canonical().String() {
+			t.Errorf("parseChord(%q) returned wrong value: %v != %v", str, actual, ch.Canonical())
+		}
+	}
+
+	cases := []struct {
+		chord, exp string
+	}{
+		{chord: "C△7", exp: "C^7"},
+		{chord: "C9 11", exp: "C11"},
+		{chord: "C11", exp: "C7add11"},
+		{chord: "C13", exp: "C7add13"},
+		{chord: "C-11", exp: "C-7add11"},
+		{chord: "Csus4 13", exp: "C7susadd13"},
+		{chord: "Cdim", exp: "Co"},
+		{chord: "Co", exp: "Co7"},
+		{chord: "Cdim2", exp: "Co2"},
+		{chord: "Cdim♭6", exp: "Cob6"},
+		{chord: "Cø", exp: "Ch7"},
+		{chord: "Cø11", exp: "Ch7add11"},
+	}
+	for _, tc := range cases {
+		if actual := formatChord(chords.MustParseChord(tc.chord)); actual != tc.exp {
+			t.Errorf("formatChord for %s returned wrong value: %q != %q", tc.chord, actual, tc.exp)
+		}
+	}
+}
+
+func TestObfuscate50(t *testing.T) {
+	s := "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWX"
+	scrambled := obfuscate50(s)
+	if scrambled == s {
+		t.Errorf("obfuscate50 returned its input unchanged")
+	}
+	if actual := obfuscate50(scrambled); actual != s {
+		t.Errorf("obfuscate50 returned wrong value: %q != %q", actual, s)
+	}
+}
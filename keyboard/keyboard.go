@@ -0,0 +1,222 @@
+// Package keyboard draws diagrams of a piano keyboard with some of its keys
+// highlighted, such as the tones of a chord or scale. Diagrams can be drawn as
+// plain text, for terminals, or as SVG images, for web pages:
+//
+//	kb := keyboard.ForChord(chords.MustParseChord("C7"), 4, 4)
+//	fmt.Print(kb.ASCII())
+//
+// The keys of each octave are drawn from C up to B, the same way octaves are
+// numbered for chords.Pitch.
+package keyboard
+
+import (
+	"bytes"
+	"fmt"
+
+	"github.com/jhump/chords"
+)
+
+// Keyboard is a range of piano keys, some of which are highlighted.
+type Keyboard struct {
+	// LowOctave and HighOctave are the first and last octaves shown. Each
+	// octave has the twelve keys from C up to B. Octave 4 starts at middle C.
+	LowOctave, HighOctave int8
+	// Notes are highlighted in every octave. So a C in Notes highlights every
+	// C on the keyboard.
+	Notes []chords.Note
+	// Pitches are highlighted only in their own octave. They can be used to
+	// show a particular voicing of a chord.
+	Pitches []chords.Pitch
+}
+
+// ForChord returns a keyboard, from the given low octave to the given high
+// octave, with the notes of the given chord highlighted. (See
+// chords.Chord.Spell.)
+func ForChord(ch *chords.Chord, lowOctave, highOctave int8) Keyboard {
+	return Keyboard{LowOctave: lowOctave, HighOctave: highOctave, Notes: ch.Spell()}
+}
+
+// ForScale returns a keyboard, from the given low octave to the given high
+// octave, with the notes of the given scale highlighted. (See
+// chords.Scale.Spell.)
+func ForScale(sc *chords.Scale, lowOctave, highOctave int8) Keyboard {
+	return Keyboard{LowOctave: lowOctave, HighOctave: highOctave, Notes: sc.Spell()}
+}
+
+// key is a single key of the keyboard.
+type key struct {
+	midi        int
+	black       bool
+	highlighted bool
+}
+
+// blackKeys are the pitch classes, as half-steps above C, of the black keys.
+var blackKeys = [12]bool{1: true, 3: true, 6: true, 8: true, 10: true}
+
+// keys returns the keys of the keyboard, from lowest to highest.
+func (k Keyboard) keys() []key {
+	classes := map[int]bool{}
+	for _, n := range k.Notes {
+		classes[int(n.Cardinal())] = true
+	}
+	pitches := map[int]bool{}
+	for _, p := range k.Pitches {
+		pitches[p.MIDINumber()] = true
+	}
+	var keys []key
+	for o := int(k.LowOctave); o <= int(k.HighOctave); o++ {
+		for i := 0; i < 12; i++ {
+			midi := (o+1)*12 + i
+			// Note.Cardinal counts half-steps above A, not C
+			pc := (i + 3) % 12
+			keys = append(keys, key{
+				midi:        midi,
+				black:       blackKeys[i],
+				highlighted: classes[pc] || pitches[midi],
+			})
+		}
+	}
+	return keys
+}
+
+// ASCII returns a plain-text drawing of the keyboard. Highlighted keys are
+// marked with an asterisk, and the first key of each octave is labeled with
+// its octave. For example, one octave with the notes of C7 highlighted is:
+//
+//	 ___________________________
+//	|  | | | |  |  | | | | | |  |
+//	|  |#| |#|  |  |#| |#| |*|  |
+//	|  |_| |_|  |  |_| |_| |_|  |
+//	| * |   | * |   | * |   |   |
+//	|___|___|___|___|___|___|___|
+//	 C4
+//
+// This returns the empty string if the keyboard has no keys.
+func (k Keyboard) ASCII() string {
+	keys := k.keys()
+	whites := 0
+	for _, ky := range keys {
+		if !ky.black {
+			whites++
+		}
+	}
+	if whites == 0 {
+		return ""
+	}
+	width := whites*4 + 1
+	rows := make([][]byte, 5)
+	for r := range rows {
+		rows[r] = bytes.Repeat([]byte{' '}, width)
+		for c := 0; c < width; c += 4 {
+			rows[r][c] = '|'
+		}
+	}
+	for c := 1; c < width; c++ {
+		if c%4 != 0 {
+			rows[4][c] = '_'
+		}
+	}
+	labels := bytes.Repeat([]byte{' '}, width)
+
+	white := 0
+	for _, ky := range keys {
+		if ky.black {
+			// black keys straddle the line between two white keys
+			c := white * 4
+			fill := byte('#')
+			if ky.highlighted {
+				fill = '*'
+			}
+			copy(rows[0][c-1:], "| |")
+			copy(rows[1][c-1:], []byte{'|', fill, '|'})
+			copy(rows[2][c-1:], "|_|")
+			continue
+		}
+		c := white*4 + 2
+		if ky.highlighted {
+			rows[3][c] = '*'
+		}
+		if ky.midi%12 == 0 {
+			copy(labels[c-1:], fmt.Sprintf("C%d", ky.midi/12-1))
+		}
+		white++
+	}
+
+	var b bytes.Buffer
+	b.WriteByte(' ')
+	b.Write(bytes.Repeat([]byte{'_'}, width-2))
+	b.WriteByte('\n')
+	for _, row := range rows {
+		b.Write(row)
+		b.WriteByte('\n')
+	}
+	b.Write(bytes.TrimRight(labels, " "))
+	b.WriteByte('\n')
+	return b.String()
+}
+
+// Sizes, in pixels, of the keys in SVG diagrams.
+const (
+	whiteKeyWidth  = 24
+	whiteKeyHeight = 120
+	blackKeyWidth  = 14
+	blackKeyHeight = 75
+)
+
+// highlightColor is the fill color of highlighted keys in SVG diagrams.
+const highlightColor = "#4a90d9"
+
+// SVG returns an SVG image of the keyboard. Highlighted keys are filled with
+// blue. Each white key is 24 pixels wide and 120 pixels tall, and the image is
+// exactly as wide as its keys.
+//
+// This returns the empty string if the keyboard has no keys.
+func (k Keyboard) SVG() string {
+	keys := k.keys()
+	whites := 0
+	for _, ky := range keys {
+		if !ky.black {
+			whites++
+		}
+	}
+	if whites == 0 {
+		return ""
+	}
+	width := whites * whiteKeyWidth
+
+	var b bytes.Buffer
+	fmt.Fprintf(&b, `<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="%d" viewBox="0 0 %d %d">`,
+		width, whiteKeyHeight, width, whiteKeyHeight)
+	b.WriteByte('\n')
+	// white keys are drawn first, so that the black keys are drawn over them
+	white := 0
+	for _, ky := range keys {
+		if ky.black {
+			continue
+		}
+		fill := "white"
+		if ky.highlighted {
+			fill = highlightColor
+		}
+		fmt.Fprintf(&b, `<rect x="%d" y="0" width="%d" height="%d" fill="%s" stroke="black"/>`,
+			white*whiteKeyWidth, whiteKeyWidth, whiteKeyHeight, fill)
+		b.WriteByte('\n')
+		white++
+	}
+	white = 0
+	for _, ky := range keys {
+		if !ky.black {
+			white++
+			continue
+		}
+		fill := "black"
+		if ky.highlighted {
+			fill = highlightColor
+		}
+		fmt.Fprintf(&b, `<rect x="%d" y="0" width="%d" height="%d" fill="%s" stroke="black"/>`,
+			white*whiteKeyWidth-blackKeyWidth/2, blackKeyWidth, blackKeyHeight, fill)
+		b.WriteByte('\n')
+	}
+	b.WriteString("</svg>\n")
+	return b.String()
+}
@@ -0,0 +1,103 @@
+package keyboard
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/jhump/chords"
+)
+
+func TestKeyboard_ASCII(t *testing.T) {
+	cases := []struct {
+		kb       Keyboard
+		expected string
+	}{
+		{
+			kb: ForChord(chords.MustParseChord("C7"), 4, 4),
+			expected: `
+ ___________________________
+|  | | | |  |  | | | | | |  |
+|  |#| |#|  |  |#| |#| |*|  |
+|  |_| |_|  |  |_| |_| |_|  |
+| * |   | * |   | * |   |   |
+|___|___|___|___|___|___|___|
+ C4
+`,
+		},
+		{
+			kb: Keyboard{
+				LowOctave:  3,
+				HighOctave: 4,
+				Pitches:    []chords.Pitch{chords.MustParsePitch("F♯3"), chords.MustParsePitch("E4")},
+			},
+			expected: `
+ _______________________________________________________
+|  | | | |  |  | | | | | |  |  | | | |  |  | | | | | |  |
+|  |#| |#|  |  |*| |#| |#|  |  |#| |#|  |  |#| |#| |#|  |
+|  |_| |_|  |  |_| |_| |_|  |  |_| |_|  |  |_| |_| |_|  |
+|   |   |   |   |   |   |   |   |   | * |   |   |   |   |
+|___|___|___|___|___|___|___|___|___|___|___|___|___|___|
+ C3                          C4
+`,
+		},
+		{
+			kb: ForScale(chords.Key{Tonic: chords.MustParseNote("E♭")}.Scale(), 4, 4),
+			expected: `
+ ___________________________
+|  | | | |  |  | | | | | |  |
+|  |#| |*|  |  |#| |*| |*|  |
+|  |_| |_|  |  |_| |_| |_|  |
+| * | * |   | * | * |   |   |
+|___|___|___|___|___|___|___|
+ C4
+`,
+		},
+		{
+			kb:       Keyboard{LowOctave: 4, HighOctave: 3},
+			expected: "",
+		},
+	}
+	for _, tc := range cases {
+		actual := tc.kb.ASCII()
+		expected := strings.TrimPrefix(tc.expected, "\n")
+		if actual != expected {
+			t.Errorf("Keyboard.ASCII for %+v returned wrong value:\n%s", tc.kb, actual)
+		}
+	}
+}
+
+func TestKeyboard_SVG(t *testing.T) {
+	kb := Keyboard{LowOctave: 4, HighOctave: 4, Notes: []chords.Note{chords.MustParseNote("C"), chords.MustParseNote("C♯")}}
+	actual := kb.SVG()
+	expected := `<svg xmlns="http://www.w3.org/2000/svg" width="168" height="120" viewBox="0 0 168 120">
+<rect x="0" y="0" width="24" height="120" fill="#4a90d9" stroke="black"/>
+<rect x="24" y="0" width="24" height="120" fill="white" stroke="black"/>
+<rect x="48" y="0" width="24" height="120" fill="white" stroke="black"/>
+<rect x="72" y="0" width="24" height="120" fill="white" stroke="black"/>
+<rect x="96" y="0" width="24" height="120" fill="white" stroke="black"/>
+<rect x="120" y="0" width="24" height="120" fill="white" stroke="black"/>
+<rect x="144" y="0" width="24" height="120" fill="white" stroke="black"/>
+<rect x="17" y="0" width="14" height="75" fill="#4a90d9" stroke="black"/>
+<rect x="41" y="0" width="14" height="75" fill="black" stroke="black"/>
+<rect x="89" y="0" width="14" height="75" fill="black" stroke="black"/>
+<rect x="113" y="0" width="14" height="75" fill="black" stroke="black"/>
+<rect x="137" y="0" width="14" height="75" fill="black" stroke="black"/>
+</svg>
+`
+	if actual != expected {
+		t.Errorf("Keyboard.SVG returned wrong value:\n%s", actual)
+	}
+
+	// two octaves are twice as wide
+	kb = ForChord(chords.MustParseChord("G"), 2, 3)
+	if !strings.HasPrefix(kb.SVG(), `<svg xmlns="http://www.w3.org/2000/svg" width="336" `) {
+		t.Errorf("wrong width for two octaves: %s", kb.SVG())
+	}
+	if n := strings.Count(kb.SVG(), highlightColor); n != 6 {
+		t.Errorf("Keyboard.SVG for G returned wrong number of highlighted keys: %d != 6", n)
+	}
+
+	if svg := (Keyboard{LowOctave: 1, HighOctave: 0}).SVG(); svg != "" {
+		t.Errorf("Keyboard.SVG for empty keyboard returned wrong value: %q", svg)
+	}
+}
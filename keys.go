@@ -0,0 +1,241 @@
+package chords
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Key represents a musical key, which is a tonic note and a mode. For
+// example, the key of E♭ major has a tonic of E♭ and the key of F♯ minor
+// has a tonic of F♯.
+type Key struct {
+	// Tonic is the root note of the key. If the tonic is the zero value
+	// (more specifically, if Tonic.N is zero, which isn't valid), the key
+	// is considered not present.
+	Tonic Note
+	// Minor is true for minor keys and false for major keys.
+	Minor bool
+}
+
+// ParseKey parses a key from the given string. The string must start with a
+// note (same syntax as accepted by ParseNote), which is the tonic of the key.
+// The note may be followed by a mode indicator. A minor key is indicated by
+// 'm', 'min', 'minor', or '-'. A major key is indicated by 'maj', 'major', or
+// by omitting the mode indicator altogether. So "F#m", "F# minor", and "F#-"
+// are all F♯ minor; and "Eb", "Ebmaj", and "Eb major" are all E♭ major.
+func ParseKey(s string) (Key, error) {
+	n, rest, err := parseNotePrefix(strings.TrimSpace(s))
+	if err != nil {
+		return Key{}, err
+	}
+	switch strings.ToLower(strings.TrimSpace(rest)) {
+	case "", "maj", "major":
+		return Key{Tonic: n}, nil
+	case "m", "min", "minor", "-":
+		return Key{Tonic: n, Minor: true}, nil
+	default:
+		return Key{}, fmt.Errorf("invalid key mode %q", strings.TrimSpace(rest))
+	}
+}
+
+// MustParseKey parses the given string into a key and panics if the string is
+// not valid. (See ParseKey.)
+func MustParseKey(s string) Key {
+	k, err := ParseKey(s)
+	if err != nil {
+		panic(err)
+	}
+	return k
+}
+
+// String implements the Stringer interface. Major keys are printed as just the
+// tonic note. Minor keys have an 'm' suffix. So the key of F♯ minor is printed
+// as "F♯m".
+func (k Key) String() string {
+	if k.Minor {
+		return k.Tonic.String() + "m"
+	}
+	return k.Tonic.String()
+}
+
+// IsValid returns true if the key's tonic is a valid note.
+func (k Key) IsValid() bool {
+	return k.Tonic.IsValid()
+}
+
+// Scale returns the scale for this key: a major scale for major keys and a
+// natural minor scale for minor keys.
+func (k Key) Scale() *Scale {
+	if k.Minor {
+		return MinorScale.WithRoot(k.Tonic)
+	}
+	return MajorScale.WithRoot(k.Tonic)
+}
+
+// KeySignature returns the key signature for this key.
+func (k Key) KeySignature() KeySignature {
+	return KeySignature(k.fifths())
+}
+
+// KeySignature describes the sharps or flats in a key signature. Positive
+// values are the number of sharps and negative values are the number of flats.
+// So the key signature for E major is 4 and for E♭ major is -3. Values beyond
+// 7 (or -7) are theoretical key signatures, which include double-sharps (or
+// double-flats): G♯ major has a key signature of 8, with an F𝄪.
+type KeySignature int
+
+// Accidental returns Sharp for key signatures with sharps, Flat for those with
+// flats, and Natural for the key signature with neither.
+func (ks KeySignature) Accidental() Accidental {
+	switch {
+	case ks > 0:
+		return Sharp
+	case ks < 0:
+		return Flat
+	default:
+		return Natural
+	}
+}
+
+// Count returns the number of sharps or flats in the key signature.
+func (ks KeySignature) Count() int {
+	return abs(int(ks))
+}
+
+// Notes returns the notes with accidentals in the key signature, in the order
+// they are written. So the notes in the key signature for D major are F♯ and
+// C♯, and for A♭ major are B♭, E♭, A♭, and D♭. For theoretical key
+// signatures, notes may be repeated with a double accidental: the notes in
+// the key signature for G♯ major are F♯, C♯, G♯, D♯, A♯, E♯, B♯, and F𝄪.
+func (ks KeySignature) Notes() []Note {
+	order := [7]NoteName{F, C, G, D, A, E, B}
+	notes := make([]Note, ks.Count())
+	for i := range notes {
+		if ks > 0 {
+			notes[i] = Note{N: order[i%7], Acc: Accidental(1 + i/7)}
+		} else {
+			notes[i] = Note{N: order[6-i%7], Acc: Accidental(-1 - i/7)}
+		}
+	}
+	return notes
+}
+
+// String implements the Stringer interface. Key signatures are rendered as
+// the number of accidentals followed by the accidental, like "3♯" or "2♭".
+// The key signature with no accidentals is "0".
+func (ks KeySignature) String() string {
+	if ks == 0 {
+		return "0"
+	}
+	return fmt.Sprintf("%d%v", ks.Count(), ks.Accidental())
+}
+
+// fifthsByNoteName is the position of each natural note on the circle of
+// fifths, relative to C. Positive values are clockwise (sharp keys) and
+// negative values are counter-clockwise (flat keys).
+var fifthsByNoteName = [...]int{
+	int(A - 'A'): 3,
+	int(B - 'A'): 5,
+	int(C - 'A'): 0,
+	int(D - 'A'): 2,
+	int(E - 'A'): 4,
+	int(F - 'A'): -1,
+	int(G - 'A'): 1,
+}
+
+// fifths returns the number of sharps (if positive) or flats (if negative) in
+// the key signature for this key. Keys with more than seven accidentals, like
+// G♯ major, have theoretical key signatures that include double-sharps or
+// double-flats, so this can return values greater than 7 or less than -7.
+func (k Key) fifths() int {
+	f := fifthsByNoteName[k.Tonic.N-'A'] + 7*int(k.Tonic.Acc.Offset())
+	if k.Minor {
+		f -= 3
+	}
+	return f
+}
+
+// NextFifth returns the next key clockwise on the circle of fifths: the key
+// whose tonic is a perfect fifth above this key's tonic. Its key signature
+// has one more sharp (or one fewer flat). So the next fifth after C major is
+// G major, and the next fifth after F minor is C minor.
+func (k Key) NextFifth() Key {
+	return Key{Tonic: k.Tonic.Transpose(Interval{Val: 5}), Minor: k.Minor}
+}
+
+// PreviousFifth returns the next key counter-clockwise on the circle of
+// fifths: the key whose tonic is a perfect fifth below this key's tonic. Its
+// key signature has one more flat (or one fewer sharp).
+func (k Key) PreviousFifth() Key {
+	return Key{Tonic: k.Tonic.Transpose(Interval{Val: 4}), Minor: k.Minor}
+}
+
+// DistanceInFifths returns the number of steps around the circle of fifths
+// from this key to the given key. The result is positive if the other key is
+// clockwise (sharper) and negative if it is counter-clockwise (flatter). Keys
+// that share a key signature, like C major and A minor, have a distance of
+// zero. Enharmonic keys are not considered the same: the distance from F♯
+// major to G♭ major is -12.
+func (k Key) DistanceInFifths(other Key) int {
+	return other.fifths() - k.fifths()
+}
+
+// Compare orders keys by their key signatures, from the most flats to the
+// most sharps. It returns a negative number if this key comes first, a
+// positive number if the other key comes first, and zero if they are the
+// same. Major keys come before minor keys with the same key signature.
+func (k Key) Compare(other Key) int {
+	if d := k.fifths() - other.fifths(); d != 0 {
+		return d
+	}
+	switch {
+	case k.Minor == other.Minor:
+		return 0
+	case k.Minor:
+		return 1
+	default:
+		return -1
+	}
+}
+
+// SortKeys sorts the given keys by key signature. (See Key.Compare.)
+func SortKeys(keys []Key) {
+	sort.SliceStable(keys, func(i, j int) bool {
+		return keys[i].Compare(keys[j]) < 0
+	})
+}
+
+// CircleOfFifths returns the twelve major (or minor, if minor is true) keys in
+// order around the circle of fifths, starting with C major (or A minor). Each
+// key is spelled with the simpler key signature, except for the key with six
+// accidentals, which is spelled with sharps: F♯ major or D♯ minor.
+func CircleOfFifths(minor bool) []Key {
+	keys := make([]Key, 12)
+	for i := range keys {
+		f := i
+		if f > 6 {
+			f -= 12
+		}
+		keys[i] = keyWithFifths(f, minor)
+	}
+	return keys
+}
+
+// keyWithFifths returns the key whose signature has the given number of
+// sharps (if positive) or flats (if negative).
+func keyWithFifths(f int, minor bool) Key {
+	if minor {
+		f += 3
+	}
+	// F is one step counter-clockwise from C, and the naturals are seven
+	// consecutive positions starting at F
+	pos := f + 1
+	acc := floorDiv(pos, 7)
+	for n, nf := range fifthsByNoteName {
+		if nf+1 == pos-7*acc {
+			return Key{Tonic: Note{N: A + NoteName(n), Acc: Accidental(acc)}, Minor: minor}
+		}
+	}
+	panic("unreachable")
+}
@@ -0,0 +1,73 @@
+package chords
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParseKey(t *testing.T) {
+	cases := map[string]Key{
+		"C":        {Tonic: Note{N: C}},
+		"Eb":       {Tonic: Note{N: E, Acc: Flat}},
+		"Eb major": {Tonic: Note{N: E, Acc: Flat}},
+		"F#m":      {Tonic: Note{N: F, Acc: Sharp}, Minor: true},
+		"Bbm":      {Tonic: Note{N: B, Acc: Flat}, Minor: true},
+		"Bbb":      {Tonic: Note{N: B, Acc: DblFlat}},
+		"G minor":  {Tonic: Note{N: G}, Minor: true},
+		"A-":       {Tonic: Note{N: A}, Minor: true},
+	}
+	for s, exp := range cases {
+		k, err := ParseKey(s)
+		if err != nil {
+			t.Errorf("ParseKey(%q) failed: %v", s, err)
+		} else if k != exp {
+			t.Errorf("ParseKey(%q) returned wrong value: %v", s, k)
+		}
+	}
+	for _, s := range []string{"", "H", "C dorian", "Cq"} {
+		if _, err := ParseKey(s); err == nil {
+			t.Errorf("ParseKey(%q) should have failed", s)
+		}
+	}
+}
+
+func TestCircleOfFifths(t *testing.T) {
+	var names []string
+	for _, k := range CircleOfFifths(false) {
+		names = append(names, k.String())
+	}
+	if strings.Join(names, " ") != "C G D A E B F♯ D♭ A♭ E♭ B♭ F" {
+		t.Errorf("wrong major keys: %v", names)
+	}
+	names = nil
+	for _, k := range CircleOfFifths(true) {
+		names = append(names, k.String())
+	}
+	if strings.Join(names, " ") != "Am Em Bm F♯m C♯m G♯m D♯m B♭m Fm Cm Gm Dm" {
+		t.Errorf("wrong minor keys: %v", names)
+	}
+
+	k := MustParseKey("Bb")
+	if n := k.NextFifth(); n != MustParseKey("F") {
+		t.Errorf("wrong next fifth for %v: %v", k, n)
+	}
+	if n := k.PreviousFifth(); n != MustParseKey("Eb") {
+		t.Errorf("wrong previous fifth for %v: %v", k, n)
+	}
+	distances := map[string]int{"C": 2, "Am": 2, "Gm": 0, "Ab": -2, "F#": 8, "Gb": -4, "C#m": 6}
+	for s, exp := range distances {
+		if d := k.DistanceInFifths(MustParseKey(s)); d != exp {
+			t.Errorf("wrong distance from %v to %s: %d != %d", k, s, d, exp)
+		}
+	}
+
+	keys := []Key{MustParseKey("D"), MustParseKey("Am"), MustParseKey("Eb"), MustParseKey("C"), MustParseKey("F#m")}
+	SortKeys(keys)
+	names = nil
+	for _, k := range keys {
+		names = append(names, k.String())
+	}
+	if strings.Join(names, " ") != "E♭ C Am D F♯m" {
+		t.Errorf("wrong sort order: %v", names)
+	}
+}
@@ -0,0 +1,111 @@
+package chords
+
+import (
+	"errors"
+	"strings"
+)
+
+// legacyReplacements are applied, in order, to the portion of a legacy chord
+// symbol that follows the root note. They translate legacy spellings into the
+// syntax accepted by ParseChord.
+var legacyReplacements = []struct {
+	old, new string
+}{
+	// symbols
+	{"°", "o"}, {"Δ", "△"}, {"ø7", "ø"}, {"h7", "ø"}, {"h", "ø"},
+	// minor-major seventh
+	{"mMaj7", "-maj7"}, {"mmaj7", "-maj7"}, {"mM7", "-maj7"}, {"m/maj7", "-maj7"},
+	{"minMaj7", "-maj7"}, {"-Maj7", "-maj7"}, {"-M7", "-maj7"},
+	// major seventh
+	{"Maj", "maj"}, {"M7", "maj7"}, {"M9", "maj9"}, {"M13", "maj13"},
+	// six-nine
+	{"6/9", "6 2"}, {"69", "6 2"},
+	// added tones (which do not imply a 7th)
+	{"add9", "2"}, {"add2", "2"}, {"add11", "4"}, {"add4", "4"}, {"add13", "6"}, {"add6", "6"},
+	// dominant suspended chords
+	{"13sus4", "sus4 13"}, {"13sus", "sus4 13"},
+	{"9sus4", "sus4 9"}, {"9sus", "sus4 9"},
+	{"7sus4", "sus4 7"}, {"7sus2", "sus2 7"}, {"7sus", "sus4 7"},
+	// altered dominant
+	{"7alt", "7#5#9"}, {"alt", "7#5#9"},
+}
+
+// ParseLegacyChord parses a chord symbol written in the style of legacy
+// chord-chart software, such as Band-in-a-Box and other PG Music products.
+// These symbols are similar to those accepted by ParseChord, but there are
+// extra spellings and some symbols have different meanings:
+//
+//   - 'M7', 'Maj7', and 'Δ7' are major sevenths; 'mM7', 'mMaj7', and 'm/maj7'
+//     are minor chords with a major seventh.
+//   - 'h' and 'h7' are half-diminished; '°' is diminished.
+//   - '69' and '6/9' are a six-nine chord: a 6th and 9th with no 7th.
+//   - 'add9', 'add11', and 'add13' add a tone without implying a 7th.
+//   - 'sus' with no number means 'sus4'. Extensions before the 'sus' (as in
+//     '7sus' or '9sus4') are suspended dominant chords.
+//   - '7alt' is an altered dominant, spelled as 7♯5♯9.
+//   - '7+' and '9+' (with the '+' at the end) have an augmented 5th.
+//   - Unaltered 11ths and 13ths imply the 9th. So 'C13' is C E G B♭ D A,
+//     and 'C11' is C E G B♭ D F. (With ParseChord, 'C13' has no 9th.)
+//   - Parentheses and commas around tones are ignored, as in 'C7(♭9,♯11)'.
+//
+// Power chords ('C5'), which have no 3rd, cannot be represented by Chord, so
+// they result in an error.
+func ParseLegacyChord(s string) (*Chord, error) {
+	s = strings.Map(func(r rune) rune {
+		switch r {
+		case '(', ')', ',', ' ':
+			return -1
+		}
+		return r
+	}, s)
+	root, suffix, err := parseNotePrefix(s)
+	if err != nil {
+		return nil, err
+	}
+	// replace these first since they look like a bass note
+	suffix = strings.Replace(suffix, "m/maj7", "-maj7", -1)
+	suffix = strings.Replace(suffix, "6/9", "6 2", -1)
+	var bass string
+	if pos := strings.LastIndexByte(suffix, '/'); pos >= 0 {
+		suffix, bass = suffix[:pos], suffix[pos:]
+	}
+	if suffix == "5" {
+		return nil, errors.New("power chords (no 3rd) are not supported")
+	}
+	for _, r := range legacyReplacements {
+		suffix = strings.Replace(suffix, r.old, r.new, -1)
+	}
+	if strings.HasSuffix(suffix, "M") {
+		suffix = suffix[:len(suffix)-1]
+	}
+	if len(suffix) > 1 && suffix[len(suffix)-1] == '+' &&
+		suffix[len(suffix)-2] >= '0' && suffix[len(suffix)-2] <= '9' {
+		// augmented fifth written after the extension, like "7+"
+		suffix = suffix[:len(suffix)-1] + "#5"
+	}
+	if strings.HasSuffix(suffix, "sus") {
+		suffix += "4"
+	}
+
+	ch, err := ParseChord(root.String() + suffix + bass)
+	if err != nil {
+		return nil, err
+	}
+
+	// unaltered 11ths and 13ths imply the 9th
+	hasNinth, hasHigher := false, false
+	for _, tn := range ch.ExtraTones {
+		switch tn.Val {
+		case 2, 9:
+			hasNinth = true
+		case 11, 13:
+			if tn.Acc == Natural {
+				hasHigher = true
+			}
+		}
+	}
+	if hasHigher && !hasNinth {
+		ch.ExtraTones = append(ch.ExtraTones, ChordTone{Val: 9})
+	}
+	return ch, nil
+}
@@ -0,0 +1,51 @@
+package chords
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParseLegacyChord(t *testing.T) {
+	cases := map[string]string{
+		"C7b9b13":    "C E G B♭ D♭ A♭",
+		"Cm7b5":      "C E♭ G♭ B♭",
+		"Ch7":        "C E♭ G♭ B♭",
+		"C69":        "C E G A D",
+		"Cm6/9":      "C E♭ G A D",
+		"C13#11#9":   "C E G B♭ D♯ F♯ A",
+		"C13":        "C E G B♭ D A",
+		"C11":        "C E G B♭ D F",
+		"CM7":        "C E G B",
+		"CmM7":       "C E♭ G B",
+		"Cm/maj7":    "C E♭ G B",
+		"C7sus":      "C F G B♭",
+		"C9sus4":     "C F G B♭ D",
+		"Csus":       "C F G",
+		"Cadd9":      "C E G D",
+		"C7+":        "C E G♯ B♭",
+		"C7+9":       "C E G B♭ D♯",
+		"C7alt":      "C E G♯ B♭ D♯",
+		"C7(b9,#11)": "C E G B♭ D♭ F♯",
+		"C°7":        "C E♭ G♭ B𝄫",
+		"C7sus4/Bb":  "B♭ C F G B♭",
+	}
+	for s, exp := range cases {
+		ch, err := ParseLegacyChord(s)
+		if err != nil {
+			t.Errorf("ParseLegacyChord(%q) failed: %v", s, err)
+			continue
+		}
+		ch.Canonicalize()
+		var notes []string
+		for _, n := range ch.Spell() {
+			notes = append(notes, n.String())
+		}
+		if strings.Join(notes, " ") != exp {
+			t.Errorf("ParseLegacyChord(%q) spelled wrong: %v != %s", s, notes, exp)
+		}
+	}
+
+	if _, err := ParseLegacyChord("C5"); err == nil {
+		t.Errorf("ParseLegacyChord should have failed for power chord")
+	}
+}
@@ -0,0 +1,140 @@
+package midi
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math"
+	"sort"
+)
+
+// TicksPerBeat is the resolution of the MIDI files written by WriteFile: the
+// number of ticks (the unit of time in a MIDI file) in each beat.
+const TicksPerBeat = 480
+
+// DefaultTempo is the tempo, in beats per minute, used by WriteFile when the
+// given tempo is zero.
+const DefaultTempo = 120
+
+// WriteFile writes the given note events to the given writer as a standard
+// MIDI file, at the given tempo in beats per minute, so they can be played by
+// any MIDI player or imported into a sequencer. The file has a single track
+// (it is a format 0 file), and note events may be in any order. If the tempo
+// is zero, DefaultTempo is used. Event times are rounded to the nearest tick.
+// (See TicksPerBeat.)
+//
+// This returns an error if any event's note, velocity, or channel is out of
+// range (see NoteEvent), except that a zero velocity means DefaultVelocity.
+// It also returns an error if any event is shorter than a tick, since a
+// player may never stop a note whose start and stop are at the same time, or
+// if the tempo cannot be stored in a MIDI file: it must be at least about 3.6
+// beats per minute.
+func WriteFile(w io.Writer, events []NoteEvent, bpm float64) error {
+	if bpm == 0 {
+		bpm = DefaultTempo
+	}
+	// the tempo is stored in microseconds per beat, in three bytes
+	micros := math.Round(60e6 / bpm)
+	switch {
+	case bpm < 0 || math.IsNaN(bpm):
+		return fmt.Errorf("invalid tempo %v", bpm)
+	case micros > 0xffffff:
+		return fmt.Errorf("invalid tempo %v: must be at least 3.6 beats per minute", bpm)
+	case micros < 1:
+		return fmt.Errorf("invalid tempo %v: too fast", bpm)
+	}
+
+	type message struct {
+		tick int
+		data [3]byte
+	}
+	msgs := make([]message, 0, 2*len(events))
+	for _, e := range events {
+		velocity := e.Velocity
+		if velocity == 0 {
+			velocity = DefaultVelocity
+		}
+		switch {
+		case e.Note < 0 || e.Note > 127:
+			return fmt.Errorf("event %v: note must be from 0 to 127", e)
+		case velocity < 1 || velocity > 127:
+			return fmt.Errorf("event %v: velocity must be from 1 to 127", e)
+		case e.Channel < 0 || e.Channel > 15:
+			return fmt.Errorf("event %v: channel must be from 0 to 15", e)
+		case e.Start < 0:
+			return fmt.Errorf("event %v: start must not be negative", e)
+		}
+		start := int(math.Round(e.Start * TicksPerBeat))
+		end := int(math.Round((e.Start + e.Duration) * TicksPerBeat))
+		if end <= start {
+			return fmt.Errorf("event %v: duration must be at least one tick", e)
+		}
+		msgs = append(msgs,
+			message{tick: start, data: [3]byte{0x90 | byte(e.Channel), byte(e.Note), byte(velocity)}},
+			message{tick: end, data: [3]byte{0x80 | byte(e.Channel), byte(e.Note), 0}},
+		)
+	}
+	// at the same time, notes stop before others start, so a note that is
+	// struck again is not cut off
+	sort.SliceStable(msgs, func(i, j int) bool {
+		if msgs[i].tick != msgs[j].tick {
+			return msgs[i].tick < msgs[j].tick
+		}
+		return msgs[i].data[0]&0xf0 < msgs[j].data[0]&0xf0
+	})
+
+	var track bytes.Buffer
+	// set the tempo, in microseconds per beat
+	tempo := int(micros)
+	track.Write([]byte{0, 0xff, 0x51, 3, byte(tempo >> 16), byte(tempo >> 8), byte(tempo)})
+	tick := 0
+	for _, m := range msgs {
+		writeVarInt(&track, m.tick-tick)
+		track.Write(m.data[:])
+		tick = m.tick
+	}
+	// end of track
+	track.Write([]byte{0, 0xff, 0x2f, 0})
+
+	bw := bufio.NewWriter(w)
+	header := struct {
+		MThd     [4]byte
+		Size     uint32
+		Format   uint16
+		Tracks   uint16
+		Division uint16
+		MTrk     [4]byte
+		Length   uint32
+	}{
+		MThd:     [4]byte{'M', 'T', 'h', 'd'},
+		Size:     6,
+		Format:   0,
+		Tracks:   1,
+		Division: TicksPerBeat,
+		MTrk:     [4]byte{'M', 'T', 'r', 'k'},
+		Length:   uint32(track.Len()),
+	}
+	if err := binary.Write(bw, binary.BigEndian, &header); err != nil {
+		return err
+	}
+	if _, err := bw.Write(track.Bytes()); err != nil {
+		return err
+	}
+	return bw.Flush()
+}
+
+// writeVarInt writes the given number as a MIDI variable-length quantity: seven
+// bits per byte, most significant first, with the high bit set on every byte
+// but the last.
+func writeVarInt(b *bytes.Buffer, n int) {
+	var buf [4]byte
+	i := len(buf) - 1
+	buf[i] = byte(n & 0x7f)
+	for n >>= 7; n > 0 && i > 0; n >>= 7 {
+		i--
+		buf[i] = byte(n&0x7f) | 0x80
+	}
+	b.Write(buf[i:])
+}
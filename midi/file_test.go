@@ -0,0 +1,75 @@
+package midi
+
+import (
+	"bytes"
+	"encoding/hex"
+	"math"
+	"testing"
+)
+
+func TestWriteFile(t *testing.T) {
+	events := []NoteEvent{
+		{Note: 64, Start: 1, Duration: 1, Velocity: 100, Channel: 1},
+		{Note: 60, Start: 0, Duration: 1},
+		// a note that is struck again as soon as it stops
+		{Note: 60, Start: 1, Duration: 0.5},
+	}
+	var b bytes.Buffer
+	if err := WriteFile(&b, events, 0); err != nil {
+		t.Fatal(err)
+	}
+	exp := "4d546864" + "00000006" + "0000" + "0001" + "01e0" +
+		"4d54726b" + "00000026" +
+		// tempo: 500,000 microseconds per beat (120 bpm)
+		"00ff510307a120" +
+		"00903c5a" +
+		// 480 ticks later, as a variable-length quantity
+		"8360803c00" + "00914064" + "00903c5a" +
+		"8170803c00" + "8170814000" +
+		"00ff2f00"
+	if actual := hex.EncodeToString(b.Bytes()); actual != exp {
+		t.Errorf("WriteFile returned wrong value: %s != %s", actual, exp)
+	}
+
+	for _, e := range []NoteEvent{
+		{Note: 128, Duration: 1},
+		{Note: 60, Duration: 1, Velocity: 128},
+		{Note: 60, Duration: 1, Channel: 16},
+		{Note: 60, Duration: 1, Start: -1},
+		{Note: 60, Duration: -1},
+		// notes that start and stop on the same tick are never stopped
+		{Note: 60},
+		{Note: 60, Start: 1, Duration: 0.4 / TicksPerBeat},
+	} {
+		if err := WriteFile(&b, []NoteEvent{e}, 120); err == nil {
+			t.Errorf("WriteFile for %v should have failed", e)
+		}
+	}
+	if err := WriteFile(&b, []NoteEvent{{Note: 60, Duration: 1.0 / TicksPerBeat}}, 120); err != nil {
+		t.Errorf("WriteFile for one tick failed: %v", err)
+	}
+
+	// the tempo must fit in three bytes, in microseconds per beat
+	for _, bpm := range []float64{-1, 3.5, 1e9, math.NaN()} {
+		if err := WriteFile(&b, nil, bpm); err == nil {
+			t.Errorf("WriteFile for tempo %v should have failed", bpm)
+		}
+	}
+	b.Reset()
+	if err := WriteFile(&b, nil, 3.6); err != nil {
+		t.Errorf("WriteFile for tempo 3.6 failed: %v", err)
+	} else if actual := hex.EncodeToString(b.Bytes()[22:29]); actual != "00ff5103fe502b" {
+		t.Errorf("WriteFile for tempo 3.6 returned wrong tempo: %s != 00ff5103fe502b", actual)
+	}
+}
+
+func TestWriteVarInt(t *testing.T) {
+	cases := map[int]string{0: "00", 0x40: "40", 0x7f: "7f", 0x80: "8100", 0x2000: "c000", 0x3fff: "ff7f", 0x4000: "818000"}
+	for n, exp := range cases {
+		var b bytes.Buffer
+		writeVarInt(&b, n)
+		if actual := hex.EncodeToString(b.Bytes()); actual != exp {
+			t.Errorf("writeVarInt for %d returned wrong value: %s != %s", n, actual, exp)
+		}
+	}
+}
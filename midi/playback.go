@@ -0,0 +1,237 @@
+package midi
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/jhump/chords"
+)
+
+// NoteEvent is a note to be played: a MIDI note number that starts at some
+// time and lasts for some duration. Times are measured in beats, so they don't
+// depend on the tempo. (See NoteEvent.At.) Playback functions return note
+// events that can be written to a MIDI file or sent to a MIDI output.
+type NoteEvent struct {
+	// Note is the MIDI note number, where 60 is middle C.
+	Note int
+	// Start is when the note starts, in beats from the start of playback.
+	Start float64
+	// Duration is how long the note lasts, in beats.
+	Duration float64
+	// Velocity is how hard the note is played, from 1 to 127.
+	Velocity int
+	// Channel is the MIDI channel on which the note is played, from 0 to 15.
+	Channel int
+}
+
+// String implements the Stringer interface. The result is the note number
+// followed by its start and duration in beats, like "60@4+1.5".
+func (e NoteEvent) String() string {
+	return fmt.Sprintf("%d@%v+%v", e.Note, e.Start, e.Duration)
+}
+
+// At returns when the note starts and stops, at the given tempo in beats per
+// minute.
+func (e NoteEvent) At(bpm float64) (start, end time.Duration) {
+	beat := float64(time.Minute) / bpm
+	return time.Duration(e.Start * beat), time.Duration((e.Start + e.Duration) * beat)
+}
+
+// CompStyle is a rhythmic pattern for playing chords, as an accompanist
+// "comps" behind a soloist.
+type CompStyle int
+
+const (
+	// BlockComp plays all of the notes of each chord together, held for as
+	// long as the chord lasts.
+	BlockComp CompStyle = iota
+	// ArpeggiatedComp plays the notes of each chord one at a time, from lowest
+	// to highest and then starting again from the lowest, with a note on
+	// every eighth note for as long as the chord lasts.
+	ArpeggiatedComp
+	// PushedComp is like BlockComp, but each chord after the first is
+	// anticipated by an eighth note: it starts on the "and" of the beat
+	// before, cutting off the chord before it.
+	PushedComp
+)
+
+// String implements the Stringer interface.
+func (s CompStyle) String() string {
+	switch s {
+	case BlockComp:
+		return "block"
+	case ArpeggiatedComp:
+		return "arpeggiated"
+	case PushedComp:
+		return "pushed"
+	default:
+		return fmt.Sprintf("?(%d)", s)
+	}
+}
+
+// DefaultVelocity is the velocity used when PlaybackOptions.Velocity is zero.
+const DefaultVelocity = 90
+
+// PlaybackOptions control how chords are turned into note events.
+type PlaybackOptions struct {
+	// Style is the rhythmic pattern in which the chords are played.
+	Style CompStyle
+	// Voicing determines the voicings that are used for the chords of a
+	// progression. (See ProgressionEvents.)
+	Voicing chords.VoicingOptions
+	// Velocity is how hard the notes are played, from 1 to 127. If zero,
+	// DefaultVelocity is used.
+	Velocity int
+	// Channel is the MIDI channel on which the notes are played, from 0 to
+	// 15.
+	Channel int
+}
+
+// VoicingEvents returns the note events that play the given voicing, in the
+// style of the given options, starting at the given beat and lasting for the
+// given number of beats. Since a pushed chord starts early, PushedComp is the
+// same as BlockComp for a single voicing. The events are ordered by start
+// time and then by note.
+func VoicingEvents(v chords.Voicing, start, beats float64, opts PlaybackOptions) []NoteEvent {
+	velocity := opts.Velocity
+	if velocity == 0 {
+		velocity = DefaultVelocity
+	}
+	var events []NoteEvent
+	if opts.Style == ArpeggiatedComp {
+		if len(v) == 0 {
+			return nil
+		}
+		for i := 0; float64(i)*0.5 < beats; i++ {
+			offset := float64(i) * 0.5
+			dur := 0.5
+			if offset+dur > beats {
+				dur = beats - offset
+			}
+			events = append(events, NoteEvent{
+				Note:     v[i%len(v)].MIDINumber(),
+				Start:    start + offset,
+				Duration: dur,
+				Velocity: velocity,
+				Channel:  opts.Channel,
+			})
+		}
+		return events
+	}
+	for _, p := range v {
+		events = append(events, NoteEvent{
+			Note:     p.MIDINumber(),
+			Start:    start,
+			Duration: beats,
+			Velocity: velocity,
+			Channel:  opts.Channel,
+		})
+	}
+	return events
+}
+
+// ProgressionEvents returns the note events that play the given progression,
+// in the style of the given options. The progression is unrolled first, so
+// repeated sections are played as many times as they are repeated. (See
+// chords.Progression.Unroll.)
+//
+// Each chord is played with one of the voicings allowed by the options. (See
+// chords.Chord.VoicingsWithOptions.) The first chord uses its voicing closest
+// to the middle of the range, and each chord after that uses the voicing
+// whose pitches are closest to those of the chord before it, so the voices
+// move as little as possible. Chords with no allowed voicings are skipped,
+// leaving silence. The events are ordered by start time and then by note.
+func ProgressionEvents(prog *chords.Progression, opts PlaybackOptions) []NoteEvent {
+	var events []NoteEvent
+	var prev chords.Voicing
+	beat := 0.0
+	first := true
+	for _, bar := range prog.Unroll().Bars {
+		for _, bc := range bar.Chords {
+			start, beats := beat, float64(bc.Beats)
+			beat += beats
+			vs := bc.Chord.VoicingsWithOptions(opts.Voicing)
+			if len(vs) == 0 {
+				continue
+			}
+			var v chords.Voicing
+			if prev == nil {
+				v = middleVoicing(vs, opts.Voicing.Range)
+			} else {
+				v = nearestVoicing(vs, prev)
+			}
+			prev = v
+			if opts.Style == PushedComp && !first {
+				start -= 0.5
+				beats += 0.5
+				// cut off the notes of the chord before
+				for i := range events {
+					if end := events[i].Start + events[i].Duration; end > start {
+						events[i].Duration -= end - start
+					}
+				}
+			}
+			first = false
+			events = append(events, VoicingEvents(v, start, beats, opts)...)
+		}
+	}
+	sort.SliceStable(events, func(i, j int) bool {
+		if events[i].Start != events[j].Start {
+			return events[i].Start < events[j].Start
+		}
+		return events[i].Note < events[j].Note
+	})
+	return events
+}
+
+// middleVoicing returns the voicing whose middle is closest to the middle of
+// the given range. If the range is the zero value, the range used by
+// chords.Chord.VoicingsWithOptions, C3 to C6, is assumed.
+func middleVoicing(vs []chords.Voicing, register chords.PitchRange) chords.Voicing {
+	low, high := 48, 84
+	if register != (chords.PitchRange{}) {
+		low, high = register.Low.MIDINumber(), register.High.MIDINumber()
+	}
+	var best chords.Voicing
+	bestDist := -1
+	for _, v := range vs {
+		dist := abs(v[0].MIDINumber() + v[len(v)-1].MIDINumber() - low - high)
+		if bestDist < 0 || dist < bestDist {
+			best, bestDist = v, dist
+		}
+	}
+	return best
+}
+
+// nearestVoicing returns the voicing whose pitches are closest to the pitches
+// of the given previous voicing: the one for which the distance from each of
+// its pitches to the nearest pitch of the previous voicing, in total, is the
+// smallest.
+func nearestVoicing(vs []chords.Voicing, prev chords.Voicing) chords.Voicing {
+	var best chords.Voicing
+	bestDist := -1
+	for _, v := range vs {
+		dist := 0
+		for _, p := range v {
+			nearest := -1
+			for _, q := range prev {
+				if d := abs(p.MIDINumber() - q.MIDINumber()); nearest < 0 || d < nearest {
+					nearest = d
+				}
+			}
+			dist += nearest
+		}
+		if bestDist < 0 || dist < bestDist {
+			best, bestDist = v, dist
+		}
+	}
+	return best
+}
+
+func abs(i int) int {
+	if i < 0 {
+		return -i
+	}
+	return i
+}
@@ -0,0 +1,77 @@
+package midi
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/jhump/chords"
+)
+
+func TestProgressionEvents(t *testing.T) {
+	prog := chords.MustParseProgression("| D-7 G7 | C△7 |")
+	cases := []struct {
+		style CompStyle
+		exp   string
+	}{
+		{
+			style: BlockComp,
+			exp:   "[62@0+2 65@0+2 69@0+2 72@0+2 62@2+2 65@2+2 67@2+2 71@2+2 64@4+4 67@4+4 71@4+4 72@4+4]",
+		},
+		{
+			style: ArpeggiatedComp,
+			exp: "[62@0+0.5 65@0.5+0.5 69@1+0.5 72@1.5+0.5 62@2+0.5 65@2.5+0.5 67@3+0.5 71@3.5+0.5 " +
+				"64@4+0.5 67@4.5+0.5 71@5+0.5 72@5.5+0.5 64@6+0.5 67@6.5+0.5 71@7+0.5 72@7.5+0.5]",
+		},
+		{
+			style: PushedComp,
+			exp:   "[62@0+1.5 65@0+1.5 69@0+1.5 72@0+1.5 62@1.5+2 65@1.5+2 67@1.5+2 71@1.5+2 64@3.5+4.5 67@3.5+4.5 71@3.5+4.5 72@3.5+4.5]",
+		},
+	}
+	for _, tc := range cases {
+		events := ProgressionEvents(prog, PlaybackOptions{Style: tc.style})
+		if actual := fmt.Sprint(events); actual != tc.exp {
+			t.Errorf("ProgressionEvents for %v returned wrong value: %s != %s", tc.style, actual, tc.exp)
+		}
+		for _, e := range events {
+			if e.Velocity != DefaultVelocity || e.Channel != 0 {
+				t.Errorf("%v: wrong velocity or channel: %+v", tc.style, e)
+			}
+		}
+	}
+
+	// voicing options are used to choose the voicings
+	events := ProgressionEvents(chords.MustParseProgression("| C |: F :|"), PlaybackOptions{
+		Voicing:  chords.VoicingOptions{Style: chords.Shell, Range: chords.PitchRange{Low: chords.MustParsePitch("C3"), High: chords.MustParsePitch("C4")}},
+		Velocity: 64,
+		Channel:  9,
+	})
+	if actual, exp := fmt.Sprint(events), "[48@0+4 52@0+4 55@0+4 53@4+4 57@4+4 60@4+4 53@8+4 57@8+4 60@8+4]"; actual != exp {
+		t.Errorf("ProgressionEvents returned wrong value: %s != %s", actual, exp)
+	}
+	if events[0].Velocity != 64 || events[0].Channel != 9 {
+		t.Errorf("wrong velocity or channel: %+v", events[0])
+	}
+}
+
+func TestVoicingEvents(t *testing.T) {
+	v := chords.Voicing{chords.MustParsePitch("C4"), chords.MustParsePitch("E4"), chords.MustParsePitch("G4")}
+	events := VoicingEvents(v, 2, 1.75, PlaybackOptions{Style: ArpeggiatedComp})
+	if actual, exp := fmt.Sprint(events), "[60@2+0.5 64@2.5+0.5 67@3+0.5 60@3.5+0.25]"; actual != exp {
+		t.Errorf("VoicingEvents returned wrong value: %s != %s", actual, exp)
+	}
+	events = VoicingEvents(v, 0, 4, PlaybackOptions{Style: PushedComp})
+	if actual, exp := fmt.Sprint(events), "[60@0+4 64@0+4 67@0+4]"; actual != exp {
+		t.Errorf("VoicingEvents returned wrong value: %s != %s", actual, exp)
+	}
+	if events := VoicingEvents(nil, 0, 4, PlaybackOptions{Style: ArpeggiatedComp}); len(events) != 0 {
+		t.Errorf("VoicingEvents for empty voicing returned wrong value: %v", events)
+	}
+}
+
+func TestNoteEvent_At(t *testing.T) {
+	start, end := NoteEvent{Note: 60, Start: 2, Duration: 1.5}.At(120)
+	if start != time.Second || end != 1750*time.Millisecond {
+		t.Errorf("wrong times: %v to %v", start, end)
+	}
+}
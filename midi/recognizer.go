@@ -0,0 +1,174 @@
+// Package midi connects the chords package to MIDI. It works with MIDI note
+// numbers and events, but is not tied to any particular MIDI library: callers
+// adapt whatever library they use to deliver note events to the types in this
+// package. For example, a Recognizer names the chord being played on a MIDI
+// keyboard:
+//
+//	r := &midi.Recognizer{OnChord: func(ch *chords.Chord) {
+//		fmt.Println(ch) // C△7, G7/B, ...
+//	}}
+//	// then, for each event from the MIDI input:
+//	r.NoteOn(60, 100)
+//	r.NoteOff(60)
+//
+// In the other direction, ProgressionEvents turns a progression into timed
+// note events, which can be sent to a MIDI output or written to a standard
+// MIDI file with WriteFile, and
+// Segment turns timed note events, like those read from a file, back into a
+// progression.
+package midi
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/jhump/chords"
+)
+
+// NoteHandler handles MIDI note events. By MIDI convention, a NoteOn event
+// with a velocity of zero is the same as a NoteOff event.
+type NoteHandler interface {
+	// NoteOn is called when the note with the given MIDI note number starts,
+	// with the given velocity (from 0 to 127).
+	NoteOn(note, velocity int)
+	// NoteOff is called when the note with the given MIDI note number stops.
+	NoteOff(note int)
+}
+
+// DefaultDebounce is the debounce duration used by a Recognizer whose Debounce
+// is zero. It is long enough to treat the notes of a chord as played together,
+// even though the fingers never strike the keys at exactly the same time.
+const DefaultDebounce = 50 * time.Millisecond
+
+// Recognizer is a NoteHandler that keeps track of the notes being held and
+// recognizes the chord that they play, using chords.InferChordFromMIDI. The
+// lowest held note is the bass.
+//
+// Events are debounced: the chord is only recognized once the held notes have
+// not changed for the debounce duration. So OnChord isn't called for the
+// intermediate sets of notes while a chord is being struck or released.
+//
+// A Recognizer is safe to use from multiple goroutines. Its exported fields
+// must not be changed once it has handled its first event.
+type Recognizer struct {
+	// Debounce is how long the held notes must stay the same before their
+	// chord is recognized. If zero, DefaultDebounce is used.
+	Debounce time.Duration
+	// OnChord is called with the chord each time the recognized chord
+	// changes. It is called with nil when the held notes stop being a chord,
+	// like when all notes are released. It is called from its own goroutine,
+	// but calls are never concurrent.
+	OnChord func(ch *chords.Chord)
+
+	mu    sync.Mutex
+	held  map[int]bool
+	timer *time.Timer
+	// gen is incremented for each event, so that a timer that fires after
+	// another event has been handled does nothing
+	gen   int
+	chord *chords.Chord
+	// emit serializes calls to OnChord
+	emit sync.Mutex
+}
+
+var _ NoteHandler = (*Recognizer)(nil)
+
+// NoteOn implements the NoteHandler interface.
+func (r *Recognizer) NoteOn(note, velocity int) {
+	if velocity == 0 {
+		r.NoteOff(note)
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.held == nil {
+		r.held = map[int]bool{}
+	}
+	r.held[note] = true
+	r.changedLocked()
+}
+
+// NoteOff implements the NoteHandler interface.
+func (r *Recognizer) NoteOff(note int) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.held, note)
+	r.changedLocked()
+}
+
+// Held returns the MIDI note numbers of the notes being held, from lowest to
+// highest.
+func (r *Recognizer) Held() []int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.heldLocked()
+}
+
+// Chord returns the chord most recently recognized, or nil if the held notes
+// are not a chord.
+func (r *Recognizer) Chord() *chords.Chord {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.chord
+}
+
+// heldLocked returns the held notes, in order. It must be called with r.mu
+// held.
+func (r *Recognizer) heldLocked() []int {
+	notes := make([]int, 0, len(r.held))
+	for n := range r.held {
+		notes = append(notes, n)
+	}
+	sort.Ints(notes)
+	return notes
+}
+
+// changedLocked restarts the debounce timer after the held notes change. It
+// must be called with r.mu held.
+func (r *Recognizer) changedLocked() {
+	r.gen++
+	gen := r.gen
+	if r.timer != nil {
+		r.timer.Stop()
+	}
+	debounce := r.Debounce
+	if debounce == 0 {
+		debounce = DefaultDebounce
+	}
+	r.timer = time.AfterFunc(debounce, func() {
+		r.recognize(gen)
+	})
+}
+
+// recognize recognizes the chord of the held notes, unless they have changed
+// since the given generation.
+func (r *Recognizer) recognize(gen int) {
+	r.emit.Lock()
+	defer r.emit.Unlock()
+
+	r.mu.Lock()
+	if gen != r.gen {
+		r.mu.Unlock()
+		return
+	}
+	ch := inferChord(r.heldLocked())
+	changed := (ch == nil) != (r.chord == nil) || (ch != nil && ch.String() != r.chord.String())
+	r.chord = ch
+	r.mu.Unlock()
+
+	if changed && r.OnChord != nil {
+		r.OnChord(ch)
+	}
+}
+
+// inferChord returns the best description of the chord played by the given
+// MIDI note numbers, or nil if they are not a chord. (See
+// chords.InferChordFromMIDI.)
+func inferChord(notes []int) *chords.Chord {
+	cands := chords.InferChordFromMIDI(notes)
+	if len(cands) == 0 {
+		return nil
+	}
+	return cands[0].Chord
+}
@@ -0,0 +1,105 @@
+package midi
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/jhump/chords"
+)
+
+func TestRecognizer(t *testing.T) {
+	results := make(chan *chords.Chord, 10)
+	r := &Recognizer{
+		Debounce: 20 * time.Millisecond,
+		OnChord: func(ch *chords.Chord) {
+			results <- ch
+		},
+	}
+	expect := func(exp string) {
+		t.Helper()
+		select {
+		case ch := <-results:
+			actual := "<nil>"
+			if ch != nil {
+				actual = ch.String()
+			}
+			if actual != exp {
+				t.Errorf("Recognizer.OnChord received wrong value: %s != %s", actual, exp)
+			}
+		case <-time.After(time.Second):
+			t.Fatalf("timed out waiting for %s", exp)
+		}
+	}
+	expectNothing := func() {
+		t.Helper()
+		select {
+		case ch := <-results:
+			t.Errorf("Recognizer.OnChord received an unexpected chord: %v", ch)
+		case <-time.After(100 * time.Millisecond):
+		}
+	}
+
+	// C major, struck one note at a time, is only recognized once
+	r.NoteOn(60, 100)
+	r.NoteOn(64, 100)
+	r.NoteOn(67, 100)
+	expect("C")
+	if held := fmt.Sprint(r.Held()); held != "[60 64 67]" {
+		t.Errorf("wrong held notes: %s", held)
+	}
+	if ch := r.Chord(); ch == nil || ch.String() != "C" {
+		t.Errorf("wrong current chord: %v", ch)
+	}
+
+	// adding a B♭ in the bass makes an inversion of C7
+	r.NoteOn(58, 90)
+	expect("C7/B♭")
+
+	// a note-on with zero velocity is a note-off
+	r.NoteOn(58, 0)
+	expect("C")
+
+	// doubling a note an octave higher doesn't change the chord
+	r.NoteOn(72, 100)
+	expectNothing()
+
+	// releasing all but two notes is no longer a chord
+	r.NoteOff(60)
+	r.NoteOff(72)
+	r.NoteOff(64)
+	expect("<nil>")
+	r.NoteOff(67)
+	expectNothing()
+	if held := r.Held(); len(held) != 0 {
+		t.Errorf("Recognizer.Held returned wrong value: %v", held)
+	}
+
+	// the lowest note is the bass
+	r.NoteOn(64, 100)
+	r.NoteOn(67, 100)
+	r.NoteOn(72, 100)
+	expect("C/E")
+}
+
+func TestInferChord(t *testing.T) {
+	cases := []struct {
+		notes []int
+		exp   string
+	}{
+		{[]int{60, 64, 67}, "C"},
+		{[]int{67, 71, 74, 77}, "G7"},
+		{[]int{62, 66, 69}, "D"},
+		{[]int{61, 65, 68}, "D♭"},
+		{[]int{66, 70, 73}, "F♯"},
+		{[]int{59, 63, 66}, "B"},
+		{[]int{55, 60, 64}, "C/G"},
+		{[]int{60, 64}, "<nil>"},
+		{nil, "<nil>"},
+	}
+	for _, tc := range cases {
+		if actual := fmt.Sprint(inferChord(tc.notes)); actual != tc.exp {
+			t.Errorf("InferChord for %v returned wrong value: %s != %s", tc.notes, actual, tc.exp)
+		}
+	}
+}
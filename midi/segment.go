@@ -0,0 +1,159 @@
+package midi
+
+import (
+	"math"
+	"sort"
+
+	"github.com/jhump/chords"
+)
+
+// SegmentOptions control how Segment divides note events into chords.
+type SegmentOptions struct {
+	// Window is the length, in beats, of the windows in which the notes are
+	// analyzed. A chord can change only at the start of a window, so this is
+	// the shortest duration of a chord in the result. If zero, each window is
+	// one beat.
+	Window int
+	// BeatsPerBar is the number of beats in each bar of the result. If zero,
+	// the result is in 4/4 time.
+	BeatsPerBar int
+	// MinPresence is the fraction of a window, from 0 to 1, for which a pitch
+	// class must sound to be counted as part of the window's chord. Pitch
+	// classes heard for less time, like passing tones, are ignored. If zero,
+	// a pitch class must sound for a quarter of the window.
+	MinPresence float64
+}
+
+// defaultMinPresence is the fraction of a window used when
+// SegmentOptions.MinPresence is zero.
+const defaultMinPresence = 0.25
+
+// Segment returns the chord progression played by the given note events, for
+// "chordifying" a MIDI file. The events may be in any order, and they may be
+// on any channel. (Events in drum channels should be left out by the caller.)
+//
+// The events are divided into windows (see SegmentOptions) and the notes that
+// sound in each window are named with chords.InferChordFromMIDI, so the lowest
+// note is the bass. A new chord starts only when a window's notes change the
+// harmony: a window whose notes are all in the chord before it, like a chord
+// whose upper notes have been released, continues that chord, as does a window
+// whose notes are not a chord. Consecutive windows with the same chord are
+// merged, and chords that cross a bar line are split into one chord in each
+// bar. Any leading windows with no chord are merged into the first chord, and
+// the last chord is extended to the end of its bar.
+//
+// This returns nil if the events never form a chord.
+func Segment(events []NoteEvent, opts SegmentOptions) *chords.Progression {
+	window := opts.Window
+	if window <= 0 {
+		window = 1
+	}
+	beatsPerBar := opts.BeatsPerBar
+	if beatsPerBar <= 0 {
+		beatsPerBar = 4
+	}
+	minPresence := opts.MinPresence
+	if minPresence <= 0 {
+		minPresence = defaultMinPresence
+	}
+
+	end := 0.0
+	for _, e := range events {
+		end = math.Max(end, e.Start+e.Duration)
+	}
+	numWindows := int(math.Ceil(end / float64(window)))
+
+	var regions []chords.BarChord
+	var current chords.PitchClassSet
+	leading := 0
+	for i := 0; i < numWindows; i++ {
+		start, stop := float64(i*window), float64((i+1)*window)
+		notes, pcs := windowNotes(events, start, stop, minPresence*float64(window))
+		var ch *chords.Chord
+		if len(regions) == 0 || !pcs.IsSubsetOf(current) {
+			if cands := chords.InferChordFromMIDI(notes); len(cands) > 0 {
+				ch = cands[0].Chord
+			}
+		}
+		switch {
+		case ch == nil && len(regions) == 0:
+			leading += window
+		case ch != nil && (len(regions) == 0 || ch.String() != regions[len(regions)-1].Chord.String()):
+			regions = append(regions, chords.BarChord{Chord: ch, Beats: window + leading})
+			leading = 0
+			current = ch.PitchClassSet()
+		default:
+			regions[len(regions)-1].Beats += window
+		}
+	}
+	if len(regions) == 0 {
+		return nil
+	}
+	return barsOf(regions, beatsPerBar)
+}
+
+// windowNotes returns the MIDI note numbers that sound in the window from
+// start to stop (in beats), from lowest to highest, and their pitch classes.
+// Only pitch classes that sound for at least the given number of beats in
+// total are included.
+func windowNotes(events []NoteEvent, start, stop, minBeats float64) ([]int, chords.PitchClassSet) {
+	var presence [12]float64
+	for _, e := range events {
+		if overlap := math.Min(stop, e.Start+e.Duration) - math.Max(start, e.Start); overlap > 0 {
+			presence[posMod(e.Note, 12)] += overlap
+		}
+	}
+	var pcs chords.PitchClassSet
+	for pc, beats := range presence {
+		if beats >= minBeats {
+			pcs = pcs.With(pc)
+		}
+	}
+	seen := map[int]bool{}
+	var notes []int
+	for _, e := range events {
+		if math.Min(stop, e.Start+e.Duration) > math.Max(start, e.Start) && pcs.Contains(posMod(e.Note, 12)) && !seen[e.Note] {
+			seen[e.Note] = true
+			notes = append(notes, e.Note)
+		}
+	}
+	sort.Ints(notes)
+	return notes, pcs
+}
+
+// barsOf arranges the given chords into bars with the given number of beats,
+// splitting chords that cross a bar line. The last chord is extended to fill
+// its bar.
+func barsOf(regions []chords.BarChord, beatsPerBar int) *chords.Progression {
+	prog := &chords.Progression{BeatsPerBar: beatsPerBar}
+	var bar chords.Bar
+	beats := 0
+	for _, r := range regions {
+		for r.Beats > 0 {
+			n := r.Beats
+			if beats+n > beatsPerBar {
+				n = beatsPerBar - beats
+			}
+			bar.Chords = append(bar.Chords, chords.BarChord{Chord: r.Chord, Beats: n})
+			beats += n
+			r.Beats -= n
+			if beats == beatsPerBar {
+				prog.Bars = append(prog.Bars, bar)
+				bar, beats = chords.Bar{}, 0
+			}
+		}
+	}
+	if beats > 0 {
+		bar.Chords[len(bar.Chords)-1].Beats += beatsPerBar - beats
+		prog.Bars = append(prog.Bars, bar)
+	}
+	return prog
+}
+
+func posMod(i, n int) int {
+	i %= n
+	if i < 0 {
+		i += n
+	}
+	return i
+}
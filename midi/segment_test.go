@@ -0,0 +1,47 @@
+package midi
+
+import (
+	"testing"
+
+	"github.com/jhump/chords"
+)
+
+func TestSegment(t *testing.T) {
+	// the chords of a progression are recovered from the events that play it,
+	// as long as the voicings have their roots in the bass
+	prog := chords.MustParseProgression("| D-7 G7 | C△7 | A-7 / D7 / | G |")
+	for _, style := range []CompStyle{BlockComp, ArpeggiatedComp} {
+		events := ProgressionEvents(prog, PlaybackOptions{Style: style, Voicing: chords.VoicingOptions{Style: chords.Shell}})
+		// an arpeggio needs a window long enough to hear all of its notes
+		actual := Segment(events, SegmentOptions{Window: 2})
+		if actual == nil || actual.String() != prog.String() {
+			t.Errorf("Segment for %v returned wrong value: %v != %v", style, actual, prog)
+		}
+	}
+
+	events := []NoteEvent{
+		// a rest, then C/E held for six beats
+		{Note: 52, Start: 1, Duration: 6},
+		{Note: 60, Start: 1, Duration: 6},
+		{Note: 67, Start: 1, Duration: 3},
+		// a passing tone, which is ignored
+		{Note: 69, Start: 4, Duration: 0.125},
+		// then F for one beat
+		{Note: 53, Start: 7, Duration: 1},
+		{Note: 57, Start: 7, Duration: 1},
+		{Note: 60, Start: 7, Duration: 1},
+	}
+	actual := Segment(events, SegmentOptions{BeatsPerBar: 3})
+	if exp := "3/4 | C/E | C/E | C/E F / |"; actual == nil || actual.String() != exp {
+		t.Errorf("Segment returned wrong value: %v != %s", actual, exp)
+	}
+	// with a longer window, the end of C/E is heard with F
+	actual = Segment(events, SegmentOptions{Window: 2})
+	if exp := "| C/E | C/E F△7/E |"; actual == nil || actual.String() != exp {
+		t.Errorf("Segment returned wrong value: %v != %s", actual, exp)
+	}
+
+	if actual := Segment([]NoteEvent{{Note: 60, Duration: 4}}, SegmentOptions{}); actual != nil {
+		t.Errorf("Segment for one note returned wrong value: %v", actual)
+	}
+}
@@ -0,0 +1,418 @@
+// Package musicxml reads and writes chord symbols in the MusicXML format,
+// which is used to exchange scores between notation programs like MuseScore,
+// Finale, and Sibelius. In MusicXML, a chord symbol is a <harmony> element:
+//
+//	<harmony>
+//	  <root>
+//	    <root-step>B</root-step>
+//	    <root-alter>-1</root-alter>
+//	  </root>
+//	  <kind text="7">dominant</kind>
+//	  <degree>
+//	    <degree-value>9</degree-value>
+//	    <degree-alter>1</degree-alter>
+//	    <degree-type>add</degree-type>
+//	  </degree>
+//	</harmony>
+//
+// The kind names the chord's quality, like "dominant" or "minor-seventh", and
+// degrees add or alter the tones that the kind doesn't include. A whole song
+// can be written as a score whose measures contain only chord symbols and
+// rests. (See WriteScore.) Chord symbols can also be read from
+// existing scores. (See ReadHarmonies.)
+package musicxml
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/jhump/chords"
+)
+
+// Harmony is a MusicXML <harmony> element: a chord symbol.
+type Harmony struct {
+	XMLName xml.Name `xml:"harmony"`
+	// Root is the root of the chord.
+	Root Root `xml:"root"`
+	// Kind is the quality of the chord.
+	Kind Kind `xml:"kind"`
+	// Bass is the bass note of a slash chord, or nil if the root is the bass.
+	Bass *Bass `xml:"bass,omitempty"`
+	// Inversion is the inversion of the chord: 1 for the first inversion,
+	// where the 3rd is the bass, 2 for the second, and so on. It is an
+	// alternative to Bass for describing a chord whose root is not the bass.
+	// HarmonyOf always uses Bass instead.
+	Inversion int `xml:"inversion,omitempty"`
+	// Degrees are the tones that are added to, altered in, or removed from
+	// the chord described by the kind.
+	Degrees []Degree `xml:"degree"`
+}
+
+// Root is the root of a chord symbol.
+type Root struct {
+	// Step is the letter name of the root, from "A" to "G".
+	Step string `xml:"root-step"`
+	// Alter is the root's accidental in half-steps: -1 for flat, 1 for sharp,
+	// and so on.
+	Alter int `xml:"root-alter,omitempty"`
+}
+
+// Kind is the quality of a chord symbol, like "major" or "dominant-ninth".
+type Kind struct {
+	// Value is the name of the quality, from the MusicXML vocabulary. (See
+	// HarmonyOf.)
+	Value string `xml:",chardata"`
+	// Text is how the quality is displayed, like "7" or "-7♭5".
+	Text string `xml:"text,attr,omitempty"`
+}
+
+// Bass is the bass note of a slash chord.
+type Bass struct {
+	// Step is the letter name of the bass note, from "A" to "G".
+	Step string `xml:"bass-step"`
+	// Alter is the bass note's accidental in half-steps: -1 for flat, 1 for
+	// sharp, and so on.
+	Alter int `xml:"bass-alter,omitempty"`
+}
+
+// Degree is a tone that is added to, altered in, or removed from a chord
+// symbol.
+type Degree struct {
+	// Value is the tone's number, like 5 for the 5th or 9 for the 9th.
+	Value int `xml:"degree-value"`
+	// Alter is the tone's alteration in half-steps, relative to the tone in
+	// a major scale. So a dominant 7th is a 7 with an alteration of -1.
+	Alter int `xml:"degree-alter"`
+	// Type is "add", "alter", or "subtract".
+	Type string `xml:"degree-type"`
+}
+
+// Degree types.
+const (
+	DegreeAdd      = "add"
+	DegreeAlter    = "alter"
+	DegreeSubtract = "subtract"
+)
+
+// kindsBySeventh are the kinds of chords with a 7th, indexed by the type of
+// triad (major or minor) and whether the 7th is a major 7th. Each has the
+// kinds for a 7th chord and for 9th, 11th, and 13th chords. MusicXML has no
+// 9th, 11th, or 13th kinds for a minor triad with a major 7th, so those
+// extensions are left empty and become degrees instead.
+var kindsBySeventh = map[chords.TriadType]map[bool][4]string{
+	chords.Maj3: {
+		false: {"dominant", "dominant-ninth", "dominant-11th", "dominant-13th"},
+		true:  {"major-seventh", "major-ninth", "major-11th", "major-13th"},
+	},
+	chords.Min3: {
+		false: {"minor-seventh", "minor-ninth", "minor-11th", "minor-13th"},
+		true:  {"major-minor", "", "", ""},
+	},
+}
+
+// HarmonyOf returns the chord symbol for the given chord. The kind is chosen
+// from the MusicXML vocabulary, and the chord's other tones become degrees.
+// The 9th, 11th, and 13th kinds are used the same way as chord names in this
+// package: C13 ("dominant-13th") is a dominant 7th chord with a 13th, but no
+// 9th or 11th. Altered 5ths are degrees of type "alter", and all other tones
+// that the kind doesn't include are degrees of type "add". The kind's text is
+// the chord's name without its root or bass note, like "7♯9".
+func HarmonyOf(ch *chords.Chord) Harmony {
+	ch = ch.Canonical()
+	h := Harmony{
+		Root: Root{Step: ch.Root.N.String(), Alter: int(ch.Root.Acc.Offset())},
+	}
+	if ch.Bass.N != 0 {
+		h.Bass = &Bass{Step: ch.Bass.N.String(), Alter: int(ch.Bass.Acc.Offset())}
+	}
+
+	var seventh *chords.ChordTone
+	var rest []chords.ChordTone
+	for i, tn := range ch.ExtraTones {
+		if tn.Val == 7 && seventh == nil {
+			seventh = &ch.ExtraTones[i]
+			continue
+		}
+		rest = append(rest, tn)
+	}
+	// take removes the given tone from rest, returning false if it isn't
+	// there
+	take := func(tn chords.ChordTone) bool {
+		for i := range rest {
+			if rest[i] == tn {
+				rest = append(rest[:i:i], rest[i+1:]...)
+				return true
+			}
+		}
+		return false
+	}
+
+	switch ch.Triad {
+	case chords.Maj3, chords.Min3:
+		if seventh != nil && (seventh.Acc == chords.Natural || seventh.Acc == chords.Sharp) {
+			kinds := kindsBySeventh[ch.Triad][seventh.Acc == chords.Sharp]
+			h.Kind.Value = kinds[0]
+			for i, ext := range []int8{9, 11, 13} {
+				if kinds[i+1] != "" && take(chords.ChordTone{Val: ext}) {
+					h.Kind.Value = kinds[i+1]
+					break
+				}
+			}
+			seventh = nil
+		} else if take(chords.ChordTone{Val: 6}) {
+			h.Kind.Value = "major-sixth"
+			if ch.Triad == chords.Min3 {
+				h.Kind.Value = "minor-sixth"
+			}
+		} else {
+			h.Kind.Value = "major"
+			if ch.Triad == chords.Min3 {
+				h.Kind.Value = "minor"
+			}
+		}
+	case chords.Aug3:
+		h.Kind.Value = "augmented"
+		switch {
+		case seventh == nil:
+		case seventh.Acc == chords.Natural:
+			h.Kind.Value = "augmented-seventh"
+			seventh = nil
+		case seventh.Acc == chords.Sharp:
+			// there's no kind for an augmented triad with a major 7th
+			h.Kind.Value = "major-seventh"
+			h.Degrees = append(h.Degrees, Degree{Value: 5, Alter: 1, Type: DegreeAlter})
+			seventh = nil
+		}
+	case chords.Dim3:
+		h.Kind.Value = "diminished"
+	case chords.HDim:
+		h.Kind.Value = "half-diminished"
+		seventh = nil
+	case chords.FDim:
+		h.Kind.Value = "diminished-seventh"
+		seventh = nil
+	case chords.Sus:
+		h.Kind.Value = "suspended-fourth"
+		switch {
+		case take(chords.ChordTone{Val: 4}):
+		case take(chords.ChordTone{Val: 2}):
+			h.Kind.Value = "suspended-second"
+		default:
+			// an altered suspension, like sus♯4
+			for _, tn := range rest {
+				if tn.Val == 2 || tn.Val == 4 {
+					take(tn)
+					if tn.Val == 2 {
+						h.Kind.Value = "suspended-second"
+					}
+					h.Degrees = append(h.Degrees, Degree{Value: int(tn.Val), Alter: int(tn.Acc.Offset()), Type: DegreeAlter})
+					break
+				}
+			}
+		}
+	}
+
+	if seventh != nil {
+		h.Degrees = append(h.Degrees, degreeOf(*seventh, DegreeAdd))
+	}
+	for _, tn := range rest {
+		typ := DegreeAdd
+		if tn.Val == 5 {
+			typ = DegreeAlter
+		}
+		h.Degrees = append(h.Degrees, degreeOf(tn, typ))
+	}
+	noBass := ch.Clone()
+	noBass.Bass = chords.Note{}
+	h.Kind.Text = strings.TrimPrefix(noBass.String(), ch.Root.String())
+	return h
+}
+
+// degreeOf returns the degree for the given chord tone.
+func degreeOf(tn chords.ChordTone, typ string) Degree {
+	alter := int(tn.Acc.Offset())
+	if tn.Val == 7 {
+		// in a chord name, an unaltered 7th is a minor 7th
+		alter--
+	}
+	return Degree{Value: int(tn.Val), Alter: alter, Type: typ}
+}
+
+// WriteHarmonies writes the <harmony> elements for the given chords to the
+// given writer, one after another and indented. (See HarmonyOf.)
+func WriteHarmonies(w io.Writer, chs ...*chords.Chord) error {
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+	for _, ch := range chs {
+		if err := enc.Encode(HarmonyOf(ch)); err != nil {
+			return err
+		}
+	}
+	if err := enc.Flush(); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
+
+// score is a MusicXML <score-partwise> element: a score with one part.
+type score struct {
+	XMLName        xml.Name        `xml:"score-partwise"`
+	Version        string          `xml:"version,attr"`
+	Work           *work           `xml:"work,omitempty"`
+	Identification *identification `xml:"identification,omitempty"`
+	PartList       struct {
+		ScorePart struct {
+			ID   string `xml:"id,attr"`
+			Name string `xml:"part-name"`
+		} `xml:"score-part"`
+	} `xml:"part-list"`
+	Part struct {
+		ID       string    `xml:"id,attr"`
+		Measures []measure `xml:"measure"`
+	} `xml:"part"`
+}
+
+// measure is a MusicXML <measure> element.
+type measure struct {
+	Number     int           `xml:"number,attr"`
+	Attributes *attributes   `xml:"attributes,omitempty"`
+	Elements   []interface{} `xml:",any"`
+}
+
+// work is a MusicXML <work> element, which has the title of a score.
+type work struct {
+	Title string `xml:"work-title"`
+}
+
+// identification is a MusicXML <identification> element, which names the
+// score's creators.
+type identification struct {
+	Creators []creator `xml:"creator"`
+}
+
+// creator is a MusicXML <creator> element, like the composer of a score.
+type creator struct {
+	Type string `xml:"type,attr"`
+	Name string `xml:",chardata"`
+}
+
+// attributes is a MusicXML <attributes> element, which sets the key and time
+// signatures for the first measure.
+type attributes struct {
+	Divisions int     `xml:"divisions"`
+	Key       *keySig `xml:"key,omitempty"`
+	Beats     int     `xml:"time>beats"`
+	BeatType  int     `xml:"time>beat-type"`
+	ClefSign  string  `xml:"clef>sign"`
+	ClefLine  int     `xml:"clef>line"`
+}
+
+// keySig is a MusicXML <key> element: a key signature, as the number of
+// sharps (positive) or flats (negative), and the mode.
+type keySig struct {
+	Fifths int    `xml:"fifths"`
+	Mode   string `xml:"mode"`
+}
+
+// sound is a MusicXML <sound> element, which sets the tempo for playback, in
+// quarter notes per minute.
+type sound struct {
+	XMLName xml.Name `xml:"sound"`
+	Tempo   float64  `xml:"tempo,attr"`
+}
+
+// rest is a MusicXML <note> element for a rest.
+type rest struct {
+	XMLName  xml.Name `xml:"note"`
+	Rest     struct{} `xml:"rest"`
+	Duration int      `xml:"duration"`
+	Voice    int      `xml:"voice"`
+}
+
+// xmlHeader is the start of a MusicXML score.
+const xmlHeader = `<?xml version="1.0" encoding="UTF-8" standalone="no"?>
+<!DOCTYPE score-partwise PUBLIC "-//Recordare//DTD MusicXML 4.0 Partwise//EN" "http://www.musicxml.org/dtds/partwise.dtd">
+`
+
+// WriteScore writes a MusicXML score for the given song to the given writer.
+// The score has one part, with a measure for each bar of the song. Each
+// measure has the bar's chord symbols over rests that last as long as the
+// chords, so a notation program shows the chords as a chart. The song is
+// unrolled, so repeated sections and endings are written out. (See
+// chords.Song.Unroll.)
+//
+// The song's metadata is written to the score, when present: the title, the
+// composer, the key signature, the tempo, and the time signature (the "time"
+// field, like "6/8"). Without a time signature, a beat is a quarter note.
+func WriteScore(w io.Writer, song *chords.Song) error {
+	prog := song.Unroll()
+	beats := prog.BeatsPerBar
+	if beats == 0 {
+		beats = 4
+	}
+	beatType := 4
+	for _, f := range song.Metadata.Extra {
+		if strings.EqualFold(f.Name, "time") {
+			if pos := strings.IndexByte(f.Value, '/'); pos >= 0 {
+				if n, err := strconv.Atoi(strings.TrimSpace(f.Value[pos+1:])); err == nil && n > 0 {
+					beatType = n
+				}
+			}
+		}
+	}
+	// durations are in divisions of a quarter note, so there must be a whole
+	// number of divisions in a beat
+	divisions := 1
+	if beatType > 4 {
+		divisions = beatType / 4
+	}
+	beatDuration := 4 * divisions / beatType
+
+	var s score
+	s.Version = "4.0"
+	if song.Metadata.Title != "" {
+		s.Work = &work{Title: song.Metadata.Title}
+	}
+	if song.Metadata.Composer != "" {
+		s.Identification = &identification{Creators: []creator{{Type: "composer", Name: song.Metadata.Composer}}}
+	}
+	s.PartList.ScorePart.ID = "P1"
+	s.PartList.ScorePart.Name = "Chords"
+	s.Part.ID = "P1"
+	for i, bar := range prog.Bars {
+		m := measure{Number: i + 1}
+		if i == 0 {
+			m.Attributes = &attributes{Divisions: divisions, Beats: beats, BeatType: beatType, ClefSign: "G", ClefLine: 2}
+			if key := song.Metadata.Key; key.Tonic.N != 0 {
+				mode := "major"
+				if key.Minor {
+					mode = "minor"
+				}
+				m.Attributes.Key = &keySig{Fifths: int(key.KeySignature()), Mode: mode}
+			}
+			if song.Metadata.Tempo > 0 {
+				m.Elements = append(m.Elements, sound{Tempo: float64(song.Metadata.Tempo*4) / float64(beatType)})
+			}
+		}
+		for _, bc := range bar.Chords {
+			m.Elements = append(m.Elements, HarmonyOf(bc.Chord), rest{Duration: bc.Beats * beatDuration, Voice: 1})
+		}
+		s.Part.Measures = append(s.Part.Measures, m)
+	}
+
+	var b bytes.Buffer
+	b.WriteString(xmlHeader)
+	enc := xml.NewEncoder(&b)
+	enc.Indent("", "  ")
+	if err := enc.Encode(s); err != nil {
+		return fmt.Errorf("failed to encode score: %v", err)
+	}
+	b.WriteByte('\n')
+	_, err := w.Write(b.Bytes())
+	return err
+}
@@ -0,0 +1,171 @@
+package musicxml
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/jhump/chords"
+)
+
+func TestHarmonyOf(t *testing.T) {
+	cases := []struct {
+		chord   string
+		kind    string
+		text    string
+		degrees string
+	}{
+		{chord: "C", kind: "major", text: ""},
+		{chord: "C-", kind: "minor", text: "-"},
+		{chord: "C7", kind: "dominant", text: "7"},
+		{chord: "C△7", kind: "major-seventh", text: "△7"},
+		{chord: "C-7", kind: "minor-seventh", text: "-7"},
+		{chord: "C-△7", kind: "major-minor", text: "-△7"},
+		{chord: "C9", kind: "dominant-ninth", text: "9"},
+		{chord: "C13", kind: "dominant-13th", text: "13"},
+		{chord: "C-11", kind: "minor-11th", text: "-11"},
+		{chord: "C6", kind: "major-sixth", text: "6"},
+		{chord: "C-6", kind: "minor-sixth", text: "-6"},
+		{chord: "C+", kind: "augmented", text: "+"},
+		{chord: "C+7", kind: "augmented-seventh", text: "+7"},
+		{chord: "C+△7", kind: "major-seventh", text: "+△7", degrees: "alter 5 +1"},
+		{chord: "Cdim", kind: "diminished", text: "dim"},
+		{chord: "Cø", kind: "half-diminished", text: "ø"},
+		{chord: "Co", kind: "diminished-seventh", text: "o"},
+		{chord: "Csus2", kind: "suspended-second", text: "sus2"},
+		{chord: "Gsus4 7", kind: "suspended-fourth", text: "sus4 7", degrees: "add 7 -1"},
+		{chord: "Gsus♯4", kind: "suspended-fourth", text: "sus♯4", degrees: "alter 4 +1"},
+		{chord: "C7♯9", kind: "dominant", text: "7♯9", degrees: "add 9 +1"},
+		{chord: "C7♭5", kind: "dominant", text: "7♭5", degrees: "alter 5 -1"},
+		{chord: "C13♯11", kind: "dominant-13th", text: "7♯11 13", degrees: "add 11 +1"},
+		{chord: "C2", kind: "major", text: "2", degrees: "add 2 +0"},
+	}
+	for _, tc := range cases {
+		h := HarmonyOf(chords.MustParseChord(tc.chord))
+		var degrees []string
+		for _, d := range h.Degrees {
+			degrees = append(degrees, fmt.Sprintf("%s %d %+d", d.Type, d.Value, d.Alter))
+		}
+		if h.Root != (Root{Step: tc.chord[:1]}) || h.Bass != nil || h.Kind.Value != tc.kind ||
+			h.Kind.Text != tc.text || strings.Join(degrees, ", ") != tc.degrees {
+			t.Errorf("HarmonyOf for %s returned wrong value: %+v != %s %q [%s]", tc.chord, h, tc.kind, tc.text, tc.degrees)
+		}
+	}
+
+	h := HarmonyOf(chords.MustParseChord("B♭7/D"))
+	if h.Root != (Root{Step: "B", Alter: -1}) || h.Bass == nil || *h.Bass != (Bass{Step: "D"}) || h.Kind.Text != "7" {
+		t.Errorf("wrong harmony for B♭7/D: %+v", h)
+	}
+}
+
+func TestWriteHarmonies(t *testing.T) {
+	var b bytes.Buffer
+	if err := WriteHarmonies(&b, chords.MustParseChord("B♭7♯9"), chords.MustParseChord("C/E")); err != nil {
+		t.Fatalf("failed to write: %v", err)
+	}
+	exp := `<harmony>
+  <root>
+    <root-step>B</root-step>
+    <root-alter>-1</root-alter>
+  </root>
+  <kind text="7♯9">dominant</kind>
+  <degree>
+    <degree-value>9</degree-value>
+    <degree-alter>1</degree-alter>
+    <degree-type>add</degree-type>
+  </degree>
+</harmony>
+<harmony>
+  <root>
+    <root-step>C</root-step>
+  </root>
+  <kind>major</kind>
+  <bass>
+    <bass-step>E</bass-step>
+  </bass>
+</harmony>
+`
+	if actual := b.String(); actual != exp {
+		t.Errorf("wrong output:\n%s", actual)
+	}
+}
+
+func TestWriteScore(t *testing.T) {
+	song, err := chords.ParseSong("{title: Test}\n{composer: Someone}\n{key: Am}\n{tempo: 90}\n| C△7 | A-7 D7 |: G7 :|\n")
+	if err != nil {
+		t.Fatalf("failed to parse song: %v", err)
+	}
+	var b bytes.Buffer
+	if err := WriteScore(&b, song); err != nil {
+		t.Fatalf("failed to write: %v", err)
+	}
+	out := b.String()
+	if !strings.HasPrefix(out, "<?xml") || !strings.Contains(out, "<work-title>Test</work-title>") {
+		t.Errorf("wrong header:\n%s", out)
+	}
+	if !strings.Contains(out, `<creator type="composer">Someone</creator>`) {
+		t.Errorf("WriteScore did not write the composer:\n%s", out)
+	}
+
+	var parsed struct {
+		Measures []struct {
+			Number   int    `xml:"number,attr"`
+			Beats    int    `xml:"attributes>time>beats"`
+			BeatType int    `xml:"attributes>time>beat-type"`
+			Fifths   int    `xml:"attributes>key>fifths"`
+			Mode     string `xml:"attributes>key>mode"`
+			Sound    struct {
+				Tempo string `xml:"tempo,attr"`
+			} `xml:"sound"`
+			Harmony  []Harmony `xml:"harmony"`
+			Duration []int     `xml:"note>duration"`
+		} `xml:"part>measure"`
+	}
+	if err := xml.Unmarshal(b.Bytes(), &parsed); err != nil {
+		t.Fatalf("output is not valid XML: %v", err)
+	}
+	if len(parsed.Measures) != 4 {
+		t.Fatalf("WriteScore returned wrong number of measures: %d != 4", len(parsed.Measures))
+	}
+	if m := parsed.Measures[0]; m.Beats != 4 || m.BeatType != 4 || m.Fifths != 0 || m.Mode != "minor" || m.Sound.Tempo != "90" {
+		t.Errorf("wrong first measure: %+v", m)
+	}
+	m := parsed.Measures[1]
+	if m.Number != 2 || len(m.Harmony) != 2 || m.Harmony[1].Kind.Value != "dominant" ||
+		fmt.Sprint(m.Duration) != "[2 2]" {
+		t.Errorf("wrong second measure: %+v", m)
+	}
+	if m := parsed.Measures[3]; len(m.Harmony) != 1 || m.Harmony[0].Root.Step != "G" {
+		t.Errorf("wrong last measure: %+v", m)
+	}
+
+	// in 6/8, a beat is an eighth note
+	song, err = chords.ParseSong("{key: Eb}\n{time: 6/8}\n{tempo: 120}\n| E♭ / / B♭7 / / | E♭ |\n")
+	if err != nil {
+		t.Fatalf("failed to parse song: %v", err)
+	}
+	b.Reset()
+	if err := WriteScore(&b, song); err != nil {
+		t.Fatalf("failed to write: %v", err)
+	}
+	out = b.String()
+	if strings.Contains(out, "<work>") || strings.Contains(out, "<identification>") {
+		t.Errorf("WriteScore wrote a title or composer for a song with neither:\n%s", out)
+	}
+	if !strings.Contains(out, "<divisions>2</divisions>") {
+		t.Errorf("wrong divisions:\n%s", out)
+	}
+	parsed.Measures = nil
+	if err := xml.Unmarshal(b.Bytes(), &parsed); err != nil {
+		t.Fatalf("output is not valid XML: %v", err)
+	}
+	if m := parsed.Measures[0]; m.Beats != 6 || m.BeatType != 8 || m.Fifths != -3 || m.Mode != "major" ||
+		m.Sound.Tempo != "60" || fmt.Sprint(m.Duration) != "[3 3]" {
+		t.Errorf("wrong first measure in 6/8: %+v", m)
+	}
+	if m := parsed.Measures[1]; fmt.Sprint(m.Duration) != "[6]" {
+		t.Errorf("wrong second measure in 6/8: %+v", m)
+	}
+}
@@ -0,0 +1,217 @@
+package musicxml
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+
+	"github.com/jhump/chords"
+)
+
+// kindTones are the triad and extra tones for each kind in the MusicXML
+// vocabulary that can be described by a chords.Chord. As in HarmonyOf, the
+// 11th and 13th kinds include a 7th but not the tones in between, so
+// "dominant-13th" is C13, not C9 11 13.
+var kindTones = map[string]struct {
+	triad chords.TriadType
+	tones []chords.ChordTone
+}{
+	"major":              {triad: chords.Maj3},
+	"minor":              {triad: chords.Min3},
+	"augmented":          {triad: chords.Aug3},
+	"diminished":         {triad: chords.Dim3},
+	"dominant":           {triad: chords.Maj3, tones: []chords.ChordTone{{Val: 7}}},
+	"major-seventh":      {triad: chords.Maj3, tones: []chords.ChordTone{{Val: 7, Acc: chords.Sharp}}},
+	"minor-seventh":      {triad: chords.Min3, tones: []chords.ChordTone{{Val: 7}}},
+	"diminished-seventh": {triad: chords.FDim},
+	"augmented-seventh":  {triad: chords.Aug3, tones: []chords.ChordTone{{Val: 7}}},
+	"half-diminished":    {triad: chords.HDim},
+	"major-minor":        {triad: chords.Min3, tones: []chords.ChordTone{{Val: 7, Acc: chords.Sharp}}},
+	"major-sixth":        {triad: chords.Maj3, tones: []chords.ChordTone{{Val: 6}}},
+	"minor-sixth":        {triad: chords.Min3, tones: []chords.ChordTone{{Val: 6}}},
+	"dominant-ninth":     {triad: chords.Maj3, tones: []chords.ChordTone{{Val: 7}, {Val: 9}}},
+	"major-ninth":        {triad: chords.Maj3, tones: []chords.ChordTone{{Val: 7, Acc: chords.Sharp}, {Val: 9}}},
+	"minor-ninth":        {triad: chords.Min3, tones: []chords.ChordTone{{Val: 7}, {Val: 9}}},
+	"dominant-11th":      {triad: chords.Maj3, tones: []chords.ChordTone{{Val: 7}, {Val: 11}}},
+	"major-11th":         {triad: chords.Maj3, tones: []chords.ChordTone{{Val: 7, Acc: chords.Sharp}, {Val: 11}}},
+	"minor-11th":         {triad: chords.Min3, tones: []chords.ChordTone{{Val: 7}, {Val: 11}}},
+	"dominant-13th":      {triad: chords.Maj3, tones: []chords.ChordTone{{Val: 7}, {Val: 13}}},
+	"major-13th":         {triad: chords.Maj3, tones: []chords.ChordTone{{Val: 7, Acc: chords.Sharp}, {Val: 13}}},
+	"minor-13th":         {triad: chords.Min3, tones: []chords.ChordTone{{Val: 7}, {Val: 13}}},
+	"suspended-second":   {triad: chords.Sus, tones: []chords.ChordTone{{Val: 2}}},
+	"suspended-fourth":   {triad: chords.Sus, tones: []chords.ChordTone{{Val: 4}}},
+}
+
+// KindNone is the kind of a harmony that marks a passage with no chord, which
+// is usually shown as "N.C.".
+const KindNone = "none"
+
+// Chord returns the chord described by the chord symbol. The kind and degrees
+// are interpreted the same way as by HarmonyOf, so that converting a chord to
+// a Harmony and back results in the same chord (in canonical form). A degree
+// of type "alter" replaces the tone with the same value, or alters the 3rd or
+// 5th of the triad.
+//
+// If the kind is KindNone, this returns nil and no error. This returns an
+// error if the chord symbol has no root or if it cannot be described by a
+// chords.Chord, like a power chord, a chord with no 3rd or 5th, or one of the
+// kinds that name a classical function (like "Neapolitan").
+func (h Harmony) Chord() (*chords.Chord, error) {
+	if h.Kind.Value == KindNone {
+		return nil, nil
+	}
+	root, err := noteOf(h.Root.Step, h.Root.Alter)
+	if err != nil {
+		return nil, fmt.Errorf("invalid root: %v", err)
+	}
+	kt, ok := kindTones[h.Kind.Value]
+	if !ok {
+		return nil, fmt.Errorf("unsupported kind %q", h.Kind.Value)
+	}
+	ch := &chords.Chord{
+		Root:       root,
+		Triad:      kt.triad,
+		ExtraTones: append([]chords.ChordTone(nil), kt.tones...),
+	}
+	if h.Bass != nil {
+		if ch.Bass, err = noteOf(h.Bass.Step, h.Bass.Alter); err != nil {
+			return nil, fmt.Errorf("invalid bass: %v", err)
+		}
+	}
+
+	for _, d := range h.Degrees {
+		switch d.Value {
+		case 1, 2, 3, 4, 5, 6, 7, 9, 11, 13:
+		default:
+			return nil, fmt.Errorf("invalid degree %d", d.Value)
+		}
+		tn := chords.ChordTone{Val: int8(d.Value), Acc: chords.Accidental(d.Alter)}
+		if tn.Val == 7 {
+			// in a chord name, an unaltered 7th is a minor 7th
+			tn.Acc++
+		}
+		if !tn.Acc.IsValid() {
+			return nil, fmt.Errorf("invalid alteration %d of degree %d", d.Alter, d.Value)
+		}
+		switch d.Type {
+		case DegreeAdd:
+			if tn.Val == 1 || tn.Val == 3 {
+				return nil, fmt.Errorf("cannot add degree %d", d.Value)
+			}
+			ch.ExtraTones = append(ch.ExtraTones, tn)
+		case DegreeAlter:
+			if tn.Val == 1 {
+				return nil, fmt.Errorf("cannot alter degree 1")
+			}
+			if tn.Val == 3 {
+				if err := alterThird(ch, tn.Acc); err != nil {
+					return nil, err
+				}
+				continue
+			}
+			// the altered tone replaces the unaltered one, if the kind has it
+			removeTones(ch, tn.Val)
+			ch.ExtraTones = append(ch.ExtraTones, tn)
+		case DegreeSubtract:
+			if tn.Val == 1 || tn.Val == 3 || tn.Val == 5 || (ch.Triad == chords.Sus && (tn.Val == 2 || tn.Val == 4)) {
+				return nil, fmt.Errorf("cannot subtract degree %d", d.Value)
+			}
+			if !removeTones(ch, tn.Val) {
+				return nil, fmt.Errorf("cannot subtract degree %d: chord does not have it", d.Value)
+			}
+		default:
+			return nil, fmt.Errorf("invalid degree type %q", d.Type)
+		}
+	}
+
+	if h.Inversion > 0 {
+		if ch.Bass.N != 0 {
+			return nil, fmt.Errorf("chord symbol has both a bass and an inversion")
+		}
+		if ch, err = ch.Invert(h.Inversion); err != nil {
+			return nil, err
+		}
+	}
+
+	ch.Canonicalize()
+	return ch, nil
+}
+
+// noteOf returns the note with the given letter name and alteration, as used
+// in <root> and <bass> elements.
+func noteOf(step string, alter int) (chords.Note, error) {
+	if len(step) != 1 || !chords.NoteName(step[0]).IsValid() {
+		return chords.Note{}, fmt.Errorf("invalid step %q", step)
+	}
+	n := chords.Note{N: chords.NoteName(step[0]), Acc: chords.Accidental(alter)}
+	if !n.Acc.IsValid() {
+		return chords.Note{}, fmt.Errorf("invalid alteration %d", alter)
+	}
+	return n, nil
+}
+
+// alterThird alters the 3rd of the given chord, which turns a major chord into
+// a minor chord and vice versa.
+func alterThird(ch *chords.Chord, acc chords.Accidental) error {
+	switch {
+	case ch.Triad == chords.Maj3 && acc == chords.Flat:
+		ch.Triad = chords.Min3
+	case ch.Triad == chords.Min3 && acc == chords.Sharp:
+		ch.Triad = chords.Maj3
+	case (ch.Triad == chords.Maj3 || ch.Triad == chords.Min3) && acc == chords.Natural:
+		// nothing to alter
+	default:
+		return fmt.Errorf("cannot alter the 3rd of a %v chord by %d", ch.Triad, acc.Offset())
+	}
+	return nil
+}
+
+// removeTones removes the extra tones of the given chord with the given value,
+// returning false if it has none.
+func removeTones(ch *chords.Chord, val int8) bool {
+	tones := ch.ExtraTones[:0]
+	for _, tn := range ch.ExtraTones {
+		if tn.Val != val {
+			tones = append(tones, tn)
+		}
+	}
+	removed := len(tones) < len(ch.ExtraTones)
+	ch.ExtraTones = tones
+	return removed
+}
+
+// ReadHarmonies reads a MusicXML document from the given reader and returns
+// the chords of all of its <harmony> elements, in the order they appear. The
+// document may be a whole score or just a sequence of <harmony> elements, like
+// the output of WriteHarmonies. Harmonies whose kind is KindNone are skipped.
+// This returns an error if the document is not well-formed XML or if any of
+// its chord symbols cannot be converted to a chord. (See Harmony.Chord.)
+func ReadHarmonies(r io.Reader) ([]*chords.Chord, error) {
+	dec := xml.NewDecoder(r)
+	var chs []*chords.Chord
+	for {
+		tok, err := dec.Token()
+		if err == io.EOF {
+			return chs, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		start, ok := tok.(xml.StartElement)
+		if !ok || start.Name.Local != "harmony" {
+			continue
+		}
+		var h Harmony
+		if err := dec.DecodeElement(&h, &start); err != nil {
+			return nil, err
+		}
+		ch, err := h.Chord()
+		if err != nil {
+			line, _ := dec.InputPos()
+			return nil, fmt.Errorf("harmony ending on line %d: %v", line, err)
+		}
+		if ch != nil {
+			chs = append(chs, ch)
+		}
+	}
+}
@@ -0,0 +1,138 @@
+package musicxml
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/jhump/chords"
+)
+
+func TestHarmony_Chord(t *testing.T) {
+	// every chord survives a round trip through HarmonyOf
+	for _, s := range []string{
+		"C", "C-", "C7", "C△7", "C-7", "C-△7", "C9", "C△9", "C-9", "C13", "C-11", "C6", "C-6",
+		"C+", "C+7", "C+△7", "Cdim", "Cø", "Co", "Csus2", "Gsus4 7", "Gsus♯4", "C7♯9", "C7♭5",
+		"C13♯11", "C2", "C7♭9♯9", "B♭7/D", "F♯-7/C♯", "E♭6 9",
+	} {
+		ch := chords.MustParseChord(s)
+		ch.Canonicalize()
+		actual, err := HarmonyOf(ch).Chord()
+		if err != nil {
+			t.Errorf("Harmony.Chord for %s failed: %v", s, err)
+			continue
+		}
+		if actual.String() != ch.String() {
+			t.Errorf("Harmony.Chord for %s returned wrong value: %v", s, actual)
+		}
+	}
+
+	// and so does every combination of triad, 7th, and extensions
+	for _, triad := range []string{"", "-", "+", "dim", "sus2", "sus4"} {
+		for _, seventh := range []string{"", "7", "△7"} {
+			for _, ext := range []string{"", "9", "11", "13", "♭9", "♯9", "♯11", "♭13", "6", "6 9", "9 13"} {
+				s := "E♭" + triad + seventh
+				if ext != "" {
+					s += " " + ext
+				}
+				ch, err := chords.ParseChord(s)
+				if err != nil {
+					// not every combination is a valid chord symbol
+					continue
+				}
+				ch.Canonicalize()
+				actual, err := HarmonyOf(ch).Chord()
+				if err != nil {
+					t.Errorf("Harmony.Chord for %s failed: %v", s, err)
+					continue
+				}
+				if actual.String() != ch.String() {
+					t.Errorf("Harmony.Chord for %s returned wrong value: %v != %v", s, actual, ch)
+				}
+			}
+		}
+	}
+
+	cases := []struct {
+		h   Harmony
+		exp string
+	}{
+		{
+			h:   Harmony{Root: Root{Step: "D"}, Kind: Kind{Value: "minor-seventh"}, Degrees: []Degree{{Value: 5, Alter: -1, Type: DegreeAlter}}},
+			exp: "Dø",
+		},
+		{
+			h:   Harmony{Root: Root{Step: "G"}, Kind: Kind{Value: "dominant-ninth"}, Degrees: []Degree{{Value: 9, Alter: -1, Type: DegreeAlter}}},
+			exp: "G7♭9",
+		},
+		{
+			h:   Harmony{Root: Root{Step: "G"}, Kind: Kind{Value: "dominant-ninth"}, Degrees: []Degree{{Value: 7, Type: DegreeSubtract}}},
+			exp: "G9",
+		},
+		{
+			h:   Harmony{Root: Root{Step: "E", Alter: -1}, Kind: Kind{Value: "major"}, Degrees: []Degree{{Value: 3, Alter: -1, Type: DegreeAlter}}},
+			exp: "E♭-",
+		},
+		{
+			h:   Harmony{Root: Root{Step: "C"}, Kind: Kind{Value: "dominant"}, Inversion: 1},
+			exp: "C7/E",
+		},
+	}
+	for _, tc := range cases {
+		ch, err := tc.h.Chord()
+		if err != nil {
+			t.Errorf("%s: failed to convert: %v", tc.exp, err)
+		} else if ch.String() != tc.exp {
+			t.Errorf("Harmony.Chord returned wrong value: %v != %s", ch, tc.exp)
+		}
+	}
+
+	if ch, err := (Harmony{Kind: Kind{Value: KindNone}}).Chord(); ch != nil || err != nil {
+		t.Errorf("Harmony.Chord for N.C. returned wrong value: %v, %v", ch, err)
+	}
+	for _, h := range []Harmony{
+		{Root: Root{Step: "H"}, Kind: Kind{Value: "major"}},
+		{Root: Root{Step: "C"}, Kind: Kind{Value: "power"}},
+		{Root: Root{Step: "C"}, Kind: Kind{Value: "Neapolitan"}},
+		{Root: Root{Step: "C"}, Kind: Kind{Value: "major"}, Degrees: []Degree{{Value: 5, Type: DegreeSubtract}}},
+		{Root: Root{Step: "C"}, Kind: Kind{Value: "major"}, Degrees: []Degree{{Value: 9, Type: DegreeSubtract}}},
+		{Root: Root{Step: "C"}, Kind: Kind{Value: "major"}, Degrees: []Degree{{Value: 9, Type: "replace"}}},
+		{Root: Root{Step: "C"}, Kind: Kind{Value: "major"}, Bass: &Bass{Step: "G"}, Inversion: 2},
+	} {
+		if ch, err := h.Chord(); err == nil {
+			t.Errorf("Harmony.Chord for %+v should have failed: %v", h, ch)
+		}
+	}
+}
+
+func TestReadHarmonies(t *testing.T) {
+	prog := chords.MustParseProgression("| C△7 | A-7 D7♭9 | G7/F |")
+	var b bytes.Buffer
+	if err := WriteScore(&b, &chords.Song{Sections: []*chords.Section{{Body: prog}}}); err != nil {
+		t.Fatalf("failed to write: %v", err)
+	}
+	chs, err := ReadHarmonies(&b)
+	if err != nil {
+		t.Fatalf("failed to read: %v", err)
+	}
+	var strs []string
+	for _, ch := range chs {
+		strs = append(strs, ch.String())
+	}
+	if actual, exp := strings.Join(strs, " "), "C△7 A-7 D7♭9 G7/F"; actual != exp {
+		t.Errorf("ReadHarmonies returned wrong value: %q != %q", actual, exp)
+	}
+
+	doc := `<harmony><root><root-step>F</root-step><root-alter>1</root-alter></root><kind>none</kind></harmony>
+<harmony><root><root-step>F</root-step><root-alter>1</root-alter></root><kind text="m7">minor-seventh</kind></harmony>`
+	if chs, err := ReadHarmonies(strings.NewReader(doc)); err != nil || len(chs) != 1 || chs[0].String() != "F♯-7" {
+		t.Errorf("wrong result: %v, %v", chs, err)
+	}
+	doc = `<harmony><root><root-step>C</root-step></root><kind>power</kind></harmony>`
+	if _, err := ReadHarmonies(strings.NewReader(doc)); err == nil || !strings.Contains(err.Error(), "power") {
+		t.Errorf("ReadHarmonies for power chord returned wrong error: %v", err)
+	}
+	if _, err := ReadHarmonies(strings.NewReader("<harmony><root>")); err == nil {
+		t.Errorf("ReadHarmonies for malformed XML should have failed")
+	}
+}
@@ -0,0 +1,157 @@
+package chords
+
+import (
+	"bytes"
+	"fmt"
+	"unicode"
+)
+
+// Transformation is a neo-Riemannian transformation: an operation that turns
+// a major or minor triad into a triad of the other quality by moving as few
+// voices as possible, by as little as possible. Transformations can be
+// combined into sequences, like "PL" or "LR", which describe progressions
+// between more distant triads. (See Transform.)
+type Transformation int
+
+const (
+	// Parallel (P) turns a triad into the triad of the other quality with
+	// the same root, like C to C-. Only the 3rd moves, by a half-step.
+	Parallel Transformation = iota
+	// Leittonwechsel (L, for "leading-tone exchange") turns a major triad
+	// into the minor triad whose root is its 3rd, like C to E-, and a minor
+	// triad into the major triad whose 3rd is its root, like E- to C. The
+	// root of the major triad moves a half-step to the 5th of the minor
+	// triad.
+	Leittonwechsel
+	// Relative (R) turns a major triad into its relative minor, like C to
+	// A-, and a minor triad into its relative major, like A- to C. The 5th of
+	// the major triad moves a whole step to the root of the minor triad.
+	Relative
+	// Slide (S) turns a major triad into the minor triad whose root is a
+	// half-step higher, like C to C♯-, and a minor triad into the major triad
+	// whose root is a half-step lower, like C♯- to C. The two triads share
+	// only their 3rd, and the other two voices each move a half-step.
+	Slide
+)
+
+// String implements the Stringer interface. The result is the letter that
+// names the transformation, like "P" for Parallel.
+func (t Transformation) String() string {
+	switch t {
+	case Parallel:
+		return "P"
+	case Leittonwechsel:
+		return "L"
+	case Relative:
+		return "R"
+	case Slide:
+		return "S"
+	default:
+		return fmt.Sprintf("?(%d)", t)
+	}
+}
+
+// Apply returns the triad that results from applying this transformation to
+// the given chord. The result is spelled so that the notes the two triads
+// share are spelled the same way, so the L of G♯ is B♯-, not C-. This
+// returns an error if the given chord is not a major or minor triad (with no
+// extra tones or bass note). Every transformation is its own inverse, so
+// applying the same transformation twice results in the original triad.
+func (t Transformation) Apply(ch *Chord) (*Chord, error) {
+	if (ch.Triad != Maj3 && ch.Triad != Min3) || len(ch.ExtraTones) > 0 || ch.Bass.N != 0 {
+		return nil, fmt.Errorf("%v is not a major or minor triad", ch)
+	}
+	major := ch.Triad == Maj3
+	var root Note
+	switch t {
+	case Parallel:
+		root = ch.Root
+	case Leittonwechsel:
+		if major {
+			root = ch.Root.Transpose(Interval{Val: 3})
+		} else {
+			root = ch.Root.TransposeDown(Interval{Val: 3})
+		}
+	case Relative:
+		if major {
+			root = ch.Root.TransposeDown(Interval{Val: 3, Offset: -1})
+		} else {
+			root = ch.Root.Transpose(Interval{Val: 3, Offset: -1})
+		}
+	case Slide:
+		if major {
+			root = ch.Root.Transpose(Interval{Val: 1, Offset: 1})
+		} else {
+			root = ch.Root.TransposeDown(Interval{Val: 1, Offset: 1})
+		}
+	default:
+		return nil, fmt.Errorf("invalid transformation %v", t)
+	}
+	triad := Min3
+	if !major {
+		triad = Maj3
+	}
+	return &Chord{Root: root, Triad: triad}, nil
+}
+
+// ParseTransformations parses a sequence of transformations, written as their
+// letters (see Transformation.String), like "PLR". Whitespace between the
+// letters is ignored, and the letters are not case-sensitive.
+func ParseTransformations(s string) ([]Transformation, error) {
+	var ts []Transformation
+	for _, r := range s {
+		switch unicode.ToUpper(r) {
+		case 'P':
+			ts = append(ts, Parallel)
+		case 'L':
+			ts = append(ts, Leittonwechsel)
+		case 'R':
+			ts = append(ts, Relative)
+		case 'S':
+			ts = append(ts, Slide)
+		default:
+			if !unicode.IsSpace(r) {
+				return nil, fmt.Errorf("invalid transformation %q in %q", r, s)
+			}
+		}
+	}
+	return ts, nil
+}
+
+// TransformationsString returns the given sequence of transformations as a
+// string of their letters, like "PLR". (See ParseTransformations.)
+func TransformationsString(ts []Transformation) string {
+	var b bytes.Buffer
+	for _, t := range ts {
+		b.WriteString(t.String())
+	}
+	return b.String()
+}
+
+// Transform applies the given transformations to the given triad, in order,
+// and returns the triads that result from each one. So the last element is
+// the result of the whole sequence. For example, transforming C by "PL"
+// results in [C- A♭]. Applying L and R in alternation walks through all
+// twenty-four major and minor triads, and applying P and L in alternation
+// cycles through six of them (a hexatonic cycle). Since each step keeps the
+// spelling of the notes the triads share, a cycle may end on an enharmonic
+// spelling of the triad it started from, like D𝄫 instead of C.
+//
+// This returns an error if the given chord is not a major or minor triad.
+// (See Transformation.Apply.) If there are no transformations, the result is
+// empty.
+func Transform(ch *Chord, ts ...Transformation) ([]*Chord, error) {
+	if (ch.Triad != Maj3 && ch.Triad != Min3) || len(ch.ExtraTones) > 0 || ch.Bass.N != 0 {
+		return nil, fmt.Errorf("%v is not a major or minor triad", ch)
+	}
+	chs := make([]*Chord, 0, len(ts))
+	for _, t := range ts {
+		next, err := t.Apply(ch)
+		if err != nil {
+			return nil, err
+		}
+		chs = append(chs, next)
+		ch = next
+	}
+	return chs, nil
+}
@@ -0,0 +1,93 @@
+package chords
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestTransformation_Apply(t *testing.T) {
+	cases := []struct {
+		chord string
+		p, l  string
+		r, s  string
+	}{
+		{chord: "C", p: "C-", l: "E-", r: "A-", s: "C♯-"},
+		{chord: "C-", p: "C", l: "A♭", r: "E♭", s: "C♭"},
+		{chord: "E♭", p: "E♭-", l: "G-", r: "C-", s: "E-"},
+		{chord: "G♯", p: "G♯-", l: "B♯-", r: "E♯-", s: "G𝄪-"},
+		{chord: "F♯-", p: "F♯", l: "D", r: "A", s: "F"},
+	}
+	for _, tc := range cases {
+		ch := MustParseChord(tc.chord)
+		for i, exp := range []string{tc.p, tc.l, tc.r, tc.s} {
+			tr := Transformation(i)
+			actual, err := tr.Apply(ch)
+			if err != nil {
+				t.Errorf("Transformation.Apply for %v of %s failed: %v", tr, tc.chord, err)
+				continue
+			}
+			if actual.String() != exp {
+				t.Errorf("Transformation.Apply for %v of %s returned wrong value: %v != %s", tr, tc.chord, actual, exp)
+			}
+			// every transformation is its own inverse
+			if back, err := tr.Apply(actual); err != nil || back.String() != tc.chord {
+				t.Errorf("Transformation.Apply for %v of %v returned wrong value: %v, %v != %s", tr, actual, back, err, tc.chord)
+			}
+		}
+	}
+
+	for _, s := range []string{"C7", "Cdim", "C+", "Csus4", "C/E"} {
+		if actual, err := Parallel.Apply(MustParseChord(s)); err == nil {
+			t.Errorf("Transformation.Apply for P of %s should have failed: %v", s, actual)
+		}
+	}
+}
+
+func TestTransform(t *testing.T) {
+	cases := []struct {
+		chord, ts, exp string
+	}{
+		{chord: "C", ts: "PL", exp: "[C- A♭]"},
+		{chord: "C", ts: "p l r", exp: "[C- A♭ F-]"},
+		// the hexatonic cycle ends on a C, but spelled as D𝄫 since spellings
+		// of the common tones are kept at each step
+		{chord: "C", ts: "PLPLPL", exp: "[C- A♭ A♭- F♭ F♭- D𝄫]"},
+		{chord: "A-", ts: "", exp: "[]"},
+	}
+	for _, tc := range cases {
+		ts, err := ParseTransformations(tc.ts)
+		if err != nil {
+			t.Fatalf("failed to parse %q: %v", tc.ts, err)
+		}
+		chs, err := Transform(MustParseChord(tc.chord), ts...)
+		if err != nil {
+			t.Errorf("Transform for %s %s failed: %v", tc.chord, tc.ts, err)
+		} else if actual := fmt.Sprint(chs); actual != tc.exp {
+			t.Errorf("Transform for %s %s returned wrong value: %s != %s", tc.chord, tc.ts, actual, tc.exp)
+		}
+	}
+
+	// alternating L and R visits all 24 major and minor triads
+	ts, _ := ParseTransformations("LR LR LR LR LR LR LR LR LR LR LR LR")
+	chs, err := Transform(MustParseChord("C"), ts...)
+	if err != nil {
+		t.Fatalf("Transform failed: %v", err)
+	}
+	seen := map[PitchClassSet]bool{}
+	for _, ch := range chs {
+		seen[ch.PitchClassSet()] = true
+	}
+	if len(seen) != 24 || chs[len(chs)-1].PitchClassSet() != MustParseChord("C").PitchClassSet() {
+		t.Errorf("Transform for LR x 12 returned wrong value: %v", chs)
+	}
+
+	if TransformationsString(ts[:4]) != "LRLR" {
+		t.Errorf("wrong string: %s", TransformationsString(ts[:4]))
+	}
+	if _, err := ParseTransformations("PLX"); err == nil {
+		t.Errorf("Transform for invalid transformation should have failed")
+	}
+	if _, err := Transform(MustParseChord("C7"), Parallel); err == nil {
+		t.Errorf("Transform for C7 should have failed")
+	}
+}
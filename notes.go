@@ -3,6 +3,7 @@ package chords
 import (
 	"errors"
 	"fmt"
+	"strings"
 )
 
 // TransposeNote returns a new set of notes that are the given root note
@@ -47,30 +48,7 @@ func MeasureIntervals(root Note, notes ...Note) []Interval {
 func Negate(root Note, notes ...Note) []Note {
 	neg := make([]Note, len(notes))
 	for i, n := range notes {
-		intv := root.IntervalTo(n)
-		dist := 12 - intv.NumHalfSteps()
-		if dist == 12 {
-			neg[i] = n
-			continue
-		}
-		negIntv := Interval{Val: 8 - intv.Val}
-		offs := dist - negIntv.NumHalfSteps()
-		for offs < -2 {
-			negIntv.Val--
-			if negIntv.Val < 1 {
-				negIntv.Val += 7
-			}
-			offs = dist - negIntv.NumHalfSteps()
-		}
-		for offs > 2 {
-			negIntv.Val++
-			if negIntv.Val > 7 {
-				negIntv.Val -= 7
-			}
-			offs = dist - negIntv.NumHalfSteps()
-		}
-		negIntv.Offset = offs
-		neg[i] = root.Transpose(negIntv)
+		neg[i] = root.TransposeDown(root.IntervalTo(n))
 	}
 	return neg
 }
@@ -97,17 +75,16 @@ const (
 	G
 )
 
-var noteCardinals []int8
-
-func init() {
-	noteCardinals = make([]int8, int(G-'A')+1)
-	noteCardinals[int(A-'A')] = 0
-	noteCardinals[int(B-'A')] = 2
-	noteCardinals[int(C-'A')] = 3
-	noteCardinals[int(D-'A')] = 5
-	noteCardinals[int(E-'A')] = 7
-	noteCardinals[int(F-'A')] = 8
-	noteCardinals[int(G-'A')] = 10
+// noteCardinals is the cardinality of each note name, indexed by the name's
+// offset from A.
+var noteCardinals = [...]int8{
+	int(A - 'A'): 0,
+	int(B - 'A'): 2,
+	int(C - 'A'): 3,
+	int(D - 'A'): 5,
+	int(E - 'A'): 7,
+	int(F - 'A'): 8,
+	int(G - 'A'): 10,
 }
 
 // Cardinal returns the cardinality of this note name, as measured in half-step
@@ -129,6 +106,12 @@ func (n NoteName) IsValid() bool {
 	return n >= A && n <= G
 }
 
+// add returns the note name that is the given number of letters after this
+// one, wrapping from G back to A. So C.add(2) is E and C.add(-3) is G.
+func (n NoteName) add(steps int8) NoteName {
+	return A + NoteName(posMod(int8(n-A)+steps, 7))
+}
+
 // Note represents a note in modern diatonic music. It is a note name combined
 // with an accidental. For example, the note A is the note name 'A' with an
 // accidental NATURAL. The note F# is the note name 'F' with an accidental SHARP.
@@ -159,6 +142,31 @@ func ParseNote(s string) (Note, error) {
 	return Note{N: n, Acc: a}, nil
 }
 
+// parseNotePrefix parses a note from the start of the given string and returns
+// the remaining, unparsed portion of the string. The longest possible
+// accidental is consumed, so "Bbb" is a double-flat B, not a flat B followed by
+// a "b".
+func parseNotePrefix(s string) (Note, string, error) {
+	if len(s) == 0 {
+		return Note{}, "", errors.New("cannot parse note from empty string")
+	}
+	n := NoteName(s[0])
+	if !n.IsValid() {
+		return Note{}, "", fmt.Errorf("invalid note name %q", string(rune(s[0])))
+	}
+	s = s[1:]
+	for _, acc := range []string{"bb", "𝄫", "𝄪", "♮", "♯", "♭", "n", "#", "b", "x"} {
+		if strings.HasPrefix(s, acc) {
+			a, err := parseAccidental(acc)
+			if err != nil {
+				return Note{}, "", err
+			}
+			return Note{N: n, Acc: a}, s[len(acc):], nil
+		}
+	}
+	return Note{N: n}, s, nil
+}
+
 // MustParseNote parses the given string into a note and panics if the string is
 // not valid. (See ParseNote.)
 func MustParseNote(s string) Note {
@@ -182,49 +190,143 @@ func (n Note) Cardinal() int8 {
 }
 
 // Transpose returns the note that results from transposing this note by the
-// given interval.
+// given interval. The note name of the result is determined by the interval's
+// Val, so transposing C up a minor third is E♭ (not D♯). If that would require
+// more than a double-sharp or double-flat, as when transposing C𝄪 up an
+// augmented second, an enharmonically equivalent note with an adjacent note
+// name is returned instead (E♯ instead of D𝄪♯).
 func (n Note) Transpose(interval Interval) Note {
-	np := majorScales[n][posMod(int8(interval.Val)-1, 7)]
-	o := interval.Offset
-	for o != 0 {
-		if o >= -4 && o <= 4 {
-			np = offsetsByNote[np][o+4]
-			break
-		}
-		if o < 0 {
-			np = offsetsByNote[np][0]
-			o += 4
-		} else {
-			np = offsetsByNote[np][4]
-			o -= 4
-		}
+	v := posMod(interval.Val-1, 7)
+	name := n.N.add(v)
+	c := posMod(n.N.Cardinal()+n.Acc.Offset()+stepsByInterval[v]+interval.Offset, 12)
+	acc := halfStepDelta(c, name.Cardinal())
+	for acc > 2 {
+		name = name.add(1)
+		acc = halfStepDelta(c, name.Cardinal())
 	}
-	return np
+	for acc < -2 {
+		name = name.add(-1)
+		acc = halfStepDelta(c, name.Cardinal())
+	}
+	return Note{N: name, Acc: Accidental(acc)}
 }
 
+// IntervalTo returns the interval from this note up to the given note. The
+// interval's Val is determined by the note names, so the interval from C to E
+// is a third, and the interval from C to F♭ is a (diminished) fourth, even
+// though E and F♭ are enharmonic equivalents. If the distance cannot be
+// represented with an accidental offset between -2 and 2 (as with C to F𝄪𝄪,
+// which is not a valid note but could arise from transposition), an
+// enharmonically equivalent interval is returned instead.
 func (n Note) IntervalTo(other Note) Interval {
 	var intv Interval
-	intv.Val = posMod(int8(other.N-'a')-int8(n.N-'a'), 8)
-	dHalfSteps := posMod(int8(other.Cardinal()-n.Cardinal()), 12)
-	offs := dHalfSteps - intv.NumHalfSteps()
+	intv.Val = posMod(int8(other.N)-int8(n.N), 7) + 1
+	dHalfSteps := posMod(other.Cardinal()-n.Cardinal(), 12)
+	offs := halfStepDelta(dHalfSteps, intv.NumHalfSteps())
 	for offs < -2 {
 		intv.Val--
 		if intv.Val < 1 {
 			intv.Val += 7
 		}
-		offs = dHalfSteps - intv.NumHalfSteps()
+		offs = halfStepDelta(dHalfSteps, intv.NumHalfSteps())
 	}
 	for offs > 2 {
 		intv.Val++
 		if intv.Val > 7 {
 			intv.Val -= 7
 		}
-		offs = dHalfSteps - intv.NumHalfSteps()
+		offs = halfStepDelta(dHalfSteps, intv.NumHalfSteps())
 	}
 	intv.Offset = offs
 	return intv
 }
 
+// TransposeDown returns the note that results from transposing this note down
+// by the given interval. For example, transposing C down a minor third is A
+// (not B♯♯ or any other enharmonic equivalent).
+func (n Note) TransposeDown(interval Interval) Note {
+	return n.Transpose(interval.Invert())
+}
+
+// TransposeHalfSteps returns the note that results from transposing this note
+// up by the given number of half-steps (or down, if n is negative). The given
+// preference determines how the result is spelled. (See AccidentalPreference.)
+func (n Note) TransposeHalfSteps(steps int8, prefer AccidentalPreference) Note {
+	if prefer == PreferSimplest {
+		np := n.Transpose(intervalsByHalfSteps[posMod(steps, 12)])
+		if np.Acc == Natural ||
+			(np.Acc >= Flat && np.Acc <= Sharp && np.Enharmonic(Natural) == (Note{})) {
+			return np
+		}
+		if np.Acc < 0 {
+			prefer = PreferFlats
+		} else {
+			prefer = PreferSharps
+		}
+	}
+	return spellPitchClass(posMod(n.Cardinal()+steps, 12), prefer)
+}
+
+// Enharmonic returns the spelling of this note that uses the given accidental,
+// or the zero Note if there is no such spelling. For example, the
+// enharmonic of C♯ with a flat accidental is D♭.
+func (n Note) Enharmonic(acc Accidental) Note {
+	if n.Acc == acc {
+		return n
+	}
+	for _, e := range n.Enharmonics() {
+		if e.Acc == acc {
+			return e
+		}
+	}
+	return Note{}
+}
+
+// spellPitchClass returns a note whose Cardinal is c. If the pitch class
+// corresponds to a natural note, that note is returned. Otherwise, the note is
+// sharp or flat according to the given preference.
+func spellPitchClass(c int8, prefer AccidentalPreference) Note {
+	acc := Sharp
+	if prefer == PreferFlats {
+		acc = Flat
+	}
+	var ret Note
+	for name := A; name <= G; name++ {
+		switch name.Cardinal() {
+		case c:
+			return Note{N: name}
+		case posMod(c-acc.Offset(), 12):
+			ret = Note{N: name, Acc: acc}
+		}
+	}
+	return ret
+}
+
+// Enharmonics returns the other spellings of this note: the notes with
+// different names but the same pitch class. Only spellings with at most a
+// double-sharp or double-flat are returned. For example, the enharmonics of
+// C♯ are D♭ and B𝄪, and the only enharmonic of G♯ is A♭. The notes are
+// ordered by name, starting with the name after this note's name.
+func (n Note) Enharmonics() []Note {
+	var notes []Note
+	c := n.Cardinal()
+	for i := int8(1); i < 7; i++ {
+		name := n.N.add(i)
+		acc := Accidental(halfStepDelta(c, name.Cardinal()))
+		if acc.IsValid() {
+			notes = append(notes, Note{N: name, Acc: acc})
+		}
+	}
+	return notes
+}
+
+// halfStepDelta returns the difference between the two given numbers of
+// half-steps, both of which are within a single octave, as a value between -6
+// and 5 (inclusive).
+func halfStepDelta(a, b int8) int8 {
+	return posMod(a-b+6, 12) - 6
+}
+
 // posMod computes modulo, but always returning non-negative result
 func posMod(x int8, n int8) int8 {
 	return (x%n + n) % n
@@ -288,97 +390,16 @@ type Interval struct {
 	Offset int8
 }
 
-var stepsByInterval = []int8{0, 2, 4, 5, 7, 9, 11}
-var offsetsByNote_strings = map[string][]string{
-	"Ax":  {"Abb", "Ab", "A", "A#", "Ax", "B#", "Bx", "Cx", "D#"},
-	"A#":  {"Gb", "Abb", "Ab", "A", "A#", "Ax", "B#", "Bx", "Cx"},
-	"A":   {"Gbb", "Gb", "Abb", "Ab", "A", "A#", "Ax", "B#", "Bx"},
-	"Ab":  {"Fb", "Gbb", "Gb", "Abb", "Ab", "A", "A#", "Ax", "B#"},
-	"Abb": {"Fbb", "Fb", "Gbb", "Gb", "Abb", "Ab", "A", "A#", "Ax"},
-
-	"Bx":  {"Bbb", "Bb", "B", "B#", "Bx", "Cx", "D#", "Dx", "E#"},
-	"B#":  {"Ab", "Bbb", "Bb", "B", "B#", "Bx", "Cx", "D#", "Dx"},
-	"B":   {"Abb", "Ab", "Bbb", "Bb", "B", "B#", "Bx", "Cx", "D#"},
-	"Bb":  {"Gb", "Abb", "Ab", "Bbb", "Bb", "B", "B#", "Bx", "Cx"},
-	"Bbb": {"Gbb", "Gb", "Abb", "Ab", "Bbb", "Bb", "B", "B#", "Bx"},
-
-	"Cx":  {"Cbb", "Cb", "C", "C#", "Cx", "D#", "Dx", "E#", "Ex"},
-	"C#":  {"Bbb", "Cbb", "Cb", "C", "C#", "Cx", "D#", "Dx", "E#"},
-	"C":   {"Ab", "Bbb", "Cbb", "Cb", "C", "C#", "Cx", "D#", "Dx"},
-	"Cb":  {"Abb", "Ab", "Bbb", "Cbb", "Cb", "C", "C#", "Cx", "D#"},
-	"Cbb": {"Gb", "Abb", "Ab", "Bbb", "Cbb", "Cb", "C", "C#", "Cx"},
-
-	"Dx":  {"Dbb", "Db", "D", "D#", "Dx", "E#", "Ex", "Fx", "G#"},
-	"D#":  {"Cb", "Dbb", "Db", "D", "D#", "Dx", "E#", "Ex", "Fx"},
-	"D":   {"Cbb", "Cb", "Dbb", "Db", "D", "D#", "Dx", "E#", "Ex"},
-	"Db":  {"Bbb", "Cbb", "Cb", "Dbb", "Db", "D", "D#", "Dx", "E#"},
-	"Dbb": {"Ab", "Bbb", "Cbb", "Cb", "Dbb", "Db", "D", "D#", "Dx"},
-
-	"Ex":  {"Ebb", "Eb", "E", "E#", "Ex", "Fx", "G#", "Gx", "A#"},
-	"E#":  {"Db", "Ebb", "Eb", "E", "E#", "Ex", "Fx", "G#", "Gx"},
-	"E":   {"Dbb", "Db", "Ebb", "Eb", "E", "E#", "Ex", "Fx", "G#"},
-	"Eb":  {"Cb", "Dbb", "Db", "Ebb", "Eb", "E", "E#", "Ex", "Fx"},
-	"Ebb": {"Cbb", "Cb", "Dbb", "Db", "Ebb", "Eb", "E", "E#", "Ex"},
-
-	"Fx":  {"Fbb", "Fb", "F", "F#", "Fx", "G#", "Gx", "A#", "Ax"},
-	"F#":  {"Ebb", "Fbb", "Fb", "F", "F#", "Fx", "G#", "Gx", "A#"},
-	"F":   {"Db", "Ebb", "Fbb", "Fb", "F", "F#", "Fx", "G#", "Gx"},
-	"Fb":  {"Dbb", "Db", "Ebb", "Fbb", "Fb", "F", "F#", "Fx", "G#"},
-	"Fbb": {"Cb", "Dbb", "Db", "Ebb", "Fbb", "Fb", "F", "F#", "Fx"},
-
-	"Gx":  {"Gbb", "Gb", "G", "G#", "Gx", "A#", "Ax", "B#", "Bx"},
-	"G#":  {"Fb", "Gbb", "Gb", "G", "G#", "Gx", "A#", "Ax", "B#"},
-	"G":   {"Fbb", "Fb", "Gbb", "Gb", "G", "G#", "Gx", "A#", "Ax"},
-	"Gb":  {"Ebb", "Fbb", "Fb", "Gbb", "Gb", "G", "G#", "Gx", "A#"},
-	"Gbb": {"Db", "Ebb", "Fbb", "Fb", "Gbb", "Gb", "G", "G#", "Gx"},
-}
-var offsetsByNote map[Note][]Note
-var majorScales_strings = map[string][]string{
-	"A": {"A", "B", "C#", "D", "E", "F#", "G#"},
-	"B": {"B", "C#", "D#", "E", "F#", "G#", "A#"},
-	"C": {"C", "D", "E", "F", "G", "A", "B"},
-	"D": {"D", "E", "F#", "G", "A", "B", "C#"},
-	"E": {"E", "F#", "G#", "A", "B", "C#", "D#"},
-	"F": {"F", "G", "A", "Bb", "C", "D", "E"},
-	"G": {"G", "A", "B", "C", "D", "E", "F#"},
-}
-var majorScales map[Note][]Note
-
-func init() {
-	offsetsByNote = map[Note][]Note{}
-	for k, vs := range offsetsByNote_strings {
-		n := MustParseNote(k)
-		ns := make([]Note, len(vs))
-		for i, v := range vs {
-			ns[i] = MustParseNote(v)
-			// sanity check that the table has valid data
-			if int8(ns[i].Cardinal()) != posMod(int8(n.Cardinal())+int8(i)-4, 12) {
-				panic(fmt.Errorf("offset table is incorrect! %v (%d) offset by %d (%d) != %v (%d)",
-					n, n.Cardinal(), i-4, posMod(int8(n.Cardinal())+int8(i)-4, 12), ns[i], ns[i].Cardinal()))
-			}
-		}
-		offsetsByNote[n] = ns
-	}
-	majorScales = map[Note][]Note{}
-	for k, vs := range majorScales_strings {
-		n := MustParseNote(k)
-		ns := make([]Note, len(vs))
-		for i, v := range vs {
-			ns[i] = MustParseNote(v)
-		}
-		for acc := Natural; acc < DblSharp; acc++ {
-			if acc.Offset() == 0 {
-				majorScales[n] = ns
-				continue
-			}
-			accn := Note{N: n.N, Acc: acc}
-			accns := make([]Note, len(ns))
-			for i, pp := range ns {
-				accns[i] = offsetsByNote[pp][acc.Offset()+4]
-			}
-			majorScales[accn] = accns
-		}
-	}
+// stepsByInterval is the number of half-steps in each interval, indexed by
+// Val-1, when the Offset is zero.
+var stepsByInterval = [...]int8{0, 2, 4, 5, 7, 9, 11}
+
+// intervalsByHalfSteps is the conventional spelling of the interval for each
+// number of half-steps in an octave.
+var intervalsByHalfSteps = [12]Interval{
+	{Val: 1}, {Val: 2, Offset: -1}, {Val: 2}, {Val: 3, Offset: -1},
+	{Val: 3}, {Val: 4}, {Val: 5, Offset: -1}, {Val: 5},
+	{Val: 6, Offset: -1}, {Val: 6}, {Val: 7, Offset: -1}, {Val: 7},
 }
 
 // NumHalfSteps returns the distance, in half-steps, that this interval
@@ -388,6 +409,18 @@ func (i Interval) NumHalfSteps() int8 {
 	return posMod(stepsByInterval[i.Val-1]+i.Offset, 12)
 }
 
+// Invert returns the inversion of this interval, which is the interval that
+// spans the rest of the octave. For example, a minor third inverts to a major
+// sixth, and a perfect fourth inverts to a perfect fifth. Transposing a note
+// up by an interval's inversion results in the same note as transposing it
+// down by the interval. The unison interval inverts to itself.
+func (i Interval) Invert() Interval {
+	inv := Interval{Val: posMod(8-i.Val, 7) + 1}
+	steps := stepsByInterval[i.Val-1] + i.Offset
+	inv.Offset = halfStepDelta(posMod(12-steps, 12), inv.NumHalfSteps())
+	return inv
+}
+
 // IsValid returns true if the interval is valid. The interval is valid if its
 // Val is between 1 and 7 (inclusive) and its Offset is between -2 and 2 (which
 // correspond to the extents of a double-flat or double-sharp qualifier).
@@ -395,6 +428,237 @@ func (i Interval) IsValid() bool {
 	return i.Val >= 1 && i.Val <= 7 && i.Offset >= -2 && i.Offset <= 2
 }
 
+// Quality returns the quality of this interval. Unisons, fourths, and fifths
+// are perfect intervals: with a zero Offset they are Perfect, and lowering
+// them by a half-step makes them Diminished. The others are Major with a zero
+// Offset and Minor when lowered by a half-step; lowering them by two
+// half-steps makes them Diminished. All intervals are Augmented when raised
+// by a half-step.
+func (i Interval) Quality() Quality {
+	if i.isPerfect() {
+		switch i.Offset {
+		case -2:
+			return DoublyDiminished
+		case -1:
+			return Diminished
+		case 0:
+			return Perfect
+		}
+	} else {
+		switch i.Offset {
+		case -2:
+			return Diminished
+		case -1:
+			return Minor
+		case 0:
+			return Major
+		}
+	}
+	switch i.Offset {
+	case 1:
+		return Augmented
+	case 2:
+		return DoublyAugmented
+	default:
+		return Quality(0)
+	}
+}
+
+// isPerfect returns true for unisons, fourths, and fifths.
+func (i Interval) isPerfect() bool {
+	return i.Val == 1 || i.Val == 4 || i.Val == 5
+}
+
+// IntervalFromHalfSteps returns an interval that spans the given number of
+// half-steps. This is the inverse of Interval.NumHalfSteps. Since there are
+// several ways to spell most intervals, the given quality indicates which
+// spelling is preferred. For example, 6 half-steps is an augmented fourth
+// (♯4) if Augmented is preferred, or a diminished fifth (♭5) if Diminished is
+// preferred. If no interval of the preferred quality spans the given number
+// of half-steps, the conventional spelling is returned: a minor or major
+// interval, a perfect interval, or, for 6 half-steps, a diminished fifth.
+//
+// The given number of half-steps is reduced to a single octave, so 13
+// half-steps is the same as 1 and -1 is the same as 11.
+func IntervalFromHalfSteps(n int8, prefer Quality) Interval {
+	n = posMod(n, 12)
+	for v := int8(1); v <= 7; v++ {
+		intv := Interval{Val: v}
+		intv.Offset = halfStepDelta(n, intv.NumHalfSteps())
+		if intv.IsValid() && intv.Quality() == prefer {
+			return intv
+		}
+	}
+	return intervalsByHalfSteps[n]
+}
+
+// Quality describes an interval as perfect, major, minor, augmented, or
+// diminished. (See Interval.Quality.)
+type Quality int8
+
+const (
+	// Perfect is the quality of unisons, fourths, and fifths that have no
+	// accidental offset.
+	Perfect Quality = iota + 1
+	// Major is the quality of seconds, thirds, sixths, and sevenths that have
+	// no accidental offset.
+	Major
+	// Minor is the quality of a major interval lowered by a half-step.
+	Minor
+	// Augmented is the quality of a perfect or major interval raised by a
+	// half-step.
+	Augmented
+	// Diminished is the quality of a perfect interval lowered by a half-step
+	// or a major interval lowered by two half-steps.
+	Diminished
+	// DoublyAugmented is the quality of a perfect or major interval raised by
+	// two half-steps.
+	DoublyAugmented
+	// DoublyDiminished is the quality of a perfect interval lowered by two
+	// half-steps.
+	DoublyDiminished
+)
+
+// String implements the Stringer interface.
+func (q Quality) String() string {
+	switch q {
+	case Perfect:
+		return "perfect"
+	case Major:
+		return "major"
+	case Minor:
+		return "minor"
+	case Augmented:
+		return "augmented"
+	case Diminished:
+		return "diminished"
+	case DoublyAugmented:
+		return "doubly augmented"
+	case DoublyDiminished:
+		return "doubly diminished"
+	default:
+		return fmt.Sprintf("?(%d)", q)
+	}
+}
+
+// IsValid returns true if the quality value is valid.
+func (q Quality) IsValid() bool {
+	return q >= Perfect && q <= DoublyDiminished
+}
+
+// CompoundInterval is an interval that may span more than one octave. It is
+// like an Interval except that its Val may be greater than 7. A Val of 8 is an
+// octave, 9 is a ninth (an octave plus a second), and so on. The Offset has the
+// same meaning as it does for an Interval. So a minor ninth has a Val of 9 and
+// an Offset of -1; a sharp eleventh has a Val of 11 and an Offset of 1.
+//
+// A CompoundInterval can also be descending, which is indicated by a negative
+// Val. The Offset still describes the quality of the interval, not its
+// direction. So a descending minor third has a Val of -3 and an Offset of -1,
+// and it spans -3 half-steps. A Val of zero is not valid.
+type CompoundInterval struct {
+	Val    int8
+	Offset int8
+}
+
+// Compound returns the compound interval that is the given interval plus the
+// given number of octaves. For example, Compound(Interval{Val: 2}, 1) is a
+// major ninth.
+func Compound(intv Interval, octaves int8) CompoundInterval {
+	return CompoundInterval{Val: intv.Val + 7*octaves, Offset: intv.Offset}
+}
+
+// Simple returns the interval within a single octave that corresponds to this
+// compound interval. For example, the simple interval for a major ninth is a
+// major second. The direction of the interval is ignored, so the simple
+// interval for a descending minor tenth is a minor third.
+func (i CompoundInterval) Simple() Interval {
+	return Interval{Val: posMod(i.size()-1, 7) + 1, Offset: i.Offset}
+}
+
+// Octaves returns the number of whole octaves spanned by this interval, as
+// measured by its Val. So a ninth spans one octave, and a seventh spans zero.
+// The direction of the interval is ignored, so this is never negative.
+func (i CompoundInterval) Octaves() int8 {
+	return (i.size() - 1) / 7
+}
+
+// size returns the magnitude of i.Val.
+func (i CompoundInterval) size() int8 {
+	if i.Val < 0 {
+		return -i.Val
+	}
+	return i.Val
+}
+
+// IsDescending returns true if this interval is descending (i.e. has a
+// negative Val).
+func (i CompoundInterval) IsDescending() bool {
+	return i.Val < 0
+}
+
+// Reverse returns the interval that spans the same distance but in the
+// opposite direction. So reversing a minor third results in a descending minor
+// third, and vice versa.
+func (i CompoundInterval) Reverse() CompoundInterval {
+	return CompoundInterval{Val: -i.Val, Offset: i.Offset}
+}
+
+// NumHalfSteps returns the distance, in half-steps, that this interval
+// represents. Unlike Interval.NumHalfSteps, the result is not reduced to a
+// single octave. So a major ninth is 14 half-steps. The result is negative
+// for descending intervals (and for ascending intervals that are lowered below
+// a unison, like a diminished unison).
+func (i CompoundInterval) NumHalfSteps() int {
+	steps := 12*int(i.Octaves()) + int(stepsByInterval[posMod(i.size()-1, 7)]) + int(i.Offset)
+	if i.Val < 0 {
+		return -steps
+	}
+	return steps
+}
+
+// IsValid returns true if the interval is valid. The interval is valid if its
+// Val is not zero and its Offset is between -2 and 2.
+func (i CompoundInterval) IsValid() bool {
+	return i.Val != 0 && i.Offset >= -2 && i.Offset <= 2
+}
+
+// String implements the Stringer interface. It returns the conventional name
+// of the interval, such as "minor 9th", "perfect 11th", or "augmented 4th".
+// Descending intervals have a "descending" prefix, as in "descending major
+// 2nd".
+func (i CompoundInterval) String() string {
+	quality := i.Simple().Quality()
+	if !quality.IsValid() || i.Val == 0 {
+		return fmt.Sprintf("?(%d, %d)", i.Val, i.Offset)
+	}
+	if i.Val < 0 {
+		return "descending " + quality.String() + " " + ordinal(-i.Val)
+	}
+	return quality.String() + " " + ordinal(i.Val)
+}
+
+func ordinal(v int8) string {
+	switch {
+	case v == 1:
+		return "unison"
+	case v == 8:
+		return "octave"
+	case v == 15:
+		return "double octave"
+	case v%100 >= 11 && v%100 <= 13:
+		return fmt.Sprintf("%dth", v)
+	case v%10 == 1:
+		return fmt.Sprintf("%dst", v)
+	case v%10 == 2:
+		return fmt.Sprintf("%dnd", v)
+	case v%10 == 3:
+		return fmt.Sprintf("%drd", v)
+	default:
+		return fmt.Sprintf("%dth", v)
+	}
+}
+
 // Accidental describes a note modifier. An unmodified note is a "natural" note,
 // which means no accidental. The others are standard symbols used in music
 // notation to indicate pitches that fall outside a key signature and to
@@ -449,6 +713,26 @@ func (a Accidental) IsValid() bool {
 	return a >= DblFlat && a <= DblSharp
 }
 
+// AccidentalPreference indicates how to spell a note when there is a choice of
+// accidentals, such as when the note is computed from a number of half-steps.
+type AccidentalPreference int8
+
+const (
+	// PreferSimplest spells notes conventionally, relative to a starting note.
+	// For example, a note one half-step above D is spelled E♭ (a minor second)
+	// and a note one half-step above F♯ is spelled G. But spellings with
+	// double accidentals or with accidentals on notes that have a natural
+	// spelling (like E♯ or C♭) are avoided: the note four half-steps above
+	// B♭ is D, but the note four half-steps above C♯ is F (not E♯).
+	PreferSimplest AccidentalPreference = iota
+	// PreferSharps spells notes as naturals when possible and as sharps
+	// otherwise.
+	PreferSharps
+	// PreferFlats spells notes as naturals when possible and as flats
+	// otherwise.
+	PreferFlats
+)
+
 func parseAccidental(s string) (Accidental, error) {
 	switch s {
 	case "n", "♮":
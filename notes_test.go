@@ -1,6 +1,9 @@
 package chords
 
 import (
+	"fmt"
+	"math"
+	"strings"
 	"testing"
 )
 
@@ -109,11 +112,329 @@ func TestNote_Cardinal(t *testing.T) {
 }
 
 func TestNote_IntervalTo(t *testing.T) {
-	// TODO
+	cases := []struct {
+		from, to string
+		exp      Interval
+	}{
+		{"C", "C", Interval{Val: 1}},
+		{"C", "D", Interval{Val: 2}},
+		{"C", "Eb", Interval{Val: 3, Offset: -1}},
+		{"C", "D#", Interval{Val: 2, Offset: 1}},
+		{"C", "Fb", Interval{Val: 4, Offset: -1}},
+		{"C", "Cb", Interval{Val: 1, Offset: -1}},
+		{"C", "B", Interval{Val: 7}},
+		{"A", "C", Interval{Val: 3, Offset: -1}},
+		{"F#", "C", Interval{Val: 5, Offset: -1}},
+		{"Bb", "Ab", Interval{Val: 7, Offset: -1}},
+		{"G#", "Fx", Interval{Val: 7}},
+		{"Cbb", "Fx", Interval{Val: 5, Offset: 2}},
+	}
+	for _, tc := range cases {
+		from, to := MustParseNote(tc.from), MustParseNote(tc.to)
+		intv := from.IntervalTo(to)
+		if intv != tc.exp {
+			t.Errorf("Note.IntervalTo for %v -> %v returned wrong value: %v != %v", from, to, intv, tc.exp)
+		}
+		if from.Transpose(intv).Cardinal() != to.Cardinal() {
+			t.Errorf("Note.IntervalTo for %v -> %v returned %v, which is the wrong distance", from, to, intv)
+		}
+	}
+}
+
+func TestIntervalFromHalfSteps(t *testing.T) {
+	cases := []struct {
+		n      int8
+		prefer Quality
+		exp    Interval
+	}{
+		{0, Perfect, Interval{Val: 1}},
+		{6, Augmented, Interval{Val: 4, Offset: 1}},
+		{6, Diminished, Interval{Val: 5, Offset: -1}},
+		{6, Major, Interval{Val: 5, Offset: -1}},
+		{3, Minor, Interval{Val: 3, Offset: -1}},
+		{3, Augmented, Interval{Val: 2, Offset: 1}},
+		{3, Major, Interval{Val: 3, Offset: -1}},
+		{8, Augmented, Interval{Val: 5, Offset: 1}},
+		{9, Diminished, Interval{Val: 7, Offset: -2}},
+		{14, Major, Interval{Val: 2}},
+		{-1, Major, Interval{Val: 7}},
+	}
+	for _, tc := range cases {
+		intv := IntervalFromHalfSteps(tc.n, tc.prefer)
+		if intv != tc.exp {
+			t.Errorf("IntervalFromHalfSteps for %d, %v returned wrong value: %v != %v", tc.n, tc.prefer, intv, tc.exp)
+		}
+	}
+	// round trip for all qualities
+	for n := int8(0); n < 12; n++ {
+		for q := Perfect; q <= DoublyDiminished; q++ {
+			if steps := IntervalFromHalfSteps(n, q).NumHalfSteps(); steps != n {
+				t.Errorf("IntervalFromHalfSteps(%d, %v) spans %d half-steps", n, q, steps)
+			}
+		}
+	}
+}
+
+func TestNote_Enharmonics(t *testing.T) {
+	cases := map[string][]string{
+		"C#": {"D♭", "B𝄪"},
+		"G#": {"A♭"},
+		"C":  {"D𝄫", "B♯"},
+		"E":  {"F♭", "D𝄪"},
+		"Fx": {"G", "A𝄫"},
+	}
+	for s, exp := range cases {
+		var names []string
+		for _, n := range MustParseNote(s).Enharmonics() {
+			names = append(names, n.String())
+		}
+		if strings.Join(names, " ") != strings.Join(exp, " ") {
+			t.Errorf("Note.Enharmonics for %s returned wrong value: %v != %v", s, names, exp)
+		}
+	}
+}
+
+func TestNote_TransposeHalfSteps(t *testing.T) {
+	cases := []struct {
+		n      string
+		steps  int8
+		prefer AccidentalPreference
+		exp    string
+	}{
+		{"C", 1, PreferSharps, "C♯"},
+		{"C", 1, PreferFlats, "D♭"},
+		{"C", 1, PreferSimplest, "D♭"},
+		{"D", 1, PreferSimplest, "E♭"},
+		{"F#", 1, PreferSimplest, "G"},
+		{"Bb", 5, PreferSimplest, "E♭"},
+		{"C#", 4, PreferSimplest, "F"},
+		{"B", 4, PreferSimplest, "D♯"},
+		{"Gb", 11, PreferSimplest, "F"},
+		{"E", -1, PreferFlats, "E♭"},
+		{"E", -1, PreferSharps, "D♯"},
+		{"A", 14, PreferSharps, "B"},
+		{"E#", 0, PreferSharps, "F"},
+	}
+	for _, tc := range cases {
+		n := MustParseNote(tc.n).TransposeHalfSteps(tc.steps, tc.prefer)
+		if n.String() != tc.exp {
+			t.Errorf("Note.TransposeHalfSteps for %s, %d, %d returned wrong value: %v != %s", tc.n, tc.steps, tc.prefer, n, tc.exp)
+		}
+	}
+}
+
+func TestInterval_Invert(t *testing.T) {
+	cases := map[Interval]Interval{
+		{Val: 1}:             {Val: 1},
+		{Val: 1, Offset: 1}:  {Val: 1, Offset: -1},
+		{Val: 2, Offset: -1}: {Val: 7},
+		{Val: 3, Offset: -1}: {Val: 6},
+		{Val: 3}:             {Val: 6, Offset: -1},
+		{Val: 4}:             {Val: 5},
+		{Val: 4, Offset: 1}:  {Val: 5, Offset: -1},
+		{Val: 7, Offset: -1}: {Val: 2},
+	}
+	for intv, exp := range cases {
+		if intv.Invert() != exp {
+			t.Errorf("Interval.Invert for %v returned wrong value: %v != %v", intv, intv.Invert(), exp)
+		}
+		if intv.Invert().Invert() != intv {
+			t.Errorf("Interval.Invert for %v is not reversible", intv)
+		}
+	}
+}
+
+func TestNote_TransposeDown(t *testing.T) {
+	cases := []struct {
+		start string
+		intv  Interval
+		exp   string
+	}{
+		{"C", Interval{Val: 3, Offset: -1}, "A"},
+		{"C", Interval{Val: 3}, "Ab"},
+		{"E", Interval{Val: 2, Offset: -1}, "D#"},
+		{"F", Interval{Val: 4, Offset: 1}, "Cb"},
+		{"G", Interval{Val: 1}, "G"},
+	}
+	for _, tc := range cases {
+		start := MustParseNote(tc.start)
+		if n := start.TransposeDown(tc.intv); n != MustParseNote(tc.exp) {
+			t.Errorf("Note.TransposeDown for %v by %v returned wrong value: %v != %s", start, tc.intv, n, tc.exp)
+		}
+	}
+}
+
+func TestNegate(t *testing.T) {
+	root := MustParseNote("C")
+	notes := []Note{MustParseNote("C"), MustParseNote("E"), MustParseNote("G"), MustParseNote("Bb"), MustParseNote("Eb")}
+	exp := []Note{MustParseNote("C"), MustParseNote("Ab"), MustParseNote("F"), MustParseNote("D"), MustParseNote("A")}
+	neg := Negate(root, notes...)
+	for i := range exp {
+		if neg[i] != exp[i] {
+			t.Errorf("Negate returned wrong value for %v: %v != %v", notes[i], neg[i], exp[i])
+		}
+	}
 }
 
 func TestNote_Transpose(t *testing.T) {
-	// TODO
+	// compare against the tables, which were the original implementation; the
+	// tables did not always preserve note names when double accidentals were
+	// involved, so those are only checked for the right pitch
+	for name := A; name <= G; name++ {
+		for acc := DblFlat; acc <= DblSharp; acc++ {
+			n := Note{N: name, Acc: acc}
+			for v := int8(1); v <= 7; v++ {
+				for o := int8(-2); o <= 2; o++ {
+					intv := Interval{Val: v, Offset: o}
+					actual := n.Transpose(intv)
+					expected := transposeWithTables(n, intv)
+					if actual.Cardinal() != expected.Cardinal() {
+						t.Errorf("%v.Transpose(%v): %v != %v", n, intv, actual, expected)
+					}
+					if acc >= Flat && acc <= Sharp && o >= -1 && o <= 1 && actual != expected {
+						t.Errorf("%v.Transpose(%v): %v != %v", n, intv, actual, expected)
+					}
+					// the note name only changes when a triple accidental is needed
+					want := name.add(v - 1)
+					if d := halfStepDelta(actual.Cardinal(), want.Cardinal()); actual.N != want && d >= -2 && d <= 2 {
+						t.Errorf("%v.Transpose(%v): %v should be spelled as %v", n, intv, actual, want)
+					}
+				}
+			}
+		}
+	}
+}
+
+func BenchmarkNote_Transpose(b *testing.B) {
+	b.ReportAllocs()
+	n := MustParseNote("Eb")
+	intv := Interval{Val: 6, Offset: -1}
+	for i := 0; i < b.N; i++ {
+		n = n.Transpose(intv)
+	}
+}
+
+func BenchmarkNote_IntervalTo(b *testing.B) {
+	b.ReportAllocs()
+	n, other := MustParseNote("Eb"), MustParseNote("C#")
+	for i := 0; i < b.N; i++ {
+		_ = n.IntervalTo(other)
+	}
+}
+
+// transposeWithTables transposes using the offsetsByNote and majorScales
+// tables. This is how Note.Transpose used to be implemented, and it is used as
+// an oracle in tests.
+func transposeWithTables(n Note, interval Interval) Note {
+	np := majorScales[n][posMod(int8(interval.Val)-1, 7)]
+	o := interval.Offset
+	for o != 0 {
+		if o >= -4 && o <= 4 {
+			np = offsetsByNote[np][o+4]
+			break
+		}
+		if o < 0 {
+			np = offsetsByNote[np][0]
+			o += 4
+		} else {
+			np = offsetsByNote[np][4]
+			o -= 4
+		}
+	}
+	return np
+}
+
+var offsetsByNote_strings = map[string][]string{
+	"Ax":  {"Abb", "Ab", "A", "A#", "Ax", "B#", "Bx", "Cx", "D#"},
+	"A#":  {"Gb", "Abb", "Ab", "A", "A#", "Ax", "B#", "Bx", "Cx"},
+	"A":   {"Gbb", "Gb", "Abb", "Ab", "A", "A#", "Ax", "B#", "Bx"},
+	"Ab":  {"Fb", "Gbb", "Gb", "Abb", "Ab", "A", "A#", "Ax", "B#"},
+	"Abb": {"Fbb", "Fb", "Gbb", "Gb", "Abb", "Ab", "A", "A#", "Ax"},
+
+	"Bx":  {"Bbb", "Bb", "B", "B#", "Bx", "Cx", "D#", "Dx", "E#"},
+	"B#":  {"Ab", "Bbb", "Bb", "B", "B#", "Bx", "Cx", "D#", "Dx"},
+	"B":   {"Abb", "Ab", "Bbb", "Bb", "B", "B#", "Bx", "Cx", "D#"},
+	"Bb":  {"Gb", "Abb", "Ab", "Bbb", "Bb", "B", "B#", "Bx", "Cx"},
+	"Bbb": {"Gbb", "Gb", "Abb", "Ab", "Bbb", "Bb", "B", "B#", "Bx"},
+
+	"Cx":  {"Cbb", "Cb", "C", "C#", "Cx", "D#", "Dx", "E#", "Ex"},
+	"C#":  {"Bbb", "Cbb", "Cb", "C", "C#", "Cx", "D#", "Dx", "E#"},
+	"C":   {"Ab", "Bbb", "Cbb", "Cb", "C", "C#", "Cx", "D#", "Dx"},
+	"Cb":  {"Abb", "Ab", "Bbb", "Cbb", "Cb", "C", "C#", "Cx", "D#"},
+	"Cbb": {"Gb", "Abb", "Ab", "Bbb", "Cbb", "Cb", "C", "C#", "Cx"},
+
+	"Dx":  {"Dbb", "Db", "D", "D#", "Dx", "E#", "Ex", "Fx", "G#"},
+	"D#":  {"Cb", "Dbb", "Db", "D", "D#", "Dx", "E#", "Ex", "Fx"},
+	"D":   {"Cbb", "Cb", "Dbb", "Db", "D", "D#", "Dx", "E#", "Ex"},
+	"Db":  {"Bbb", "Cbb", "Cb", "Dbb", "Db", "D", "D#", "Dx", "E#"},
+	"Dbb": {"Ab", "Bbb", "Cbb", "Cb", "Dbb", "Db", "D", "D#", "Dx"},
+
+	"Ex":  {"Ebb", "Eb", "E", "E#", "Ex", "Fx", "G#", "Gx", "A#"},
+	"E#":  {"Db", "Ebb", "Eb", "E", "E#", "Ex", "Fx", "G#", "Gx"},
+	"E":   {"Dbb", "Db", "Ebb", "Eb", "E", "E#", "Ex", "Fx", "G#"},
+	"Eb":  {"Cb", "Dbb", "Db", "Ebb", "Eb", "E", "E#", "Ex", "Fx"},
+	"Ebb": {"Cbb", "Cb", "Dbb", "Db", "Ebb", "Eb", "E", "E#", "Ex"},
+
+	"Fx":  {"Fbb", "Fb", "F", "F#", "Fx", "G#", "Gx", "A#", "Ax"},
+	"F#":  {"Ebb", "Fbb", "Fb", "F", "F#", "Fx", "G#", "Gx", "A#"},
+	"F":   {"Db", "Ebb", "Fbb", "Fb", "F", "F#", "Fx", "G#", "Gx"},
+	"Fb":  {"Dbb", "Db", "Ebb", "Fbb", "Fb", "F", "F#", "Fx", "G#"},
+	"Fbb": {"Cb", "Dbb", "Db", "Ebb", "Fbb", "Fb", "F", "F#", "Fx"},
+
+	"Gx":  {"Gbb", "Gb", "G", "G#", "Gx", "A#", "Ax", "B#", "Bx"},
+	"G#":  {"Fb", "Gbb", "Gb", "G", "G#", "Gx", "A#", "Ax", "B#"},
+	"G":   {"Fbb", "Fb", "Gbb", "Gb", "G", "G#", "Gx", "A#", "Ax"},
+	"Gb":  {"Ebb", "Fbb", "Fb", "Gbb", "Gb", "G", "G#", "Gx", "A#"},
+	"Gbb": {"Db", "Ebb", "Fbb", "Fb", "Gbb", "Gb", "G", "G#", "Gx"},
+}
+var offsetsByNote map[Note][]Note
+
+var majorScales_strings = map[string][]string{
+	"A": {"A", "B", "C#", "D", "E", "F#", "G#"},
+	"B": {"B", "C#", "D#", "E", "F#", "G#", "A#"},
+	"C": {"C", "D", "E", "F", "G", "A", "B"},
+	"D": {"D", "E", "F#", "G", "A", "B", "C#"},
+	"E": {"E", "F#", "G#", "A", "B", "C#", "D#"},
+	"F": {"F", "G", "A", "Bb", "C", "D", "E"},
+	"G": {"G", "A", "B", "C", "D", "E", "F#"},
+}
+var majorScales map[Note][]Note
+
+func init() {
+	offsetsByNote = map[Note][]Note{}
+	for k, vs := range offsetsByNote_strings {
+		n := MustParseNote(k)
+		ns := make([]Note, len(vs))
+		for i, v := range vs {
+			ns[i] = MustParseNote(v)
+			// sanity check that the table has valid data
+			if int8(ns[i].Cardinal()) != posMod(int8(n.Cardinal())+int8(i)-4, 12) {
+				panic(fmt.Errorf("offset table is incorrect! %v (%d) offset by %d (%d) != %v (%d)",
+					n, n.Cardinal(), i-4, posMod(int8(n.Cardinal())+int8(i)-4, 12), ns[i], ns[i].Cardinal()))
+			}
+		}
+		offsetsByNote[n] = ns
+	}
+	majorScales = map[Note][]Note{}
+	for k, vs := range majorScales_strings {
+		n := MustParseNote(k)
+		ns := make([]Note, len(vs))
+		for i, v := range vs {
+			ns[i] = MustParseNote(v)
+		}
+		for acc := DblFlat; acc <= DblSharp; acc++ {
+			if acc.Offset() == 0 {
+				majorScales[n] = ns
+				continue
+			}
+			accn := Note{N: n.N, Acc: acc}
+			accns := make([]Note, len(ns))
+			for i, pp := range ns {
+				accns[i] = offsetsByNote[pp][acc.Offset()+4]
+			}
+			majorScales[accn] = accns
+		}
+	}
 }
 
 func TestParseNote(t *testing.T) {
@@ -132,3 +453,184 @@ func TestParseNote(t *testing.T) {
 		}
 	}
 }
+
+func TestPitch_Frequency(t *testing.T) {
+	cases := []struct {
+		pitch  string
+		tuning Tuning
+		exp    float64
+	}{
+		{"A4", Tuning{}, 440},
+		{"A4", StandardTuning, 440},
+		{"A3", StandardTuning, 220},
+		{"A5", StandardTuning, 880},
+		{"C4", StandardTuning, 261.6256},
+		{"B#3", StandardTuning, 261.6256},
+		{"Cb4", StandardTuning, 246.9417},
+		{"E2", StandardTuning, 82.4069},
+		{"A4", A432Tuning, 432},
+		{"A4", BaroqueTuning, 415},
+		{"C4", BaroqueTuning, 246.7605},
+		{"A4", Tuning{Reference: MustParsePitch("C4"), Frequency: 256}, 430.5390},
+	}
+	for _, tc := range cases {
+		p := MustParsePitch(tc.pitch)
+		f := p.Frequency(tc.tuning)
+		if math.Abs(f-tc.exp) > 0.0001 {
+			t.Errorf("Pitch.Frequency for %s returned wrong value: %f != %f", p, f, tc.exp)
+		}
+	}
+}
+
+func TestCentsBetween(t *testing.T) {
+	cases := []struct {
+		a, b string
+		exp  float64
+	}{
+		{"A4", "A4", 0},
+		{"A4", "A5", 1200},
+		{"C4", "E4", 400},
+		{"E4", "C4", -400},
+		{"B#3", "C4", 0},
+		{"C4", "G3", -500},
+	}
+	for _, tc := range cases {
+		for _, tuning := range []Tuning{{}, BaroqueTuning} {
+			c := CentsBetween(MustParsePitch(tc.a), MustParsePitch(tc.b), tuning)
+			if math.Abs(c-tc.exp) > 1e-9 {
+				t.Errorf("CentsBetween for %s, %s returned wrong value: %f != %f", tc.a, tc.b, c, tc.exp)
+			}
+		}
+	}
+
+	a4 := MustParsePitch("A4")
+	if c := a4.CentsTo(445, StandardTuning); math.Abs(c-19.5622) > 1e-4 {
+		t.Errorf("Pitch.CentsTo for A4, 445 returned wrong value: %f != 19.5622", c)
+	}
+	if c := a4.CentsTo(415, StandardTuning); math.Abs(c+101.2706) > 1e-4 {
+		t.Errorf("Pitch.CentsTo for A4, 415 returned wrong value: %f != -101.2706", c)
+	}
+}
+
+func TestParsePitch(t *testing.T) {
+	cases := map[string]int{
+		"C4":   60,
+		"A4":   69,
+		"C-1":  0,
+		"G9":   127,
+		"Bb3":  58,
+		"B#3":  60,
+		"Cbb4": 58,
+	}
+	for s, exp := range cases {
+		p, err := ParsePitch(s)
+		if err != nil {
+			t.Errorf("ParsePitch(%q) failed: %v", s, err)
+		} else if p.MIDINumber() != exp {
+			t.Errorf("Pitch.MIDINumber for %s returned wrong value: %d != %d", p, p.MIDINumber(), exp)
+		}
+	}
+	for _, s := range []string{"", "C", "H4", "C#x", "C999"} {
+		if _, err := ParsePitch(s); err == nil {
+			t.Errorf("ParsePitch(%q) should have failed", s)
+		}
+	}
+}
+
+func TestPitch_Compare(t *testing.T) {
+	ordered := []string{"B2", "Cb3", "B#2", "C3", "C#3", "Db3", "Bx3", "C#4", "A4", "B4", "C5"}
+	for i := range ordered {
+		for j := range ordered {
+			a, b := MustParsePitch(ordered[i]), MustParsePitch(ordered[j])
+			if a.Less(b) != (i < j) {
+				t.Errorf("%v.Less(%v) should be %v", a, b, i < j)
+			}
+			if c := a.Compare(b); (c < 0) != (i < j) || (c == 0) != (i == j) {
+				t.Errorf("%v.Compare(%v) returned wrong result: %d", a, b, c)
+			}
+		}
+	}
+
+	r := PitchRange{Low: MustParsePitch("E2"), High: MustParsePitch("E5")}
+	for _, s := range []string{"E2", "Fb2", "G3", "D#5", "E5"} {
+		if !r.Contains(MustParsePitch(s)) {
+			t.Errorf("range %v should contain %s", r, s)
+		}
+	}
+	for _, s := range []string{"D#2", "Eb2", "E#5", "C6"} {
+		if r.Contains(MustParsePitch(s)) {
+			t.Errorf("range %v should not contain %s", r, s)
+		}
+	}
+	if (PitchRange{Low: MustParsePitch("C5"), High: MustParsePitch("C4")}).IsValid() {
+		t.Errorf("range with low higher than high should not be valid")
+	}
+}
+
+func TestCompoundInterval(t *testing.T) {
+	cases := []struct {
+		intv  CompoundInterval
+		steps int
+		name  string
+	}{
+		{CompoundInterval{Val: 1}, 0, "perfect unison"},
+		{CompoundInterval{Val: 3, Offset: -1}, 3, "minor 3rd"},
+		{CompoundInterval{Val: 4, Offset: 1}, 6, "augmented 4th"},
+		{CompoundInterval{Val: 8}, 12, "perfect octave"},
+		{CompoundInterval{Val: 9, Offset: -1}, 13, "minor 9th"},
+		{CompoundInterval{Val: 11, Offset: 1}, 18, "augmented 11th"},
+		{CompoundInterval{Val: 13}, 21, "major 13th"},
+		{CompoundInterval{Val: 15}, 24, "perfect double octave"},
+		{CompoundInterval{Val: 17, Offset: -2}, 26, "diminished 17th"},
+		{CompoundInterval{Val: 22}, 36, "perfect 22nd"},
+		{CompoundInterval{Val: -3, Offset: -1}, -3, "descending minor 3rd"},
+		{CompoundInterval{Val: -9}, -14, "descending major 9th"},
+	}
+	for _, tc := range cases {
+		if tc.intv.NumHalfSteps() != tc.steps {
+			t.Errorf("CompoundInterval.NumHalfSteps for %v returned wrong value: %d", tc.intv, tc.intv.NumHalfSteps())
+		}
+		if tc.intv.String() != tc.name {
+			t.Errorf("CompoundInterval.String returned wrong value: %q != %q", tc.intv.String(), tc.name)
+		}
+		simple := tc.intv.Simple()
+		if !tc.intv.IsDescending() && Compound(simple, tc.intv.Octaves()) != tc.intv {
+			t.Errorf("Compound(%v, %d) != %v", simple, tc.intv.Octaves(), tc.intv)
+		}
+	}
+}
+
+func TestPitch_Transpose(t *testing.T) {
+	cases := []struct {
+		start string
+		intv  CompoundInterval
+		exp   string
+	}{
+		{"C4", CompoundInterval{Val: 1}, "C4"},
+		{"B3", CompoundInterval{Val: 2, Offset: -1}, "C4"},
+		{"C4", CompoundInterval{Val: 9}, "D5"},
+		{"G3", CompoundInterval{Val: 10, Offset: -1}, "B♭4"},
+		{"A4", CompoundInterval{Val: 8}, "A5"},
+		{"E4", CompoundInterval{Val: 11, Offset: 1}, "A♯5"},
+		{"C4", CompoundInterval{Val: 15}, "C6"},
+		{"C4", CompoundInterval{Val: -3, Offset: -1}, "A3"},
+		{"C4", CompoundInterval{Val: -2, Offset: -1}, "B3"},
+		{"D5", CompoundInterval{Val: -9}, "C4"},
+		{"A3", CompoundInterval{Val: -8}, "A2"},
+		{"F4", CompoundInterval{Val: -4, Offset: 1}, "C♭4"},
+		{"C4", CompoundInterval{Val: -1, Offset: 1}, "C♭4"},
+	}
+	for _, tc := range cases {
+		start := MustParsePitch(tc.start)
+		p := start.Transpose(tc.intv)
+		if p.String() != tc.exp {
+			t.Errorf("Pitch.Transpose for %v by %v returned wrong value: %v != %s", start, tc.intv, p, tc.exp)
+		}
+		if p.MIDINumber()-start.MIDINumber() != tc.intv.NumHalfSteps() {
+			t.Errorf("Pitch.Transpose for %v by %v returned %v, which is the wrong distance", start, tc.intv, p)
+		}
+		if intv := start.IntervalTo(p); intv != tc.intv {
+			t.Errorf("Pitch.IntervalTo for %v -> %v returned wrong value: %v != %v", start, p, intv, tc.intv)
+		}
+	}
+}
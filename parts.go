@@ -0,0 +1,251 @@
+package chords
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+)
+
+// Instrument describes how parts are written for an instrument. Many
+// instruments are "transposing instruments": the notes written in their parts
+// are not the notes that sound (aka concert pitch). For example, when a B♭
+// trumpet plays a written C, the sounding note is B♭. Guitarists that use a
+// capo are similar: the chord shapes they play are lower than the chords that
+// sound.
+type Instrument struct {
+	// Name is a label for parts written for this instrument, like "B♭" or
+	// "Guitar (capo 3)".
+	Name string
+	// Transposition is the interval from concert pitch up to the written
+	// pitch. For concert pitch instruments, this is the unison interval. For
+	// B♭ instruments, whose written notes are a major second higher than the
+	// notes that sound, this is a major second.
+	Transposition Interval
+	// Capo is the fret at which a capo is placed, for fretted instruments.
+	// Zero means no capo.
+	Capo int
+}
+
+var (
+	// ConcertInstrument is for parts written in concert pitch, such as for
+	// piano, guitar (without a capo), bass, flute, and most strings.
+	ConcertInstrument = Instrument{Name: "Concert", Transposition: Interval{Val: 1}}
+	// BbInstrument is for parts written for B♭ instruments, such as trumpet,
+	// clarinet, and tenor and soprano saxophones.
+	BbInstrument = Instrument{Name: "B♭", Transposition: Interval{Val: 2}}
+	// EbInstrument is for parts written for E♭ instruments, such as alto and
+	// baritone saxophones.
+	EbInstrument = Instrument{Name: "E♭", Transposition: Interval{Val: 6}}
+	// FInstrument is for parts written for F instruments, such as French horn.
+	FInstrument = Instrument{Name: "F", Transposition: Interval{Val: 5}}
+)
+
+// CapoInstrument returns an instrument for a guitar (or other fretted
+// instrument) with a capo at the given fret. The chords in its parts are the
+// shapes played, which are lower than the chords that sound by the given
+// number of half-steps.
+func CapoInstrument(fret int) Instrument {
+	return Instrument{
+		Name:          fmt.Sprintf("Guitar (capo %d)", fret),
+		Transposition: intervalsByHalfSteps[posMod(int8(-(fret%12)), 12)],
+		Capo:          fret,
+	}
+}
+
+// Part is a chart of a song that is written for a particular instrument.
+type Part struct {
+	// Instrument is the instrument for which the part is written.
+	Instrument Instrument
+	// Song is the song, transposed for the instrument. The key and capo in
+	// its metadata are adjusted for the instrument.
+	Song *Song
+	// cellWidth is the width of the column for each chord when rendered.
+	cellWidth int
+}
+
+// RenderParts transposes a song for each of the given instruments, returning
+// one part per instrument. Each part keeps the song's sections, repeats,
+// endings, form, and time signature.
+//
+// The same spelling is used throughout each part: every chord is transposed by
+// the same interval, chosen so that the key of the part has the simplest key
+// signature, and then respelled consistently (see Song.Transpose). So a
+// concert chart in B major is written for B♭ instruments in D♭ major, not C♯
+// major. If the metadata does not indicate the song's key, it is inferred from
+// the chords. Parts written in concert pitch are not re-spelled.
+//
+// All parts share the same layout: when printed, a given chord occupies the
+// same columns in every part.
+func RenderParts(song *Song, instruments ...Instrument) []*Part {
+	key := song.key()
+	parts := make([]*Part, len(instruments))
+	width := 0
+	for i, inst := range instruments {
+		var sng *Song
+		if intv := inst.Transposition; intv.NumHalfSteps() == 0 {
+			sng = song.clone()
+		} else {
+			intv = simplestTransposition(key, intv)
+			sng = song.transpose(intv, Key{Tonic: key.Tonic.Transpose(intv), Minor: key.Minor})
+		}
+		sng.Metadata.Capo = inst.Capo
+		for _, p := range sng.progressions() {
+			for _, bar := range p.Bars {
+				for _, tok := range bar.tokens() {
+					if w := utf8.RuneCountInString(tok); w > width {
+						width = w
+					}
+				}
+			}
+		}
+		parts[i] = &Part{Instrument: inst, Song: sng}
+	}
+	for _, p := range parts {
+		p.cellWidth = width
+	}
+	return parts
+}
+
+// simplestTransposition returns an interval that is enharmonically equivalent
+// to the given interval and that, when used to transpose the given key,
+// results in the simplest key signature. Unison intervals are returned as is.
+func simplestTransposition(key Key, intv Interval) Interval {
+	steps := intv.NumHalfSteps()
+	if steps == 0 {
+		return intv
+	}
+	best := intv
+	bestScore := abs(Key{Tonic: key.Tonic.Transpose(intv), Minor: key.Minor}.fifths())
+	for _, d := range []int8{-1, 1} {
+		alt := Interval{Val: posMod(intv.Val-1+d, 7) + 1}
+		alt.Offset = posMod(steps-alt.NumHalfSteps()+6, 12) - 6
+		if !alt.IsValid() {
+			continue
+		}
+		score := abs(Key{Tonic: key.Tonic.Transpose(alt), Minor: key.Minor}.fifths())
+		if score < bestScore {
+			best, bestScore = alt, score
+		}
+	}
+	return best
+}
+
+func abs(i int) int {
+	if i < 0 {
+		return -i
+	}
+	return i
+}
+
+// String implements the Stringer interface. The part is rendered as a chart
+// in the format accepted by ParseSong: its metadata, as ChordPro directives
+// (including the instrument), followed by its sections, with their bars four
+// per line.
+func (p *Part) String() string {
+	var b bytes.Buffer
+	meta := p.Song.Metadata
+	meta.Extra = append([]MetadataField(nil), meta.Extra...)
+	if p.Instrument.Name != "" {
+		_ = meta.Set("instrument", p.Instrument.Name)
+	}
+	b.WriteString(meta.Directives())
+	if len(p.Song.Form) > 0 {
+		fmt.Fprintf(&b, "{form: %s}\n", strings.Join(p.Song.Form, " "))
+	}
+	width := p.cellWidth
+	if width == 0 {
+		for _, prog := range p.Song.progressions() {
+			for _, bar := range prog.Bars {
+				for _, tok := range bar.tokens() {
+					if w := utf8.RuneCountInString(tok); w > width {
+						width = w
+					}
+				}
+			}
+		}
+	}
+	// the time signature in the metadata applies to every section
+	beats := 4
+	for _, f := range meta.Extra {
+		if strings.EqualFold(f.Name, "time") {
+			if n, err := parseTimeSignature(f.Value); err == nil {
+				beats = n
+			}
+		}
+	}
+	for _, sect := range p.Song.Sections {
+		if sect.Name != "" || len(p.Song.Sections) > 1 || sect.Repeat > 1 {
+			fmt.Fprintf(&b, "[%s]", sect.Name)
+			if sect.Repeat > 1 {
+				fmt.Fprintf(&b, " x%d", sect.Repeat)
+			}
+			b.WriteByte('\n')
+		}
+		if sect.Body != nil {
+			writeChart(&b, "", sect.Body, beats, width)
+		}
+		for i, ending := range sect.Endings {
+			writeChart(&b, fmt.Sprintf("%d. ", i+1), ending, beats, width)
+		}
+	}
+	return b.String()
+}
+
+// writeChart writes the bars of the given progression to b, four per line,
+// after the given prefix. The time signature is written if the progression
+// does not have the given number of beats per bar. Each chord (and slash) is
+// padded to the given width so that chords line up in columns.
+func writeChart(b *bytes.Buffer, prefix string, p *Progression, beats, width int) {
+	if len(p.Bars) == 0 {
+		return
+	}
+	b.WriteString(prefix)
+	if n := p.beatsPerBar(); n != beats {
+		fmt.Fprintf(b, "%d/4 ", n)
+	}
+	// prevRepeat is the repeat count of the previous bar on the same line
+	prevRepeat := 0
+	for i, bar := range p.Bars {
+		if i > 0 && i%4 == 0 {
+			endLine(b, prevRepeat)
+			b.WriteString(strings.Repeat(" ", len(prefix)))
+			prevRepeat = -1
+		}
+		switch {
+		case bar.RepeatStart && prevRepeat == 2:
+			// combine the bar lines into ":|:"
+			b.Truncate(b.Len() - 1)
+			b.WriteString(": ")
+		case bar.RepeatStart:
+			b.WriteString("|: ")
+		case prevRepeat <= 0:
+			b.WriteString("| ")
+		}
+		for _, tok := range bar.tokens() {
+			b.WriteString(tok)
+			b.WriteString(strings.Repeat(" ", width-utf8.RuneCountInString(tok)+1))
+		}
+		prevRepeat = bar.RepeatCount
+		if bar.RepeatCount > 0 {
+			b.WriteString(":|")
+			if bar.RepeatCount != 2 {
+				fmt.Fprintf(b, " x%d", bar.RepeatCount)
+			}
+			b.WriteByte(' ')
+		}
+	}
+	endLine(b, prevRepeat)
+}
+
+// endLine ends a line of a chart whose last bar has the given repeat count,
+// adding a closing bar line unless the bar ends a repeated section.
+func endLine(b *bytes.Buffer, repeatCount int) {
+	if repeatCount == 0 {
+		b.WriteByte('|')
+	} else {
+		// remove the space after the repeat sign
+		b.Truncate(b.Len() - 1)
+	}
+	b.WriteByte('\n')
+}
@@ -0,0 +1,95 @@
+package chords
+
+import (
+	"testing"
+)
+
+func TestRenderParts(t *testing.T) {
+	song, err := ParseSong("{title: Test}\n{key: B}\n| Bmaj7 | G#-7 C#7 |")
+	if err != nil {
+		t.Fatalf("failed to parse song: %v", err)
+	}
+	parts := RenderParts(song, ConcertInstrument, BbInstrument, EbInstrument, CapoInstrument(4))
+	expected := []struct {
+		key    string
+		chords []string
+	}{
+		{"B", []string{"B△7", "G♯-7", "C♯7"}},
+		{"D♭", []string{"D♭△7", "B♭-7", "E♭7"}},
+		{"A♭", []string{"A♭△7", "F-7", "B♭7"}},
+		{"G", []string{"G△7", "E-7", "A7"}},
+	}
+	for i, p := range parts {
+		exp := expected[i]
+		if p.Song.Metadata.Key.String() != exp.key {
+			t.Errorf("%s part has wrong key: %v", p.Instrument.Name, p.Song.Metadata.Key)
+		}
+		var actual []string
+		for _, ch := range p.Song.Unroll().Chords() {
+			actual = append(actual, ch.String())
+		}
+		for j := range exp.chords {
+			if actual[j] != exp.chords[j] {
+				t.Errorf("%s part has wrong chord at %d: %s != %s", p.Instrument.Name, j, actual[j], exp.chords[j])
+			}
+		}
+	}
+	if parts[3].Song.Metadata.Capo != 4 {
+		t.Errorf("capo part has wrong capo: %d", parts[3].Song.Metadata.Capo)
+	}
+	if song.Metadata.Key.String() != "B" || song.Metadata.Capo != 0 {
+		t.Errorf("RenderParts should not modify song metadata")
+	}
+
+	exp := "{title: Test}\n{key: D♭}\n{meta: instrument B♭}\n| D♭△7 | B♭-7 E♭7  |\n"
+	if s := parts[1].String(); s != exp {
+		t.Errorf("wrong rendered part:\n%s", s)
+	}
+}
+
+func TestRenderParts_Sections(t *testing.T) {
+	song, err := ParseSong(`{title: Test}
+{key: F}
+{time: 3/4}
+{form: A A B}
+[A]
+|: F | D-7 | G-7 | C7 :|
+| F | Bb |
+1. | C7 |
+2. | F |
+[B] x2
+| Bb | Bb-6 | F | F |`)
+	if err != nil {
+		t.Fatalf("failed to parse song: %v", err)
+	}
+	parts := RenderParts(song, ConcertInstrument, EbInstrument)
+	exp := `{title: Test}
+{key: D}
+{time: 3/4}
+{meta: instrument E♭}
+{form: A A B}
+[A]
+|: D    | B-7  | E-7  | A7   :|
+| D    | G    |
+1. | A7   |
+2. | D    |
+[B] x2
+| G    | G-6  | D    | D    |
+`
+	if s := parts[1].String(); s != exp {
+		t.Errorf("wrong rendered part:\n%s", s)
+	}
+	for _, p := range parts {
+		round, err := ParseSong(p.String())
+		if err != nil {
+			t.Errorf("failed to parse %s part: %v", p.Instrument.Name, err)
+			continue
+		}
+		if actual, exp := round.Unroll().String(), p.Song.Unroll().String(); actual != exp {
+			t.Errorf("%s part did not round trip: %s != %s", p.Instrument.Name, actual, exp)
+		}
+	}
+	if actual := len(parts[0].Song.Unroll().Bars); actual != 2*2*(4*2+2+1)+2*4 {
+		t.Errorf("concert part has wrong number of bars: %d", actual)
+	}
+}
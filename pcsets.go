@@ -0,0 +1,412 @@
+package chords
+
+import (
+	"bytes"
+	"fmt"
+	"math/bits"
+	"strconv"
+	"strings"
+)
+
+// pitchClasses returns the set of distinct pitch classes in the given notes,
+// as a bitmask. Bit n is set if a note with cardinality n (see Note.Cardinal)
+// is present.
+func pitchClasses(notes []Note) uint16 {
+	var set uint16
+	for _, n := range notes {
+		set |= 1 << uint(n.Cardinal())
+	}
+	return set
+}
+
+// intervalVector computes the interval class vector of the given set of pitch
+// classes.
+func intervalVector(set uint16) [6]int {
+	var vec [6]int
+	for i := 0; i < 12; i++ {
+		if set&(1<<uint(i)) == 0 {
+			continue
+		}
+		for j := i + 1; j < 12; j++ {
+			if set&(1<<uint(j)) == 0 {
+				continue
+			}
+			ic := j - i
+			if ic > 6 {
+				ic = 12 - ic
+			}
+			vec[ic-1]++
+		}
+	}
+	return vec
+}
+
+// rotatePitchClasses transposes the given set of pitch classes up by n
+// half-steps.
+func rotatePitchClasses(set uint16, n int) uint16 {
+	n = ((n % 12) + 12) % 12
+	return ((set << uint(n)) | (set >> uint(12-n))) & 0xfff
+}
+
+// invertPitchClasses inverts the given set of pitch classes around zero, so
+// pitch class n maps to 12-n.
+func invertPitchClasses(set uint16) uint16 {
+	var inv uint16
+	for i := 0; i < 12; i++ {
+		if set&(1<<uint(i)) != 0 {
+			inv |= 1 << uint((12-i)%12)
+		}
+	}
+	return inv
+}
+
+// sameSetClass returns true if the two sets of pitch classes are related by
+// transposition or by inversion followed by transposition.
+func sameSetClass(a, b uint16) bool {
+	inv := invertPitchClasses(a)
+	for n := 0; n < 12; n++ {
+		if rotatePitchClasses(a, n) == b || rotatePitchClasses(inv, n) == b {
+			return true
+		}
+	}
+	return false
+}
+
+// pitchClasses returns the set of distinct pitch classes in the chord,
+// including its bass note.
+func (ch *Chord) pitchClasses() uint16 {
+	return pitchClasses(ch.Spell())
+}
+
+// IntervalVector returns the interval class vector of the chord. This is a
+// summary of all of the intervals between the chord's distinct pitch classes
+// (including its bass note). The first element is the number of pairs of
+// tones that are one half-step apart (or 11), the second is the number that
+// are two half-steps apart (or 10), and so on, up to the sixth element, which
+// is the number of tritones.
+//
+// For example, a C major triad has the vector [0 0 1 1 1 0]: one minor third
+// (E-G), one major third (C-E), and one perfect fourth (G-C).
+func (ch *Chord) IntervalVector() [6]int {
+	return intervalVector(ch.pitchClasses())
+}
+
+// ZRelated returns true if the two given chords are Z-related. Z-related
+// chords have the same interval vector but are not transpositions or
+// inversions of one another. The classic example is the all-interval
+// tetrachords, like C D♭ E G♭ (set class 4-Z15) and C D♭ E♭ G (4-Z29).
+func ZRelated(a, b *Chord) bool {
+	pa, pb := a.pitchClasses(), b.pitchClasses()
+	return intervalVector(pa) == intervalVector(pb) && !sameSetClass(pa, pb)
+}
+
+// ZRelatedPairs returns all pairs of chords in the given slice that are
+// Z-related. Each pair is returned only once, with the chord that appears
+// first in the given slice as the first element of the pair.
+func ZRelatedPairs(chs []*Chord) [][2]*Chord {
+	pcs := make([]uint16, len(chs))
+	vecs := make([][6]int, len(chs))
+	for i, ch := range chs {
+		pcs[i] = ch.pitchClasses()
+		vecs[i] = intervalVector(pcs[i])
+	}
+	var pairs [][2]*Chord
+	for i := range chs {
+		for j := i + 1; j < len(chs); j++ {
+			if vecs[i] == vecs[j] && !sameSetClass(pcs[i], pcs[j]) {
+				pairs = append(pairs, [2]*Chord{chs[i], chs[j]})
+			}
+		}
+	}
+	return pairs
+}
+
+// PitchClassSet is a set of pitch classes. Pitch classes are numbered as they
+// are in pitch-class set theory and in MIDI, by half-steps above C: 0 is C, 1
+// is C♯ or D♭, and so on, up to 11 for B. (This differs from Note.Cardinal,
+// which counts from A.) Bit n of the set is set if pitch class n is in the
+// set, and the four high bits are always zero. Since a set is just a number,
+// all of its operations are cheap and don't allocate, and sets can be
+// compared with == and used as map keys.
+type PitchClassSet uint16
+
+// allPitchClasses is the set of all twelve pitch classes.
+const allPitchClasses PitchClassSet = 0xfff
+
+// PitchClass returns the pitch class of the given note: the number of
+// half-steps from C up to the note, from 0 to 11. (See PitchClassSet.)
+func PitchClass(n Note) int {
+	return int(posMod(n.Cardinal()-C.Cardinal(), 12))
+}
+
+// PitchClassSetOf returns the set of the pitch classes of the given notes.
+func PitchClassSetOf(notes ...Note) PitchClassSet {
+	var set PitchClassSet
+	for _, n := range notes {
+		set |= 1 << uint(PitchClass(n))
+	}
+	return set
+}
+
+// fromCardinals converts a set of pitch classes numbered by Note.Cardinal
+// into a PitchClassSet.
+func fromCardinals(set uint16) PitchClassSet {
+	return PitchClassSet(rotatePitchClasses(set, -int(C.Cardinal())))
+}
+
+// PitchClassSet returns the set of the pitch classes in the chord, including
+// its bass note.
+func (ch *Chord) PitchClassSet() PitchClassSet {
+	return fromCardinals(ch.pitchClasses())
+}
+
+// PitchClassSet returns the set of the pitch classes in the scale.
+func (s *Scale) PitchClassSet() PitchClassSet {
+	return fromCardinals(pitchClasses(s.Spell()))
+}
+
+// String implements the Stringer interface. The result is the pitch classes
+// in the set, in order, like "{0,4,7}" for a C major triad.
+func (s PitchClassSet) String() string {
+	var b bytes.Buffer
+	b.WriteByte('{')
+	for i, pc := range s.PitchClasses() {
+		if i > 0 {
+			b.WriteByte(',')
+		}
+		b.WriteString(strconv.Itoa(pc))
+	}
+	b.WriteByte('}')
+	return b.String()
+}
+
+// PitchClasses returns the pitch classes in the set, in order from 0 to 11.
+func (s PitchClassSet) PitchClasses() []int {
+	pcs := make([]int, 0, s.Len())
+	for pc := 0; pc < 12; pc++ {
+		if s.Contains(pc) {
+			pcs = append(pcs, pc)
+		}
+	}
+	return pcs
+}
+
+// Len returns the number of pitch classes in the set.
+func (s PitchClassSet) Len() int {
+	return bits.OnesCount16(uint16(s & allPitchClasses))
+}
+
+// Contains returns true if the given pitch class is in the set. The pitch
+// class wraps, so 12 is the same as 0 and -1 is the same as 11.
+func (s PitchClassSet) Contains(pc int) bool {
+	return s&(1<<uint(posModInt(pc, 12))) != 0
+}
+
+// With returns the set with the given pitch class added. The pitch class
+// wraps, so 12 is the same as 0 and -1 is the same as 11.
+func (s PitchClassSet) With(pc int) PitchClassSet {
+	return s | 1<<uint(posModInt(pc, 12))
+}
+
+// Without returns the set with the given pitch class removed. The pitch class
+// wraps, so 12 is the same as 0 and -1 is the same as 11.
+func (s PitchClassSet) Without(pc int) PitchClassSet {
+	return s &^ (1 << uint(posModInt(pc, 12)))
+}
+
+// Union returns the set of pitch classes in either set.
+func (s PitchClassSet) Union(other PitchClassSet) PitchClassSet {
+	return s | other
+}
+
+// Intersection returns the set of pitch classes in both sets.
+func (s PitchClassSet) Intersection(other PitchClassSet) PitchClassSet {
+	return s & other
+}
+
+// Difference returns the set of pitch classes in this set but not the other.
+func (s PitchClassSet) Difference(other PitchClassSet) PitchClassSet {
+	return s &^ other
+}
+
+// Complement returns the set of pitch classes that are not in this set. For
+// example, the complement of a major scale is a pentatonic scale.
+func (s PitchClassSet) Complement() PitchClassSet {
+	return ^s & allPitchClasses
+}
+
+// Transpose returns the set with every pitch class transposed up by the
+// given number of half-steps (or down, if negative). This is the Tn operation
+// of set theory.
+func (s PitchClassSet) Transpose(halfSteps int) PitchClassSet {
+	return PitchClassSet(rotatePitchClasses(uint16(s), halfSteps))
+}
+
+// Invert returns the inversion of the set around C, so pitch class n becomes
+// 12-n. Combined with Transpose, this is the TnI operation of set theory.
+func (s PitchClassSet) Invert() PitchClassSet {
+	return PitchClassSet(invertPitchClasses(uint16(s)))
+}
+
+// IsSubsetOf returns true if every pitch class in this set is also in the
+// other set. A set is a subset of itself.
+func (s PitchClassSet) IsSubsetOf(other PitchClassSet) bool {
+	return s&^other == 0
+}
+
+// IsSupersetOf returns true if every pitch class in the other set is also in
+// this set. A set is a superset of itself.
+func (s PitchClassSet) IsSupersetOf(other PitchClassSet) bool {
+	return other.IsSubsetOf(s)
+}
+
+// IntervalVector returns the interval class vector of the set. (See
+// Chord.IntervalVector.)
+func (s PitchClassSet) IntervalVector() [6]int {
+	return intervalVector(uint16(s))
+}
+
+// posModInt is like posMod, but for ints.
+func posModInt(x, n int) int {
+	return ((x % n) + n) % n
+}
+
+// NormalOrder returns the pitch classes of the set in normal order: the
+// rotation of the set, in ascending order, that is most compactly packed. The
+// most compact rotation has the smallest interval from its first to its last
+// pitch class. Ties are broken by the interval from the first to the
+// second-to-last pitch class, then to the third-to-last, and so on (Rahn's
+// method), and then by starting on the lowest pitch class. For example, the
+// normal order of a C major triad is [0 4 7], and the normal order of a
+// G7 chord is [11 2 5 7].
+func (s PitchClassSet) NormalOrder() []int {
+	s &= allPitchClasses
+	if s == 0 {
+		return []int{}
+	}
+	// The integer value of a set weighs each pitch class more than all of the
+	// ones below it. So, among the rotations transposed to start on zero, the
+	// one with the smallest value is the most compact by Rahn's method.
+	best, bestSet := -1, PitchClassSet(0)
+	for pc := 0; pc < 12; pc++ {
+		if !s.Contains(pc) {
+			continue
+		}
+		if rot := s.Transpose(-pc); best < 0 || rot < bestSet {
+			best, bestSet = pc, rot
+		}
+	}
+	order := make([]int, 0, s.Len())
+	for _, pc := range bestSet.PitchClasses() {
+		order = append(order, (pc+best)%12)
+	}
+	return order
+}
+
+// PrimeForm returns the prime form of the set: the most compact form of the
+// set or of its inversion (see Invert), transposed to start on pitch class 0.
+// All sets that are transpositions or inversions of one another, which make
+// up a set class, have the same prime form. For example, the prime form of
+// both major and minor triads is {0,3,7}. Compactness is determined the same
+// way as by NormalOrder, so this is the prime form by Rahn's method. It
+// differs from the prime form originally listed by Forte for a few set
+// classes, like 5-20, which is {0,1,5,6,8} here and {0,1,3,7,8} in Forte's
+// list.
+func (s PitchClassSet) PrimeForm() PitchClassSet {
+	s &= allPitchClasses
+	var best PitchClassSet
+	for _, set := range []PitchClassSet{s, s.Invert()} {
+		for pc := 0; pc < 12; pc++ {
+			if !set.Contains(pc) {
+				continue
+			}
+			if rot := set.Transpose(-pc); best == 0 || rot < best {
+				best = rot
+			}
+		}
+	}
+	return best
+}
+
+// forteSetClasses are the prime forms of the set classes with three to six
+// pitch classes, in order of their Forte numbers. (Where Forte's and Rahn's
+// methods disagree, either prime form identifies the set class.) A 'Z'
+// prefix means that the set class is Z-related to another of the same size:
+// they have the same interval vector. Pitch classes 10 and 11 are written as
+// 'T' and 'E'. The set classes with seven to nine pitch classes are the
+// complements of those with five to three and have the same numbers.
+var forteSetClasses = [][]string{
+	3: {
+		"012", "013", "014", "015", "016", "024", "025", "026", "027", "036", "037", "048",
+	},
+	4: {
+		"0123", "0124", "0134", "0125", "0126", "0127", "0145", "0156", "0167", "0235",
+		"0135", "0236", "0136", "0237", "Z0146", "0157", "0347", "0147", "0148", "0158",
+		"0246", "0247", "0257", "0248", "0268", "0358", "0258", "0369", "Z0137",
+	},
+	5: {
+		"01234", "01235", "01245", "01236", "01237", "01256", "01267", "02346", "01246", "01346",
+		"02347", "Z01356", "01248", "01257", "01268", "01347", "Z01348", "Z01457", "01367", "01568",
+		"01458", "01478", "02357", "01357", "02358", "02458", "01358", "02368", "01368", "01468",
+		"01369", "01469", "02468", "02469", "02479", "Z01247", "Z03458", "Z01258",
+	},
+	6: {
+		"012345", "012346", "Z012356", "Z012456", "012367", "Z012567", "012678", "023457", "012357", "Z013457",
+		"Z012457", "Z012467", "Z013467", "013458", "012458", "014568", "Z012478", "012578", "Z013478", "014589",
+		"023468", "012468", "Z023568", "Z013468", "Z013568", "Z013578", "013469", "Z013569", "Z013689", "013679",
+		"014579", "024579", "023579", "013579", "02468T", "Z012347", "Z012348", "Z012378", "Z023458", "Z012358",
+		"Z012368", "Z012369", "Z012568", "Z012569", "Z023469", "Z012469", "Z012479", "Z012579", "Z013479", "Z014679",
+	},
+}
+
+// forteNames are the Forte names of the set classes, indexed by their prime
+// forms (by Rahn's method, as returned by PitchClassSet.PrimeForm).
+var forteNames = func() map[PitchClassSet]string {
+	names := map[PitchClassSet]string{
+		0:     "0-1",
+		1:     "1-1",
+		0x7ff: "11-1",
+		0xfff: "12-1",
+	}
+	// dyads are numbered by their interval class
+	for ic := 1; ic <= 6; ic++ {
+		dyad := PitchClassSet(1 | 1<<uint(ic))
+		names[dyad] = fmt.Sprintf("2-%d", ic)
+		names[dyad.Complement().PrimeForm()] = fmt.Sprintf("10-%d", ic)
+	}
+	for size, classes := range forteSetClasses {
+		for i, class := range classes {
+			z := ""
+			if class[0] == 'Z' {
+				z, class = "Z", class[1:]
+			}
+			var set PitchClassSet
+			for _, r := range class {
+				pc := strings.IndexRune("0123456789TE", r)
+				set = set.With(pc)
+			}
+			names[set.PrimeForm()] = fmt.Sprintf("%d-%s%d", size, z, i+1)
+			if size < 6 {
+				names[set.Complement().PrimeForm()] = fmt.Sprintf("%d-%s%d", 12-size, z, i+1)
+			}
+		}
+	}
+	return names
+}()
+
+// ForteName returns the name of the set's set class in Allen Forte's
+// catalog, like "3-11" for major and minor triads or "4-Z15" for one of the
+// all-interval tetrachords. The number before the hyphen is the number of
+// pitch classes in the set, and the number after it is the set class's
+// position in the catalog. A 'Z' means that the set class is Z-related to
+// another of the same size. (See ZRelated.)
+//
+// Forte's catalog only includes sets with three to nine pitch classes. Sets
+// with other sizes are named by the same convention: dyads are numbered by
+// their interval class (so a perfect 5th is "2-5"), sets with ten pitch
+// classes are numbered the same as their complements, and the empty set, a
+// single pitch class, eleven, and all twelve are "0-1", "1-1", "11-1", and
+// "12-1".
+func (s PitchClassSet) ForteName() string {
+	return forteNames[s.PrimeForm()]
+}
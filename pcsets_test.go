@@ -0,0 +1,226 @@
+package chords
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestChord_IntervalVector(t *testing.T) {
+	cases := map[string][6]int{
+		"C":    {0, 0, 1, 1, 1, 0},
+		"C-":   {0, 0, 1, 1, 1, 0},
+		"C7":   {0, 1, 2, 1, 1, 1},
+		"C△7":  {1, 0, 1, 2, 2, 0},
+		"Co":   {0, 0, 4, 0, 0, 2},
+		"C+":   {0, 0, 0, 3, 0, 0},
+		"C/E":  {0, 0, 1, 1, 1, 0},
+		"C/D":  {0, 2, 1, 1, 2, 0},
+		"G7#9": {1, 1, 3, 2, 2, 1},
+	}
+	for s, exp := range cases {
+		vec := MustParseChord(s).IntervalVector()
+		if vec != exp {
+			t.Errorf("Chord.IntervalVector for %s returned wrong value: %v != %v", s, vec, exp)
+		}
+	}
+}
+
+func TestZRelated(t *testing.T) {
+	// 4-Z15 and 4-Z29, the all-interval tetrachords
+	z15 := &Chord{Root: Note{N: C}, ExtraTones: []ChordTone{{Val: 2, Acc: Flat}, {Val: 5, Acc: Flat}}}
+	z29 := &Chord{Root: Note{N: C}, Triad: Min3, ExtraTones: []ChordTone{{Val: 2, Acc: Flat}}}
+	if !ZRelated(z15, z29) {
+		t.Errorf("%v and %v should be Z-related", z15, z29)
+	}
+	major := MustParseChord("C")
+	for _, s := range []string{"G", "C-", "Ab-"} {
+		if ZRelated(major, MustParseChord(s)) {
+			t.Errorf("%v and %s should not be Z-related", major, s)
+		}
+	}
+	pairs := ZRelatedPairs([]*Chord{major, z15, MustParseChord("F#"), z29})
+	if len(pairs) != 1 || pairs[0][0] != z15 || pairs[0][1] != z29 {
+		t.Errorf("ZRelatedPairs returned wrong value: %v", pairs)
+	}
+}
+
+func TestPitchClassSet(t *testing.T) {
+	c := MustParseChord("C").PitchClassSet()
+	if c.String() != "{0,4,7}" || c.Len() != 3 {
+		t.Errorf("wrong set for C: %v", c)
+	}
+	if actual := MustParseChord("G7/F").PitchClassSet().String(); actual != "{2,5,7,11}" {
+		t.Errorf("wrong set for G7/F: %s", actual)
+	}
+	major := MustParseScale("C major").PitchClassSet()
+	if major.String() != "{0,2,4,5,7,9,11}" {
+		t.Errorf("wrong set for C major: %v", major)
+	}
+	if actual := PitchClassSetOf(MustParseNote("B♯"), MustParseNote("C"), MustParseNote("E♭"), MustParseNote("A𝄪")); actual.String() != "{0,3,11}" {
+		t.Errorf("wrong set for notes: %v", actual)
+	}
+	if PitchClass(MustParseNote("C")) != 0 || PitchClass(MustParseNote("C♭")) != 11 || PitchClass(MustParseNote("A")) != 9 {
+		t.Errorf("wrong pitch classes")
+	}
+
+	if !c.Contains(4) || c.Contains(5) || !c.Contains(-5) || !c.Contains(12) {
+		t.Errorf("wrong membership for %v", c)
+	}
+	if actual := c.With(10).Without(0).Without(1); actual.String() != "{4,7,10}" {
+		t.Errorf("wrong result of With and Without: %v", actual)
+	}
+	if !c.IsSubsetOf(major) || major.IsSubsetOf(c) || !major.IsSupersetOf(c) || !c.IsSubsetOf(c) {
+		t.Errorf("wrong subset tests")
+	}
+	// the complement of a major scale is a pentatonic scale
+	if actual := major.Complement(); actual != MustParseScale("F♯ major pentatonic").PitchClassSet() || actual.Len() != 5 {
+		t.Errorf("wrong complement: %v", actual)
+	}
+	a := MustParseChord("A-").PitchClassSet()
+	if actual := c.Union(a); actual.String() != "{0,4,7,9}" {
+		t.Errorf("wrong union: %v", actual)
+	}
+	if actual := c.Intersection(a); actual.String() != "{0,4}" {
+		t.Errorf("wrong intersection: %v", actual)
+	}
+	if actual := c.Difference(a); actual.String() != "{7}" {
+		t.Errorf("wrong difference: %v", actual)
+	}
+	if actual := c.Transpose(7); actual != MustParseChord("G").PitchClassSet() {
+		t.Errorf("wrong transposition: %v", actual)
+	}
+	if actual := c.Transpose(-12); actual != c {
+		t.Errorf("wrong transposition: %v", actual)
+	}
+	// the inversion of a major triad is a minor triad
+	if actual := c.Invert(); actual != MustParseChord("F-").PitchClassSet() {
+		t.Errorf("wrong inversion: %v", actual)
+	}
+	if actual := c.IntervalVector(); actual != MustParseChord("C").IntervalVector() {
+		t.Errorf("wrong interval vector: %v", actual)
+	}
+	if actual := PitchClassSet(0).String(); actual != "{}" {
+		t.Errorf("wrong empty set: %s", actual)
+	}
+}
+
+func TestPitchClassSet_NormalOrder(t *testing.T) {
+	cases := []struct {
+		set    string
+		normal string
+		prime  string
+	}{
+		{set: "C", normal: "[0 4 7]", prime: "{0,3,7}"},
+		{set: "C-", normal: "[0 3 7]", prime: "{0,3,7}"},
+		{set: "G7", normal: "[11 2 5 7]", prime: "{0,2,5,8}"},
+		{set: "Bø", normal: "[9 11 2 5]", prime: "{0,2,5,8}"},
+		{set: "C△7", normal: "[11 0 4 7]", prime: "{0,1,5,8}"},
+		{set: "Co", normal: "[0 3 6 9]", prime: "{0,3,6,9}"},
+		{set: "C+", normal: "[0 4 8]", prime: "{0,4,8}"},
+		{set: "E♭6", normal: "[7 10 0 3]", prime: "{0,3,5,8}"},
+	}
+	for _, tc := range cases {
+		set := MustParseChord(tc.set).PitchClassSet()
+		if actual := fmt.Sprint(set.NormalOrder()); actual != tc.normal {
+			t.Errorf("PitchClassSet.NormalOrder for %s returned wrong value: %s != %s", tc.set, actual, tc.normal)
+		}
+		if actual := set.PrimeForm().String(); actual != tc.prime {
+			t.Errorf("PitchClassSet.PrimeForm for %s returned wrong value: %s != %s", tc.set, actual, tc.prime)
+		}
+	}
+	if actual := fmt.Sprint(PitchClassSet(0).NormalOrder()); actual != "[]" {
+		t.Errorf("wrong normal order for empty set: %s", actual)
+	}
+	// Rahn's prime form of 5-20, which differs from Forte's
+	set := PitchClassSetOf(parseNotes(t, "C D♭ E♭ G A♭")...)
+	if actual := set.PrimeForm().String(); actual != "{0,1,5,6,8}" {
+		t.Errorf("wrong prime form for 5-20: %s", actual)
+	}
+}
+
+func TestPitchClassSet_ForteName(t *testing.T) {
+	cases := []struct {
+		set, exp string
+	}{
+		{set: "C", exp: "3-11"},
+		{set: "C-", exp: "3-11"},
+		{set: "Cdim", exp: "3-10"},
+		{set: "C+", exp: "3-12"},
+		{set: "G7", exp: "4-27"},
+		{set: "C△7", exp: "4-20"},
+		{set: "C-7", exp: "4-26"},
+		{set: "Co", exp: "4-28"},
+		{set: "Csus4", exp: "3-9"},
+	}
+	for _, tc := range cases {
+		if actual := MustParseChord(tc.set).PitchClassSet().ForteName(); actual != tc.exp {
+			t.Errorf("PitchClassSet.ForteName for %s returned wrong value: %s != %s", tc.set, actual, tc.exp)
+		}
+	}
+	scales := []struct {
+		scale, exp string
+	}{
+		{scale: "C major", exp: "7-35"},
+		{scale: "C major pentatonic", exp: "5-35"},
+		{scale: "C whole tone", exp: "6-35"},
+		{scale: "C harmonic minor", exp: "7-32"},
+		{scale: "C melodic minor", exp: "7-34"},
+	}
+	for _, tc := range scales {
+		if actual := MustParseScale(tc.scale).PitchClassSet().ForteName(); actual != tc.exp {
+			t.Errorf("PitchClassSet.ForteName for %s returned wrong value: %s != %s", tc.scale, actual, tc.exp)
+		}
+	}
+	z15 := PitchClassSetOf(parseNotes(t, "C D♭ E G♭")...)
+	z29 := PitchClassSetOf(parseNotes(t, "C D♭ E♭ G")...)
+	if z15.ForteName() != "4-Z15" || z29.ForteName() != "4-Z29" {
+		t.Errorf("wrong names for all-interval tetrachords: %s, %s", z15.ForteName(), z29.ForteName())
+	}
+	if actual := PitchClassSet(0x81).ForteName(); actual != "2-5" {
+		t.Errorf("wrong name for a perfect 5th: %s", actual)
+	}
+
+	// every set has a name, and the names are consistent with set classes,
+	// sizes, complements, and Z-relations
+	classes := map[string]PitchClassSet{}
+	for set := PitchClassSet(0); set <= allPitchClasses; set++ {
+		name := set.ForteName()
+		if name == "" {
+			t.Fatalf("no name for %v", set)
+		}
+		if prime, ok := classes[name]; ok && prime != set.PrimeForm() {
+			t.Fatalf("%s is the name of %v and %v", name, prime, set.PrimeForm())
+		}
+		classes[name] = set.PrimeForm()
+		if !strings.HasPrefix(name, fmt.Sprintf("%d-", set.Len())) {
+			t.Errorf("wrong size in name %s for %v", name, set)
+		}
+		comp := set.Complement().ForteName()
+		if set.Len() != 6 && name[strings.IndexByte(name, '-'):] != comp[strings.IndexByte(comp, '-'):] {
+			t.Errorf("%v is %s, but its complement is %s", set, name, comp)
+		}
+	}
+	if len(classes) != 224 {
+		t.Errorf("PitchClassSet.ForteName returned wrong number of set classes: %d != 224", len(classes))
+	}
+	for name, prime := range classes {
+		var related []string
+		for other, otherPrime := range classes {
+			if other != name && otherPrime.Len() == prime.Len() && otherPrime.IntervalVector() == prime.IntervalVector() {
+				related = append(related, other)
+			}
+		}
+		if strings.Contains(name, "Z") != (len(related) == 1) || len(related) > 1 {
+			t.Errorf("%s has the same interval vector as %v", name, related)
+		}
+		// Z-related hexachords are complements of each other, and other
+		// hexachords are their own complements
+		if prime.Len() == 6 {
+			comp := prime.Complement().ForteName()
+			if (comp == name) == strings.Contains(name, "Z") {
+				t.Errorf("%s has complement %s", name, comp)
+			}
+		}
+	}
+}
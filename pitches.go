@@ -0,0 +1,244 @@
+package chords
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+)
+
+// Pitch is a note in a particular octave. Octaves are numbered using scientific
+// pitch notation, where middle C is C4 and the A above it (commonly used as a
+// tuning reference) is A4. Octave numbers increment at C, so B3 is one half-step
+// below C4. The octave is determined by the note name, not by the accidental:
+// so B♯3 sounds the same as C4 and C♭4 sounds the same as B3.
+type Pitch struct {
+	Note   Note
+	Octave int8
+}
+
+// ParsePitch parses a pitch from the given string. The string must be a note
+// (same syntax as accepted by ParseNote) followed by an octave number. For
+// example "C4" is middle C, and "Bb3" is the B-flat below middle C. Octave
+// numbers may be negative, as in "C-1".
+func ParsePitch(s string) (Pitch, error) {
+	n, rest, err := parseNotePrefix(s)
+	if err != nil {
+		return Pitch{}, err
+	}
+	if len(rest) == 0 {
+		return Pitch{}, fmt.Errorf("pitch %q is missing octave", s)
+	}
+	o, err := strconv.ParseInt(rest, 10, 8)
+	if err != nil {
+		return Pitch{}, fmt.Errorf("invalid octave %q", rest)
+	}
+	return Pitch{Note: n, Octave: int8(o)}, nil
+}
+
+// MustParsePitch parses the given string into a pitch and panics if the string
+// is not valid. (See ParsePitch.)
+func MustParsePitch(s string) Pitch {
+	p, err := ParsePitch(s)
+	if err != nil {
+		panic(err)
+	}
+	return p
+}
+
+// String implements the Stringer interface.
+func (p Pitch) String() string {
+	return fmt.Sprintf("%v%d", p.Note, p.Octave)
+}
+
+// IsValid returns true only if this pitch is valid. A valid pitch has a valid
+// note.
+func (p Pitch) IsValid() bool {
+	return p.Note.IsValid()
+}
+
+// MIDINumber returns the MIDI note number for this pitch. Middle C (C4) is 60
+// and A4 is 69. Each half-step changes the number by one. Pitches below C-1
+// have negative numbers and pitches above G9 have numbers greater than 127,
+// neither of which are representable in MIDI messages.
+func (p Pitch) MIDINumber() int {
+	return (int(p.Octave)+1)*12 + int(halfStepsFromC(p.Note.N)) + int(p.Note.Acc.Offset())
+}
+
+// Compare returns a negative number if this pitch is lower than the given
+// pitch, a positive number if it is higher, and zero if they are the same.
+// Pitches are ordered by how they sound, so C4 is higher than B♭3. Enharmonic
+// equivalents that sound the same, like B♯3 and C4, are ordered by their
+// position on the staff: B♯3 is lower than C4 because B is below C.
+func (p Pitch) Compare(other Pitch) int {
+	if d := p.MIDINumber() - other.MIDINumber(); d != 0 {
+		return d
+	}
+	return p.staffPosition() - other.staffPosition()
+}
+
+// Less returns true if this pitch is lower than the given pitch. (See
+// Pitch.Compare.)
+func (p Pitch) Less(other Pitch) bool {
+	return p.Compare(other) < 0
+}
+
+// staffPosition returns the number of note names between C0 and this pitch.
+func (p Pitch) staffPosition() int {
+	return int(p.Octave)*7 + int(letterFromC(p.Note.N))
+}
+
+// PitchRange is a range of pitches, such as the range of an instrument or of
+// a voice. Both ends of the range are inclusive.
+type PitchRange struct {
+	Low, High Pitch
+}
+
+// Contains returns true if the given pitch is in the range. Since a range
+// describes what can be played or sung, this is based on how the pitch
+// sounds, not how it is spelled. So a range whose lowest pitch is C4 contains
+// B♯3.
+func (r PitchRange) Contains(p Pitch) bool {
+	m := p.MIDINumber()
+	return m >= r.Low.MIDINumber() && m <= r.High.MIDINumber()
+}
+
+// IsValid returns true if the range has valid pitches and its low pitch is
+// not higher than its high pitch.
+func (r PitchRange) IsValid() bool {
+	return r.Low.IsValid() && r.High.IsValid() && r.Low.Compare(r.High) <= 0
+}
+
+// String implements the Stringer interface.
+func (r PitchRange) String() string {
+	return fmt.Sprintf("%v-%v", r.Low, r.High)
+}
+
+// halfStepsFromC returns the number of half-steps between C and the given note
+// name, going up. So C is zero and B is 11.
+func halfStepsFromC(n NoteName) int8 {
+	return posMod(n.Cardinal()-C.Cardinal(), 12)
+}
+
+// Tuning describes how pitches are mapped to frequencies. All tunings use
+// twelve-tone equal temperament (12-TET), where every half-step is the same
+// ratio, 2^(1/12). The Reference pitch sounds at the given Frequency, and all
+// other frequencies are computed relative to it.
+//
+// The zero value is not a valid tuning. Functions that accept a tuning use
+// StandardTuning in its place.
+type Tuning struct {
+	// Reference is the pitch whose frequency is known.
+	Reference Pitch
+	// Frequency is the frequency of the reference pitch, in hertz.
+	Frequency float64
+}
+
+var (
+	// StandardTuning is the standard modern concert pitch, A4 = 440 Hz.
+	StandardTuning = Tuning{Reference: Pitch{Note: Note{N: A}, Octave: 4}, Frequency: 440}
+	// A432Tuning is an alternate tuning where A4 = 432 Hz.
+	A432Tuning = Tuning{Reference: Pitch{Note: Note{N: A}, Octave: 4}, Frequency: 432}
+	// BaroqueTuning is the tuning commonly used for performances of baroque
+	// music, where A4 = 415 Hz (about a half-step lower than standard).
+	BaroqueTuning = Tuning{Reference: Pitch{Note: Note{N: A}, Octave: 4}, Frequency: 415}
+)
+
+// IsValid returns true if the tuning has a valid reference pitch and a
+// positive frequency.
+func (t Tuning) IsValid() bool {
+	return t.Reference.IsValid() && t.Frequency > 0
+}
+
+// orDefault returns t or, if t is the zero value, StandardTuning.
+func (t Tuning) orDefault() Tuning {
+	if t == (Tuning{}) {
+		return StandardTuning
+	}
+	return t
+}
+
+// Frequency returns the frequency, in hertz, of this pitch using the given
+// tuning. If the given tuning is the zero value, StandardTuning is used.
+func (p Pitch) Frequency(tuning Tuning) float64 {
+	tuning = tuning.orDefault()
+	steps := p.MIDINumber() - tuning.Reference.MIDINumber()
+	return tuning.Frequency * math.Pow(2, float64(steps)/12)
+}
+
+// CentsBetween returns the distance, in cents, from pitch a to pitch b using
+// the given tuning. A cent is one hundredth of an equal-tempered half-step, so
+// there are 1200 cents in an octave. The result is negative if b is lower than
+// a. If the given tuning is the zero value, StandardTuning is used.
+func CentsBetween(a, b Pitch, tuning Tuning) float64 {
+	return cents(a.Frequency(tuning), b.Frequency(tuning))
+}
+
+// CentsTo returns the distance, in cents, from this pitch to the given
+// frequency, in hertz, using the given tuning. This is how far out of tune
+// the frequency is: the result is positive if the frequency is sharp and
+// negative if it is flat. If the given tuning is the zero value,
+// StandardTuning is used.
+func (p Pitch) CentsTo(freq float64, tuning Tuning) float64 {
+	return cents(p.Frequency(tuning), freq)
+}
+
+// cents returns the distance, in cents, between the two given frequencies.
+func cents(from, to float64) float64 {
+	return 1200 * math.Log2(to/from)
+}
+
+// Transpose returns the pitch that results from transposing this pitch by the
+// given interval. The octave of the result accounts for the number of note
+// names spanned by the interval, so transposing B3 up a minor second is C4,
+// and transposing C4 up a major ninth is D5. If the interval is descending,
+// the pitch is transposed down: so transposing C4 by a descending minor third
+// is A3.
+func (p Pitch) Transpose(intv CompoundInterval) Pitch {
+	letter := int(letterFromC(p.Note.N))
+	if intv.IsDescending() {
+		n := p.Note.TransposeDown(intv.Simple())
+		steps := letter - int(intv.size()) + 1
+		return Pitch{Note: n, Octave: p.Octave + int8(floorDiv(steps, 7))}
+	}
+	n := p.Note.Transpose(intv.Simple())
+	steps := letter + int(intv.Val) - 1
+	return Pitch{Note: n, Octave: p.Octave + int8(steps/7)}
+}
+
+// IntervalTo returns the interval from this pitch to the given pitch. If the
+// given pitch is lower than this one, the interval is descending. As with
+// Note.IntervalTo, the interval's Val is determined by the note names. So the
+// interval from C4 to E♭4 is a minor third, but the interval from C4 to D♯4 is
+// an augmented second.
+func (p Pitch) IntervalTo(other Pitch) CompoundInterval {
+	letters := int(other.Octave)*7 + int(letterFromC(other.Note.N)) -
+		int(p.Octave)*7 - int(letterFromC(p.Note.N))
+	steps := other.MIDINumber() - p.MIDINumber()
+	if letters < 0 || (letters == 0 && steps < 0) {
+		return other.IntervalTo(p).Reverse()
+	}
+	intv := CompoundInterval{Val: int8(letters + 1)}
+	intv.Offset = int8(steps - intv.NumHalfSteps())
+	if intv.Offset < -2 || intv.Offset > 2 {
+		// not representable with this interval's note names, so use the
+		// conventional spelling for the distance
+		simple := intervalsByHalfSteps[posMod(int8(steps%12), 12)]
+		intv = Compound(simple, int8(steps/12))
+	}
+	return intv
+}
+
+// floorDiv divides a by b, rounding toward negative infinity.
+func floorDiv(a, b int) int {
+	q := a / b
+	if a%b != 0 && (a < 0) != (b < 0) {
+		q--
+	}
+	return q
+}
+
+// letterFromC returns the number of note names between C and the given note
+// name, going up. So C is zero, D is one, and B is six.
+func letterFromC(n NoteName) int8 {
+	return posMod(int8(n-C), 7)
+}
@@ -0,0 +1,108 @@
+package chords
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Polychord is one chord played over another, like a D major triad over a C
+// major triad, written D|C. Polychords are common in contemporary scores,
+// where the upper chord is usually a triad that is heard as a unit rather
+// than as extensions of the lower chord. Unlike a slash chord, whose bass is
+// a single note, the lower element of a polychord is a full chord.
+type Polychord struct {
+	// Upper is the chord on top. It has no bass note.
+	Upper *Chord
+	// Lower is the chord underneath, which may have a bass note of its own.
+	Lower *Chord
+}
+
+// ParsePolychord parses the given string into a polychord. The upper and
+// lower chords are separated by a '|', like "D|C", or by a '/', like "D/C△",
+// with each chord parsed by ParseChord. When separated by a '/', the lower
+// chord must be more than a note name, since "D/C" is a slash chord: a D major
+// triad with C in the bass. So "D/C△" or "D/C-" is a polychord. Whitespace
+// around the separator is allowed.
+//
+// This returns an error if either chord is invalid or if the upper chord has
+// a bass note.
+func ParsePolychord(s string) (*Polychord, error) {
+	upper, lower, ok := splitPolychord(s)
+	if !ok {
+		return nil, fmt.Errorf("%q is not a polychord: it must be two chords separated by '|' or '/'", s)
+	}
+	u, err := ParseChord(upper)
+	if err != nil {
+		return nil, fmt.Errorf("invalid upper chord %q: %v", upper, err)
+	}
+	if u.Bass.N != 0 {
+		return nil, fmt.Errorf("upper chord %q cannot have a bass note", upper)
+	}
+	l, err := ParseChord(lower)
+	if err != nil {
+		return nil, fmt.Errorf("invalid lower chord %q: %v", lower, err)
+	}
+	return &Polychord{Upper: u, Lower: l}, nil
+}
+
+// splitPolychord splits the given string into its upper and lower chords. The
+// string is split at its first '|', if it has one. Otherwise it is split at
+// its first '/' that is not followed by just a note name.
+func splitPolychord(s string) (upper, lower string, ok bool) {
+	if i := strings.IndexByte(s, '|'); i >= 0 {
+		return strings.TrimSpace(s[:i]), strings.TrimSpace(s[i+1:]), true
+	}
+	for i := 0; i < len(s); i++ {
+		if s[i] != '/' {
+			continue
+		}
+		rest := strings.TrimSpace(s[i+1:])
+		if _, err := ParseNote(rest); err == nil {
+			continue
+		}
+		return strings.TrimSpace(s[:i]), rest, true
+	}
+	return "", "", false
+}
+
+// MustParsePolychord parses the given string and panics if it is not a valid
+// polychord representation.
+func MustParsePolychord(s string) *Polychord {
+	p, err := ParsePolychord(s)
+	if err != nil {
+		panic(err)
+	}
+	return p
+}
+
+// String implements the Stringer interface. The chords are separated by a
+// '|', like "D|C", since a '/' could be mistaken for a slash chord.
+func (p *Polychord) String() string {
+	return p.Upper.String() + "|" + p.Lower.String()
+}
+
+// Spell returns the notes of the polychord, from the bottom up: the notes of
+// the lower chord (see Chord.Spell), followed by the notes of the upper chord.
+// Notes of the upper chord that are also in the lower chord, spelled the same
+// way, are left out. So D|C is spelled C E G D F♯ A.
+func (p *Polychord) Spell() []Note {
+	notes := p.Lower.Spell()
+	lower := len(notes)
+	for _, n := range p.Upper.Spell() {
+		if !containsNote(notes[:lower], n) {
+			notes = append(notes, n)
+		}
+	}
+	return notes
+}
+
+// PitchClassSet returns the set of the pitch classes in both chords.
+func (p *Polychord) PitchClassSet() PitchClassSet {
+	return p.Upper.PitchClassSet().Union(p.Lower.PitchClassSet())
+}
+
+// Transpose returns a new polychord that is this polychord transposed by the
+// given interval. (See Chord.Transpose.) This polychord is not modified.
+func (p *Polychord) Transpose(interval Interval) *Polychord {
+	return &Polychord{Upper: p.Upper.Transpose(interval), Lower: p.Lower.Transpose(interval)}
+}
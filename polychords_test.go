@@ -0,0 +1,59 @@
+package chords
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestParsePolychord(t *testing.T) {
+	cases := []struct {
+		input  string
+		exp    string
+		spell  string
+		errMsg string
+	}{
+		{input: "D|C", exp: "D|C", spell: "[C E G D F♯ A]"},
+		{input: "D/C△", exp: "D|C", spell: "[C E G D F♯ A]"},
+		{input: "E♭ / C-", exp: "E♭|C-", spell: "[C E♭ G B♭]"},
+		{input: "F♯-|C7/E", exp: "F♯-|C7/E", spell: "[E C E G B♭ F♯ A C♯]"},
+		{input: "D/C", errMsg: `"D/C" is not a polychord: it must be two chords separated by '|' or '/'`},
+		{input: "D/F♯|C", errMsg: `upper chord "D/F♯" cannot have a bass note`},
+		{input: "D|X", errMsg: `invalid lower chord "X": `},
+		{input: "|C", errMsg: `invalid upper chord "": `},
+	}
+	for _, tc := range cases {
+		p, err := ParsePolychord(tc.input)
+		if tc.errMsg != "" {
+			if err == nil {
+				t.Errorf("ParsePolychord(%q) should have failed: %v", tc.input, p)
+			} else if len(err.Error()) < len(tc.errMsg) || err.Error()[:len(tc.errMsg)] != tc.errMsg {
+				t.Errorf("ParsePolychord for %q returned wrong value: %q != %q", tc.input, err, tc.errMsg)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("ParsePolychord for %q failed: %v", tc.input, err)
+			continue
+		}
+		if actual := p.String(); actual != tc.exp {
+			t.Errorf("ParsePolychord for %q returned wrong value: %s != %s", tc.input, actual, tc.exp)
+		}
+		if actual := fmt.Sprint(p.Spell()); actual != tc.spell {
+			t.Errorf("ParsePolychord for %q returned wrong value: %s != %s", tc.input, actual, tc.spell)
+		}
+	}
+}
+
+func TestPolychord_Transpose(t *testing.T) {
+	p := MustParsePolychord("D|C")
+	tp := p.Transpose(Interval{Val: 2})
+	if actual, exp := tp.String(), "E|D"; actual != exp {
+		t.Errorf("Polychord.Transpose returned wrong value: %s != %s", actual, exp)
+	}
+	if actual, exp := p.String(), "D|C"; actual != exp {
+		t.Errorf("Polychord.Transpose modified the original polychord: %s != %s", actual, exp)
+	}
+	if actual, exp := tp.PitchClassSet().String(), "{2,4,6,8,9,11}"; actual != exp {
+		t.Errorf("Polychord.Transpose returned wrong value: %s != %s", actual, exp)
+	}
+}
@@ -0,0 +1,499 @@
+package chords
+
+import (
+	"bytes"
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Progression is a chord chart: a sequence of bars (aka measures), each of
+// which contains one or more chords. Each chord lasts for some number of
+// beats. Sections of the chart may be repeated.
+type Progression struct {
+	// BeatsPerBar is the number of beats in each bar. This is the top number
+	// of the time signature: 4 for 4/4 time, 3 for 3/4 time, etc. Zero is
+	// treated the same as 4.
+	BeatsPerBar int
+	// Bars are the bars of the progression, in order.
+	Bars []Bar
+}
+
+// Bar is one bar (aka measure) of a Progression.
+type Bar struct {
+	// Chords are the chords in the bar, in the order they are played.
+	Chords []BarChord
+	// RepeatStart is true if a repeated section starts with this bar.
+	RepeatStart bool
+	// RepeatCount is the number of times to play the repeated section that
+	// ends with this bar, or zero if no repeated section ends with this bar.
+	// The section starts with the most recent bar before it (or the bar itself)
+	// whose RepeatStart is true. If there is no such bar, it starts after the
+	// previous repeated section or, if there is none, at the first bar of the
+	// progression. A value of 2 means the section is played twice: once and
+	// then repeated once.
+	RepeatCount int
+}
+
+// BarChord is a chord in a Bar, along with how long it is played.
+type BarChord struct {
+	// Chord is the chord that is played.
+	Chord *Chord
+	// Beats is the number of beats for which the chord is played.
+	Beats int
+}
+
+// ParseProgression parses the given chord chart. The chart is written as bars
+// separated by bar lines ('|'), each of which contains chords separated by
+// whitespace. Chords are parsed with ParseChord. For example:
+//
+//	| C△7 | A-7 D7 | G-7 C7 | F△7 |
+//
+// The chart may start with a time signature, like '3/4', before the first bar
+// line. Otherwise, the chart is in 4/4 time. Only the number of beats per bar
+// is used: the bottom number of the time signature is ignored.
+//
+// The beats in a bar are divided evenly among its chords. So in 4/4 time, a bar
+// with two chords has two beats for each chord. A slash ('/') can be used in
+// place of a chord to mean that the previous chord continues for another share
+// of the bar: so '| C / / G |' has three beats of C and one beat of G. If the
+// beats do not divide evenly, the earlier chords get the extra beats. A bar
+// that contains only a percent sign ('%') repeats the chords of the previous
+// bar.
+//
+// Repeated sections start with '|:' and end with ':|'. The end of a repeat may
+// be followed by a count, like ':| x3', to indicate how many times the section
+// is played; otherwise it is played twice. A double bar line ('||') is treated
+// the same as a single bar line.
+//
+// Each chord is validated and then canonicalized.
+func ParseProgression(s string) (*Progression, error) {
+	return parseProgression(s, 4)
+}
+
+// parseProgression parses the given chord chart. If the chart does not start
+// with a time signature, it has the given number of beats per bar.
+func parseProgression(s string, beatsPerBar int) (*Progression, error) {
+	tokens := tokenizeProgression(s)
+	p := &Progression{BeatsPerBar: beatsPerBar}
+	if len(tokens) > 0 && tokens[0][0] >= '0' && tokens[0][0] <= '9' {
+		beats, err := parseTimeSignature(tokens[0])
+		if err != nil {
+			return nil, err
+		}
+		p.BeatsPerBar = beats
+		tokens = tokens[1:]
+	}
+
+	var cur []string
+	repeatStart := false
+	endBar := func(repeatCount int) error {
+		if len(cur) == 0 {
+			if repeatCount > 0 {
+				if len(p.Bars) == 0 {
+					return fmt.Errorf("repeat sign %q must follow a bar", ":|")
+				}
+				p.Bars[len(p.Bars)-1].RepeatCount = repeatCount
+			}
+			return nil
+		}
+		bar, err := p.parseBar(cur)
+		if err != nil {
+			return fmt.Errorf("bar %d: %v", len(p.Bars)+1, err)
+		}
+		bar.RepeatStart = repeatStart
+		bar.RepeatCount = repeatCount
+		p.Bars = append(p.Bars, *bar)
+		cur = nil
+		repeatStart = false
+		return nil
+	}
+	for i := 0; i < len(tokens); i++ {
+		tok := tokens[i]
+		switch tok {
+		case "|":
+			if err := endBar(0); err != nil {
+				return nil, err
+			}
+		case "|:":
+			if err := endBar(0); err != nil {
+				return nil, err
+			}
+			repeatStart = true
+		case ":|":
+			count := 2
+			if i+1 < len(tokens) && len(tokens[i+1]) > 1 && (tokens[i+1][0] == 'x' || tokens[i+1][0] == 'X') {
+				n, err := strconv.Atoi(tokens[i+1][1:])
+				if err == nil {
+					if n < 1 {
+						return nil, fmt.Errorf("invalid repeat count %q", tokens[i+1])
+					}
+					count = n
+					i++
+				}
+			}
+			if err := endBar(count); err != nil {
+				return nil, err
+			}
+		default:
+			cur = append(cur, tok)
+		}
+	}
+	if err := endBar(0); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+// MustParseProgression parses the given chord chart and panics if it is not
+// valid. (See ParseProgression.)
+func MustParseProgression(s string) *Progression {
+	p, err := ParseProgression(s)
+	if err != nil {
+		panic(err)
+	}
+	return p
+}
+
+// tokenizeProgression splits the given chord chart into tokens: bar lines
+// ("|", "|:", and ":|") and the text between them, split on whitespace.
+func tokenizeProgression(s string) []string {
+	var tokens []string
+	start := -1
+	flush := func(end int) {
+		if start >= 0 {
+			tokens = append(tokens, s[start:end])
+			start = -1
+		}
+	}
+	for i := 0; i < len(s); {
+		r, sz := utf8.DecodeRuneInString(s[i:])
+		switch {
+		case strings.HasPrefix(s[i:], ":|"):
+			flush(i)
+			tokens = append(tokens, ":|")
+			i += 2
+			if strings.HasPrefix(s[i:], ":") {
+				// ":|:" ends one repeat and starts another
+				tokens = append(tokens, "|:")
+				i++
+			}
+			continue
+		case strings.HasPrefix(s[i:], "|:"):
+			flush(i)
+			tokens = append(tokens, "|:")
+			i += 2
+			continue
+		case strings.HasPrefix(s[i:], "||"):
+			flush(i)
+			tokens = append(tokens, "|")
+			i += 2
+			continue
+		case r == '|':
+			flush(i)
+			tokens = append(tokens, "|")
+		case unicode.IsSpace(r):
+			flush(i)
+		default:
+			if start < 0 {
+				start = i
+			}
+		}
+		i += sz
+	}
+	flush(len(s))
+	return tokens
+}
+
+// parseTimeSignature parses a time signature, like "3/4", and returns the
+// number of beats per bar.
+func parseTimeSignature(s string) (int, error) {
+	pos := strings.IndexByte(s, '/')
+	if pos < 0 {
+		return 0, fmt.Errorf("invalid time signature %q", s)
+	}
+	beats, err := strconv.Atoi(s[:pos])
+	if err != nil || beats <= 0 {
+		return 0, fmt.Errorf("invalid time signature %q", s)
+	}
+	if unit, err := strconv.Atoi(s[pos+1:]); err != nil || unit <= 0 {
+		return 0, fmt.Errorf("invalid time signature %q", s)
+	}
+	return beats, nil
+}
+
+// parseBar parses the given tokens, which are the contents of one bar.
+func (p *Progression) parseBar(tokens []string) (*Bar, error) {
+	if len(tokens) == 1 && tokens[0] == "%" {
+		if len(p.Bars) == 0 {
+			return nil, fmt.Errorf("%q must follow another bar", "%")
+		}
+		prev := p.Bars[len(p.Bars)-1]
+		return &Bar{Chords: append([]BarChord(nil), prev.Chords...)}, nil
+	}
+	beats := p.beatsPerBar()
+	if len(tokens) > beats {
+		return nil, fmt.Errorf("%d chords and slashes do not fit in %d beats", len(tokens), beats)
+	}
+	var bar Bar
+	for i, tok := range tokens {
+		n := beats / len(tokens)
+		if i < beats%len(tokens) {
+			n++
+		}
+		if tok == "/" {
+			if len(bar.Chords) == 0 {
+				return nil, fmt.Errorf("%q must follow a chord", "/")
+			}
+			bar.Chords[len(bar.Chords)-1].Beats += n
+			continue
+		}
+		ch, err := ParseChord(tok)
+		if err == nil {
+			err = ch.Validate()
+		}
+		if err != nil {
+			return nil, fmt.Errorf("invalid chord %q: %v", tok, err)
+		}
+		ch.Canonicalize()
+		bar.Chords = append(bar.Chords, BarChord{Chord: ch, Beats: n})
+	}
+	return &bar, nil
+}
+
+func (p *Progression) beatsPerBar() int {
+	if p.BeatsPerBar <= 0 {
+		return 4
+	}
+	return p.BeatsPerBar
+}
+
+// Unroll returns a new progression with the repeated sections expanded, so
+// that the bars are in the order they are played. None of the resulting bars
+// start or end a repeated section.
+func (p *Progression) Unroll() *Progression {
+	ret := &Progression{BeatsPerBar: p.BeatsPerBar}
+	start := 0
+	for i, bar := range p.Bars {
+		if bar.RepeatStart {
+			start = i
+		}
+		ret.Bars = append(ret.Bars, Bar{Chords: bar.Chords})
+		for n := 1; n < bar.RepeatCount; n++ {
+			for _, b := range p.Bars[start : i+1] {
+				ret.Bars = append(ret.Bars, Bar{Chords: b.Chords})
+			}
+		}
+		if bar.RepeatCount > 0 {
+			start = i + 1
+		}
+	}
+	return ret
+}
+
+// Clone returns a deep copy of the progression. The copy shares none of the
+// progression's bars or chords, so either can be modified without affecting
+// the other. (See Chord.Clone.)
+func (p *Progression) Clone() *Progression {
+	ret := &Progression{BeatsPerBar: p.BeatsPerBar, Bars: make([]Bar, len(p.Bars))}
+	for i, bar := range p.Bars {
+		ret.Bars[i] = bar
+		ret.Bars[i].Chords = make([]BarChord, len(bar.Chords))
+		for j, bc := range bar.Chords {
+			ret.Bars[i].Chords[j] = BarChord{Chord: bc.Chord.Clone(), Beats: bc.Beats}
+		}
+	}
+	return ret
+}
+
+// Chords returns all of the chords in the progression, in the order they
+// are written. Repeated sections are not expanded, and a chord that spans
+// several bars appears once for each bar.
+func (p *Progression) Chords() []*Chord {
+	var chs []*Chord
+	for _, bar := range p.Bars {
+		for _, bc := range bar.Chords {
+			chs = append(chs, bc.Chord)
+		}
+	}
+	return chs
+}
+
+// String implements the Stringer interface. The result is in the format
+// accepted by ParseProgression. The time signature is only included if the
+// progression is not in 4/4 time. Slashes are only used in bars whose chords
+// do not all have the same number of beats.
+func (p *Progression) String() string {
+	var b bytes.Buffer
+	if beats := p.beatsPerBar(); beats != 4 {
+		fmt.Fprintf(&b, "%d/4 ", beats)
+	}
+	// prevRepeat is the repeat count of the previous bar
+	prevRepeat := 0
+	for _, bar := range p.Bars {
+		switch {
+		case bar.RepeatStart && prevRepeat == 2:
+			// combine the bar lines into ":|:"
+			b.Truncate(b.Len() - 1)
+			b.WriteString(": ")
+		case bar.RepeatStart:
+			b.WriteString("|: ")
+		case prevRepeat == 0:
+			b.WriteString("| ")
+		}
+		for _, tok := range bar.tokens() {
+			b.WriteString(tok)
+			b.WriteByte(' ')
+		}
+		prevRepeat = bar.RepeatCount
+		if bar.RepeatCount > 0 {
+			b.WriteString(":|")
+			if bar.RepeatCount != 2 {
+				fmt.Fprintf(&b, " x%d", bar.RepeatCount)
+			}
+			b.WriteByte(' ')
+		}
+	}
+	if prevRepeat == 0 && len(p.Bars) > 0 {
+		b.WriteByte('|')
+	}
+	return strings.TrimSpace(b.String())
+}
+
+// tokens returns the chords of the bar, with slashes as needed to indicate
+// their durations.
+func (bar *Bar) tokens() []string {
+	unit := 0
+	for _, bc := range bar.Chords {
+		unit = gcd(unit, bc.Beats)
+	}
+	var tokens []string
+	for _, bc := range bar.Chords {
+		tokens = append(tokens, bc.Chord.String())
+		for i := unit; i < bc.Beats; i += unit {
+			tokens = append(tokens, "/")
+		}
+	}
+	return tokens
+}
+
+func gcd(a, b int) int {
+	for b != 0 {
+		a, b = b, a%b
+	}
+	return a
+}
+
+// Transpose returns a new progression that is this progression transposed by
+// the given interval.
+//
+// Unlike transposing each chord individually, the whole chart is spelled
+// consistently. The progression's key is inferred (see InferKey), and the
+// interval is replaced with an enharmonically equivalent one if that results
+// in a key with a simpler key signature: so transposing a chart in C up by an
+// augmented unison results in a chart in D♭, not C♯. The chords are then
+// respelled as needed so that each pitch class has the same spelling in every
+// chord (see TransposeToKey).
+func (p *Progression) Transpose(intv Interval) *Progression {
+	key := InferKey(p.Chords()...)
+	intv = simplestTransposition(key, intv)
+	return p.transpose(intv, Key{Tonic: key.Tonic.Transpose(intv), Minor: key.Minor})
+}
+
+// TransposeToKey returns a new progression that is this progression
+// transposed into the given key. The progression's current key is inferred
+// (see InferKey). If the progression is in a minor key and the given key is
+// major (or vice versa), the progression is transposed into the relative
+// minor (or major) of the given key, which shares its key signature.
+//
+// After transposing, the chords are respelled so that each pitch class has
+// the same spelling in every chord. Chord roots and bass notes that are in the
+// key's scale use the scale's spelling (for minor keys, this includes the
+// raised 7th of the harmonic minor scale). Other notes keep their spelling if
+// every chord spells them the same way with at most one accidental;
+// otherwise, they are spelled with sharps in keys with sharps and with flats
+// in other keys. So a chart in E major will not have both D♭ and C♯ chords:
+// they will all be C♯.
+func (p *Progression) TransposeToKey(key Key) *Progression {
+	return p.transpose(intervalToKey(InferKey(p.Chords()...), key), key)
+}
+
+// intervalToKey returns the interval by which music in the from key is
+// transposed to move it into the to key. If one key is major and the other is
+// minor, the interval moves the from key to the relative major or minor of the
+// to key.
+func intervalToKey(from, to Key) Interval {
+	target := to
+	switch {
+	case from.Minor && !to.Minor:
+		target = Key{Tonic: to.Tonic.Transpose(Interval{Val: 6}), Minor: true}
+	case !from.Minor && to.Minor:
+		target = Key{Tonic: to.Tonic.Transpose(Interval{Val: 3, Offset: -1})}
+	}
+	return from.Tonic.IntervalTo(target.Tonic)
+}
+
+// transpose transposes all chords by the given interval and then respells
+// them for the given key.
+func (p *Progression) transpose(intv Interval, key Key) *Progression {
+	ret := &Progression{BeatsPerBar: p.BeatsPerBar, Bars: make([]Bar, len(p.Bars))}
+	// spellings tracks how each pitch class is spelled in chord roots and bass
+	// notes; a zero Note means the pitch class has more than one spelling
+	spellings := map[int8]Note{}
+	addSpelling := func(n Note) {
+		c := n.Cardinal()
+		if prev, ok := spellings[c]; ok && prev != n {
+			spellings[c] = Note{}
+		} else if !ok {
+			spellings[c] = n
+		}
+	}
+	for i, bar := range p.Bars {
+		ret.Bars[i] = bar
+		ret.Bars[i].Chords = make([]BarChord, len(bar.Chords))
+		for j, bc := range bar.Chords {
+			ch := bc.Chord.Transpose(intv)
+			addSpelling(ch.Root)
+			if ch.Bass.N != 0 {
+				addSpelling(ch.Bass)
+			}
+			ret.Bars[i].Chords[j] = BarChord{Chord: ch, Beats: bc.Beats}
+		}
+	}
+
+	// choose one spelling for each pitch class
+	keyNotes := key.Scale().Spell()
+	if key.Minor {
+		keyNotes = append(keyNotes, key.Tonic.Transpose(Interval{Val: 7}))
+	}
+	prefer := PreferFlats
+	if key.KeySignature() > 0 {
+		prefer = PreferSharps
+	}
+	for c, n := range spellings {
+		if n.N == 0 || n.Acc == DblFlat || n.Acc == DblSharp {
+			spellings[c] = spellPitchClass(c, prefer)
+		}
+	}
+	for _, n := range keyNotes {
+		if _, ok := spellings[n.Cardinal()]; ok {
+			spellings[n.Cardinal()] = n
+		}
+	}
+
+	for _, bar := range ret.Bars {
+		for j, bc := range bar.Chords {
+			ch := bc.Chord
+			if root := spellings[ch.Root.Cardinal()]; root != ch.Root {
+				ch = ch.Transpose(ch.Root.IntervalTo(root))
+			}
+			if ch.Bass.N != 0 {
+				ch.Bass = spellings[ch.Bass.Cardinal()]
+			}
+			bar.Chords[j].Chord = ch
+		}
+	}
+	return ret
+}
@@ -0,0 +1,120 @@
+package chords
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestParseProgression(t *testing.T) {
+	cases := []struct {
+		input string
+		beats string
+		str   string
+	}{
+		{"| Cmaj7 | A-7 D7 |", "4 | 2 2", "| C△7 | A-7 D7 |"},
+		{"|Cmaj7|A-7 D7|", "4 | 2 2", "| C△7 | A-7 D7 |"},
+		{"| C / / G | F |", "3 1 | 4", "| C / / G | F |"},
+		{"| C D E |", "2 1 1", "| C / D E |"},
+		{"3/4 | C | G7 / C |", "3 | 2 1", "3/4 | C | G7 / C |"},
+		{"|: C | G7 :| F | % |", "4 | 4 | 4 | 4", "|: C | G7 :| F | F |"},
+		{"| C | D- :| x3 E- | F :|: G |", "4 | 4 | 4 | 4 | 4", "| C | D- :| x3 E- | F :|: G |"},
+		{"| Bb7 || F#m7b5 B7 ||", "4 | 2 2", "| B♭7 | F♯ø B7 |"},
+		{"C/E F G", "2 1 1", "| C/E / F G |"},
+		{"", "", ""},
+	}
+	for _, tc := range cases {
+		p, err := ParseProgression(tc.input)
+		if err != nil {
+			t.Errorf("failed to parse %q: %v", tc.input, err)
+			continue
+		}
+		var beats string
+		for i, bar := range p.Bars {
+			if i > 0 {
+				beats += " | "
+			}
+			for j, bc := range bar.Chords {
+				if j > 0 {
+					beats += " "
+				}
+				beats += strconv.Itoa(bc.Beats)
+			}
+		}
+		if beats != tc.beats {
+			t.Errorf("ParseProgression for %q returned wrong value: %q != %q", tc.input, beats, tc.beats)
+		}
+		if actual := p.String(); actual != tc.str {
+			t.Errorf("ParseProgression for %q returned wrong value: %q != %q", tc.input, actual, tc.str)
+		}
+		if again := MustParseProgression(p.String()).String(); again != p.String() {
+			t.Errorf("%q: round trip changed progression: %q", tc.input, again)
+		}
+	}
+
+	p := MustParseProgression("| C | D- :| x3 E- | F :|: G |")
+	var repeats []string
+	for _, bar := range p.Bars {
+		repeats = append(repeats, fmt.Sprintf("%v/%d", bar.RepeatStart, bar.RepeatCount))
+	}
+	if actual := strings.Join(repeats, " "); actual != "false/0 false/3 false/0 false/2 true/0" {
+		t.Errorf("wrong repeats: %s", actual)
+	}
+
+	for _, s := range []string{"| C D E F G |", "| / C |", "| % |", "| Cfoo |", "0/4 | C |", ":| C |"} {
+		if _, err := ParseProgression(s); err == nil {
+			t.Errorf("ParseProgression(%q) should have failed", s)
+		}
+	}
+}
+
+func TestProgression_Transpose(t *testing.T) {
+	cases := []struct {
+		prog string
+		intv Interval
+		exp  string
+	}{
+		{"| C△7 | A-7 D7 | D-7 G7 | C△7 |", Interval{Val: 2}, "| D△7 | B-7 E7 | E-7 A7 | D△7 |"},
+		// C♯ would have seven sharps, so D♭ is used instead
+		{"| C | F | G7 | C |", Interval{Val: 1, Offset: 1}, "| D♭ | G♭ | A♭7 | D♭ |"},
+		{"| C | E♭ | D-7 G7 | C/E |", Interval{Val: 3}, "| E | G | F♯-7 B7 | E/G♯ |"},
+		// mixed spellings are made consistent
+		{"| C | D♭7 | C♯-7 | C |", Interval{Val: 1}, "| C | D♭7 | D♭-7 | C |"},
+		{"| D | A♭7 | G♯-7 | D |", Interval{Val: 1}, "| D | G♯7 | G♯-7 | D |"},
+	}
+	for _, tc := range cases {
+		actual := MustParseProgression(tc.prog).Transpose(tc.intv).String()
+		if actual != tc.exp {
+			t.Errorf("Progression.Transpose for %s + %v returned wrong value: %q != %q", tc.prog, tc.intv, actual, tc.exp)
+		}
+	}
+
+	p := MustParseProgression("| A- | D- | E7 | A- |")
+	if actual := p.TransposeToKey(MustParseKey("Cm")).String(); actual != "| C- | F- | G7 | C- |" {
+		t.Errorf("wrong transposition to C minor: %s", actual)
+	}
+	if actual := p.TransposeToKey(MustParseKey("E♭")).String(); actual != "| C- | F- | G7 | C- |" {
+		t.Errorf("wrong transposition to relative minor of E♭: %s", actual)
+	}
+	p = MustParseProgression("| C | C♯o | D-7 | G7 | C |")
+	if actual := p.TransposeToKey(MustParseKey("F")).String(); actual != "| F | F♯o | G-7 | C7 | F |" {
+		t.Errorf("wrong transposition to F: %s", actual)
+	}
+}
+
+func TestProgression_Clone(t *testing.T) {
+	prog := MustParseProgression("3/4 | C△7 | A-7 / D7 | G7 |")
+	orig := prog.String()
+	clone := prog.Clone()
+	if clone.String() != orig {
+		t.Errorf("Progression.Clone returned wrong value: %s != %s", clone, orig)
+	}
+	clone.Bars[0].Chords[0].Chord.ExtraTones[0] = ChordTone{Val: 6}
+	clone.Bars[1].Chords[1].Beats = 1
+	clone.Bars[2].RepeatCount = 2
+	clone.Bars = append(clone.Bars[:1], clone.Bars[2:]...)
+	if prog.String() != orig {
+		t.Errorf("Progression.Clone shares state with the original progression: %s", prog)
+	}
+}
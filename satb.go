@@ -0,0 +1,404 @@
+package chords
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Voice is one of the four voices of four-part (SATB) harmony.
+type Voice int
+
+const (
+	Soprano Voice = iota
+	Alto
+	Tenor
+	Bass
+)
+
+// String implements the Stringer interface.
+func (v Voice) String() string {
+	switch v {
+	case Soprano:
+		return "soprano"
+	case Alto:
+		return "alto"
+	case Tenor:
+		return "tenor"
+	case Bass:
+		return "bass"
+	default:
+		return fmt.Sprintf("?(%d)", v)
+	}
+}
+
+// SATBRanges are the standard ranges of each voice, indexed by Voice.
+var SATBRanges = [4]PitchRange{
+	Soprano: {Low: Pitch{Note: Note{N: C}, Octave: 4}, High: Pitch{Note: Note{N: G}, Octave: 5}},
+	Alto:    {Low: Pitch{Note: Note{N: G}, Octave: 3}, High: Pitch{Note: Note{N: D}, Octave: 5}},
+	Tenor:   {Low: Pitch{Note: Note{N: C}, Octave: 3}, High: Pitch{Note: Note{N: G}, Octave: 4}},
+	Bass:    {Low: Pitch{Note: Note{N: E}, Octave: 2}, High: Pitch{Note: Note{N: C}, Octave: 4}},
+}
+
+// SATBVoicing is a chord realized in four voices. It is indexed by Voice, so
+// the soprano's pitch is first and the bass's pitch is last.
+type SATBVoicing [4]Pitch
+
+// String implements the Stringer interface. The result is the pitch of each
+// voice, from soprano to bass, like "S:C5 A:G4 T:E4 B:C3".
+func (v SATBVoicing) String() string {
+	return fmt.Sprintf("S:%v A:%v T:%v B:%v", v[Soprano], v[Alto], v[Tenor], v[Bass])
+}
+
+// Voicing returns the pitches of the voices from lowest to highest (if the
+// voices do not cross).
+func (v SATBVoicing) Voicing() Voicing {
+	return Voicing{v[Bass], v[Tenor], v[Alto], v[Soprano]}
+}
+
+// PartWritingRule is a rule of classical four-part writing. (See
+// CheckPartWriting.)
+type PartWritingRule int
+
+const (
+	// OutOfRange means that a voice is outside of its range (see SATBRanges).
+	OutOfRange PartWritingRule = iota + 1
+	// VoiceCrossing means that a voice is lower than the voice below it.
+	VoiceCrossing
+	// Spacing means that adjacent upper voices (soprano and alto, or alto
+	// and tenor) are more than an octave apart.
+	Spacing
+	// ParallelFifths means that two voices a perfect fifth apart (or a
+	// compound fifth) both move to other pitches that are a perfect fifth
+	// apart.
+	ParallelFifths
+	// ParallelOctaves means that two voices an octave apart (or a unison or
+	// compound octave) both move to other pitches that are an octave apart.
+	ParallelOctaves
+)
+
+// String implements the Stringer interface.
+func (r PartWritingRule) String() string {
+	switch r {
+	case OutOfRange:
+		return "out of range"
+	case VoiceCrossing:
+		return "voice crossing"
+	case Spacing:
+		return "spacing"
+	case ParallelFifths:
+		return "parallel fifths"
+	case ParallelOctaves:
+		return "parallel octaves"
+	default:
+		return fmt.Sprintf("?(%d)", r)
+	}
+}
+
+// PartWritingViolation is a violation of a part-writing rule. (See
+// CheckPartWriting.)
+type PartWritingViolation struct {
+	// Rule is the rule that is violated.
+	Rule PartWritingRule
+	// Index is the index of the voicing in which the violation occurs. For
+	// parallel fifths and octaves, this is the second of the two voicings.
+	Index int
+	// Voices are the voices involved. For OutOfRange, both are the same.
+	// Otherwise, the upper voice is first.
+	Voices [2]Voice
+}
+
+// String implements the Stringer interface. The result describes the
+// violation, like "parallel fifths between soprano and bass @ 2".
+func (v PartWritingViolation) String() string {
+	if v.Voices[0] == v.Voices[1] {
+		return fmt.Sprintf("%v %v @ %d", v.Voices[0], v.Rule, v.Index)
+	}
+	return fmt.Sprintf("%v between %v and %v @ %d", v.Rule, v.Voices[0], v.Voices[1], v.Index)
+}
+
+// CheckPartWriting checks the given sequence of voicings against the rules of
+// classical part writing and returns the violations, ordered by the index of
+// the voicing in which they occur. The rules are:
+//
+//   - Each voice must be in its range (see SATBRanges).
+//   - Voices must not cross: each voice must be at or above the voice below
+//     it.
+//   - Adjacent upper voices must be within an octave of each other. The tenor
+//     and bass may be further apart.
+//   - Two voices must not move in parallel perfect fifths or octaves, where
+//     both voices move and they are a perfect fifth (or an octave) apart both
+//     before and after the move. Fifths and octaves approached by contrary
+//     motion (like an octave moving to a unison) are also reported.
+func CheckPartWriting(vs []SATBVoicing) []PartWritingViolation {
+	var violations []PartWritingViolation
+	for i, v := range vs {
+		for voice := Soprano; voice <= Bass; voice++ {
+			if !SATBRanges[voice].Contains(v[voice]) {
+				violations = append(violations, PartWritingViolation{Rule: OutOfRange, Index: i, Voices: [2]Voice{voice, voice}})
+			}
+		}
+		for voice := Soprano; voice < Bass; voice++ {
+			if v[voice].MIDINumber() < v[voice+1].MIDINumber() {
+				violations = append(violations, PartWritingViolation{Rule: VoiceCrossing, Index: i, Voices: [2]Voice{voice, voice + 1}})
+			}
+		}
+		for voice := Soprano; voice < Tenor; voice++ {
+			if v[voice].MIDINumber()-v[voice+1].MIDINumber() > 12 {
+				violations = append(violations, PartWritingViolation{Rule: Spacing, Index: i, Voices: [2]Voice{voice, voice + 1}})
+			}
+		}
+		if i == 0 {
+			continue
+		}
+		prev := vs[i-1]
+		for upper := Soprano; upper < Bass; upper++ {
+			for lower := upper + 1; lower <= Bass; lower++ {
+				if rule := parallelRule(prev[upper], prev[lower], v[upper], v[lower]); rule != 0 {
+					violations = append(violations, PartWritingViolation{Rule: rule, Index: i, Voices: [2]Voice{upper, lower}})
+				}
+			}
+		}
+	}
+	return violations
+}
+
+// parallelRule returns ParallelFifths or ParallelOctaves if two voices, which
+// move from pitches a1 and b1 to a2 and b2, move in parallel fifths or octaves.
+// Otherwise, it returns zero.
+func parallelRule(a1, b1, a2, b2 Pitch) PartWritingRule {
+	if a1.MIDINumber() == a2.MIDINumber() || b1.MIDINumber() == b2.MIDINumber() {
+		// a voice does not move
+		return 0
+	}
+	before := posMod(int8((a1.MIDINumber()-b1.MIDINumber())%12), 12)
+	after := posMod(int8((a2.MIDINumber()-b2.MIDINumber())%12), 12)
+	switch {
+	case before == 7 && after == 7:
+		return ParallelFifths
+	case before == 0 && after == 0:
+		return ParallelOctaves
+	default:
+		return 0
+	}
+}
+
+// RealizeSATB realizes the given chords in four voices, following the rules
+// of classical part writing (see CheckPartWriting) where possible. The bass
+// voice has the chord's bass note, or its root if it has no bass note. The
+// upper voices include the rest of the chord's tones.
+//
+// Triads double one tone, preferring the root, then the fifth, then the
+// third. Chords with four tones have each tone once, except that the fifth
+// may be omitted (and another tone doubled instead). For chords with more than four
+// tones, the fifth is omitted and only the root, third, seventh, and highest
+// extension are used.
+//
+// Among the voicings that follow the rules, the ones chosen are those with
+// the smoothest voice leading: the least total motion of the voices from
+// chord to chord. If there is no voicing of a chord that follows the rules,
+// or if the rules can only be followed by leaving out a required tone, this
+// returns an error.
+//
+// To realize a roman numeral progression, convert it to chords with
+// ScaleChord.InKey.
+func RealizeSATB(chs ...*Chord) ([]SATBVoicing, error) {
+	if len(chs) == 0 {
+		return nil, nil
+	}
+	candidates := make([][]satbCandidate, len(chs))
+	for i, ch := range chs {
+		candidates[i] = satbCandidates(ch)
+		if len(candidates[i]) == 0 {
+			return nil, fmt.Errorf("chord %d (%v) cannot be realized in four voices", i, ch)
+		}
+	}
+
+	// Viterbi: costs[j] is the least total cost of realizing the chords so
+	// far where the current chord uses candidate j; from[i][j] is the
+	// candidate of the previous chord on that path.
+	const infinite = int(^uint(0) >> 1)
+	costs := make([]int, len(candidates[0]))
+	for j, c := range candidates[0] {
+		costs[j] = c.cost
+	}
+	from := make([][]int, len(chs))
+	for i := 1; i < len(chs); i++ {
+		next := make([]int, len(candidates[i]))
+		from[i] = make([]int, len(candidates[i]))
+		for j, c := range candidates[i] {
+			next[j] = infinite
+			for k, p := range candidates[i-1] {
+				if costs[k] == infinite || hasParallels(p.v, c.v) {
+					continue
+				}
+				cost := costs[k] + c.cost + motion(p.v, c.v)
+				if cost < next[j] {
+					next[j], from[i][j] = cost, k
+				}
+			}
+		}
+		costs = next
+	}
+
+	best := -1
+	for j, cost := range costs {
+		if cost != infinite && (best < 0 || cost < costs[best]) {
+			best = j
+		}
+	}
+	if best < 0 {
+		return nil, errors.New("chords cannot be realized without parallel fifths or octaves")
+	}
+	vs := make([]SATBVoicing, len(chs))
+	for i := len(chs) - 1; i >= 0; i-- {
+		vs[i] = candidates[i][best].v
+		if i > 0 {
+			best = from[i][best]
+		}
+	}
+	return vs, nil
+}
+
+// satbCandidate is a possible voicing of a chord, along with the cost of its
+// doubling and unisons (lower is better).
+type satbCandidate struct {
+	v    SATBVoicing
+	cost int
+}
+
+// satbCandidates returns all voicings of the given chord that follow the
+// rules of part writing for a single chord: range, voice crossing, and
+// spacing.
+func satbCandidates(ch *Chord) []satbCandidate {
+	upper := ch.Clone()
+	upper.Bass = Note{}
+	tones := upper.Spell()
+	if len(tones) > 4 {
+		// omit the fifth, and keep the root, third, seventh, and highest
+		// extension
+		tones = append(tones[:2:2], tones[3:]...)
+		tones = append(tones[:3:3], tones[len(tones)-1])
+	}
+	bassNote := ch.Root
+	if ch.Bass.N != 0 {
+		bassNote = ch.Bass
+	}
+
+	pitchesOf := func(voice Voice) []Pitch {
+		var ps []Pitch
+		r := SATBRanges[voice]
+		for _, n := range tones {
+			for o := r.Low.Octave - 1; o <= r.High.Octave+1; o++ {
+				p := Pitch{Note: n, Octave: o}
+				if r.Contains(p) {
+					ps = append(ps, p)
+				}
+			}
+		}
+		return ps
+	}
+	var basses []Pitch
+	for o := SATBRanges[Bass].Low.Octave - 1; o <= SATBRanges[Bass].High.Octave+1; o++ {
+		if p := (Pitch{Note: bassNote, Octave: o}); SATBRanges[Bass].Contains(p) {
+			basses = append(basses, p)
+		}
+	}
+	tenors, altos, sopranos := pitchesOf(Tenor), pitchesOf(Alto), pitchesOf(Soprano)
+
+	var candidates []satbCandidate
+	for _, b := range basses {
+		for _, t := range tenors {
+			if t.MIDINumber() < b.MIDINumber() {
+				continue
+			}
+			for _, a := range altos {
+				if a.MIDINumber() < t.MIDINumber() || a.MIDINumber()-t.MIDINumber() > 12 {
+					continue
+				}
+				for _, s := range sopranos {
+					if s.MIDINumber() < a.MIDINumber() || s.MIDINumber()-a.MIDINumber() > 12 {
+						continue
+					}
+					v := SATBVoicing{s, a, t, b}
+					cost, ok := doublingCost(v, tones)
+					if !ok {
+						continue
+					}
+					for voice := Soprano; voice < Bass; voice++ {
+						if v[voice].MIDINumber() == v[voice+1].MIDINumber() {
+							// unisons are allowed but weaken the texture
+							cost++
+						}
+					}
+					candidates = append(candidates, satbCandidate{v: v, cost: cost})
+				}
+			}
+		}
+	}
+	return candidates
+}
+
+// doublingCost returns the cost of the doubling in the given voicing of a
+// chord with the given tones (where the first tone is the root, the second is
+// the third, and the third is the fifth). Doubling the root is free, and
+// doubling the third costs the most. It returns false if a required tone is
+// missing.
+func doublingCost(v SATBVoicing, tones []Note) (int, bool) {
+	counts := make([]int, len(tones))
+	for _, p := range v {
+		for i, n := range tones {
+			if p.Note.Cardinal() == n.Cardinal() {
+				counts[i]++
+				break
+			}
+		}
+	}
+	cost := 0
+	for i, count := range counts {
+		switch {
+		case count > 0:
+			continue
+		case i == 2 && len(tones) == 4:
+			// a four-tone chord may omit the fifth
+			cost += 2
+		default:
+			return 0, false
+		}
+	}
+	if len(tones) == 3 {
+		switch {
+		case counts[1] > 1:
+			cost += 3
+		case counts[2] > 1:
+			cost++
+		}
+	}
+	return cost, true
+}
+
+// hasParallels returns true if moving from voicing a to voicing b results in
+// parallel fifths or octaves.
+func hasParallels(a, b SATBVoicing) bool {
+	for upper := Soprano; upper < Bass; upper++ {
+		for lower := upper + 1; lower <= Bass; lower++ {
+			if parallelRule(a[upper], a[lower], b[upper], b[lower]) != 0 {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// motion returns the total number of half-steps that the voices move from
+// voicing a to voicing b.
+func motion(a, b SATBVoicing) int {
+	total := 0
+	for voice := range a {
+		d := a[voice].MIDINumber() - b[voice].MIDINumber()
+		if d < 0 {
+			d = -d
+		}
+		total += d
+	}
+	return total
+}
@@ -0,0 +1,81 @@
+package chords
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestRealizeSATB(t *testing.T) {
+	chs := parseChords("C F G7 C A- D-7 G/B C")
+	vs, err := RealizeSATB(chs...)
+	if err != nil {
+		t.Fatalf("failed to realize: %v", err)
+	}
+	if len(vs) != len(chs) {
+		t.Fatalf("RealizeSATB returned wrong number of voicings: %d != %d", len(vs), len(chs))
+	}
+	if violations := CheckPartWriting(vs); len(violations) > 0 {
+		t.Errorf("realization has violations: %v", violations)
+	}
+	for i, v := range vs {
+		ch := chs[i]
+		bass := ch.Root
+		if ch.Bass.N != 0 {
+			bass = ch.Bass
+		}
+		if v[Bass].Note != bass {
+			t.Errorf("RealizeSATB for %v returned wrong value: %v != %v", ch, v, bass)
+		}
+		tones := map[int8]bool{}
+		for _, n := range ch.Spell() {
+			tones[n.Cardinal()] = true
+		}
+		for _, p := range v {
+			if !tones[p.Note.Cardinal()] {
+				t.Errorf("%v: %v is not a chord tone in %v", ch, p, v)
+			}
+		}
+	}
+	exp := "S:G5 A:G4 T:D4 B:B2"
+	if actual := vs[len(vs)-2].String(); actual != exp {
+		t.Errorf("RealizeSATB returned wrong value: %q != %q", actual, exp)
+	}
+
+	vs, err = RealizeSATB(MustParseChord("C"))
+	if err != nil || len(vs) != 1 || vs[0].Voicing().String() != "C3 C4 E4 G4" {
+		t.Errorf("wrong realization of C: %v, %v", vs, err)
+	}
+}
+
+func TestCheckPartWriting(t *testing.T) {
+	voicing := func(s string) SATBVoicing {
+		var v SATBVoicing
+		for i, f := range strings.Fields(s) {
+			v[i] = MustParsePitch(f)
+		}
+		return v
+	}
+	vs := []SATBVoicing{
+		voicing("G4 E4 C4 C3"),
+		voicing("A4 F4 D4 D3"),
+		voicing("A5 F4 D4 D3"),
+		voicing("C4 E4 G3 C2"),
+	}
+	var strs []string
+	for _, v := range CheckPartWriting(vs) {
+		strs = append(strs, v.String())
+	}
+	exp := []string{
+		"parallel fifths between soprano and tenor @ 1",
+		"parallel fifths between soprano and bass @ 1",
+		"parallel octaves between tenor and bass @ 1",
+		"soprano out of range @ 2",
+		"spacing between soprano and alto @ 2",
+		"bass out of range @ 3",
+		"voice crossing between soprano and alto @ 3",
+	}
+	if actual, expected := fmt.Sprint(strs), fmt.Sprint(exp); actual != expected {
+		t.Errorf("CheckPartWriting returned wrong value: %s != %s", actual, expected)
+	}
+}
@@ -1,8 +1,11 @@
 package chords
 
 import (
+	"errors"
 	"fmt"
+	"math/bits"
 	"sort"
+	"strings"
 )
 
 // ScaleType represents a sequence of notes relative to a scale root.
@@ -134,6 +137,228 @@ func (t ScaleType) NthMode(n int8) ScaleType {
 	return intvs
 }
 
+// ScaleChords harmonizes the scale type: it returns a chord for each degree of
+// the scale, built by stacking thirds from the notes of the scale. The given
+// depth is the number of notes in each chord: 3 for triads, 4 for seventh
+// chords, 5 for ninth chords, and so on, up to 7 for thirteenth chords. Depths
+// outside that range are clamped to it. So the chords of a major scale with a
+// depth of 4 are Imaj7, ii-7, iii-7, IVmaj7, V7, vi-7, and viiø.
+//
+// Stacking thirds only makes sense for heptatonic scales, so this returns nil
+// if the scale type (after it is cleaned) does not have exactly one interval
+// for each Val, 1 through 7. Odd scales can produce "thirds" that are really
+// seconds or fourths (like the diminished third from the ♯4 to the ♭6 of a
+// Hungarian minor scale); these are treated as suspensions.
+func (t ScaleType) ScaleChords(depth int) []*ScaleChord {
+	t = t.Clean()
+	if !t.isHeptatonic() {
+		return nil
+	}
+	if depth < 3 {
+		depth = 3
+	} else if depth > 7 {
+		depth = 7
+	}
+	minor := t.hasMinorThird()
+	chs := make([]*ScaleChord, len(t))
+	for i, root := range t {
+		rootNote := Note{N: C}.Transpose(root)
+		stack := make([]Interval, depth-1)
+		for j := range stack {
+			stack[j] = rootNote.IntervalTo(Note{N: C}.Transpose(t[(i+2*(j+1))%7]))
+		}
+		chs[i] = &ScaleChord{Root: root, InMinorKey: minor, Type: *chordFromStack(stack).ChordType()}
+	}
+	return chs
+}
+
+// DiatonicChordOptions controls which chords are returned by
+// ScaleType.DiatonicChords.
+type DiatonicChordOptions struct {
+	// Size is the number of notes in each chord: 3 for triads, 4 for seventh
+	// chords, and so on. If zero, triads are returned. As with the depth
+	// given to ScaleType.ScaleChords, sizes outside of 3 through 7 are
+	// clamped to that range.
+	Size int
+	// Degrees, if not empty, restricts the results to chords whose roots are
+	// the given scale degrees. The first note of the scale is degree 1. The
+	// results are always in scale order, regardless of the order of Degrees.
+	Degrees []int
+	// Stacking is the distance, in scale degrees, between the notes of each
+	// chord. If zero or 2, chords are built by stacking thirds. A value of 3
+	// stacks fourths (quartal harmony), 4 stacks fifths (quintal harmony),
+	// and so on. The distance wraps around the scale, so 9 is the same as 2,
+	// and -2 (stacking thirds downward) is the same as 5.
+	Stacking int
+}
+
+// DiatonicChords returns chords built from the notes of the scale type,
+// according to the given options. With zero options, this returns the same
+// triads as ScaleChords(3).
+//
+// Since a Chord always has a 5th, chords built by stacking intervals other
+// than thirds will include a perfect 5th even if the stacked notes do not.
+// Stacked notes that cannot be represented as a chord (e.g. if there is no
+// 3rd and no note that can be its suspension) are omitted from the results.
+// Like ScaleChords, this returns nil for scale types that are not heptatonic.
+func (t ScaleType) DiatonicChords(opts DiatonicChordOptions) []*ScaleChord {
+	size, stacking := opts.Size, opts.Stacking
+	if size < 3 {
+		size = 3
+	} else if size > 7 {
+		size = 7
+	}
+	if stacking == 0 {
+		stacking = 2
+	}
+	stacking = posModInt(stacking, 7)
+	t = t.Clean()
+	if !t.isHeptatonic() {
+		return nil
+	}
+	var all []*ScaleChord
+	if stacking == 2 {
+		all = t.ScaleChords(size)
+	} else {
+		all = make([]*ScaleChord, len(t))
+		for i, root := range t {
+			var set uint16
+			for j := 0; j < size; j++ {
+				set |= 1 << uint(posMod(t[(i+stacking*j)%7].NumHalfSteps()-root.NumHalfSteps(), 12))
+			}
+			ch := chordFromPitchClasses(Note{N: C}.Transpose(root), set)
+			if ch != nil {
+				all[i] = &ScaleChord{Root: root, InMinorKey: t.hasMinorThird(), Type: *ch.ChordType()}
+			}
+		}
+	}
+	var chs []*ScaleChord
+	for i, sc := range all {
+		if sc == nil {
+			continue
+		}
+		if len(opts.Degrees) > 0 {
+			found := false
+			for _, d := range opts.Degrees {
+				if d == i+1 {
+					found = true
+					break
+				}
+			}
+			if !found {
+				continue
+			}
+		}
+		chs = append(chs, sc)
+	}
+	return chs
+}
+
+// hasMinorThird returns true if the scale type includes a minor third.
+func (t ScaleType) hasMinorThird() bool {
+	for _, intv := range t {
+		if intv == (Interval{Val: 3, Offset: -1}) {
+			return true
+		}
+	}
+	return false
+}
+
+// isHeptatonic returns true if the scale type has exactly seven intervals,
+// one for each Val 1 through 7, in order.
+func (t ScaleType) isHeptatonic() bool {
+	if len(t) != 7 {
+		return false
+	}
+	for i, intv := range t {
+		if intv.Val != int8(i+1) {
+			return false
+		}
+	}
+	return true
+}
+
+// chordFromStack returns a canonical chord, with a root of C, whose tones are
+// the given stack of thirds above the root: the 3rd, 5th, 7th, 9th, 11th, and
+// 13th, in that order. The stack may be shorter, but it must include at least
+// the 3rd and 5th.
+func chordFromStack(stack []Interval) *Chord {
+	ch := &Chord{Root: Note{N: C}}
+	switch third := stack[0]; {
+	case third.Offset == 0:
+		ch.Triad = Maj3
+	case third.Offset == -1:
+		ch.Triad = Min3
+	case third.Offset < -1:
+		ch.Triad = Sus
+		ch.ExtraTones = append(ch.ExtraTones, ChordTone{Val: 2, Acc: Accidental(third.Offset + 2)})
+	default:
+		ch.Triad = Sus
+		ch.ExtraTones = append(ch.ExtraTones, ChordTone{Val: 4, Acc: Accidental(third.Offset - 1)})
+	}
+	if fifth := stack[1]; fifth.Offset != 0 {
+		ch.ExtraTones = append(ch.ExtraTones, ChordTone{Val: 5, Acc: Accidental(fifth.Offset)})
+	}
+	for i, intv := range stack[2:] {
+		tone := ChordTone{Val: int8(7 + 2*i), Acc: Accidental(intv.Offset)}
+		if tone.Val == 7 {
+			// a chord's 7 tone is a minor 7th
+			tone.Acc++
+		}
+		ch.ExtraTones = append(ch.ExtraTones, tone)
+	}
+	ch.Canonicalize()
+	return ch
+}
+
+// Equal returns true if this scale type has the same intervals as the given
+// scale type, after both are cleaned. Intervals must be spelled the same: a
+// scale with a ♯4 is not equal to an otherwise identical scale with a ♭5.
+func (t ScaleType) Equal(other ScaleType) bool {
+	t, other = t.Clean(), other.Clean()
+	if len(t) != len(other) {
+		return false
+	}
+	for i := range t {
+		if t[i] != other[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// IsModeOf determines if this scale type is a mode of the given scale type:
+// if it has the same notes as the other, but starting from a different root.
+// If so, it returns n such that other.NthMode(n) has the same notes as this
+// scale type. For example, DorianMode.IsModeOf(MajorScale) returns 2. Every
+// scale type is a mode of itself, with n equal to 1.
+//
+// Unlike Equal, this compares the number of half-steps in each interval, not
+// how they are spelled.
+func (t ScaleType) IsModeOf(other ScaleType) (n int, ok bool) {
+	t, other = t.Clean(), other.Clean()
+	if len(t) != len(other) {
+		return 0, false
+	}
+	set, otherSet := t.pitchClasses(), other.pitchClasses()
+	for i, intv := range other {
+		if rotatePitchClasses(otherSet, -int(intv.NumHalfSteps())) == set {
+			return i + 1, true
+		}
+	}
+	return 0, false
+}
+
+// pitchClasses returns the set of pitch classes in the scale type, relative
+// to a root with cardinality zero. (See pitchClasses.)
+func (t ScaleType) pitchClasses() uint16 {
+	var set uint16
+	for _, intv := range t {
+		set |= 1 << uint(intv.NumHalfSteps())
+	}
+	return set
+}
+
 // HeptatonicScaleType is a factory function for creating heptatonic scale
 // types from 7 integer offsets. Offsets of zero map to the major scale. So
 // if the value in the 3rd element (index 2) is -1, the scale type will have
@@ -169,6 +394,26 @@ var (
 	HungarianMinorScale      = HeptatonicScaleType([7]int8{0, 0, -1, 1, 0, -1, 0})
 	DoubleHarmonicMinorScale = HungarianMinorScale
 
+	// Modes of melodic minor
+
+	DorianFlat2Mode      = MelodicMinorScale.NthMode(2)
+	LydianAugmentedMode  = MelodicMinorScale.NthMode(3)
+	LydianDominantMode   = MelodicMinorScale.NthMode(4)
+	MixolydianFlat6Mode  = MelodicMinorScale.NthMode(5)
+	LocrianNatural2Mode  = MelodicMinorScale.NthMode(6)
+	AlteredScale         = MelodicMinorScale.NthMode(7)
+	SuperLocrianScale    = AlteredScale
+	PhrygianNatural6Mode = DorianFlat2Mode
+
+	// Modes of harmonic minor
+
+	LocrianNatural6Mode    = HarmonicMinorScale.NthMode(2)
+	IonianSharp5Mode       = HarmonicMinorScale.NthMode(3)
+	DorianSharp4Mode       = HarmonicMinorScale.NthMode(4)
+	PhrygianDominantMode   = HarmonicMinorScale.NthMode(5)
+	LydianSharp2Mode       = HarmonicMinorScale.NthMode(6)
+	AlteredDiminishedScale = HarmonicMinorScale.NthMode(7)
+
 	// Non-heptatonic scales
 
 	HalfWholeScale = ScaleType{
@@ -209,6 +454,231 @@ var (
 	}
 )
 
+// scaleTypeNames is the registry of named scale types. The first name for each
+// is its canonical name; the rest are aliases. Names are normalized (see
+// normalizeScaleName) before being looked up.
+var scaleTypeNames = []struct {
+	names []string
+	typ   ScaleType
+}{
+	{[]string{"major", "ionian"}, MajorScale},
+	{[]string{"dorian"}, DorianMode},
+	{[]string{"phrygian"}, PhrygianMode},
+	{[]string{"lydian"}, LydianMode},
+	{[]string{"mixolydian", "dominant"}, MixolydianMode},
+	{[]string{"minor", "natural minor", "aeolian"}, MinorScale},
+	{[]string{"locrian"}, LocrianMode},
+	{[]string{"harmonic minor"}, HarmonicMinorScale},
+	{[]string{"melodic minor", "jazz minor"}, MelodicMinorScale},
+	{[]string{"hungarian minor", "double harmonic minor", "gypsy minor"}, HungarianMinorScale},
+	{[]string{"dorian ♭2", "phrygian ♮6"}, DorianFlat2Mode},
+	{[]string{"lydian augmented", "lydian ♯5"}, LydianAugmentedMode},
+	{[]string{"lydian dominant", "lydian ♭7", "overtone", "acoustic"}, LydianDominantMode},
+	{[]string{"mixolydian ♭6", "aeolian dominant", "melodic major"}, MixolydianFlat6Mode},
+	{[]string{"locrian ♮2", "half diminished", "aeolian ♭5"}, LocrianNatural2Mode},
+	{[]string{"altered", "super locrian", "diminished whole tone"}, AlteredScale},
+	{[]string{"locrian ♮6"}, LocrianNatural6Mode},
+	{[]string{"ionian ♯5", "ionian augmented"}, IonianSharp5Mode},
+	{[]string{"dorian ♯4", "ukrainian dorian", "romanian minor"}, DorianSharp4Mode},
+	{[]string{"phrygian dominant", "spanish phrygian", "freygish"}, PhrygianDominantMode},
+	{[]string{"lydian ♯2"}, LydianSharp2Mode},
+	{[]string{"altered diminished", "super locrian 𝄫7", "ultra locrian"}, AlteredDiminishedScale},
+	{[]string{"half whole", "half whole diminished", "octatonic", "dominant diminished"}, HalfWholeScale},
+	{[]string{"whole half", "diminished", "whole half diminished"}, WholeHalfScale},
+	{[]string{"whole tone"}, WholeToneScale},
+	{[]string{"major pentatonic", "pentatonic major", "pentatonic"}, PentatonicMajorScale},
+	{[]string{"minor pentatonic", "pentatonic minor"}, PentatonicMinorScale},
+	{[]string{"blues", "minor blues"}, BluesScale},
+	{[]string{"chromatic"}, ChromaticScale},
+}
+
+// ParseScaleType returns the scale type with the given name. Names are not
+// case-sensitive, words may be separated by spaces, hyphens, or underscores,
+// and a trailing "scale" or "mode" is ignored. So "Harmonic Minor",
+// "harmonic-minor", and "harmonic minor scale" are all the same. Accidentals
+// in names may be written with ASCII characters, the same as for notes, so
+// "lydian b7" is the same as "lydian ♭7". Many scale
+// types have aliases: "ionian" is the same as "major", and "aeolian" and
+// "natural minor" are the same as "minor".
+func ParseScaleType(s string) (ScaleType, error) {
+	name := normalizeScaleName(s)
+	if name == "" {
+		return nil, errors.New("cannot parse scale type from empty string")
+	}
+	for _, entry := range scaleTypeNames {
+		for _, n := range entry.names {
+			if normalizeScaleName(n) == name {
+				return entry.typ, nil
+			}
+		}
+	}
+	return nil, fmt.Errorf("unknown scale type: %q", s)
+}
+
+// MustParseScaleType parses the given string into a scale type and panics if
+// the string is not valid. (See ParseScaleType.)
+func MustParseScaleType(s string) ScaleType {
+	t, err := ParseScaleType(s)
+	if err != nil {
+		panic(err)
+	}
+	return t
+}
+
+// ScaleTypeName returns the canonical name of the given scale type, such as
+// "dorian" or "harmonic minor". If the scale type is not one of the named
+// types known to ParseScaleType, it returns false.
+func ScaleTypeName(t ScaleType) (string, bool) {
+	for _, entry := range scaleTypeNames {
+		if t.Equal(entry.typ) {
+			return entry.names[0], true
+		}
+	}
+	return "", false
+}
+
+var scaleNameReplacer = strings.NewReplacer("♭", "b", "♯", "#", "♮", "n", "𝄫", "bb", "𝄪", "x")
+
+func normalizeScaleName(s string) string {
+	s = scaleNameReplacer.Replace(strings.ToLower(s))
+	words := strings.FieldsFunc(s, func(r rune) bool {
+		return r == ' ' || r == '-' || r == '_' || r == '\t'
+	})
+	if len(words) > 1 && (words[len(words)-1] == "scale" || words[len(words)-1] == "mode") {
+		words = words[:len(words)-1]
+	}
+	return strings.Join(words, " ")
+}
+
+// ParseScale parses a scale from the given string, which is a root note
+// followed by the name of a scale type, like "D dorian" or "Bb harmonic
+// minor". The note uses the same syntax as accepted by ParseNote, and the
+// scale type name, which must be separated from the root by whitespace, is
+// parsed using ParseScaleType.
+func ParseScale(s string) (*Scale, error) {
+	s = strings.TrimSpace(s)
+	pos := strings.IndexAny(s, " \t")
+	if pos < 0 {
+		return nil, fmt.Errorf("scale %q is missing scale type", s)
+	}
+	root, err := ParseNote(s[:pos])
+	if err != nil {
+		return nil, err
+	}
+	t, err := ParseScaleType(s[pos+1:])
+	if err != nil {
+		return nil, err
+	}
+	return t.WithRoot(root), nil
+}
+
+// MustParseScale parses the given string into a scale and panics if the
+// string is not valid. (See ParseScale.)
+func MustParseScale(s string) *Scale {
+	sc, err := ParseScale(s)
+	if err != nil {
+		panic(err)
+	}
+	return sc
+}
+
+// DirectionalScaleType is a scale type whose notes differ depending on the
+// direction in which it is played. The classic example is the melodic minor
+// scale as taught in classical music: ascending, it has a major sixth and
+// seventh, but descending, it is the same as the natural minor scale.
+type DirectionalScaleType struct {
+	Ascending  ScaleType
+	Descending ScaleType
+}
+
+// ClassicalMelodicMinorScale is the melodic minor scale with different
+// ascending and descending forms. (MelodicMinorScale is the ascending form,
+// which jazz musicians use in both directions.)
+var ClassicalMelodicMinorScale = DirectionalScaleType{
+	Ascending:  MelodicMinorScale,
+	Descending: MinorScale,
+}
+
+// IsValid returns true if both the ascending and descending forms are valid.
+func (t DirectionalScaleType) IsValid() bool {
+	return t.Ascending.IsValid() && t.Descending.IsValid()
+}
+
+// WithRoot creates a directional scale with the given root and this scale
+// type.
+func (t DirectionalScaleType) WithRoot(root Note) *DirectionalScale {
+	return &DirectionalScale{Root: root, Type: t}
+}
+
+// DirectionalScale is a scale whose notes differ depending on the direction in
+// which it is played. It is described by a root note and a directional scale
+// type.
+type DirectionalScale struct {
+	Root Note
+	Type DirectionalScaleType
+}
+
+// IsValid returns true if the scale's Root and Type are valid.
+func (s *DirectionalScale) IsValid() bool {
+	return s.Root.IsValid() && s.Type.IsValid()
+}
+
+// Ascending returns the scale used when playing upwards.
+func (s *DirectionalScale) Ascending() *Scale {
+	return s.Type.Ascending.WithRoot(s.Root)
+}
+
+// Descending returns the scale used when playing downwards.
+func (s *DirectionalScale) Descending() *Scale {
+	return s.Type.Descending.WithRoot(s.Root)
+}
+
+// SpellAscending returns the notes of the scale, in ascending order from the
+// root.
+func (s *DirectionalScale) SpellAscending() []Note {
+	return s.Ascending().SpellFromRoot()
+}
+
+// SpellDescending returns the notes of the scale, in descending order from the
+// root. So the descending A classical melodic minor scale is A, G, F, E, D, C,
+// B.
+func (s *DirectionalScale) SpellDescending() []Note {
+	notes := s.Descending().SpellFromRoot()
+	for i, j := 1, len(notes)-1; i < j; i, j = i+1, j-1 {
+		notes[i], notes[j] = notes[j], notes[i]
+	}
+	return notes
+}
+
+// Run returns the pitches for playing the scale up the given number of
+// octaves from the given starting pitch and then back down, as in a scale
+// exercise. The ascending form is used on the way up and the descending form
+// on the way down. The root is not repeated at the top. So a one octave run
+// of A classical melodic minor starting at A4 is A4 B4 C5 D5 E5 F♯5 G♯5 A5 G5
+// F5 E5 D5 C5 B4 A4.
+//
+// The start pitch should be the root of the scale (in some octave). If it is
+// not, the run starts from the root in the start pitch's octave.
+func (s *DirectionalScale) Run(start Pitch, octaves int) []Pitch {
+	root := Pitch{Note: s.Root, Octave: start.Octave}
+	up := s.Type.Ascending.Clean()
+	down := s.Type.Descending.Clean()
+	var run []Pitch
+	for o := 0; o < octaves; o++ {
+		for _, intv := range up {
+			run = append(run, root.Transpose(Compound(intv, int8(o))))
+		}
+	}
+	top := root.Transpose(CompoundInterval{Val: int8(1 + 7*octaves)})
+	run = append(run, top)
+	for o := octaves - 1; o >= 0; o-- {
+		for i := len(down) - 1; i >= 0; i-- {
+			run = append(run, root.Transpose(Compound(down[i], int8(o))))
+		}
+	}
+	return run
+}
+
 // Scale represents a scale, which is a set of notes. It is described by
 // a root note and a scale type.
 type Scale struct {
@@ -226,11 +696,401 @@ func (s *Scale) Clean() {
 	s.Type = s.Type.Clean()
 }
 
-// Spell returns the notes in the scale.
+// Spell returns the notes in the scale. If the scale's key signature is a
+// theoretical one, with double-sharps or double-flats (see KeySignature), the
+// notes are spelled using the enharmonic scale instead (see Enharmonic). So
+// the notes of G♯ major are spelled A♭, B♭, C, D♭, E♭, F, G. To spell the
+// notes of G♯ major as G♯, A♯, B♯, C♯, D♯, E♯, F𝄪, use SpellFromRoot.
 func (s *Scale) Spell() []Note {
+	if ks := s.KeySignature(); ks > 7 || ks < -7 {
+		return s.Enharmonic().SpellFromRoot()
+	}
+	return s.SpellFromRoot()
+}
+
+// SpellFromRoot returns the notes in the scale, spelled by transposing the
+// scale's root by each of the scale's intervals.
+func (s *Scale) SpellFromRoot() []Note {
 	notes := make([]Note, len(s.Type))
 	for i, intv := range s.Type {
 		notes[i] = s.Root.Transpose(intv)
 	}
 	return notes
 }
+
+// scaleKeyParents are the scale types whose modes have key signatures that
+// are derived from the parent scale.
+var scaleKeyParents = []struct {
+	typ   ScaleType
+	minor bool
+}{
+	{MajorScale, false},
+	{HarmonicMinorScale, true},
+	{MelodicMinorScale, true},
+	{HungarianMinorScale, true},
+}
+
+// KeySignature returns the natural key signature for the scale. For modes of
+// the major scale, this is the key signature of the major scale from which the
+// mode is derived: so D dorian has the same key signature as C major. Modes of
+// the harmonic, melodic, and Hungarian minor scales similarly use the key
+// signature of the minor key from which they are derived, so E phrygian
+// dominant (a mode of A harmonic minor) has no sharps or flats. Other scales
+// use the minor key signature of their root if they have a minor third, and
+// the major key signature otherwise.
+func (s *Scale) KeySignature() KeySignature {
+	for _, parent := range scaleKeyParents {
+		if n, ok := s.Type.IsModeOf(parent.typ); ok {
+			intv := parent.typ.Clean()[n-1]
+			return Key{Tonic: s.Root.TransposeDown(intv), Minor: parent.minor}.KeySignature()
+		}
+	}
+	minor := false
+	for _, intv := range s.Type {
+		if intv == (Interval{Val: 3, Offset: -1}) {
+			minor = true
+		}
+		if intv == (Interval{Val: 3}) {
+			minor = false
+			break
+		}
+	}
+	return Key{Tonic: s.Root, Minor: minor}.KeySignature()
+}
+
+// Enharmonic returns the enharmonically equivalent scale, with the same type
+// but a differently spelled root, that has the fewest sharps or flats in its
+// key signature. So the enharmonic scale for G♯ major is A♭ major. If this
+// scale already has the simplest key signature, it is returned.
+func (s *Scale) Enharmonic() *Scale {
+	best, bestCount := s, s.KeySignature().Count()
+	for _, root := range s.Root.Enharmonics() {
+		alt := s.Type.WithRoot(root)
+		if count := alt.KeySignature().Count(); count < bestCount {
+			best, bestCount = alt, count
+		}
+	}
+	return best
+}
+
+// Chords harmonizes the scale: it returns a chord for each note of the scale,
+// built by stacking thirds. The depth is the number of notes in each chord. So
+// the chords of C major with a depth of 3 are C, D-, E-, F, G, A-, and Bdim.
+// (See ScaleType.ScaleChords.)
+func (s *Scale) Chords(depth int) []*Chord {
+	scs := s.Type.ScaleChords(depth)
+	if scs == nil {
+		return nil
+	}
+	chs := make([]*Chord, len(scs))
+	for i, sc := range scs {
+		chs[i] = sc.InKey(s.Root)
+	}
+	return chs
+}
+
+// ScaleMatch is a candidate scale returned by InferScale.
+type ScaleMatch struct {
+	// Scale is the candidate scale.
+	Scale *Scale
+	// Coverage is the fraction of the distinct pitch classes in the given
+	// notes that are in the scale. A value of 1 means the scale contains all
+	// of the notes.
+	Coverage float64
+	// Outside are the given notes that are not in the scale, in the order
+	// they were given. Repeated notes are included only once.
+	Outside []Note
+}
+
+// InferScale identifies candidate scales that contain the given notes, such as
+// the notes of a transcribed melody. Every named scale type (see
+// ParseScaleType), other than the chromatic scale, is considered for all
+// twelve possible roots. Scales that contain more than half of the distinct
+// pitch classes in the given notes are returned.
+//
+// The results are ranked by coverage, highest first. Among scales with the
+// same coverage, scales with fewer notes that are not among the given notes
+// are ranked first. Since modes of the same scale (like E major and F♯
+// dorian) contain the same notes, remaining ties are broken by a guess as to
+// which note is the tonic: a scale whose root is the last of the given notes
+// is ranked first, followed by one whose root is the first of the given
+// notes, followed by the rest ranked by how often their root appears in the
+// given notes.
+//
+// The root of each scale is spelled to match the given notes as closely as
+// possible.
+func InferScale(notes ...Note) []ScaleMatch {
+	if len(notes) == 0 {
+		return nil
+	}
+	input := pitchClasses(notes)
+	var count [12]int
+	for _, n := range notes {
+		count[n.Cardinal()]++
+	}
+	distinct := bits.OnesCount16(input)
+	first, last := notes[0].Cardinal(), notes[len(notes)-1].Cardinal()
+
+	type candidate struct {
+		ScaleMatch
+		unused, tonic, order int
+	}
+	var candidates []candidate
+	for i, entry := range scaleTypeNames {
+		if entry.names[0] == "chromatic" {
+			continue
+		}
+		t := entry.typ.Clean()
+		for c := int8(0); c < 12; c++ {
+			set := rotatePitchClasses(t.pitchClasses(), int(c))
+			matched := bits.OnesCount16(set & input)
+			if matched*2 <= distinct {
+				continue
+			}
+			cand := candidate{
+				ScaleMatch: ScaleMatch{
+					Scale:    t.WithRoot(spellRootForNotes(c, t, notes)),
+					Coverage: float64(matched) / float64(distinct),
+				},
+				unused: bits.OnesCount16(set &^ input),
+				tonic:  count[c],
+				order:  i,
+			}
+			switch c {
+			case last:
+				cand.tonic += 2 * len(notes)
+			case first:
+				cand.tonic += len(notes)
+			}
+			var seen uint16
+			for _, n := range notes {
+				bit := uint16(1) << uint(n.Cardinal())
+				if set&bit == 0 && seen&bit == 0 {
+					cand.Outside = append(cand.Outside, n)
+				}
+				seen |= bit
+			}
+			candidates = append(candidates, cand)
+		}
+	}
+	sort.SliceStable(candidates, func(i, j int) bool {
+		a, b := candidates[i], candidates[j]
+		if a.Coverage != b.Coverage {
+			return a.Coverage > b.Coverage
+		}
+		if a.unused != b.unused {
+			return a.unused < b.unused
+		}
+		if a.tonic != b.tonic {
+			return a.tonic > b.tonic
+		}
+		return a.order < b.order
+	})
+	matches := make([]ScaleMatch, len(candidates))
+	for i, cand := range candidates {
+		matches[i] = cand.ScaleMatch
+	}
+	return matches
+}
+
+// CompatibleScales returns scales that can be used to improvise over the given
+// chord. Each scale has the same root as the chord and contains all of the
+// chord's tones, including its bass note. Every named scale type (see
+// ParseScaleType), other than the chromatic scale, is considered. As an
+// exception, an unaltered 5th may be missing from a scale if the chord has
+// altered extensions, since that 5th is usually omitted from such chords. That
+// way, the altered scale is suggested for a chord like G7♭13♯9.
+//
+// The results are ranked by fit. Scales that contain all chord tones come
+// first. Then scales are ranked by how few of their other notes are a
+// half-step away from a chord tone, since those notes clash with the chord.
+// Remaining ties are broken using the order in which ParseScaleType's names
+// are documented, which puts more common scales first. So the first scale
+// for G7 is G mixolydian, and the first scale for G7♯5 is G whole tone.
+func CompatibleScales(ch *Chord) []*Scale {
+	canon := ch.Canonical()
+	chordSet := pitchClasses(canon.Spell())
+	root := canon.Root.Cardinal()
+	fifth := uint16(1) << uint(posMod(root+7, 12))
+	optional := uint16(0)
+	if chordSet&fifth != 0 {
+		for _, tn := range canon.ExtraTones {
+			if tn.Val > 7 && tn.Acc != Natural {
+				optional = fifth
+				break
+			}
+		}
+	}
+	// notes that are a half-step from a chord tone
+	near := rotatePitchClasses(chordSet, 1) | rotatePitchClasses(chordSet, -1)
+
+	type candidate struct {
+		scale                   *Scale
+		missing, clashes, order int
+	}
+	var candidates []candidate
+	for i, entry := range scaleTypeNames {
+		if entry.names[0] == "chromatic" {
+			continue
+		}
+		set := rotatePitchClasses(entry.typ.pitchClasses(), int(root))
+		if set&(chordSet&^optional) != chordSet&^optional {
+			continue
+		}
+		candidates = append(candidates, candidate{
+			scale:   &Scale{Root: canon.Root, Type: entry.typ},
+			missing: bits.OnesCount16(chordSet &^ set),
+			clashes: bits.OnesCount16(set &^ chordSet & near),
+			order:   i,
+		})
+	}
+	sort.SliceStable(candidates, func(i, j int) bool {
+		a, b := candidates[i], candidates[j]
+		if a.missing != b.missing {
+			return a.missing < b.missing
+		}
+		if a.clashes != b.clashes {
+			return a.clashes < b.clashes
+		}
+		return a.order < b.order
+	})
+	scales := make([]*Scale, len(candidates))
+	for i, cand := range candidates {
+		scales[i] = cand.scale
+	}
+	return scales
+}
+
+// tensionsByHalfSteps maps the number of half-steps above a chord's root to
+// the tension at that distance. Distances that correspond to other chord
+// tones (3rds, 5ths, and 7ths) have a zero value.
+var tensionsByHalfSteps = [12]ChordTone{
+	1: {Val: 9, Acc: Flat},
+	2: {Val: 9},
+	3: {Val: 9, Acc: Sharp},
+	5: {Val: 11},
+	6: {Val: 11, Acc: Sharp},
+	8: {Val: 13, Acc: Flat},
+	9: {Val: 13},
+}
+
+// Tensions analyzes the notes of the given scale in the context of this chord.
+// It returns the tensions (extensions) that are available, like 9, ♯11, or 13,
+// and the scale's avoid notes. To analyze a chord in the context of a key,
+// use the key's scale (see Key.Scale).
+//
+// An avoid note is a note of the scale, other than a chord tone, that is a
+// half-step above a chord tone, such as the 11th (F) of a Cmaj7 chord in C
+// major. The exception is for dominant 7th chords, where a ♭9 and a ♭13 are
+// available tensions even though they are a half-step above the root and 5th.
+// All other notes of the scale that are 9ths, 11ths, or 13ths relative to the
+// chord's root are available tensions. Tensions are identified by their
+// distance from the root: so the B♭ in G altered is a ♯9, not a minor 3rd.
+//
+// The tensions are sorted from lowest to highest. The avoid notes are in the
+// order they appear in the scale.
+func (ch *Chord) Tensions(s *Scale) (tensions []ChordTone, avoid []Note) {
+	chordSet := pitchClasses(ch.Spell())
+	root := ch.Root.Cardinal()
+	rel := rotatePitchClasses(chordSet, -int(root))
+	dominant := rel&(1<<4) != 0 && rel&(1<<10) != 0
+	var seen uint16
+	for _, n := range s.Spell() {
+		c := n.Cardinal()
+		bit := uint16(1) << uint(c)
+		if chordSet&bit != 0 || seen&bit != 0 {
+			continue
+		}
+		seen |= bit
+		steps := posMod(c-root, 12)
+		if chordSet&(1<<uint(posMod(c-1, 12))) != 0 && !(dominant && (steps == 1 || steps == 8)) {
+			avoid = append(avoid, n)
+			continue
+		}
+		if tn := tensionsByHalfSteps[steps]; tn.Val != 0 {
+			tensions = append(tensions, tn)
+		}
+	}
+	sort.Slice(tensions, func(i, j int) bool {
+		if tensions[i].Val != tensions[j].Val {
+			return tensions[i].Val < tensions[j].Val
+		}
+		return tensions[i].Acc < tensions[j].Acc
+	})
+	return tensions, avoid
+}
+
+// spellRootForNotes returns a spelling of the pitch class c to use as the root
+// of a scale of the given type. It chooses the spelling for which the most of
+// the given notes appear in the scale, as spelled, breaking ties by choosing
+// the scale with the fewest accidentals.
+func spellRootForNotes(c int8, t ScaleType, notes []Note) Note {
+	root := spellPitchClass(c, PreferSharps)
+	var best Note
+	bestMatches, bestAccs := -1, 0
+	for _, r := range append([]Note{root}, root.Enharmonics()...) {
+		scale := t.WithRoot(r).SpellFromRoot()
+		matches, accs := 0, 0
+		for _, sn := range scale {
+			if sn.Acc < 0 {
+				accs -= int(sn.Acc)
+			} else {
+				accs += int(sn.Acc)
+			}
+			for _, n := range notes {
+				if n == sn {
+					matches++
+				}
+			}
+		}
+		if matches > bestMatches || (matches == bestMatches && accs < bestAccs) {
+			best, bestMatches, bestAccs = r, matches, accs
+		}
+	}
+	return best
+}
+
+// Mode is a mode of a scale. (See Scale.Modes.)
+type Mode struct {
+	// Name is the name of the mode's scale type, like "dorian". For modes
+	// whose scale type is not named (see ScaleTypeName), this is a
+	// description like "mode 2 of blues".
+	Name string
+	// Scale is the mode, whose root is the corresponding note of the
+	// original scale.
+	Scale *Scale
+}
+
+// Modes returns all of the modes of this scale, one for each of its notes, in
+// order. The first is the scale itself. So the modes of C major are C major,
+// D dorian, E phrygian, F lydian, G mixolydian, A minor, and B locrian. As with
+// ScaleType.NthMode, the results may be unexpected for non-heptatonic scales.
+func (s *Scale) Modes() []Mode {
+	t := s.Type.Clean()
+	name, ok := ScaleTypeName(t)
+	if !ok {
+		name = "scale"
+	}
+	modes := make([]Mode, len(t))
+	for i, intv := range t {
+		mt := t.NthMode(int8(i + 1))
+		m := Mode{Scale: mt.WithRoot(s.Root.Transpose(intv))}
+		if mn, ok := ScaleTypeName(mt); ok {
+			m.Name = mn
+		} else {
+			m.Name = fmt.Sprintf("mode %d of %s", i+1, name)
+		}
+		modes[i] = m
+	}
+	return modes
+}
+
+// String implements the Stringer interface. Scales with named types are
+// rendered like "D dorian". Other scales are rendered as the root followed by
+// the scale's intervals.
+func (s *Scale) String() string {
+	if name, ok := ScaleTypeName(s.Type); ok {
+		return fmt.Sprintf("%v %s", s.Root, name)
+	}
+	return fmt.Sprintf("%v %v", s.Root, s.Type)
+}
@@ -0,0 +1,350 @@
+package chords
+
+import (
+	"strings"
+	"testing"
+)
+
+func spellString(notes []Note) string {
+	strs := make([]string, len(notes))
+	for i, n := range notes {
+		strs[i] = n.String()
+	}
+	return strings.Join(strs, " ")
+}
+
+func TestParseScale(t *testing.T) {
+	cases := map[string]string{
+		"C major":             "C D E F G A B",
+		"D dorian":            "D E F G A B C",
+		"  Bb Harmonic-Minor": "B♭ C D♭ E♭ F G♭ A",
+		"A natural minor":     "A B C D E F G",
+		"F# aeolian mode":     "F♯ G♯ A B C♯ D E",
+		"E blues scale":       "E G A B♭ B D",
+		"Eb jazz_minor":       "E♭ F G♭ A♭ B♭ C D",
+	}
+	for s, exp := range cases {
+		sc, err := ParseScale(s)
+		if err != nil {
+			t.Errorf("failed to parse %q: %v", s, err)
+			continue
+		}
+		if actual := spellString(sc.Spell()); actual != exp {
+			t.Errorf("wrong spelling for %q: %s != %s", s, actual, exp)
+		}
+	}
+	for _, s := range []string{"", "C", "H major", "C majestic", "Cmajor"} {
+		if _, err := ParseScale(s); err == nil {
+			t.Errorf("expecting error parsing %q", s)
+		}
+	}
+
+	if s := MustParseScale("C ionian").String(); s != "C major" {
+		t.Errorf("wrong string for scale: %s", s)
+	}
+	if name, ok := ScaleTypeName(MajorScale.NthMode(2)); !ok || name != "dorian" {
+		t.Errorf("wrong name for second mode of major: %q", name)
+	}
+	if _, ok := ScaleTypeName(HeptatonicScaleType([7]int8{0, -1, 0, 0, 0, 0, 0})); ok {
+		t.Errorf("unnamed scale type should not have a name")
+	}
+}
+
+func TestScale_Chords(t *testing.T) {
+	cases := []struct {
+		scale string
+		depth int
+		exp   string
+	}{
+		{"C major", 3, "C D- E- F G A- Bdim"},
+		{"C major", 4, "C△7 D-7 E-7 F△7 G7 A-7 Bø"},
+		{"C major", 5, "C△9 D-9 E-7♭9 F△9 G9 A-9 Bø7♭9"},
+		{"A harmonic minor", 4, "A-△7 Bø C+△7 D-7 E7 F△7 G♯o"},
+		{"Eb melodic minor", 3, "E♭- F- G♭+ A♭ B♭ Cdim Ddim"},
+	}
+	for _, tc := range cases {
+		var names []string
+		for _, ch := range MustParseScale(tc.scale).Chords(tc.depth) {
+			names = append(names, ch.String())
+		}
+		if actual := strings.Join(names, " "); actual != tc.exp {
+			t.Errorf("wrong chords for %s (depth %d): %s != %s", tc.scale, tc.depth, actual, tc.exp)
+		}
+	}
+	if chs := MustParseScale("C blues").Chords(3); chs != nil {
+		t.Errorf("non-heptatonic scale should not have chords: %v", chs)
+	}
+}
+
+func TestInferScale(t *testing.T) {
+	parse := func(s string) []Note {
+		var notes []Note
+		for _, f := range strings.Fields(s) {
+			notes = append(notes, MustParseNote(f))
+		}
+		return notes
+	}
+	cases := []struct {
+		notes string
+		exp   string
+	}{
+		{"F# G# A B C# D# E F#", "F♯ dorian"},
+		{"E F# G# A B C# D# E", "E major"},
+		{"C D Eb F G Ab B C", "C harmonic minor"},
+		{"A C D E G A", "A minor pentatonic"},
+		{"Bb C D Eb F G A Bb", "B♭ major"},
+		{"D E F G A Bb C# D", "D harmonic minor"},
+	}
+	for _, tc := range cases {
+		matches := InferScale(parse(tc.notes)...)
+		if len(matches) == 0 {
+			t.Errorf("no matches for %s", tc.notes)
+			continue
+		}
+		if s := matches[0].Scale.String(); s != tc.exp {
+			t.Errorf("wrong scale for %s: %s != %s", tc.notes, s, tc.exp)
+		}
+		if matches[0].Coverage != 1 || len(matches[0].Outside) != 0 {
+			t.Errorf("scale %v should contain all of %s", matches[0].Scale, tc.notes)
+		}
+	}
+
+	// with a note outside of any named scale
+	matches := InferScale(parse("C D E F F# G A B C")...)
+	if s := matches[0].Scale.String(); s != "C major" {
+		t.Errorf("wrong best match: %s", s)
+	}
+	if matches[0].Coverage != 7.0/8 || len(matches[0].Outside) != 1 || matches[0].Outside[0] != MustParseNote("F#") {
+		t.Errorf("wrong coverage for %v: %v %v", matches[0].Scale, matches[0].Coverage, matches[0].Outside)
+	}
+}
+
+func TestScale_Modes(t *testing.T) {
+	cases := map[string][]string{
+		"C major":          {"C major", "D dorian", "E phrygian", "F lydian", "G mixolydian", "A minor", "B locrian"},
+		"A melodic minor":  {"A melodic minor", "B dorian ♭2", "C lydian augmented", "D lydian dominant", "E mixolydian ♭6", "F♯ locrian ♮2", "G♯ altered"},
+		"E harmonic minor": {"E harmonic minor", "F♯ locrian ♮6", "G ionian ♯5", "A dorian ♯4", "B phrygian dominant", "C lydian ♯2", "D♯ altered diminished"},
+		"C blues":          {"C blues", "E♭ mode 2 of blues", "F mode 3 of blues", "G♭ mode 4 of blues", "G mode 5 of blues", "B♭ mode 6 of blues"},
+	}
+	for s, exp := range cases {
+		var names []string
+		for _, m := range MustParseScale(s).Modes() {
+			names = append(names, m.Scale.Root.String()+" "+m.Name)
+			if n, ok := ScaleTypeName(m.Scale.Type); ok && n != m.Name {
+				t.Errorf("mode of %s has wrong name: %s != %s", s, m.Name, n)
+			}
+		}
+		if strings.Join(names, ", ") != strings.Join(exp, ", ") {
+			t.Errorf("wrong modes for %s: %v", s, names)
+		}
+	}
+
+	for _, s := range []string{"lydian b7", "Lydian-Dominant", "LYDIAN ♭7 SCALE"} {
+		if _, err := ParseScaleType(s); err != nil {
+			t.Errorf("failed to parse %q: %v", s, err)
+		}
+	}
+}
+
+func TestDirectionalScale(t *testing.T) {
+	s := ClassicalMelodicMinorScale.WithRoot(MustParseNote("A"))
+	if actual := spellString(s.SpellAscending()); actual != "A B C D E F♯ G♯" {
+		t.Errorf("wrong ascending notes: %s", actual)
+	}
+	if actual := spellString(s.SpellDescending()); actual != "A G F E D C B" {
+		t.Errorf("wrong descending notes: %s", actual)
+	}
+
+	var strs []string
+	for _, p := range s.Run(MustParsePitch("A4"), 1) {
+		strs = append(strs, p.String())
+	}
+	if actual := strings.Join(strs, " "); actual != "A4 B4 C5 D5 E5 F♯5 G♯5 A5 G5 F5 E5 D5 C5 B4 A4" {
+		t.Errorf("wrong run: %s", actual)
+	}
+	if run := s.Run(MustParsePitch("A2"), 2); len(run) != 29 || run[14] != MustParsePitch("A4") {
+		t.Errorf("wrong two-octave run: %v", run)
+	}
+}
+
+func TestScaleType_IsModeOf(t *testing.T) {
+	if !MajorScale.Equal(IonianMode) || !MajorScale.Equal(ScaleType{{7, 0}, {1, 0}, {2, 0}, {3, 0}, {4, 0}, {5, 0}, {6, 0}, {6, 0}}) {
+		t.Errorf("scale types should be equal")
+	}
+	if MajorScale.Equal(LydianMode) || WholeToneScale.Equal(ScaleType{{1, 0}, {2, 0}, {3, 0}, {5, -1}, {6, -1}, {7, -1}}) {
+		t.Errorf("scale types should not be equal")
+	}
+
+	cases := []struct {
+		t, other ScaleType
+		n        int
+		ok       bool
+	}{
+		{DorianMode, MajorScale, 2, true},
+		{MajorScale, MajorScale, 1, true},
+		{MinorScale, MajorScale, 6, true},
+		{MajorScale, MinorScale, 3, true},
+		{AlteredScale, MelodicMinorScale, 7, true},
+		{PentatonicMajorScale, PentatonicMinorScale, 2, true},
+		{HarmonicMinorScale, MajorScale, 0, false},
+		{BluesScale, PentatonicMinorScale, 0, false},
+	}
+	for _, tc := range cases {
+		n, ok := tc.t.IsModeOf(tc.other)
+		if n != tc.n || ok != tc.ok {
+			t.Errorf("ScaleType.IsModeOf for %v, %v returned wrong value: %d, %v != %d, %v", tc.t, tc.other, n, ok, tc.n, tc.ok)
+		}
+	}
+}
+
+func TestScale_KeySignature(t *testing.T) {
+	cases := map[string]KeySignature{
+		"C major":                0,
+		"D dorian":               0,
+		"E phrygian dominant":    0,
+		"A harmonic minor":       0,
+		"F# major":               6,
+		"Eb minor":               -6,
+		"Bb lydian":              -1,
+		"G# major":               8,
+		"C altered":              -8,
+		"D whole tone":           2,
+		"F blues":                -4,
+		"G# mixolydian":          7,
+		"Fb major":               -8,
+		"D# altered diminished":  1,
+		"Gb locrian ♮2":          -12,
+		"Ab phrygian dominant":   -8,
+		"C# harmonic minor":      4,
+		"Cb major pentatonic":    -7,
+		"E half-whole":           4,
+		"Bb ionian":              -2,
+		"A minor pentatonic":     0,
+		"D melodic minor":        -1,
+		"B locrian":              0,
+		"F# hungarian minor":     3,
+		"Db lydian augmented":    -5,
+		"Eb mixolydian ♭6 scale": -7,
+	}
+	for s, exp := range cases {
+		if ks := MustParseScale(s).KeySignature(); ks != exp {
+			t.Errorf("wrong key signature for %s: %v != %v", s, ks, exp)
+		}
+	}
+
+	gs := MustParseScale("G# major")
+	if actual := spellString(gs.Spell()); actual != "A♭ B♭ C D♭ E♭ F G" {
+		t.Errorf("wrong spelling for %v: %s", gs, actual)
+	}
+	if actual := spellString(gs.SpellFromRoot()); actual != "G♯ A♯ B♯ C♯ D♯ E♯ F𝄪" {
+		t.Errorf("wrong spelling from root for %v: %s", gs, actual)
+	}
+	if actual := spellString(gs.KeySignature().Notes()); actual != "F♯ C♯ G♯ D♯ A♯ E♯ B♯ F𝄪" {
+		t.Errorf("wrong key signature notes for %v: %s", gs, actual)
+	}
+	if actual := spellString(KeySignature(-4).Notes()); actual != "B♭ E♭ A♭ D♭" {
+		t.Errorf("wrong key signature notes for -4: %s", actual)
+	}
+	if e := MustParseScale("Gb locrian ♮2").Enharmonic(); e.String() != "F♯ locrian ♮2" {
+		t.Errorf("wrong enharmonic for G♭ locrian ♮2: %v", e)
+	}
+	if e := MustParseScale("F# major").Enharmonic(); e.Root != MustParseNote("F#") {
+		t.Errorf("Scale.Enharmonic for F♯ major returned wrong value: %v", e)
+	}
+}
+
+func TestCompatibleScales(t *testing.T) {
+	cases := map[string]string{
+		"G7":      "G mixolydian",
+		"Cmaj7":   "C major",
+		"D-7":     "D minor pentatonic",
+		"G7#5":    "G whole tone",
+		"G7b13#9": "G altered",
+		"G7b9":    "G phrygian dominant",
+		"Bø":      "B locrian",
+		"Co":      "C locrian ♮6",
+		"C-maj7":  "C melodic minor",
+		"F△7#11":  "F lydian",
+		"C7#11":   "C lydian dominant",
+		"E-7b9":   "E phrygian",
+		"A-6":     "A dorian",
+	}
+	for s, exp := range cases {
+		scales := CompatibleScales(MustParseChord(s))
+		if len(scales) == 0 {
+			t.Errorf("no compatible scales for %s", s)
+			continue
+		}
+		if scales[0].String() != exp {
+			t.Errorf("wrong best scale for %s: %v != %s", s, scales[0].String(), exp)
+		}
+		for _, sc := range scales {
+			if sc.Root != MustParseChord(s).Root {
+				t.Errorf("scale %v for %s has wrong root", sc.String(), s)
+			}
+		}
+	}
+
+	var names []string
+	for _, sc := range CompatibleScales(MustParseChord("Co")) {
+		names = append(names, sc.String())
+	}
+	if strings.Join(names, ", ") != "C locrian ♮6, C dorian ♯4, C lydian ♯2, C altered diminished, C half whole, C whole half" {
+		t.Errorf("wrong scales for Co: %v", names)
+	}
+}
+
+func TestChord_Tensions(t *testing.T) {
+	cases := []struct {
+		chord, scale     string
+		tensions, avoids string
+	}{
+		{"Cmaj7", "C major", "9 13", "F"},
+		{"Fmaj7", "C major", "9 ♯11 13", ""},
+		{"G7", "C major", "9 13", "C"},
+		{"D-7", "C major", "9 11 13", ""},
+		{"E-7", "C major", "11", "C F"},
+		{"G7", "G altered", "♭9 ♯9 ♯11 ♭13", ""},
+		{"E7", "A harmonic minor", "♭9 ♭13", "A"},
+		{"Bø", "C major", "11 ♭13", "C"},
+	}
+	for _, tc := range cases {
+		tensions, avoids := MustParseChord(tc.chord).Tensions(MustParseScale(tc.scale))
+		var strs []string
+		for _, tn := range tensions {
+			strs = append(strs, tn.String())
+		}
+		if actual := strings.Join(strs, " "); actual != tc.tensions {
+			t.Errorf("wrong tensions for %s in %s: %s != %s", tc.chord, tc.scale, actual, tc.tensions)
+		}
+		if actual := spellString(avoids); actual != tc.avoids {
+			t.Errorf("wrong avoid notes for %s in %s: %s != %s", tc.chord, tc.scale, actual, tc.avoids)
+		}
+	}
+}
+
+func TestScaleType_DiatonicChords(t *testing.T) {
+	cases := []struct {
+		opts DiatonicChordOptions
+		exp  string
+	}{
+		{DiatonicChordOptions{}, "C D- E- F G A- Bdim"},
+		{DiatonicChordOptions{Size: 4, Degrees: []int{2, 5, 1}}, "C△7 D-7 G7"},
+		{DiatonicChordOptions{Stacking: 3}, "Csus4△7 Dsus4 7 Esus4 7 Fsus♯4△7 Gsus4 7 Asus4 7 Bsus4 7"},
+		{DiatonicChordOptions{Stacking: 4, Degrees: []int{1}}, "Csus2"},
+		// sizes are clamped, and stacking wraps around the scale
+		{DiatonicChordOptions{Size: -1, Stacking: 9}, "C D- E- F G A- Bdim"},
+		{DiatonicChordOptions{Size: 12, Degrees: []int{5}}, "G9 11 13"},
+		{DiatonicChordOptions{Stacking: -3, Degrees: []int{1}}, "Csus2"},
+		{DiatonicChordOptions{Stacking: -4}, "Csus4△7 Dsus4 7 Esus4 7 Fsus♯4△7 Gsus4 7 Asus4 7 Bsus4 7"},
+	}
+	for _, tc := range cases {
+		var names []string
+		for _, sc := range MajorScale.DiatonicChords(tc.opts) {
+			names = append(names, sc.InKey(MustParseNote("C")).String())
+		}
+		if actual := strings.Join(names, " "); actual != tc.exp {
+			t.Errorf("wrong chords for %+v: %s != %s", tc.opts, actual, tc.exp)
+		}
+	}
+}
@@ -0,0 +1,563 @@
+package chords
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// SongMetadata describes header information for a song or chart. This is the
+// kind of information that usually appears at the top of a lead sheet: its
+// title, who wrote it, how fast it is played, etc.
+//
+// Song metadata can be parsed from ChordPro-style directives, like
+// "{title: Autumn Leaves}", or from a front matter block, which is a sequence
+// of "name: value" lines between two "---" lines. (See ParseSongMetadata.)
+type SongMetadata struct {
+	// Title is the name of the song.
+	Title string
+	// Composer is the person or people who wrote the song.
+	Composer string
+	// Tempo is the speed of the song in beats per minute. Zero means that the
+	// tempo is not specified.
+	Tempo int
+	// Style is a free-form description of the song's style or feel, such as
+	// "Medium Swing" or "Bossa Nova".
+	Style string
+	// Key is the default key of the song. It is considered not present if
+	// Key.Tonic is the zero value.
+	Key Key
+	// Capo is the fret at which a capo is placed for guitar charts. Zero means
+	// no capo.
+	Capo int
+	// Extra contains any other metadata fields, in the order they were
+	// parsed. These are retained so that they are not lost when a chart is
+	// parsed and then written back out.
+	Extra []MetadataField
+}
+
+// MetadataField is a single name and value pair of song metadata.
+type MetadataField struct {
+	Name  string
+	Value string
+}
+
+// ParseSongMetadata parses song metadata from the head of the given text and
+// returns the remaining text, which is the body of the song.
+//
+// If the first non-blank line of the text is "---" then the header is a front
+// matter block: every line up to the next "---" line must be a "name: value"
+// pair. Otherwise, the header is a sequence of ChordPro directives, one per
+// line, like "{title: Autumn Leaves}" or "{key: Gm}". The header ends at the
+// first line that is not a metadata directive. ChordPro's generic
+// "{meta: name value}" form is also accepted.
+//
+// The names "title" (or "t"), "composer", "tempo", "style", "key", and "capo"
+// populate the corresponding fields of the returned metadata. All other
+// names are stored in its Extra field.
+func ParseSongMetadata(text string) (*SongMetadata, string, error) {
+	var m SongMetadata
+	var consumed int
+	frontMatter := false
+	started := false
+	for _, line := range strings.SplitAfter(text, "\n") {
+		trimmed := strings.TrimSpace(line)
+		if frontMatter {
+			consumed += len(line)
+			if trimmed == "---" {
+				return &m, text[consumed:], nil
+			}
+			if trimmed == "" {
+				continue
+			}
+			name, val, ok := strings.Cut(trimmed, ":")
+			if !ok {
+				return nil, "", fmt.Errorf("invalid front matter line %q: should be 'name: value'", trimmed)
+			}
+			if err := m.Set(name, val); err != nil {
+				return nil, "", err
+			}
+			continue
+		}
+		if trimmed == "" {
+			consumed += len(line)
+			continue
+		}
+		if trimmed == "---" && !started {
+			consumed += len(line)
+			frontMatter = true
+			continue
+		}
+		name, val, ok := parseDirective(trimmed)
+		if !ok || !isMetadataDirective(name) {
+			break
+		}
+		if strings.EqualFold(name, "meta") {
+			name, val, _ = strings.Cut(strings.TrimSpace(val), " ")
+		}
+		if err := m.Set(name, val); err != nil {
+			return nil, "", err
+		}
+		started = true
+		consumed += len(line)
+	}
+	if frontMatter {
+		return nil, "", errors.New("front matter is missing closing '---' line")
+	}
+	return &m, text[consumed:], nil
+}
+
+// parseDirective parses a ChordPro directive, like "{title: Autumn Leaves}",
+// into its name and value.
+func parseDirective(s string) (name, val string, ok bool) {
+	if !strings.HasPrefix(s, "{") || !strings.HasSuffix(s, "}") {
+		return "", "", false
+	}
+	s = s[1 : len(s)-1]
+	name, val, _ = strings.Cut(s, ":")
+	return strings.TrimSpace(name), strings.TrimSpace(val), true
+}
+
+// metadataDirectives are the names of ChordPro directives that are song
+// metadata (as opposed to formatting or structural directives).
+var metadataDirectives = map[string]struct{}{
+	"title": {}, "t": {}, "subtitle": {}, "st": {}, "artist": {},
+	"composer": {}, "lyricist": {}, "copyright": {}, "album": {},
+	"year": {}, "key": {}, "time": {}, "tempo": {}, "duration": {},
+	"capo": {}, "style": {}, "meta": {},
+}
+
+func isMetadataDirective(name string) bool {
+	_, ok := metadataDirectives[strings.ToLower(name)]
+	return ok
+}
+
+// Set sets the named metadata field to the given value. Values for the
+// "tempo" and "capo" fields must be integers and a value for the "key" field
+// must be a valid key (see ParseKey). Names that do not correspond to a field
+// of SongMetadata are added to m.Extra (or replace an existing extra field with
+// the same name).
+func (m *SongMetadata) Set(name, val string) error {
+	name = strings.TrimSpace(name)
+	val = strings.TrimSpace(val)
+	switch strings.ToLower(name) {
+	case "title", "t":
+		m.Title = val
+	case "composer":
+		m.Composer = val
+	case "style":
+		m.Style = val
+	case "tempo":
+		t, err := strconv.Atoi(val)
+		if err != nil || t < 0 {
+			return fmt.Errorf("invalid tempo %q: must be a non-negative integer", val)
+		}
+		m.Tempo = t
+	case "capo":
+		c, err := strconv.Atoi(val)
+		if err != nil || c < 0 {
+			return fmt.Errorf("invalid capo %q: must be a non-negative integer", val)
+		}
+		m.Capo = c
+	case "key":
+		k, err := ParseKey(val)
+		if err != nil {
+			return fmt.Errorf("invalid key %q: %v", val, err)
+		}
+		m.Key = k
+	default:
+		for i := range m.Extra {
+			if m.Extra[i].Name == name {
+				m.Extra[i].Value = val
+				return nil
+			}
+		}
+		m.Extra = append(m.Extra, MetadataField{Name: name, Value: val})
+	}
+	return nil
+}
+
+// Fields returns all of the populated metadata as name and value pairs. The
+// known fields are first, in the order title, composer, style, key, tempo, and
+// capo, followed by all extra fields. Fields that have zero values are omitted.
+func (m *SongMetadata) Fields() []MetadataField {
+	var fields []MetadataField
+	if m.Title != "" {
+		fields = append(fields, MetadataField{Name: "title", Value: m.Title})
+	}
+	if m.Composer != "" {
+		fields = append(fields, MetadataField{Name: "composer", Value: m.Composer})
+	}
+	if m.Style != "" {
+		fields = append(fields, MetadataField{Name: "style", Value: m.Style})
+	}
+	if m.Key.Tonic.N != 0 {
+		fields = append(fields, MetadataField{Name: "key", Value: m.Key.String()})
+	}
+	if m.Tempo != 0 {
+		fields = append(fields, MetadataField{Name: "tempo", Value: strconv.Itoa(m.Tempo)})
+	}
+	if m.Capo != 0 {
+		fields = append(fields, MetadataField{Name: "capo", Value: strconv.Itoa(m.Capo)})
+	}
+	return append(fields, m.Extra...)
+}
+
+// Directives returns the metadata formatted as ChordPro directives, one per
+// line. Names that are not standard ChordPro metadata directives are written
+// using the generic "{meta: name value}" form. The result can be parsed with
+// ParseSongMetadata.
+func (m *SongMetadata) Directives() string {
+	var b bytes.Buffer
+	for _, f := range m.Fields() {
+		if isMetadataDirective(f.Name) && !strings.EqualFold(f.Name, "meta") {
+			fmt.Fprintf(&b, "{%s: %s}\n", f.Name, f.Value)
+		} else {
+			fmt.Fprintf(&b, "{meta: %s %s}\n", f.Name, f.Value)
+		}
+	}
+	return b.String()
+}
+
+// FrontMatter returns the metadata formatted as a front matter block. The
+// result can be parsed with ParseSongMetadata.
+func (m *SongMetadata) FrontMatter() string {
+	var b bytes.Buffer
+	b.WriteString("---\n")
+	for _, f := range m.Fields() {
+		fmt.Fprintf(&b, "%s: %s\n", f.Name, f.Value)
+	}
+	b.WriteString("---\n")
+	return b.String()
+}
+
+// Song is a complete chart: its metadata and its chords, which are organized
+// into named sections, like "Intro", "A", "B", and "Coda".
+type Song struct {
+	// Metadata is the song's header information.
+	Metadata SongMetadata
+	// Sections are the sections of the song, in the order they are written.
+	Sections []*Section
+	// Form is the order in which sections are played, by name, like
+	// "A A B A". If empty, the sections are played in the order they are
+	// written.
+	Form []string
+}
+
+// Section is a named part of a song.
+type Section struct {
+	// Name is the name of the section, like "Verse", "A", or "Coda". It may
+	// be empty.
+	Name string
+	// Body is the chords of the section.
+	Body *Progression
+	// Repeat is the number of times the section is played each time it
+	// appears in the song's form. Zero is treated the same as one. If the
+	// section has more endings than this, it is played once for each ending.
+	Repeat int
+	// Endings are alternate endings for the section (aka "volta" brackets).
+	// The first ending is played after the body on the first pass through
+	// the section, the second ending on the second pass, and so on. If there
+	// are more passes than endings, the last ending is used for the extra
+	// passes.
+	Endings []*Progression
+}
+
+// passes returns the number of times the section is played each time it
+// appears in the song's form.
+func (s *Section) passes() int {
+	n := s.Repeat
+	if n < len(s.Endings) {
+		n = len(s.Endings)
+	}
+	if n < 1 {
+		n = 1
+	}
+	return n
+}
+
+// ParseSong parses a song chart. The chart starts with metadata (see
+// ParseSongMetadata) and is followed by sections. Each section starts with a
+// line that has the section's name in square brackets, optionally followed by
+// a repeat count, like "[A] x2". The lines that follow are the section's chords,
+// in the format accepted by ParseProgression. Lines that start with a number
+// followed by a period, like "1." and "2.", are the section's endings, which
+// must be numbered in order. A "{form: A A B A}" directive indicates the order
+// in which the sections are played. For example:
+//
+//	{title: Blue Bossa}
+//	{key: Cm}
+//	{form: A A}
+//	[A]
+//	| C-7 | % | F-7 | % | Dø G7 | C-7 | E♭-7 A♭7 | D♭△7 |
+//	| Dø | G7 | C-7 | Dø G7 |
+//
+// If chords appear before the first section name, they are put into a section
+// with no name. If the metadata includes a time signature (the "time" field,
+// like "3/4"), it applies to all sections.
+func ParseSong(text string) (*Song, error) {
+	meta, body, err := ParseSongMetadata(text)
+	if err != nil {
+		return nil, err
+	}
+	song := &Song{Metadata: *meta}
+	beats := 4
+	for _, f := range meta.Extra {
+		if strings.EqualFold(f.Name, "time") {
+			if beats, err = parseTimeSignature(f.Value); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	var sect *Section
+	var lines []string
+	var endings [][]string
+	finish := func() error {
+		if sect == nil {
+			return nil
+		}
+		var err error
+		if sect.Body, err = parseProgression(strings.Join(lines, " "), beats); err != nil {
+			return fmt.Errorf("section %q: %v", sect.Name, err)
+		}
+		for i, ending := range endings {
+			p, err := parseProgression(strings.Join(ending, " "), beats)
+			if err != nil {
+				return fmt.Errorf("section %q, ending %d: %v", sect.Name, i+1, err)
+			}
+			sect.Endings = append(sect.Endings, p)
+		}
+		song.Sections = append(song.Sections, sect)
+		sect, lines, endings = nil, nil, nil
+		return nil
+	}
+	for _, line := range strings.Split(body, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+		if name, val, ok := parseDirective(line); ok {
+			if !strings.EqualFold(name, "form") {
+				return nil, fmt.Errorf("unsupported directive %q", line)
+			}
+			song.Form = strings.Fields(val)
+			continue
+		}
+		if strings.HasPrefix(line, "[") {
+			if err := finish(); err != nil {
+				return nil, err
+			}
+			end := strings.IndexByte(line, ']')
+			if end < 0 {
+				return nil, fmt.Errorf("invalid section name %q: missing ']'", line)
+			}
+			sect = &Section{Name: strings.TrimSpace(line[1:end])}
+			if rest := strings.TrimSpace(line[end+1:]); rest != "" {
+				n, err := strconv.Atoi(strings.TrimLeft(rest, "xX"))
+				if err != nil || n < 1 || (rest[0] != 'x' && rest[0] != 'X') {
+					return nil, fmt.Errorf("invalid repeat count for section %q: %q", sect.Name, rest)
+				}
+				sect.Repeat = n
+			}
+			continue
+		}
+		if sect == nil {
+			sect = &Section{}
+		}
+		if pos := strings.IndexByte(line, '.'); pos > 0 {
+			if n, err := strconv.Atoi(line[:pos]); err == nil {
+				if n != len(endings)+1 {
+					return nil, fmt.Errorf("section %q: ending %d should be ending %d", sect.Name, n, len(endings)+1)
+				}
+				endings = append(endings, []string{line[pos+1:]})
+				continue
+			}
+		}
+		if len(endings) > 0 {
+			// continuation of the current ending
+			endings[len(endings)-1] = append(endings[len(endings)-1], line)
+		} else {
+			lines = append(lines, line)
+		}
+	}
+	if err := finish(); err != nil {
+		return nil, err
+	}
+	for _, name := range song.Form {
+		if song.Section(name) == nil {
+			return nil, fmt.Errorf("form refers to unknown section %q", name)
+		}
+	}
+	return song, nil
+}
+
+// Section returns the first section with the given name, or nil if there is
+// no such section.
+func (s *Song) Section(name string) *Section {
+	for _, sect := range s.Sections {
+		if sect.Name == name {
+			return sect
+		}
+	}
+	return nil
+}
+
+// String implements the Stringer interface. The result is in the format
+// accepted by ParseSong.
+func (s *Song) String() string {
+	var b bytes.Buffer
+	b.WriteString(s.Metadata.Directives())
+	if len(s.Form) > 0 {
+		fmt.Fprintf(&b, "{form: %s}\n", strings.Join(s.Form, " "))
+	}
+	for _, sect := range s.Sections {
+		fmt.Fprintf(&b, "[%s]", sect.Name)
+		if sect.Repeat > 1 {
+			fmt.Fprintf(&b, " x%d", sect.Repeat)
+		}
+		b.WriteByte('\n')
+		if sect.Body != nil && len(sect.Body.Bars) > 0 {
+			b.WriteString(sect.Body.String())
+			b.WriteByte('\n')
+		}
+		for i, ending := range sect.Endings {
+			fmt.Fprintf(&b, "%d. %v\n", i+1, ending)
+		}
+	}
+	return b.String()
+}
+
+// Unroll returns the whole song as a single progression, in the order it is
+// played: sections are played in the order of the song's form, each section is
+// repeated as indicated, with the appropriate ending after each pass, and
+// repeated sections within each progression are expanded (see
+// Progression.Unroll).
+func (s *Song) Unroll() *Progression {
+	ret := &Progression{}
+	add := func(p *Progression) {
+		if p == nil {
+			return
+		}
+		if ret.BeatsPerBar == 0 {
+			ret.BeatsPerBar = p.BeatsPerBar
+		}
+		ret.Bars = append(ret.Bars, p.Unroll().Bars...)
+	}
+	sections := s.Sections
+	if len(s.Form) > 0 {
+		sections = make([]*Section, len(s.Form))
+		for i, name := range s.Form {
+			sections[i] = s.Section(name)
+		}
+	}
+	for _, sect := range sections {
+		if sect == nil {
+			continue
+		}
+		for pass := 0; pass < sect.passes(); pass++ {
+			add(sect.Body)
+			if len(sect.Endings) > 0 {
+				e := pass
+				if e >= len(sect.Endings) {
+					e = len(sect.Endings) - 1
+				}
+				add(sect.Endings[e])
+			}
+		}
+	}
+	return ret
+}
+
+// progressions returns all of the song's progressions: each section's body
+// followed by its endings.
+func (s *Song) progressions() []*Progression {
+	var ps []*Progression
+	for _, sect := range s.Sections {
+		if sect.Body != nil {
+			ps = append(ps, sect.Body)
+		}
+		ps = append(ps, sect.Endings...)
+	}
+	return ps
+}
+
+// key returns the song's key: the key in its metadata or, if that is not
+// present, the key inferred from its chords.
+func (s *Song) key() Key {
+	if s.Metadata.Key.Tonic.N != 0 {
+		return s.Metadata.Key
+	}
+	var chs []*Chord
+	for _, p := range s.progressions() {
+		chs = append(chs, p.Chords()...)
+	}
+	return InferKey(chs...)
+}
+
+// Transpose returns a new song that is this song transposed by the given
+// interval. The whole song is spelled consistently, the same as for
+// Progression.Transpose, except that the song's key is taken from its
+// metadata, if present, instead of being inferred. The key in the metadata is
+// also transposed.
+func (s *Song) Transpose(intv Interval) *Song {
+	key := s.key()
+	intv = simplestTransposition(key, intv)
+	return s.transpose(intv, Key{Tonic: key.Tonic.Transpose(intv), Minor: key.Minor})
+}
+
+// TransposeToKey returns a new song that is this song transposed into the
+// given key, the same as for Progression.TransposeToKey, except that the
+// song's key is taken from its metadata, if present, instead of being
+// inferred. The key in the metadata is also updated.
+func (s *Song) TransposeToKey(key Key) *Song {
+	return s.transpose(intervalToKey(s.key(), key), key)
+}
+
+// clone returns a deep copy of the song.
+func (s *Song) clone() *Song {
+	ret := &Song{Metadata: s.Metadata, Form: append([]string(nil), s.Form...)}
+	ret.Metadata.Extra = append([]MetadataField(nil), s.Metadata.Extra...)
+	for _, sect := range s.Sections {
+		newSect := &Section{Name: sect.Name, Repeat: sect.Repeat}
+		if sect.Body != nil {
+			newSect.Body = sect.Body.Clone()
+		}
+		for _, e := range sect.Endings {
+			newSect.Endings = append(newSect.Endings, e.Clone())
+		}
+		ret.Sections = append(ret.Sections, newSect)
+	}
+	return ret
+}
+
+func (s *Song) transpose(intv Interval, key Key) *Song {
+	// transpose all progressions together, so they are spelled consistently
+	ps := s.progressions()
+	all := &Progression{}
+	for _, p := range ps {
+		all.Bars = append(all.Bars, p.Bars...)
+	}
+	bars := all.transpose(intv, key).Bars
+	transposed := map[*Progression]*Progression{}
+	for _, p := range ps {
+		transposed[p] = &Progression{BeatsPerBar: p.BeatsPerBar, Bars: bars[:len(p.Bars):len(p.Bars)]}
+		bars = bars[len(p.Bars):]
+	}
+
+	ret := &Song{Metadata: s.Metadata, Form: append([]string(nil), s.Form...)}
+	ret.Metadata.Extra = append([]MetadataField(nil), s.Metadata.Extra...)
+	if s.Metadata.Key.Tonic.N != 0 {
+		ret.Metadata.Key = Key{Tonic: s.Metadata.Key.Tonic.Transpose(intv), Minor: s.Metadata.Key.Minor}
+	}
+	for _, sect := range s.Sections {
+		newSect := &Section{Name: sect.Name, Body: transposed[sect.Body], Repeat: sect.Repeat}
+		for _, e := range sect.Endings {
+			newSect.Endings = append(newSect.Endings, transposed[e])
+		}
+		ret.Sections = append(ret.Sections, newSect)
+	}
+	return ret
+}
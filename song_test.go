@@ -0,0 +1,160 @@
+package chords
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseSongMetadata(t *testing.T) {
+	exp := &SongMetadata{
+		Title:    "Autumn Leaves",
+		Composer: "Joseph Kosma",
+		Tempo:    120,
+		Style:    "Medium Swing",
+		Key:      Key{Tonic: Note{N: G}, Minor: true},
+		Capo:     3,
+		Extra:    []MetadataField{{Name: "subtitle", Value: "Les feuilles mortes"}, {Name: "arranger", Value: "Me"}},
+	}
+
+	directives := `{title: Autumn Leaves}
+{st: Les feuilles mortes}
+{composer: Joseph Kosma}
+{key: Gm}
+{tempo: 120}
+{capo: 3}
+{meta: style Medium Swing}
+{meta: arranger Me}
+
+{start_of_verse}
+`
+	m, body, err := ParseSongMetadata(directives)
+	if err != nil {
+		t.Fatalf("failed to parse directives: %v", err)
+	}
+	if body != "{start_of_verse}\n" {
+		t.Errorf("wrong body: %q", body)
+	}
+	exp.Extra[0].Name = "st"
+	if !reflect.DeepEqual(exp, m) {
+		t.Errorf("wrong metadata: %+v", m)
+	}
+	exp.Extra[0].Name = "subtitle"
+
+	frontMatter := `---
+title: Autumn Leaves
+composer: Joseph Kosma
+style: Medium Swing
+key: G minor
+tempo: 120
+capo: 3
+subtitle: Les feuilles mortes
+arranger: Me
+---
+| Cm7 | F7 |
+`
+	m, body, err = ParseSongMetadata(frontMatter)
+	if err != nil {
+		t.Fatalf("failed to parse front matter: %v", err)
+	}
+	if body != "| Cm7 | F7 |\n" {
+		t.Errorf("wrong body: %q", body)
+	}
+	if !reflect.DeepEqual(exp, m) {
+		t.Errorf("wrong metadata: %+v", m)
+	}
+
+	// round trip
+	for _, text := range []string{m.Directives(), m.FrontMatter()} {
+		rt, body, err := ParseSongMetadata(text)
+		if err != nil {
+			t.Fatalf("failed to parse %q: %v", text, err)
+		}
+		if body != "" {
+			t.Errorf("wrong body: %q", body)
+		}
+		if !reflect.DeepEqual(m, rt) {
+			t.Errorf("round trip of %q produced wrong metadata: %+v", text, rt)
+		}
+	}
+
+	if _, _, err := ParseSongMetadata("{tempo: fast}"); err == nil {
+		t.Error("expected error for invalid tempo")
+	}
+	if _, _, err := ParseSongMetadata("---\ntitle: Foo\n"); err == nil {
+		t.Error("expected error for unterminated front matter")
+	}
+}
+
+const testSong = `{title: Test Song}
+{key: C}
+{form: A A B A}
+[A]
+| C△7 | A-7 D7 |
+1. | D-7 G7 |
+2. | C△7 |
+[B] x2
+| F△7 | E-7 A7 |
+`
+
+func TestParseSong(t *testing.T) {
+	song, err := ParseSong(testSong)
+	if err != nil {
+		t.Fatalf("failed to parse song: %v", err)
+	}
+	if song.Metadata.Title != "Test Song" {
+		t.Errorf("wrong title: %q", song.Metadata.Title)
+	}
+	if len(song.Sections) != 2 {
+		t.Fatalf("wrong number of sections: %d", len(song.Sections))
+	}
+	if a := song.Section("A"); len(a.Endings) != 2 || a.Body.String() != "| C△7 | A-7 D7 |" {
+		t.Errorf("wrong A section: %v, %d endings", a.Body, len(a.Endings))
+	}
+	if b := song.Section("B"); b.Repeat != 2 || len(b.Endings) != 0 {
+		t.Errorf("wrong B section: repeat %d, %d endings", b.Repeat, len(b.Endings))
+	}
+	if actual := song.String(); actual != testSong {
+		t.Errorf("wrong string form:\n%s", actual)
+	}
+
+	// A (2 passes, 2 endings), A again, B twice, A again
+	exp := "| C△7 | A-7 D7 | D-7 G7 | C△7 | A-7 D7 | C△7 " +
+		"| C△7 | A-7 D7 | D-7 G7 | C△7 | A-7 D7 | C△7 " +
+		"| F△7 | E-7 A7 | F△7 | E-7 A7 " +
+		"| C△7 | A-7 D7 | D-7 G7 | C△7 | A-7 D7 | C△7 |"
+	if actual := song.Unroll().String(); actual != exp {
+		t.Errorf("wrong unrolled song: %s", actual)
+	}
+
+	song, err = ParseSong("{time: 3/4}\n| C | G7 C |\n")
+	if err != nil {
+		t.Fatalf("failed to parse song: %v", err)
+	}
+	if actual := song.Sections[0].Body.String(); actual != "3/4 | C | G7 / C |" {
+		t.Errorf("wrong body for 3/4 song: %s", actual)
+	}
+
+	for _, s := range []string{"{form: A B}\n[A]\n| C |\n", "[A]\n| C |\n2. | G |\n", "[A] 2\n| C |\n", "| Cfoo |\n"} {
+		if _, err := ParseSong(s); err == nil {
+			t.Errorf("ParseSong(%q) should have failed", s)
+		}
+	}
+}
+
+func TestSong_Transpose(t *testing.T) {
+	song, err := ParseSong(testSong)
+	if err != nil {
+		t.Fatalf("failed to parse song: %v", err)
+	}
+	actual := song.Transpose(Interval{Val: 1, Offset: 1}).String()
+	exp := strings.NewReplacer("key: C", "key: D♭", "C△7", "D♭△7", "A-7", "B♭-7", "D7", "E♭7",
+		"D-7", "E♭-7", "G7", "A♭7", "F△7", "G♭△7", "E-7", "F-7", "A7", "B♭7").Replace(testSong)
+	if actual != exp {
+		t.Errorf("wrong transposed song:\n%s", actual)
+	}
+	actual = song.TransposeToKey(MustParseKey("Cm")).String()
+	if !strings.Contains(actual, "{key: E♭}") || !strings.Contains(actual, "| E♭△7 | C-7 F7 |") {
+		t.Errorf("wrong song transposed to relative major of C minor:\n%s", actual)
+	}
+}
@@ -0,0 +1,130 @@
+package chords
+
+import (
+	"fmt"
+)
+
+// SubstitutionKind is a reharmonization technique. (See Substitutions.)
+type SubstitutionKind int
+
+const (
+	// TritoneSubstitution replaces a dominant chord with the dominant chord
+	// whose root is a tritone away, like D♭7 for G7. The two chords share
+	// their 3rd and 7th.
+	TritoneSubstitution SubstitutionKind = iota + 1
+	// RelativeSubstitution replaces a major chord with its relative minor,
+	// like A-7 for C△7, or a minor chord with its relative major. The two
+	// chords share most of their tones.
+	RelativeSubstitution
+	// DiminishedPassingChord is a fully diminished chord whose root is a
+	// half-step below the chord's root, like C♯o before D-7. It is played
+	// before the chord (usually taking some of its beats) instead of
+	// replacing it.
+	DiminishedPassingChord
+	// BackdoorDominant replaces the V7 chord of the key with the ♭VII7 chord,
+	// like B♭7 for G7 in C, which resolves to the tonic from a whole-step
+	// below.
+	BackdoorDominant
+)
+
+// String implements the Stringer interface.
+func (k SubstitutionKind) String() string {
+	switch k {
+	case TritoneSubstitution:
+		return "tritone substitution"
+	case RelativeSubstitution:
+		return "relative substitution"
+	case DiminishedPassingChord:
+		return "diminished passing chord"
+	case BackdoorDominant:
+		return "backdoor dominant"
+	default:
+		return fmt.Sprintf("?(%d)", k)
+	}
+}
+
+// Substitution is a suggested reharmonization of a chord. (See
+// Substitutions.)
+type Substitution struct {
+	// Kind is the technique used to find the substitute.
+	Kind SubstitutionKind
+	// Chord is the substitute chord.
+	Chord *Chord
+	// Passing is true if the substitute is played before the original chord
+	// instead of replacing it.
+	Passing bool
+}
+
+// String implements the Stringer interface. The result is the substitute
+// chord followed by the technique in parentheses, like
+// "D♭7 (tritone substitution)".
+func (s Substitution) String() string {
+	return fmt.Sprintf("%v (%v)", s.Chord, s.Kind)
+}
+
+// Substitutions suggests chords that can replace the given chord in the given
+// key. The suggestions are returned in this order:
+//
+//   - A tritone substitution, if the chord is a dominant 7th chord. The
+//     substitute keeps the chord's extensions and is spelled with flats, since
+//     it usually resolves down by a half-step: D♭7 for G7 and G♭7♭9 for C7♭9.
+//   - A relative substitution, if the chord is a major or minor triad or 7th
+//     chord and the substitute is diatonic to the key. Only the triad and 7th
+//     are kept: in C, A- is suggested for C and A-7 for C△7, but no relative
+//     substitution is suggested for E♭△7 (since C-7 is not diatonic to C).
+//   - A diminished passing chord, unless the chord is diminished. It is
+//     played before the chord, so its Passing field is true.
+//   - A backdoor dominant, if the chord is the V7 of the key.
+func Substitutions(ch *Chord, key Key) []Substitution {
+	var subs []Substitution
+	q := qualityOf(ch)
+	hasSeventh := false
+	for _, tn := range ch.decompose().tones {
+		if tn.Val == 7 {
+			hasSeventh = true
+		}
+	}
+
+	if q == dominantQuality {
+		sub := ch.Clone()
+		sub.Root = ch.Root.TransposeHalfSteps(6, PreferFlats)
+		sub.Bass = Note{}
+		sub.Canonicalize()
+		subs = append(subs, Substitution{Kind: TritoneSubstitution, Chord: sub})
+	}
+
+	var relative *Chord
+	switch {
+	case q == majorTriadQuality && !hasSeventh, q == majorSeventhQuality:
+		relative = &Chord{Root: ch.Root.TransposeDown(Interval{Val: 3, Offset: -1}), Triad: Min3}
+		if hasSeventh {
+			relative.ExtraTones = []ChordTone{{Val: 7}}
+		}
+	case q == minorQuality:
+		relative = &Chord{Root: ch.Root.Transpose(Interval{Val: 3, Offset: -1}), Triad: Maj3}
+		if hasSeventh {
+			relative.ExtraTones = []ChordTone{{Val: 7, Acc: Sharp}}
+		}
+	}
+	if relative != nil {
+		set := pitchClasses(relative.Spell())
+		if set&pitchClasses(key.Scale().Spell()) == set {
+			subs = append(subs, Substitution{Kind: RelativeSubstitution, Chord: relative})
+		}
+	}
+
+	if ch.Triad != Dim3 && ch.Triad != FDim {
+		passing := &Chord{Root: ch.Root.TransposeDown(Interval{Val: 2, Offset: -1}), Triad: FDim}
+		subs = append(subs, Substitution{Kind: DiminishedPassingChord, Chord: passing, Passing: true})
+	}
+
+	if q == dominantQuality && key.Tonic.IntervalTo(ch.Root).NumHalfSteps() == 7 {
+		backdoor := &Chord{
+			Root:       key.Tonic.TransposeDown(Interval{Val: 2}),
+			Triad:      Maj3,
+			ExtraTones: []ChordTone{{Val: 7}},
+		}
+		subs = append(subs, Substitution{Kind: BackdoorDominant, Chord: backdoor})
+	}
+	return subs
+}
@@ -0,0 +1,33 @@
+package chords
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestSubstitutions(t *testing.T) {
+	cases := []struct {
+		key, chord, exp string
+	}{
+		{"C", "G7", "D♭7 (tritone substitution), F♯o (diminished passing chord), B♭7 (backdoor dominant)"},
+		{"C", "C7♭9", "G♭7♭9 (tritone substitution), Bo (diminished passing chord)"},
+		{"C", "C△7", "A-7 (relative substitution), Bo (diminished passing chord)"},
+		{"C", "D-", "F (relative substitution), C♯o (diminished passing chord)"},
+		{"C", "E♭△7", "Do (diminished passing chord)"},
+		{"Am", "E7", "B♭7 (tritone substitution), D♯o (diminished passing chord), G7 (backdoor dominant)"},
+		{"C", "Bo", ""},
+	}
+	for _, tc := range cases {
+		key, err := ParseKey(tc.key)
+		if err != nil {
+			t.Fatalf("failed to parse key %q: %v", tc.key, err)
+		}
+		var strs []string
+		for _, sub := range Substitutions(MustParseChord(tc.chord), key) {
+			strs = append(strs, sub.String())
+		}
+		if actual := strings.Join(strs, ", "); actual != tc.exp {
+			t.Errorf("Substitutions for %s in %s returned wrong value: %q != %q", tc.chord, tc.key, actual, tc.exp)
+		}
+	}
+}
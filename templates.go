@@ -0,0 +1,155 @@
+package chords
+
+import (
+	"bytes"
+	"sync"
+)
+
+// Template is a standard chord progression, like a 12-bar blues or rhythm
+// changes, that is independent of key. Its chords are ScaleChords, relative
+// to the tonic of the key. Use InKey to realize the template in a particular
+// key.
+type Template struct {
+	// Name is the name of the template, like "12-bar blues".
+	Name string
+	// Minor is true if the template is in a minor key. Its chords are
+	// relative to the tonic of the minor key.
+	Minor bool
+	// BeatsPerBar is the number of beats in each bar.
+	BeatsPerBar int
+	// Bars are the bars of the template, in order.
+	Bars [][]TemplateChord
+}
+
+// TemplateChord is a chord in one of the bars of a Template, along with how
+// long it is played.
+type TemplateChord struct {
+	// Chord is the chord, relative to the tonic of the key.
+	Chord *ScaleChord
+	// Beats is the number of beats for which the chord is played.
+	Beats int
+}
+
+// InKey returns the template realized in the key with the given tonic. For
+// example, the 12-bar blues with a tonic of F is a progression that starts
+// with F7 and B♭7.
+func (t *Template) InKey(tonic Note) *Progression {
+	p := &Progression{BeatsPerBar: t.BeatsPerBar, Bars: make([]Bar, len(t.Bars))}
+	for i, bar := range t.Bars {
+		for _, tc := range bar {
+			p.Bars[i].Chords = append(p.Bars[i].Chords, BarChord{Chord: tc.Chord.InKey(tonic), Beats: tc.Beats})
+		}
+	}
+	return p
+}
+
+// String implements the Stringer interface. The result is a chart in the same
+// format as Progression.String, but with roman numerals (see
+// ScaleChord.String) instead of chords. For example, the Andalusian cadence is
+// "| i | VII | VI | V |".
+func (t *Template) String() string {
+	var b bytes.Buffer
+	for _, bar := range t.Bars {
+		b.WriteString("| ")
+		unit := 0
+		for _, tc := range bar {
+			unit = gcd(unit, tc.Beats)
+		}
+		for _, tc := range bar {
+			b.WriteString(tc.Chord.String())
+			b.WriteByte(' ')
+			for i := unit; i < tc.Beats; i += unit {
+				b.WriteString("/ ")
+			}
+		}
+	}
+	if len(t.Bars) > 0 {
+		b.WriteByte('|')
+	}
+	return b.String()
+}
+
+// templateCharts are the charts of the built-in templates. The first name for
+// each is its canonical name; the rest are aliases. Charts are in the key of C
+// major or A minor.
+var templateCharts = []struct {
+	names []string
+	minor bool
+	chart string
+}{
+	{[]string{"12-bar blues", "blues"}, false,
+		"| C7 | C7 | C7 | C7 | F7 | F7 | C7 | C7 | G7 | F7 | C7 | G7 |"},
+	{[]string{"quick-change blues"}, false,
+		"| C7 | F7 | C7 | C7 | F7 | F7 | C7 | C7 | G7 | F7 | C7 | G7 |"},
+	{[]string{"jazz blues"}, false,
+		"| C7 | F7 | C7 | G-7 C7 | F7 | F♯o | C7 | A7 | D-7 | G7 | C7 A7 | D-7 G7 |"},
+	{[]string{"bird blues", "bird changes"}, false,
+		"| C△7 | Bø E7 | A-7 D7 | G-7 C7 | F7 | F-7 B♭7 | E-7 A7 | E♭-7 A♭7 | D-7 | G7 | C△7 A7 | D-7 G7 |"},
+	{[]string{"minor blues"}, true,
+		"| A-7 | A-7 | A-7 | A-7 | D-7 | D-7 | A-7 | A-7 | F7 | E7 | A-7 | E7 |"},
+	{[]string{"rhythm changes"}, false,
+		"| C A-7 | D-7 G7 | C A-7 | D-7 G7 | C C7 | F F♯o | C A7 | D-7 G7 " +
+			"| C A-7 | D-7 G7 | C A-7 | D-7 G7 | C C7 | F F♯o | C G7 | C " +
+			"| E7 | E7 | A7 | A7 | D7 | D7 | G7 | G7 " +
+			"| C A-7 | D-7 G7 | C A-7 | D-7 G7 | C C7 | F F♯o | C G7 | C |"},
+	{[]string{"ii-V-I", "two-five-one"}, false,
+		"| D-7 | G7 | C△7 | C△7 |"},
+	{[]string{"minor ii-V-i", "minor two-five-one"}, true,
+		"| Bø | E7 | A-7 | A-7 |"},
+	{[]string{"pachelbel", "pachelbel's canon"}, false,
+		"| C G | A- E- | F C | F G |"},
+	{[]string{"andalusian cadence"}, true,
+		"| A- | G | F | E |"},
+	{[]string{"doo-wop", "50s progression"}, false,
+		"| C | A- | F | G |"},
+	{[]string{"axis", "pop-punk"}, false,
+		"| C | G | A- | F |"},
+}
+
+var (
+	templatesOnce sync.Once
+	templates     []*Template
+)
+
+// Templates returns all of the built-in templates: the 12-bar blues (plus
+// quick-change, jazz, bird, and minor variants), rhythm changes, major and
+// minor ii-V-I, Pachelbel's canon, the Andalusian cadence, the doo-wop (aka
+// 50s) progression, and the axis (I-V-vi-IV) progression.
+func Templates() []*Template {
+	templatesOnce.Do(func() {
+		for _, entry := range templateCharts {
+			key := Key{Tonic: Note{N: C}, Minor: entry.minor}
+			if entry.minor {
+				key.Tonic = Note{N: A}
+			}
+			p := MustParseProgression(entry.chart)
+			t := &Template{Name: entry.names[0], Minor: entry.minor, BeatsPerBar: p.BeatsPerBar}
+			for _, bar := range p.Bars {
+				tcs := make([]TemplateChord, len(bar.Chords))
+				for i, bc := range bar.Chords {
+					tcs[i] = TemplateChord{Chord: key.ScaleChord(bc.Chord), Beats: bc.Beats}
+				}
+				t.Bars = append(t.Bars, tcs)
+			}
+			templates = append(templates, t)
+		}
+	})
+	return templates
+}
+
+// LookupTemplate returns the built-in template with the given name, or nil if
+// there is no such template. Names are not case-sensitive, and words may be
+// separated by spaces, hyphens, or underscores. So "Rhythm Changes" and
+// "rhythm_changes" are the same. Some templates have aliases: "blues" is the
+// same as "12-bar blues", and "50s progression" is the same as "doo-wop".
+func LookupTemplate(name string) *Template {
+	name = normalizeScaleName(name)
+	for i, entry := range templateCharts {
+		for _, n := range entry.names {
+			if normalizeScaleName(n) == name {
+				return Templates()[i]
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,49 @@
+package chords
+
+import (
+	"testing"
+)
+
+func TestTemplates(t *testing.T) {
+	for _, tmpl := range Templates() {
+		if LookupTemplate(tmpl.Name) != tmpl {
+			t.Errorf("failed to look up template %q", tmpl.Name)
+		}
+		if len(tmpl.Bars) == 0 {
+			t.Errorf("template %q has no bars", tmpl.Name)
+		}
+	}
+	if LookupTemplate("Rhythm_Changes") != LookupTemplate("rhythm changes") {
+		t.Errorf("names should be normalized")
+	}
+	if LookupTemplate("50s progression") != LookupTemplate("doo-wop") {
+		t.Errorf("aliases should be supported")
+	}
+	if LookupTemplate("nope") != nil {
+		t.Errorf("LookupTemplate for nope returned wrong value: %v", LookupTemplate("nope"))
+	}
+
+	cases := []struct {
+		name, tonic, numerals, chords string
+	}{
+		{"12-bar blues", "F", "| I7 | I7 | I7 | I7 | IV7 | IV7 | I7 | I7 | V7 | IV7 | I7 | V7 |",
+			"| F7 | F7 | F7 | F7 | B♭7 | B♭7 | F7 | F7 | C7 | B♭7 | F7 | C7 |"},
+		{"jazz blues", "B♭", "| I7 | IV7 | I7 | v7 I7 | IV7 | ♯ivo | I7 | VI7 | ii7 | V7 | I7 VI7 | ii7 V7 |",
+			"| B♭7 | E♭7 | B♭7 | F-7 B♭7 | E♭7 | Eo | B♭7 | G7 | C-7 | F7 | B♭7 G7 | C-7 F7 |"},
+		{"andalusian cadence", "D", "| i | VII | VI | V |", "| D- | C | B♭ | A |"},
+		{"pachelbel", "D", "| I V | vi iii | IV I | IV V |", "| D A | B- F♯- | G D | G A |"},
+	}
+	for _, tc := range cases {
+		tmpl := LookupTemplate(tc.name)
+		if actual := tmpl.String(); actual != tc.numerals {
+			t.Errorf("Templates for %s returned wrong value: %q != %q", tc.name, actual, tc.numerals)
+		}
+		tonic, err := ParseNote(tc.tonic)
+		if err != nil {
+			t.Fatalf("failed to parse note %q: %v", tc.tonic, err)
+		}
+		if actual := tmpl.InKey(tonic).String(); actual != tc.chords {
+			t.Errorf("Templates for %s in %s returned wrong value: %q != %q", tc.name, tc.tonic, actual, tc.chords)
+		}
+	}
+}
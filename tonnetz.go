@@ -0,0 +1,183 @@
+package chords
+
+import "fmt"
+
+// TonnetzPoint is a position on the Tonnetz, the lattice on which each note is
+// a perfect 5th away from its neighbors along one axis and a major 3rd away
+// from its neighbors along the other. Each major and minor triad is a triangle
+// of adjacent points, and triads that share an edge are one neo-Riemannian
+// transformation apart. (See Transformation.)
+//
+// The lattice repeats: moving four 5ths along and one 3rd down, or three 3rds
+// up, arrives at the same pitch class. So many points have the same pitch
+// class. (See TonnetzPointOf.)
+type TonnetzPoint struct {
+	// Fifths is the number of perfect 5ths up from C.
+	Fifths int
+	// Thirds is the number of major 3rds up from C.
+	Thirds int
+}
+
+// String implements the Stringer interface. The result is the two coordinates
+// in parentheses, like "(1,2)".
+func (p TonnetzPoint) String() string {
+	return fmt.Sprintf("(%d,%d)", p.Fifths, p.Thirds)
+}
+
+// PitchClass returns the pitch class of the point, from 0 for C to 11 for B.
+// (See PitchClass.)
+func (p TonnetzPoint) PitchClass() int {
+	return posModInt(7*p.Fifths+4*p.Thirds, 12)
+}
+
+// TonnetzPointOf returns the point on the Tonnetz for the given note. Of all
+// of the points for its pitch class, the result is the one for which Fifths
+// is from 0 to 3 and Thirds is from 0 to 2. So C is (0,0), E is (0,1), and G
+// is (1,0). Enharmonic notes have the same point.
+func TonnetzPointOf(n Note) TonnetzPoint {
+	pc := PitchClass(n)
+	// within these bounds, every pitch class has exactly one point
+	for f := 0; f < 4; f++ {
+		for t := 0; t < 3; t++ {
+			if p := (TonnetzPoint{Fifths: f, Thirds: t}); p.PitchClass() == pc {
+				return p
+			}
+		}
+	}
+	panic("unreachable")
+}
+
+// TonnetzTriangle returns the three adjacent points on the Tonnetz for the
+// triad of the given chord, starting with its root, then its 3rd, then its
+// 5th. The root is the point returned by TonnetzPointOf. The triad of a
+// major chord points up and the triad of a minor chord points down: the 3rd
+// of a major triad is a major 3rd above its root, and the 3rd of a minor
+// triad is a major 3rd below its 5th.
+//
+// Only the triad of the chord is placed: its extra tones and bass note are
+// ignored, so C7/E is placed the same as C. This returns an error if the
+// chord's triad is not major or minor.
+func TonnetzTriangle(ch *Chord) ([3]TonnetzPoint, error) {
+	if ch.Triad != Maj3 && ch.Triad != Min3 {
+		return [3]TonnetzPoint{}, fmt.Errorf("%v cannot be placed on the Tonnetz: it is not a major or minor chord", ch)
+	}
+	root := TonnetzPointOf(ch.Root)
+	fifth := TonnetzPoint{Fifths: root.Fifths + 1, Thirds: root.Thirds}
+	third := TonnetzPoint{Fifths: root.Fifths, Thirds: root.Thirds + 1}
+	if ch.Triad == Min3 {
+		third = TonnetzPoint{Fifths: fifth.Fifths, Thirds: fifth.Thirds - 1}
+	}
+	return [3]TonnetzPoint{root, third, fifth}, nil
+}
+
+// tonnetzTriad identifies the triad of a chord on the Tonnetz by an index
+// from 0 to 23: twice the pitch class of its root, plus one if it is minor.
+func tonnetzTriad(ch *Chord) (int, error) {
+	if ch.Triad != Maj3 && ch.Triad != Min3 {
+		return 0, fmt.Errorf("%v cannot be placed on the Tonnetz: it is not a major or minor chord", ch)
+	}
+	t := 2 * PitchClass(ch.Root)
+	if ch.Triad == Min3 {
+		t++
+	}
+	return t, nil
+}
+
+// tonnetzNeighbor returns the index of the triad that results from applying
+// the given transformation to the triad with the given index. (See
+// tonnetzTriad.)
+func tonnetzNeighbor(triad int, t Transformation) int {
+	root, minor := triad/2, triad%2 == 1
+	switch t {
+	case Leittonwechsel:
+		if minor {
+			root -= 4
+		} else {
+			root += 4
+		}
+	case Relative:
+		if minor {
+			root += 3
+		} else {
+			root -= 3
+		}
+	}
+	next := 2 * posModInt(root, 12)
+	if !minor {
+		next++
+	}
+	return next
+}
+
+// TonnetzPath returns the shortest sequence of P, L, and R transformations
+// that turns the triad of the first chord into the triad of the second, like
+// "RL" from C to F or "PR" from C to E♭. (See Transform.) Chords are compared
+// by pitch class, so enharmonic triads are the same, and the path between a
+// triad and itself is empty. If there are several shortest paths, the result
+// prefers P, then L, then R, at each step.
+//
+// As with TonnetzTriangle, only the triads of the chords are considered, and
+// this returns an error if either chord's triad is not major or minor.
+func TonnetzPath(a, b *Chord) ([]Transformation, error) {
+	from, err := tonnetzTriad(a)
+	if err != nil {
+		return nil, err
+	}
+	to, err := tonnetzTriad(b)
+	if err != nil {
+		return nil, err
+	}
+	// breadth-first search of the 24 triads
+	type step struct {
+		prev int
+		t    Transformation
+	}
+	var steps [24]*step
+	steps[from] = &step{prev: -1}
+	queue := []int{from}
+	for len(queue) > 0 && steps[to] == nil {
+		cur := queue[0]
+		queue = queue[1:]
+		for _, t := range []Transformation{Parallel, Leittonwechsel, Relative} {
+			next := tonnetzNeighbor(cur, t)
+			if steps[next] == nil {
+				steps[next] = &step{prev: cur, t: t}
+				queue = append(queue, next)
+			}
+		}
+	}
+	var path []Transformation
+	for cur := to; cur != from; cur = steps[cur].prev {
+		path = append([]Transformation{steps[cur].t}, path...)
+	}
+	return path, nil
+}
+
+// TonnetzDistance returns the number of P, L, and R transformations in the
+// shortest path from the triad of the first chord to the triad of the second.
+// (See TonnetzPath.) Triads that share two notes are one step apart, so the
+// smaller the distance, the smoother the move from one chord to the other.
+func TonnetzDistance(a, b *Chord) (int, error) {
+	path, err := TonnetzPath(a, b)
+	return len(path), err
+}
+
+// TonnetzDistances returns the distance on the Tonnetz from each of the given
+// chords to the next, so the result has one fewer element than the given
+// chords. (See TonnetzDistance.) The sum or average of the distances measures
+// how smooth a progression is: how close together its chords are. This
+// returns an error if any of the chords cannot be placed on the Tonnetz.
+func TonnetzDistances(chs []*Chord) ([]int, error) {
+	if len(chs) < 2 {
+		return nil, nil
+	}
+	dists := make([]int, len(chs)-1)
+	for i := range dists {
+		d, err := TonnetzDistance(chs[i], chs[i+1])
+		if err != nil {
+			return nil, err
+		}
+		dists[i] = d
+	}
+	return dists, nil
+}
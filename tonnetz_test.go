@@ -0,0 +1,128 @@
+package chords
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestTonnetzPointOf(t *testing.T) {
+	seen := map[TonnetzPoint]bool{}
+	for _, n := range []string{"C", "C♯", "D", "E♭", "E", "F", "F♯", "G", "A♭", "A", "B♭", "B"} {
+		note := MustParseNote(n)
+		p := TonnetzPointOf(note)
+		if p.PitchClass() != PitchClass(note) {
+			t.Errorf("%s: point %v has wrong pitch class %d", n, p, p.PitchClass())
+		}
+		if seen[p] {
+			t.Errorf("%s: point %v already used", n, p)
+		}
+		seen[p] = true
+	}
+	if p := TonnetzPointOf(MustParseNote("D♭")); p != TonnetzPointOf(MustParseNote("C♯")) {
+		t.Errorf("TonnetzPointOf for D♭ returned wrong value: %v != %v", p, TonnetzPointOf(MustParseNote("C♯")))
+	}
+}
+
+func TestTonnetzTriangle(t *testing.T) {
+	cases := []struct {
+		chord string
+		exp   string
+	}{
+		{chord: "C", exp: "[(0,0) (0,1) (1,0)]"},
+		{chord: "C-", exp: "[(0,0) (1,-1) (1,0)]"},
+		{chord: "A-7/G", exp: "[(3,0) (4,-1) (4,0)]"},
+		{chord: "E♭", exp: "[(1,2) (1,3) (2,2)]"},
+	}
+	for _, tc := range cases {
+		ch := MustParseChord(tc.chord)
+		tri, err := TonnetzTriangle(ch)
+		if err != nil {
+			t.Errorf("TonnetzTriangle for %s failed: %v", tc.chord, err)
+			continue
+		}
+		if actual := fmt.Sprint(tri); actual != tc.exp {
+			t.Errorf("TonnetzTriangle for %s returned wrong value: %s != %s", tc.chord, actual, tc.exp)
+		}
+		notes := (&Chord{Root: ch.Root, Triad: ch.Triad}).Spell()
+		for i, p := range tri {
+			if p.PitchClass() != PitchClass(notes[i]) {
+				t.Errorf("%s: point %v should be %v", tc.chord, p, notes[i])
+			}
+		}
+	}
+	if _, err := TonnetzTriangle(MustParseChord("Cdim")); err == nil {
+		t.Errorf("TonnetzTriangle for Cdim should have failed")
+	}
+}
+
+func TestTonnetzPath(t *testing.T) {
+	cases := []struct {
+		a, b string
+		exp  string
+	}{
+		{a: "C", b: "C", exp: ""},
+		{a: "C", b: "B♯", exp: ""},
+		{a: "C", b: "C-", exp: "P"},
+		{a: "C", b: "E-", exp: "L"},
+		{a: "C", b: "A-7", exp: "R"},
+		{a: "C", b: "F", exp: "RL"},
+		{a: "C", b: "G7", exp: "LR"},
+		{a: "C", b: "E♭", exp: "PR"},
+		{a: "C", b: "C♯-", exp: "LPR"},
+	}
+	for _, tc := range cases {
+		path, err := TonnetzPath(MustParseChord(tc.a), MustParseChord(tc.b))
+		if err != nil {
+			t.Errorf("TonnetzPath for %s to %s failed: %v", tc.a, tc.b, err)
+			continue
+		}
+		if actual := TransformationsString(path); actual != tc.exp {
+			t.Errorf("TonnetzPath for %s to %s returned wrong value: %q != %q", tc.a, tc.b, actual, tc.exp)
+		}
+		// the path leads from one triad to the other
+		a, b := MustParseChord(tc.a), MustParseChord(tc.b)
+		chs, err := Transform(&Chord{Root: a.Root, Triad: a.Triad}, path...)
+		if err != nil {
+			t.Errorf("TonnetzPath for %s to %s failed: %v", tc.a, tc.b, err)
+		} else if len(chs) > 0 && chs[len(chs)-1].PitchClassSet() != (&Chord{Root: b.Root, Triad: b.Triad}).PitchClassSet() {
+			t.Errorf("%s to %s: path leads to %v", tc.a, tc.b, chs[len(chs)-1])
+		}
+	}
+
+	// no triad is more than five steps from any other
+	max := 0
+	for _, a := range []string{"C", "C-"} {
+		for i := 0; i < 12; i++ {
+			for _, q := range []TriadType{Maj3, Min3} {
+				b := &Chord{Root: MustParseNote("C").Transpose(Interval{Val: 1, Offset: int8(i)}), Triad: q}
+				d, err := TonnetzDistance(MustParseChord(a), b)
+				if err != nil {
+					t.Fatalf("TonnetzPath for %s to %v failed: %v", a, b, err)
+				}
+				if d > max {
+					max = d
+				}
+			}
+		}
+	}
+	if max != 5 {
+		t.Errorf("TonnetzDistance returned wrong greatest distance: %d != 5", max)
+	}
+
+	if _, err := TonnetzDistance(MustParseChord("C"), MustParseChord("Csus4")); err == nil {
+		t.Errorf("TonnetzDistance for Csus4 should have failed")
+	}
+}
+
+func TestTonnetzDistances(t *testing.T) {
+	dists, err := TonnetzDistances(MustParseProgression("| C | A- | F | G7 |").Chords())
+	if err != nil {
+		t.Fatalf("TonnetzDistances failed: %v", err)
+	}
+	if actual := fmt.Sprint(dists); actual != "[1 1 4]" {
+		t.Errorf("TonnetzDistances returned wrong value: %s != [1 1 4]", actual)
+	}
+	if _, err := TonnetzDistances([]*Chord{MustParseChord("C"), MustParseChord("Bø")}); err == nil {
+		t.Errorf("TonnetzDistances for Bø should have failed")
+	}
+}
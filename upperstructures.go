@@ -0,0 +1,146 @@
+package chords
+
+import (
+	"bytes"
+	"sort"
+)
+
+// UpperStructure is a triad that can be played above a chord to add tensions
+// to it. For example, an A♭ triad above C7 adds the ♯9 (E♭) and ♭13 (A♭).
+// (See Chord.UpperStructures.)
+type UpperStructure struct {
+	// Triad is the triad, a major or minor chord with no extra tones.
+	Triad *Chord
+	// Interval is the interval from the root of the chord to the root of the
+	// triad. For A♭ above C7, it is a minor 6th.
+	Interval Interval
+	// Tensions are the tones of the triad that are not the root, 3rd, 5th, or
+	// 7th of the chord, from lowest to highest. For A♭ above C7, they are ♯9
+	// and ♭13.
+	Tensions []ChordTone
+}
+
+// String implements the Stringer interface. The result is the triad followed
+// by its tensions in parentheses, like "A♭ (♯9 ♭13)".
+func (u UpperStructure) String() string {
+	var b bytes.Buffer
+	b.WriteString(u.Triad.String())
+	b.WriteString(" (")
+	for i, tn := range u.Tensions {
+		if i > 0 {
+			b.WriteByte(' ')
+		}
+		b.WriteString(tn.String())
+	}
+	b.WriteByte(')')
+	return b.String()
+}
+
+// upperStructureTensions are the tensions that an upper structure can add,
+// indexed by the number of half-steps above the chord's root. Zero values are
+// not tensions.
+var upperStructureTensions = [12]ChordTone{
+	1: {Val: 9, Acc: Flat},
+	2: {Val: 9},
+	3: {Val: 9, Acc: Sharp},
+	5: {Val: 11},
+	6: {Val: 11, Acc: Sharp},
+	8: {Val: 13, Acc: Flat},
+	9: {Val: 13},
+}
+
+// upperStructureRoots are the intervals used to spell the roots of upper
+// structure triads, indexed by the number of half-steps above the chord's
+// root. Flats are preferred, as in the usual names (like "♭VI") for them.
+var upperStructureRoots = [12]Interval{
+	{Val: 1}, {Val: 2, Offset: -1}, {Val: 2}, {Val: 3, Offset: -1},
+	{Val: 3}, {Val: 4}, {Val: 5, Offset: -1}, {Val: 5},
+	{Val: 6, Offset: -1}, {Val: 6}, {Val: 7, Offset: -1}, {Val: 7},
+}
+
+// UpperStructures returns the major and minor triads that can be played above
+// the chord, adding at least one tension without clashing with it. They are
+// ordered by the interval from the chord's root to the triad's root, with
+// major triads before minor triads that have the same root. For example, the
+// upper structures of C7 include D (9 ♯11 13), E♭ (♯9), G♭ (♭9 ♯11), A♭
+// (♯9 ♭13), and A (♭9 13).
+//
+// The tones of each triad must be tones of the chord or tensions: a 9th, 11th,
+// or 13th, each of which may be altered. A tension clashes if it is a
+// half-step above one of the chord's root, 3rd, 5th, or 7th (so the 11th
+// clashes with the major 3rd of C△7). The ♭9 and ♯9 are only allowed in
+// dominant chords, which also allow tensions a half-step above the 5th (like
+// the ♭13), since the 5th is usually omitted when they are played. If the
+// chord already has extensions, a tension also clashes if it is a half-step
+// away from one of them, so D (with its 9th) is not an upper structure of
+// C7♯9.
+func (ch *Chord) UpperStructures() []UpperStructure {
+	root := ch.Root.Cardinal()
+	dominant := qualityOf(ch) == dominantQuality
+
+	// core has the root, 3rd, 5th, and 7th (or 6th); extensions has the rest
+	core := ch.Clone()
+	core.Bass = Note{}
+	core.ExtraTones = nil
+	for _, tn := range ch.ExtraTones {
+		if tn.Val <= 7 && (tn.Val != 2 || ch.Triad == Sus) {
+			core.ExtraTones = append(core.ExtraTones, tn)
+		}
+	}
+	coreSet := relativePitchClasses(core.Spell(), root)
+	allSet := relativePitchClasses(ch.Spell(), root)
+	extensionSet := allSet &^ coreSet
+	// tensions clash when a half-step above these
+	clashSet := coreSet
+	if dominant {
+		clashSet &^= 1 << 7
+	}
+
+	var structures []UpperStructure
+	for steps := 1; steps < 12; steps++ {
+		for _, triad := range []TriadType{Maj3, Min3} {
+			t := &Chord{Root: ch.Root.Transpose(upperStructureRoots[steps]), Triad: triad}
+			var tensions []ChordTone
+			ok := true
+			for _, n := range t.Spell() {
+				pc := posMod(n.Cardinal()-root, 12)
+				bit := uint16(1) << uint(pc)
+				if coreSet&bit != 0 {
+					continue
+				}
+				tn := upperStructureTensions[pc]
+				below := uint16(1) << uint(posMod(pc-1, 12))
+				above := uint16(1) << uint(posMod(pc+1, 12))
+				switch {
+				case tn.Val == 0,
+					tn.Val == 9 && tn.Acc != Natural && !dominant,
+					clashSet&below != 0 && !(dominant && pc == 1),
+					extensionSet&bit == 0 && extensionSet&(below|above) != 0:
+					ok = false
+				}
+				tensions = append(tensions, tn)
+			}
+			if !ok || len(tensions) == 0 {
+				continue
+			}
+			sort.Slice(tensions, func(i, j int) bool {
+				if tensions[i].Val != tensions[j].Val {
+					return tensions[i].Val < tensions[j].Val
+				}
+				return tensions[i].Acc.Offset() < tensions[j].Acc.Offset()
+			})
+			structures = append(structures, UpperStructure{Triad: t, Interval: upperStructureRoots[steps], Tensions: tensions})
+		}
+	}
+	return structures
+}
+
+// relativePitchClasses returns the set of pitch classes of the given notes,
+// relative to the given pitch class, as a bit set.
+func relativePitchClasses(notes []Note, root int8) uint16 {
+	var set uint16
+	for _, n := range notes {
+		set |= 1 << uint(posMod(n.Cardinal()-root, 12))
+	}
+	return set
+}
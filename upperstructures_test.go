@@ -0,0 +1,40 @@
+package chords
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestChord_UpperStructures(t *testing.T) {
+	cases := []struct {
+		chord string
+		exp   string
+	}{
+		{"C7", "D♭- (♭9 ♭13), D (9 ♯11 13), E♭ (♯9), E♭- (♯9 ♯11), G♭ (♭9 ♯11), G♭- (♭9 ♯11 13), " +
+			"G- (9), A♭ (♯9 ♭13), A (♭9 13), A- (13)"},
+		{"C7♯9", "D♭- (♭9 ♭13), E♭ (♯9), E♭- (♯9 ♯11), G♭ (♭9 ♯11), G♭- (♭9 ♯11 13), A♭ (♯9 ♭13), " +
+			"A (♭9 13), A- (13)"},
+		{"C△7", "D (9 ♯11 13), G (9), A- (13), B- (9 ♯11)"},
+		{"C", "D (9 ♯11 13), A- (13)"},
+		{"F-7", "G (9 ♯11 13), G- (9 11 13), A♭- (♯11), B♭ (11 13), C- (9), E♭ (9 11)"},
+	}
+	for _, tc := range cases {
+		var strs []string
+		for _, us := range MustParseChord(tc.chord).UpperStructures() {
+			strs = append(strs, us.String())
+		}
+		if actual := strings.Join(strs, ", "); actual != tc.exp {
+			t.Errorf("Chord.UpperStructures for %s returned wrong value: %q != %q", tc.chord, actual, tc.exp)
+		}
+	}
+
+	for _, us := range MustParseChord("E♭7").UpperStructures() {
+		if us.Triad.Root.String() == "C♭" && us.Triad.Triad == Maj3 {
+			if us.Interval != (Interval{Val: 6, Offset: -1}) {
+				t.Errorf("wrong interval: %v", us.Interval)
+			}
+			return
+		}
+	}
+	t.Errorf("C♭ should be an upper structure of E♭7")
+}
@@ -0,0 +1,111 @@
+package chords
+
+import "fmt"
+
+// Severity indicates how serious a ValidationIssue is.
+type Severity int
+
+const (
+	// SeverityError is the severity of a problem that makes a chord
+	// invalid: one that would cause Validate to return an error.
+	SeverityError Severity = iota
+	// SeverityWarning is the severity of something that is allowed but is
+	// probably a mistake, like a tone that is listed twice.
+	SeverityWarning
+)
+
+// String implements the Stringer interface.
+func (s Severity) String() string {
+	switch s {
+	case SeverityError:
+		return "error"
+	case SeverityWarning:
+		return "warning"
+	default:
+		return fmt.Sprintf("?(%d)", s)
+	}
+}
+
+// ValidationIssue is a problem with a chord, as found by Chord.ValidateAll.
+type ValidationIssue struct {
+	// Severity indicates whether the issue makes the chord invalid.
+	Severity Severity
+	// Message describes the issue.
+	Message string
+}
+
+// String implements the Stringer interface. The result is the severity
+// followed by the message, like "warning: tone 9 is listed more than once".
+func (i ValidationIssue) String() string {
+	return i.Severity.String() + ": " + i.Message
+}
+
+// ValidateAll checks the chord like Validate, but instead of stopping at the
+// first problem, it returns every problem it finds, for editors that show all
+// of a chord's diagnostics at once. Problems that make the chord invalid are
+// errors, and the first of them is the error returned by Validate.
+//
+// If the chord has no errors, it is also checked for things that are allowed
+// but are probably mistakes, which are reported as warnings:
+//   - a tone that is listed more than once, like C7 9 9
+//   - two tones that are the same degree, like the 6 and 13 of C6 13
+//   - two tones that are the same pitch, like the ♯11 and ♭5 of C7♯11♭5
+//   - a natural 11th with a major 3rd, which clash, like C7 11
+//   - a bass note that is the root, like C/C, or that is not one of the
+//     chord's tones, like C/D
+//
+// Errors are listed first, and this returns nil if there are no problems.
+func (ch *Chord) ValidateAll() []ValidationIssue {
+	var issues []ValidationIssue
+	ch.validate(func(err error) bool {
+		issues = append(issues, ValidationIssue{Severity: SeverityError, Message: err.Error()})
+		return true
+	})
+	if len(issues) > 0 {
+		return issues
+	}
+	warn := func(format string, args ...interface{}) {
+		issues = append(issues, ValidationIssue{Severity: SeverityWarning, Message: fmt.Sprintf(format, args...)})
+	}
+
+	for i, tn := range ch.ExtraTones {
+		for _, other := range ch.ExtraTones[:i] {
+			switch {
+			case tn == other:
+				warn("tone %v is listed more than once", tn)
+			case sameDegree(tn, other) && tn.Acc == other.Acc && ch.Triad != Sus:
+				warn("tones %v and %v are the same degree", other, tn)
+			}
+		}
+	}
+
+	upper := ch.Clone()
+	upper.Bass = Note{}
+	notes := upper.Spell()
+	for i, n := range notes {
+		for _, other := range notes[:i] {
+			if n != other && PitchClass(n) == PitchClass(other) {
+				warn("%v and %v are the same pitch", other, n)
+			}
+		}
+	}
+
+	if ch.Triad == Maj3 || ch.Triad == Aug3 {
+		for _, tn := range ch.ExtraTones {
+			if (tn.Val == 4 || tn.Val == 11) && tn.Acc == Natural {
+				warn("natural %v clashes with the major 3rd", tn)
+				break
+			}
+		}
+	}
+
+	if ch.Bass.N != 0 {
+		switch {
+		case PitchClass(ch.Bass) == PitchClass(ch.Root):
+			warn("bass note %v is the root", ch.Bass)
+		case !upper.PitchClassSet().Contains(PitchClass(ch.Bass)):
+			warn("bass note %v is not a chord tone", ch.Bass)
+		}
+	}
+	return issues
+}
@@ -0,0 +1,45 @@
+package chords
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestChord_ValidateAll(t *testing.T) {
+	cases := []struct {
+		chord string
+		exp   string
+	}{
+		{chord: "C△7", exp: "[]"},
+		{chord: "C/E", exp: "[]"},
+		{chord: "Csus2 4", exp: "[]"},
+		{chord: "C7 9 9", exp: "[warning: tone 9 is listed more than once]"},
+		{chord: "C6 13", exp: "[warning: tones 6 and 13 are the same degree]"},
+		{chord: "C7♯11♭5", exp: "[warning: G♭ and F♯ are the same pitch]"},
+		{chord: "C7 11", exp: "[warning: natural 11 clashes with the major 3rd]"},
+		{chord: "C/C", exp: "[warning: bass note C is the root]"},
+		{chord: "C-7 9 9/D♭", exp: "[warning: tone 9 is listed more than once warning: bass note D♭ is not a chord tone]"},
+		{chord: "C7♭9♯9", exp: "[error: tone 9 has conflicting accidentals: ♭ and ♯]"},
+		{chord: "C+♭5", exp: "[error: augmented chord should not have non-sharp 5th: ♭]"},
+	}
+	for _, tc := range cases {
+		if actual := fmt.Sprint(MustParseChord(tc.chord).ValidateAll()); actual != tc.exp {
+			t.Errorf("Chord.ValidateAll for %s returned wrong value: %s != %s", tc.chord, actual, tc.exp)
+		}
+	}
+
+	// every error is reported, and the first is the one returned by Validate
+	ch := &Chord{Triad: 99, ExtraTones: []ChordTone{{Val: 3}, {Val: 7, Acc: Sharp}, {Val: 7, Acc: Flat}}}
+	issues := ch.ValidateAll()
+	if len(issues) != 4 {
+		t.Fatalf("Chord.ValidateAll returned wrong number of issues: %v", issues)
+	}
+	for _, issue := range issues {
+		if issue.Severity != SeverityError {
+			t.Errorf("Chord.ValidateAll returned wrong severity: %v", issue)
+		}
+	}
+	if err := ch.Validate(); err == nil || err.Error() != issues[0].Message {
+		t.Errorf("Chord.Validate returned wrong error: %v != %q", err, issues[0].Message)
+	}
+}
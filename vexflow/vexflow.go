@@ -0,0 +1,208 @@
+// Package vexflow formats chords and scales for VexFlow, the JavaScript
+// library for rendering music notation in web pages. A server can use this
+// package to spell chords and scales, and the browser only needs to render
+// the results. For example:
+//
+//	ch := chords.MustParseChord("B♭7♯9")
+//	v := ch.Voicings(chords.Close, chords.PitchRange{
+//		Low:  chords.MustParsePitch("B♭3"),
+//		High: chords.MustParsePitch("C6"),
+//	})[0]
+//	vexflow.EasyScoreChord(v, "w")
+//	// (Bb3 D4 F4 Ab4 C#5)/w
+//	vexflow.ChordSymbol(ch)
+//	// new ChordSymbol().addText("B").addGlyph("b").addTextSuperscript("7").addGlyphSuperscript("#").addTextSuperscript("9")
+//
+// Strings for EasyScore (see EasyScoreNote) are given to its notes method,
+// and keys (see Key) are used to construct a StaveNote. ChordSymbol returns a
+// JavaScript expression that expects ChordSymbol to be in scope, like by
+// importing it or with "const { ChordSymbol } = Vex.Flow;".
+package vexflow
+
+import (
+	"bytes"
+	"encoding/json"
+	"strconv"
+	"strings"
+
+	"github.com/jhump/chords"
+)
+
+// accidental returns how the given accidental is written in VexFlow, like "b"
+// for flat and "##" for double-sharp. A natural is the empty string.
+func accidental(acc chords.Accidental) string {
+	if acc < 0 {
+		return strings.Repeat("b", int(-acc))
+	}
+	return strings.Repeat("#", int(acc))
+}
+
+// Key returns the key of the given pitch, as used to construct a StaveNote,
+// like "c/4" for middle C or "bb/3" for the B♭ below it.
+func Key(p chords.Pitch) string {
+	return strings.ToLower(p.Note.N.String()) + accidental(p.Note.Acc) + "/" + octave(p)
+}
+
+// Keys returns the keys of the given pitches, for a StaveNote that plays them
+// all as a chord. (See Key.)
+func Keys(v chords.Voicing) []string {
+	keys := make([]string, len(v))
+	for i, p := range v {
+		keys[i] = Key(p)
+	}
+	return keys
+}
+
+// EasyScoreNote returns the given pitch as an EasyScore note, like "C4" for
+// middle C or "Bb3" for the B♭ below it.
+func EasyScoreNote(p chords.Pitch) string {
+	return p.Note.N.String() + accidental(p.Note.Acc) + octave(p)
+}
+
+// EasyScoreNotes returns the given pitches as a sequence of EasyScore notes,
+// each with the given duration, like "C4/q, E4, G4" for the given duration
+// "q" (a quarter note). In EasyScore, each note has the duration of the one
+// before it unless it has its own, so the duration is only given for the
+// first. Use this for the pitches of an arpeggio (see chords.Arpeggiate) or a
+// scale (see ScaleNotes).
+func EasyScoreNotes(ps []chords.Pitch, duration string) string {
+	var b bytes.Buffer
+	for i, p := range ps {
+		if i > 0 {
+			b.WriteString(", ")
+		}
+		b.WriteString(EasyScoreNote(p))
+		if i == 0 && duration != "" {
+			b.WriteString("/" + duration)
+		}
+	}
+	return b.String()
+}
+
+// EasyScoreChord returns the given voicing as an EasyScore chord with the
+// given duration, like "(C4 E4 G4)/w" for the given duration "w" (a whole
+// note). (See chords.Chord.Voicings.)
+func EasyScoreChord(v chords.Voicing, duration string) string {
+	var b bytes.Buffer
+	b.WriteByte('(')
+	for i, p := range v {
+		if i > 0 {
+			b.WriteByte(' ')
+		}
+		b.WriteString(EasyScoreNote(p))
+	}
+	b.WriteByte(')')
+	if duration != "" {
+		b.WriteString("/" + duration)
+	}
+	return b.String()
+}
+
+// ScaleNotes returns the pitches of the given scale, ascending from its root
+// in the given octave to the root an octave above. So the pitches of C major
+// in octave 4 are C4 D4 E4 F4 G4 A4 B4 C5. (See EasyScoreNotes.)
+func ScaleNotes(sc *chords.Scale, octave int8) []chords.Pitch {
+	notes := sc.Spell()
+	if len(notes) == 0 {
+		return nil
+	}
+	ps := make([]chords.Pitch, 0, len(notes)+1)
+	ps = append(ps, chords.Pitch{Note: notes[0], Octave: octave})
+	for i := 1; i <= len(notes); i++ {
+		prev := ps[len(ps)-1]
+		p := chords.Pitch{Note: notes[i%len(notes)], Octave: prev.Octave}
+		for p.MIDINumber() <= prev.MIDINumber() {
+			p.Octave++
+		}
+		ps = append(ps, p)
+	}
+	return ps
+}
+
+// octave returns the octave number of the given pitch.
+func octave(p chords.Pitch) string {
+	return strconv.Itoa(int(p.Octave))
+}
+
+// symbolGlyphs are the names of the ChordSymbol glyphs used for the symbols
+// in chord names.
+var symbolGlyphs = map[rune][]string{
+	'♭': {"b"},
+	'♯': {"#"},
+	'𝄫': {"b", "b"},
+	'𝄪': {"#", "#"},
+	'+': {"+"},
+	'-': {"-"},
+	'△': {"majorSeventh"},
+	'ø': {"halfDiminished"},
+	'o': {"diminished"},
+}
+
+// symbolPart is a call to a method of a ChordSymbol.
+type symbolPart struct {
+	method string
+	arg    string
+}
+
+// ChordSymbol returns a JavaScript expression that constructs a VexFlow
+// ChordSymbol for the given chord. The root and bass note are written as
+// text, with glyphs for their accidentals, and the rest of the chord's name
+// in canonical form (see chords.Chord.Canonicalize) is written as superscript.
+// Symbols in the name, like ♯, △, and ø, are written with their glyphs.
+func ChordSymbol(ch *chords.Chord) string {
+	name := ch.Canonical().String()
+	root := ch.Root.String()
+	bass := ""
+	if ch.Bass.N != 0 {
+		bass = ch.Bass.String()
+	}
+	suffix := strings.TrimSuffix(strings.TrimPrefix(name, root), "/"+bass)
+
+	var parts []symbolPart
+	// add appends a part, merging text with the text before it
+	add := func(method, arg string) {
+		if n := len(parts); n > 0 && parts[n-1].method == method && strings.HasPrefix(method, "addText") {
+			parts[n-1].arg += arg
+			return
+		}
+		parts = append(parts, symbolPart{method: method, arg: arg})
+	}
+	note := func(n chords.Note) {
+		add("addText", n.N.String())
+		acc := n.Acc
+		for ; acc < 0; acc++ {
+			add("addGlyph", "b")
+		}
+		for ; acc > 0; acc-- {
+			add("addGlyph", "#")
+		}
+	}
+
+	note(ch.Root)
+	for i, r := range suffix {
+		glyphs, ok := symbolGlyphs[r]
+		if r == 'o' && (i > 0 || ch.Triad != chords.FDim) {
+			// the letter o in a word, not the symbol for diminished
+			ok = false
+		}
+		if ok {
+			for _, glyph := range glyphs {
+				add("addGlyphSuperscript", glyph)
+			}
+		} else {
+			add("addTextSuperscript", string(r))
+		}
+	}
+	if bass != "" {
+		add("addGlyph", "/")
+		note(ch.Bass)
+	}
+
+	var b bytes.Buffer
+	b.WriteString("new ChordSymbol()")
+	for _, part := range parts {
+		arg, _ := json.Marshal(part.arg)
+		b.WriteString("." + part.method + "(" + string(arg) + ")")
+	}
+	return b.String()
+}
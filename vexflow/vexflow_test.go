@@ -0,0 +1,86 @@
+package vexflow
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/jhump/chords"
+)
+
+func TestNotes(t *testing.T) {
+	cases := []struct {
+		pitch, key, note string
+	}{
+		{pitch: "C4", key: "c/4", note: "C4"},
+		{pitch: "B♭3", key: "bb/3", note: "Bb3"},
+		{pitch: "F♯5", key: "f#/5", note: "F#5"},
+		{pitch: "E𝄫2", key: "ebb/2", note: "Ebb2"},
+		{pitch: "G𝄪0", key: "g##/0", note: "G##0"},
+	}
+	for _, tc := range cases {
+		p := chords.MustParsePitch(tc.pitch)
+		if actual := Key(p); actual != tc.key {
+			t.Errorf("Key for %s returned wrong value: %q != %q", tc.pitch, actual, tc.key)
+		}
+		if actual := EasyScoreNote(p); actual != tc.note {
+			t.Errorf("EasyScoreNote for %s returned wrong value: %q != %q", tc.pitch, actual, tc.note)
+		}
+	}
+
+	v := chords.Voicing{chords.MustParsePitch("B♭3"), chords.MustParsePitch("D4"), chords.MustParsePitch("F4"), chords.MustParsePitch("A♭4")}
+	if actual := fmt.Sprint(Keys(v)); actual != "[bb/3 d/4 f/4 ab/4]" {
+		t.Errorf("wrong keys: %s", actual)
+	}
+	if actual := EasyScoreChord(v, "w"); actual != "(Bb3 D4 F4 Ab4)/w" {
+		t.Errorf("wrong chord: %s", actual)
+	}
+	if actual := EasyScoreChord(v[:3], ""); actual != "(Bb3 D4 F4)" {
+		t.Errorf("wrong chord: %s", actual)
+	}
+	if actual := EasyScoreNotes(v, "q"); actual != "Bb3/q, D4, F4, Ab4" {
+		t.Errorf("wrong notes: %s", actual)
+	}
+}
+
+func TestScaleNotes(t *testing.T) {
+	cases := []struct {
+		scale  string
+		octave int8
+		exp    string
+	}{
+		{scale: "C major", octave: 4, exp: "C4/8, D4, E4, F4, G4, A4, B4, C5"},
+		{scale: "E♭ major", octave: 4, exp: "Eb4/8, F4, G4, Ab4, Bb4, C5, D5, Eb5"},
+		{scale: "B major", octave: 3, exp: "B3/8, C#4, D#4, E4, F#4, G#4, A#4, B4"},
+	}
+	for _, tc := range cases {
+		ps := ScaleNotes(chords.MustParseScale(tc.scale), tc.octave)
+		if actual := EasyScoreNotes(ps, "8"); actual != tc.exp {
+			t.Errorf("EasyScoreNotes for %s returned wrong value: %q != %q", tc.scale, actual, tc.exp)
+		}
+	}
+}
+
+func TestChordSymbol(t *testing.T) {
+	cases := []struct {
+		chord, exp string
+	}{
+		{chord: "C", exp: `new ChordSymbol().addText("C")`},
+		{chord: "B♭7♯9", exp: `new ChordSymbol().addText("B").addGlyph("b").addTextSuperscript("7").addGlyphSuperscript("#").addTextSuperscript("9")`},
+		{chord: "F♯-7", exp: `new ChordSymbol().addText("F").addGlyph("#").addGlyphSuperscript("-").addTextSuperscript("7")`},
+		{chord: "Co", exp: `new ChordSymbol().addText("C").addGlyphSuperscript("diminished")`},
+		{chord: "Cø", exp: `new ChordSymbol().addText("C").addGlyphSuperscript("halfDiminished")`},
+		{chord: "Cdim", exp: `new ChordSymbol().addText("C").addTextSuperscript("dim")`},
+		{chord: "E♭+", exp: `new ChordSymbol().addText("E").addGlyph("b").addGlyphSuperscript("+")`},
+		{chord: "Gsus4 7", exp: `new ChordSymbol().addText("G").addTextSuperscript("sus4 7")`},
+		{chord: "D♭△7/A♭", exp: `new ChordSymbol().addText("D").addGlyph("b").addGlyphSuperscript("majorSeventh").addTextSuperscript("7").addGlyph("/").addText("A").addGlyph("b")`},
+	}
+	for _, tc := range cases {
+		ch := chords.MustParseChord(tc.chord)
+		if actual := ChordSymbol(ch); actual != tc.exp {
+			t.Errorf("ChordSymbol for %s returned wrong value: %s != %s", tc.chord, actual, tc.exp)
+		}
+		if ch.String() != chords.MustParseChord(tc.chord).String() {
+			t.Errorf("%s: chord was modified: %v", tc.chord, ch)
+		}
+	}
+}
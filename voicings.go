@@ -0,0 +1,535 @@
+package chords
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+)
+
+// Voicing is an arrangement of the notes of a chord as pitches, from lowest
+// to highest. (See Chord.Voicings.)
+type Voicing []Pitch
+
+// String implements the Stringer interface. The result is the pitches,
+// separated by spaces, like "C4 E4 G4 B4".
+func (v Voicing) String() string {
+	var b bytes.Buffer
+	for i, p := range v {
+		if i > 0 {
+			b.WriteByte(' ')
+		}
+		b.WriteString(p.String())
+	}
+	return b.String()
+}
+
+// Range returns the range from the lowest to highest pitch of the voicing.
+func (v Voicing) Range() PitchRange {
+	if len(v) == 0 {
+		return PitchRange{}
+	}
+	return PitchRange{Low: v[0], High: v[len(v)-1]}
+}
+
+// VoicingStyle determines how the notes of a chord are arranged into a
+// voicing. (See Chord.Voicings.)
+type VoicingStyle int
+
+const (
+	// Close voicings have all of the notes of the chord within an octave, each
+	// as close as possible to the one below it. For example, C4 E4 G4 B4 and
+	// E4 G4 B4 C5 are close voicings of C△7.
+	Close VoicingStyle = iota
+	// Drop2 voicings are close voicings with the second-highest pitch dropped
+	// down an octave. For example, the drop-2 voicing of C4 E4 G4 B4 is
+	// G3 C4 E4 B4.
+	Drop2
+	// Drop3 voicings are close voicings with the third-highest pitch dropped
+	// down an octave. For example, the drop-3 voicing of C4 E4 G4 B4 is
+	// E3 C4 G4 B4. They need at least four notes.
+	Drop3
+	// RootlessA voicings are the "A form" of rootless jazz voicings: the 3rd,
+	// 5th, 7th, and 9th of the chord, from lowest to highest, without the
+	// root. For example, E4 G4 B4 D5 for C△7. (See Chord.RootlessVoicing.)
+	RootlessA
+	// RootlessB voicings are the "B form" of rootless jazz voicings: the 7th,
+	// 9th, 3rd, and 5th of the chord, from lowest to highest, without the
+	// root. For example, B3 D4 E4 G4 for C△7. (See Chord.RootlessVoicing.)
+	RootlessB
+	// Shell voicings are the root, 3rd, and 7th of the chord, with the root
+	// lowest. The 3rd and 7th can be in either order, like C3 E3 B3 and
+	// C3 B3 E4 for C△7.
+	Shell
+	// GuideTones voicings are shell voicings without the root: just the 3rd
+	// and 7th of the chord, in either order. For example, E3 B3 and B3 E4 for
+	// C△7.
+	GuideTones
+)
+
+// String implements the Stringer interface.
+func (s VoicingStyle) String() string {
+	switch s {
+	case Close:
+		return "close"
+	case Drop2:
+		return "drop-2"
+	case Drop3:
+		return "drop-3"
+	case RootlessA:
+		return "rootless A"
+	case RootlessB:
+		return "rootless B"
+	case Shell:
+		return "shell"
+	case GuideTones:
+		return "guide tones"
+	default:
+		return fmt.Sprintf("?(%d)", s)
+	}
+}
+
+// defaultVoicingRange is used by Chord.Voicings when the given range is the
+// zero value.
+var defaultVoicingRange = PitchRange{
+	Low:  Pitch{Note: Note{N: C}, Octave: 3},
+	High: Pitch{Note: Note{N: C}, Octave: 6},
+}
+
+// Voicings returns all voicings of the chord in the given style whose pitches
+// are within the given range. If the range is the zero value, C3 to C6 is
+// used. For close and drop voicings, every note of the chord (see Spell) is in
+// each voicing exactly once, and every inversion is included. The voicings are
+// ordered from lowest to highest (by their lowest pitch, and then by their
+// highest pitch).
+//
+// If the chord has a bass note that is one of the chord's tones, only the
+// voicings with that tone as their lowest pitch are returned. If the bass note
+// is not one of the chord's tones, it is added below each voicing of the other
+// tones, as close as possible to the lowest of them.
+//
+// Rootless voicings use only the 3rd, 5th, 7th, and 9th of the chord, and
+// they ignore its bass note. The 3rd is replaced by the suspension in
+// suspended chords, and the 5th is replaced by the 13th if the chord has one.
+// Chords without a 7th use their 6th instead, like C6 (E G A D). If the chord
+// has no 9th, a major 9th is used. Chords with neither a 7th nor a 6th have
+// no rootless voicings.
+//
+// Shell and guide tone voicings also ignore the chord's bass note, and they
+// pick the 3rd and 7th the same way as rootless voicings. Chords with neither
+// a 7th nor a 6th use their 5th instead, so the shell voicings of C are C E G
+// and C G E.
+//
+// This returns nil if the chord has too few notes for the style or if no
+// voicing fits in the range. (See VoicingsWithOptions for other constraints.)
+func (ch *Chord) Voicings(style VoicingStyle, register PitchRange) []Voicing {
+	return ch.VoicingsWithOptions(VoicingOptions{Style: style, Range: register})
+}
+
+// VoicingsWithOptions returns all voicings of the chord that satisfy the given
+// options. It is the same as Voicings, but the options can also limit the
+// span of each voicing, how it is split between hands, and how many notes it
+// has. So the same voicings can be tailored to a piano (two hands, each
+// spanning about an octave), a guitar (six notes in a narrower range), or a
+// string quartet (four notes).
+//
+// When a voicing would have more notes than allowed, tones of the chord are
+// omitted in this order: an unaltered 5th, the root, other tones except the
+// 3rd and 7th (the highest first), and finally the 3rd and 7th. The chord's
+// bass note is never omitted.
+func (ch *Chord) VoicingsWithOptions(opts VoicingOptions) []Voicing {
+	style, register := opts.Style, opts.Range
+	if register == (PitchRange{}) {
+		register = defaultVoicingRange
+	}
+	upper := ch.Clone()
+	upper.Bass = Note{}
+	notes := upper.Spell()
+	// orders are the orders, from lowest to highest, in which the notes can
+	// be arranged before dropping any
+	var orders [][]Note
+	switch style {
+	case Drop2:
+		if len(notes) < 3 {
+			return nil
+		}
+	case Drop3:
+		if len(notes) < 4 {
+			return nil
+		}
+	case RootlessA, RootlessB:
+		notes = upper.rootlessNotes()
+		if notes == nil {
+			return nil
+		}
+		if style == RootlessB {
+			notes = append(notes[2:], notes[:2]...)
+		}
+		orders = [][]Note{notes}
+	case Shell, GuideTones:
+		third, seventh := upper.guideTones()
+		if style == Shell {
+			orders = [][]Note{{upper.Root, third, seventh}, {upper.Root, seventh, third}}
+		} else {
+			orders = [][]Note{{third, seventh}, {seventh, third}}
+		}
+	}
+	if orders != nil {
+		// the other styles ignore the bass
+		ch = upper
+	}
+
+	bassIsTone := false
+	for _, n := range notes {
+		if ch.Bass.N != 0 && n.Cardinal() == ch.Bass.Cardinal() {
+			bassIsTone = true
+		}
+	}
+	if max := opts.MaxNotes; max > 0 {
+		if ch.Bass.N != 0 && !bassIsTone {
+			max--
+		}
+		if orders == nil {
+			notes = ch.omitNotes(notes, max)
+		}
+		for i := range orders {
+			orders[i] = ch.omitNotes(orders[i], max)
+		}
+		if len(notes) == 0 || (len(orders) > 0 && len(orders[0]) == 0) {
+			return nil
+		}
+	}
+	if orders == nil {
+		for i := range notes {
+			orders = append(orders, append(notes[i:len(notes):len(notes)], notes[:i]...))
+		}
+	}
+
+	var voicings []Voicing
+	lowOctave, highOctave := register.Low.Octave-1, register.High.Octave
+	for _, order := range orders {
+		for octave := lowOctave; octave <= highOctave; octave++ {
+			v := make(Voicing, len(order))
+			v[0] = Pitch{Note: order[0], Octave: octave}
+			for i := 1; i < len(order); i++ {
+				v[i] = pitchAbove(order[i], v[i-1])
+			}
+			switch style {
+			case Drop2:
+				v.drop(2)
+			case Drop3:
+				v.drop(3)
+			}
+			if ch.Bass.N != 0 {
+				if bassIsTone {
+					if v[0].Note.Cardinal() != ch.Bass.Cardinal() {
+						continue
+					}
+				} else {
+					bass := pitchAbove(ch.Bass, Pitch{Note: v[0].Note, Octave: v[0].Octave - 1})
+					v = append(Voicing{bass}, v...)
+				}
+			}
+			if register.Contains(v[0]) && register.Contains(v[len(v)-1]) && opts.allows(v) {
+				voicings = append(voicings, v)
+			}
+		}
+	}
+	sort.SliceStable(voicings, func(i, j int) bool {
+		if c := voicings[i][0].Compare(voicings[j][0]); c != 0 {
+			return c < 0
+		}
+		return voicings[i][len(voicings[i])-1].Less(voicings[j][len(voicings[j])-1])
+	})
+	return voicings
+}
+
+// drop moves the nth-highest pitch of the voicing down an octave, keeping the
+// pitches in order.
+func (v Voicing) drop(n int) {
+	i := len(v) - n
+	p := v[i]
+	p.Octave--
+	copy(v[1:i+1], v[:i])
+	v[0] = p
+}
+
+// pitchAbove returns the lowest pitch of the given note that is higher than
+// the given pitch.
+func pitchAbove(n Note, p Pitch) Pitch {
+	ret := Pitch{Note: n, Octave: p.Octave - 1}
+	for ret.MIDINumber() <= p.MIDINumber() {
+		ret.Octave++
+	}
+	return ret
+}
+
+// RootlessVoicing returns the rootless jazz voicing of the chord that best
+// fits the given range: the voicing, in either the A or B form, whose middle
+// is closest to the middle of the range. If the range is the zero value, C3 to
+// C5 is used, which is a typical range for a pianist's left hand. So the form
+// is chosen by register: in the key of C, a ii-V-I uses the A form for D-7 and
+// C△7 and the B form for G7, which keeps the voices close together. (See
+// RootlessA and RootlessB.)
+//
+// This returns nil if the chord has no rootless voicings or none fit in the
+// range.
+func (ch *Chord) RootlessVoicing(register PitchRange) Voicing {
+	if register == (PitchRange{}) {
+		register = PitchRange{
+			Low:  Pitch{Note: Note{N: C}, Octave: 3},
+			High: Pitch{Note: Note{N: C}, Octave: 5},
+		}
+	}
+	middle := register.Low.MIDINumber() + register.High.MIDINumber()
+	var best Voicing
+	bestDist := 0
+	for _, style := range []VoicingStyle{RootlessA, RootlessB} {
+		for _, v := range ch.Voicings(style, register) {
+			dist := v[0].MIDINumber() + v[len(v)-1].MIDINumber() - middle
+			if dist < 0 {
+				dist = -dist
+			}
+			if best == nil || dist < bestDist {
+				best, bestDist = v, dist
+			}
+		}
+	}
+	return best
+}
+
+// rootlessNotes returns the notes of the chord's rootless voicing in its A
+// form: the 3rd, 5th, 7th, and 9th. (See Chord.Voicings.) It returns nil if
+// the chord has neither a 7th nor a 6th.
+func (ch *Chord) rootlessNotes() []Note {
+	tones, ok := ch.jazzTones()
+	if !ok {
+		return nil
+	}
+	notes := make([]Note, len(tones))
+	for i, tn := range tones {
+		notes[i] = ch.toneNote(tn)
+	}
+	return notes
+}
+
+// guideTones returns the notes of the chord's 3rd and 7th. (See
+// Chord.Voicings.) If the chord has neither a 7th nor a 6th, its 5th is
+// returned instead of the 7th.
+func (ch *Chord) guideTones() (third, seventh Note) {
+	tones, ok := ch.jazzTones()
+	if !ok {
+		return ch.toneNote(tones[0]), ch.toneNote(tones[1])
+	}
+	return ch.toneNote(tones[0]), ch.toneNote(tones[2])
+}
+
+// jazzTones returns the 3rd, 5th, 7th, and 9th of the chord, in that order.
+// The 3rd is the suspension in suspended chords, the 5th is the 13th if the
+// chord has one, and the 7th is the 6th if the chord has no 7th. If the chord
+// has no 9th, a major 9th is used. This returns false if the chord has
+// neither a 7th nor a 6th, in which case the 3rd and 5th are still valid.
+func (ch *Chord) jazzTones() ([4]ChordTone, bool) {
+	third := ChordTone{Val: 3}
+	fifth := ch.Triad.fifthTone()
+	var seventh, ninth *ChordTone
+	hasExtension := ch.Triad == HDim || ch.Triad == FDim
+	for i, tn := range ch.ExtraTones {
+		switch tn.Val {
+		case 2, 4:
+			if ch.Triad == Sus && third.Val == 3 {
+				third = tn
+			} else if tn.Val == 2 {
+				ninth = &ch.ExtraTones[i]
+			}
+		case 5:
+			fifth = tn
+		case 6:
+			if seventh == nil {
+				seventh = &ch.ExtraTones[i]
+			}
+		case 7:
+			seventh = &ch.ExtraTones[i]
+		case 9:
+			ninth = &ch.ExtraTones[i]
+			hasExtension = true
+		case 13:
+			fifth = tn
+			hasExtension = true
+		default:
+			hasExtension = hasExtension || tn.Val > 7
+		}
+	}
+	tones := [4]ChordTone{third, fifth, {Val: 7}, {Val: 9}}
+	if seventh != nil {
+		tones[2] = *seventh
+	}
+	if ninth != nil {
+		tones[3] = *ninth
+	}
+	return tones, seventh != nil || hasExtension
+}
+
+// toneNote returns the note of the given tone of the chord, spelled the same
+// way as in Spell.
+func (ch *Chord) toneNote(tn ChordTone) Note {
+	v := tn.Val
+	if v > 7 {
+		v -= 7
+	}
+	return ch.Root.Transpose(Interval{Val: v, Offset: standardIntervals[ch.Triad][v-1] + tn.Acc.Offset()})
+}
+
+// VoicingOptions controls how voicings are built by VoicingsWithOptions and
+// VoiceUnderMelody. The zero value imposes no constraints other than the
+// default range.
+type VoicingOptions struct {
+	// Style is the style of the voicing. The zero value is Close.
+	Style VoicingStyle
+	// Range is the range of pitches allowed in the voicing. If it is the zero
+	// value, VoicingsWithOptions uses C3 to C6, and VoiceUnderMelody allows any
+	// pitch that is up to four octaves below the melody.
+	Range PitchRange
+	// MaxSpan is the largest interval, in half-steps, allowed between the
+	// lowest and highest pitches of the voicing. If zero, there is no limit.
+	MaxSpan int
+	// Hands is the number of hands (or players) among which the pitches of
+	// the voicing are divided, each playing adjacent pitches. It is only used
+	// when MaxHandSpan is non-zero. If zero, one hand is assumed.
+	Hands int
+	// MaxHandSpan is the largest interval, in half-steps, that one hand can
+	// span. A voicing is only allowed if its pitches can be divided among the
+	// hands such that none spans more than this. For example, for a piano this
+	// could be 12 (an octave) with two hands. If zero, there is no limit.
+	MaxHandSpan int
+	// MaxNotes is the largest number of pitches allowed in the voicing. Tones
+	// of the chord are omitted to stay within the limit. (See
+	// VoicingsWithOptions.) If zero, there is no limit.
+	MaxNotes int
+}
+
+// allows returns true if the given voicing is within the span limits of the
+// options.
+func (opts VoicingOptions) allows(v Voicing) bool {
+	if len(v) == 0 {
+		return false
+	}
+	if opts.MaxSpan > 0 && v[len(v)-1].MIDINumber()-v[0].MIDINumber() > opts.MaxSpan {
+		return false
+	}
+	if opts.MaxHandSpan > 0 {
+		hands := opts.Hands
+		if hands == 0 {
+			hands = 1
+		}
+		// greedily give each hand as many pitches as it can span
+		low := v[0].MIDINumber()
+		for _, p := range v[1:] {
+			if p.MIDINumber()-low > opts.MaxHandSpan {
+				hands--
+				low = p.MIDINumber()
+			}
+		}
+		if hands < 1 {
+			return false
+		}
+	}
+	return true
+}
+
+// omitNotes returns the given notes, in the same order, with tones omitted so
+// that there are at most max notes. (See Chord.VoicingsWithOptions.)
+func (ch *Chord) omitNotes(notes []Note, max int) []Note {
+	if len(notes) <= max {
+		return notes
+	}
+	third, seventh := ch.guideTones()
+	omit := make([]Note, 0, len(notes))
+	if fifth := ch.Triad.fifthTone(); fifth.Acc == Natural {
+		omit = append(omit, ch.toneNote(fifth))
+	}
+	omit = append(omit, ch.Root)
+	for i := len(notes) - 1; i >= 0; i-- {
+		if notes[i] != third && notes[i] != seventh {
+			omit = append(omit, notes[i])
+		}
+	}
+	omit = append(omit, seventh, third)
+
+	ret := append([]Note(nil), notes...)
+	for _, o := range omit {
+		if len(ret) <= max {
+			break
+		}
+		if ch.Bass.N != 0 && o.Cardinal() == ch.Bass.Cardinal() {
+			continue
+		}
+		for i, n := range ret {
+			if n == o {
+				ret = append(ret[:i], ret[i+1:]...)
+				break
+			}
+		}
+	}
+	return ret
+}
+
+// VoiceUnderMelody returns a voicing of the chord, in the style given by the
+// options, whose highest pitch is the given melody note. If the melody is one
+// of the chord's tones (in the given style), the voicing is one of the
+// chord's voicings with that pitch on top (see Chord.VoicingsWithOptions).
+// Otherwise, the melody is added above the highest voicing that is at least a
+// whole-step lower than it, so that it does not clash with the voicing's
+// highest pitch. When more than one voicing qualifies, the one with the
+// smallest span is used.
+//
+// This returns nil if the melody is outside the range given by the options or
+// if no voicing satisfies the options. The melody counts towards the limits
+// on span and number of notes.
+func VoiceUnderMelody(ch *Chord, top Pitch, opts VoicingOptions) Voicing {
+	if opts.Range == (PitchRange{}) {
+		opts.Range = PitchRange{Low: Pitch{Note: top.Note, Octave: top.Octave - 4}, High: top}
+	} else if !opts.Range.Contains(top) {
+		return nil
+	} else {
+		opts.Range.High = top
+	}
+
+	var best Voicing
+	for _, v := range ch.VoicingsWithOptions(opts) {
+		if v[len(v)-1].MIDINumber() == top.MIDINumber() &&
+			(best == nil || v[0].Compare(best[0]) > 0) {
+			best = v
+		}
+	}
+	if best != nil {
+		best[len(best)-1] = top
+		return best
+	}
+
+	// the melody is not a chord tone, so put the chord under it
+	under := opts
+	under.MaxSpan, under.MaxHandSpan = 0, 0
+	if opts.MaxNotes > 0 {
+		if opts.MaxNotes == 1 {
+			return nil
+		}
+		under.MaxNotes--
+	}
+	for _, v := range ch.VoicingsWithOptions(under) {
+		highest := v[len(v)-1]
+		if top.MIDINumber()-highest.MIDINumber() < 2 || !opts.allows(append(v, top)) {
+			continue
+		}
+		if best == nil {
+			best = v
+			continue
+		}
+		if c := highest.Compare(best[len(best)-1]); c > 0 || (c == 0 && v[0].Compare(best[0]) > 0) {
+			best = v
+		}
+	}
+	if best == nil {
+		return nil
+	}
+	return append(best, top)
+}
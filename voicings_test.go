@@ -0,0 +1,167 @@
+package chords
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestChord_Voicings(t *testing.T) {
+	register := PitchRange{Low: MustParsePitch("C4"), High: MustParsePitch("C5")}
+	cases := []struct {
+		chord string
+		style VoicingStyle
+		exp   string
+	}{
+		{"C", Close, "C4 E4 G4, E4 G4 C5"},
+		{"C△7", Close, "C4 E4 G4 B4, E4 G4 B4 C5"},
+		{"C△7/E", Close, "E4 G4 B4 C5"},
+		{"C/E", Close, "E4 G4 C5"},
+		{"D/C", Close, "C4 D4 F♯4 A4"},
+		{"C△7", Drop2, ""},
+		{"C△7", Drop3, ""},
+		{"C", Drop3, ""},
+	}
+	for _, tc := range cases {
+		var strs []string
+		for _, v := range MustParseChord(tc.chord).Voicings(tc.style, register) {
+			strs = append(strs, v.String())
+		}
+		if actual := strings.Join(strs, ", "); actual != tc.exp {
+			t.Errorf("Chord.Voicings for %s (%v) returned wrong value: %q != %q", tc.chord, tc.style, actual, tc.exp)
+		}
+	}
+
+	register = PitchRange{Low: MustParsePitch("E3"), High: MustParsePitch("G5")}
+	cases = []struct {
+		chord string
+		style VoicingStyle
+		exp   string
+	}{
+		{"C△7", Close, "E3 G3 B3 C4, G3 B3 C4 E4, B3 C4 E4 G4, C4 E4 G4 B4, E4 G4 B4 C5, G4 B4 C5 E5, B4 C5 E5 G5"},
+		{"C△7", Drop2, "E3 B3 C4 G4, G3 C4 E4 B4, B3 E4 G4 C5, C4 G4 B4 E5, E4 B4 C5 G5"},
+		{"C△7", Drop3, "E3 C4 G4 B4, G3 E4 B4 C5, B3 G4 C5 E5, C4 B4 E5 G5"},
+		{"C", Drop2, "E3 C4 G4, G3 E4 C5, C4 G4 E5, E4 C5 G5"},
+		{"C△7", Shell, "C4 E4 B4, C4 B4 E5"},
+		{"A-6/C", Shell, "A3 C4 F♯4, A3 F♯4 C5, A4 C5 F♯5"},
+		{"G7", GuideTones, "F3 B3, B3 F4, F4 B4, B4 F5"},
+		{"Dsus4", GuideTones, "G3 A3, A3 G4, G4 A4, A4 G5"},
+	}
+	for _, tc := range cases {
+		var strs []string
+		for _, v := range MustParseChord(tc.chord).Voicings(tc.style, register) {
+			strs = append(strs, v.String())
+		}
+		if actual := strings.Join(strs, ", "); actual != tc.exp {
+			t.Errorf("Chord.Voicings for %s (%v) returned wrong value: %q != %q", tc.chord, tc.style, actual, tc.exp)
+		}
+	}
+
+	if vs := MustParseChord("C").Voicings(Close, PitchRange{}); len(vs) == 0 || vs[0].Range().Low != MustParsePitch("C3") {
+		t.Errorf("wrong voicings with default range: %v", vs)
+	}
+}
+
+func TestChord_RootlessVoicing(t *testing.T) {
+	register := PitchRange{Low: MustParsePitch("G2"), High: MustParsePitch("A3")}
+	cases := []struct {
+		chord string
+		style VoicingStyle
+		exp   string
+	}{
+		{"C△7", RootlessA, "E3 G3 B3 D4"},
+		{"C△7", RootlessB, "B2 D3 E3 G3"},
+		{"G13", RootlessB, "F3 A3 B3 E4"},
+		{"C-6", RootlessA, "E♭3 G3 A3 D4"},
+		{"C7♭9/E", RootlessB, "B♭2 D♭3 E3 G3"},
+		{"Gsus4 7", RootlessA, "C3 D3 F3 A3"},
+		{"C", RootlessA, ""},
+	}
+	wide := PitchRange{Low: MustParsePitch("A2"), High: MustParsePitch("E4")}
+	for _, tc := range cases {
+		var strs []string
+		for _, v := range MustParseChord(tc.chord).Voicings(tc.style, wide) {
+			strs = append(strs, v.String())
+		}
+		if actual := strings.Join(strs, ", "); actual != tc.exp {
+			t.Errorf("Chord.Voicings for %s, %v returned wrong value: %q != %q", tc.chord, tc.style, actual, tc.exp)
+		}
+	}
+
+	// a ii-V-I alternates between A and B forms
+	for _, tc := range []struct{ chord, exp string }{
+		{"D-7", "F3 A3 C4 E4"},
+		{"G13", "F3 A3 B3 E4"},
+		{"C△7", "E3 G3 B3 D4"},
+		{"C", ""},
+	} {
+		if actual := MustParseChord(tc.chord).RootlessVoicing(PitchRange{}).String(); actual != tc.exp {
+			t.Errorf("Chord.RootlessVoicing for %s returned wrong value: %q != %q", tc.chord, actual, tc.exp)
+		}
+	}
+	if actual := MustParseChord("C△7").RootlessVoicing(register).String(); actual != "B2 D3 E3 G3" {
+		t.Errorf("wrong voicing for register %v: %s", register, actual)
+	}
+}
+
+func TestVoiceUnderMelody(t *testing.T) {
+	cases := []struct {
+		chord string
+		top   string
+		opts  VoicingOptions
+		exp   string
+	}{
+		{"C△7", "E5", VoicingOptions{}, "G4 B4 C5 E5"},
+		{"C△7", "E5", VoicingOptions{Style: Drop2}, "C4 G4 B4 E5"},
+		{"C△7", "D5", VoicingOptions{}, "E4 G4 B4 C5 D5"},
+		{"C△7", "A5", VoicingOptions{Style: Drop2}, "E4 B4 C5 G5 A5"},
+		{"C/E", "C5", VoicingOptions{}, "E4 G4 C5"},
+		{"D/C", "A4", VoicingOptions{MaxSpan: 9}, "C4 D4 F♯4 A4"},
+		{"D/C", "A4", VoicingOptions{MaxSpan: 8}, ""},
+		{"D-7", "E♭4", VoicingOptions{Style: RootlessA}, "F2 A2 C3 E3 E♭4"},
+		{"G13", "E4", VoicingOptions{Style: RootlessB}, "F3 A3 B3 E4"},
+	}
+	for _, tc := range cases {
+		v := VoiceUnderMelody(MustParseChord(tc.chord), MustParsePitch(tc.top), tc.opts)
+		if actual := v.String(); actual != tc.exp {
+			t.Errorf("VoiceUnderMelody for %s under %s (%+v) returned wrong value: %q != %q", tc.chord, tc.top, tc.opts, actual, tc.exp)
+		}
+	}
+}
+
+func TestChord_VoicingsWithOptions(t *testing.T) {
+	register := PitchRange{Low: MustParsePitch("C3"), High: MustParsePitch("C5")}
+	cases := []struct {
+		chord string
+		opts  VoicingOptions
+		exp   string
+	}{
+		{"C△7", VoicingOptions{Range: register, MaxNotes: 3}, "C3 E3 B3, E3 B3 C4, B3 C4 E4, C4 E4 B4, E4 B4 C5"},
+		{"C/G", VoicingOptions{Range: register, MaxNotes: 2}, "G3 E4"},
+		{"D/C", VoicingOptions{Range: register, MaxNotes: 3}, "C3 D3 F♯3, C3 F♯3 D4, C4 D4 F♯4"},
+		{"G7", VoicingOptions{Style: RootlessB, Range: register, MaxNotes: 3}, "F3 A3 B3, F4 A4 B4"},
+		{"C△7", VoicingOptions{Style: Drop2, Range: register, Hands: 2, MaxHandSpan: 5}, "B3 E4 G4 C5"},
+		{"C△7", VoicingOptions{Style: Drop2, Range: register, MaxHandSpan: 10}, ""},
+		{"C△7", VoicingOptions{Style: Drop3, Range: register, MaxSpan: 14}, ""},
+		{"C△7", VoicingOptions{Style: Drop3, Range: register, MaxSpan: 19}, "C3 B3 E4 G4, E3 C4 G4 B4, G3 E4 B4 C5"},
+		// four notes for a string quartet
+		{"C7♭9", VoicingOptions{Range: register, MaxNotes: 4, MaxSpan: 15}, "C3 E3 B♭3 D♭4"},
+	}
+	for _, tc := range cases {
+		var strs []string
+		for _, v := range MustParseChord(tc.chord).VoicingsWithOptions(tc.opts) {
+			strs = append(strs, v.String())
+		}
+		if actual := strings.Join(strs, ", "); actual != tc.exp {
+			t.Errorf("Chord.VoicingsWithOptions for %s (%+v) returned wrong value: %q != %q", tc.chord, tc.opts, actual, tc.exp)
+		}
+	}
+
+	opts := VoicingOptions{MaxNotes: 4, Hands: 2, MaxHandSpan: 12}
+	if v := VoiceUnderMelody(MustParseChord("C6"), MustParsePitch("D5"), opts); v.String() != "E4 A4 C5 D5" {
+		t.Errorf("wrong voicing under melody: %v", v)
+	}
+	opts.Range = register
+	if v := VoiceUnderMelody(MustParseChord("C6"), MustParsePitch("D5"), opts); v != nil {
+		t.Errorf("VoiceUnderMelody for out of range melody returned wrong value: %v", v)
+	}
+}